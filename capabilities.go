@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolCapability is the server capability a capability-dependent tool needs
+type toolCapability struct {
+	// Name of the feature in messages, e.g. "call hierarchy"
+	feature   string
+	supported func(*protocol.ServerCapabilities) bool
+}
+
+// toolCapabilities maps each capability-dependent tool to its check in internal/lsp/capabilities.go.
+// addTool consults it before every call, so a tool the server does not (or no longer) supports
+// reports that plainly instead of failing with a protocol error. Core tools are not listed.
+var toolCapabilities = map[string]toolCapability{
+	"definition":                {"definitions (textDocument/definition and workspace/symbol)", lsp.HasDefinitionSupport},
+	"definitions_batch":         {"definitions (textDocument/definition and workspace/symbol)", lsp.HasDefinitionSupport},
+	"references":                {"references", lsp.HasReferencesSupport},
+	"replace_symbol_references": {"references", lsp.HasReferencesSupport},
+	"symbol_overview": {"definitions and references", func(caps *protocol.ServerCapabilities) bool {
+		return lsp.HasDefinitionSupport(caps) && lsp.HasReferencesSupport(caps)
+	}},
+	"hover":                 {"hover", lsp.HasHoverSupport},
+	"macro_expansion":       {"hover", lsp.HasHoverSupport},
+	"rename_symbol":         {"rename", lsp.HasRenameSupport},
+	"code_actions":          {"code actions", lsp.HasCodeActionSupport},
+	"code_actions_for_file": {"code actions", lsp.HasCodeActionSupport},
+	"extract":               {"code actions", lsp.HasCodeActionSupport},
+	"refactorings": {"refactorings (refactor code actions)", func(caps *protocol.ServerCapabilities) bool {
+		return lsp.HasCodeActionKindSupport(caps, protocol.Refactor)
+	}},
+	"organize_imports": {"organize imports (source.organizeImports code actions)", func(caps *protocol.ServerCapabilities) bool {
+		return lsp.HasCodeActionKindSupport(caps, protocol.SourceOrganizeImports)
+	}},
+	"fix_all": {"fix all (source.fixAll code actions)", func(caps *protocol.ServerCapabilities) bool {
+		return lsp.HasCodeActionKindSupport(caps, protocol.SourceFixAll)
+	}},
+	"signature_help":     {"signature help", lsp.HasSignatureHelpSupport},
+	"completions":        {"completion", lsp.HasCompletionSupport},
+	"document_symbols":   {"document symbols", lsp.HasDocumentSymbolSupport},
+	"unused_symbols":     {"document symbols", lsp.HasDocumentSymbolSupport},
+	"symbol_at_position": {"document symbols", lsp.HasDocumentSymbolSupport},
+	"call_hierarchy":     {"call hierarchy", lsp.HasCallHierarchySupport},
+	"type_hierarchy":     {"type hierarchy", lsp.HasTypeHierarchySupport},
+	"monikers":           {"monikers", lsp.HasMonikerSupport},
+	"document_links":     {"document links", lsp.HasDocumentLinkSupport},
+	"document_colors":    {"document colors", lsp.HasDocumentColorSupport},
+	"execute_command":    {"execute command", lsp.HasExecuteCommandSupport},
+	"get_codelens":       {"code lens", lsp.HasCodeLensSupport},
+	"execute_codelens":   {"code lens", lsp.HasCodeLensSupport},
+}
+
+// unsupportedToolResult returns an error result naming the capability the server lacks for
+// toolName, or nil if the tool is supported or does not depend on a capability
+func (s *mcpServer) unsupportedToolResult(toolName string) *mcp.CallToolResult {
+	capability, ok := toolCapabilities[toolName]
+	if !ok || capability.supported(s.serverCapabilities()) {
+		return nil
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("This language server (%s) does not advertise %s support.",
+		s.serverDescription(), capability.feature))
+}
+
+// serverCapabilities returns the language server's capabilities, including those it
+// registered dynamically after initialization
+func (s *mcpServer) serverCapabilities() *protocol.ServerCapabilities {
+	return s.client().ServerCapabilities()
+}
+
+// isClangd reports whether the language server is clangd, whose hovers include macro
+// expansions
+func (s *mcpServer) isClangd() bool {
+	if info := s.lspServerInfo(); info != nil && info.Name != "" {
+		return strings.Contains(strings.ToLower(info.Name), "clangd")
+	}
+	return strings.Contains(strings.ToLower(filepath.Base(s.config.lspCommand)), "clangd")
+}
+
+// serverDescription names the language server for messages, e.g. "clangd 15.0.0", using the
+// serverInfo from the initialize result and falling back to the command name or address
+func (s *mcpServer) serverDescription() string {
+	if info := s.lspServerInfo(); info != nil && info.Name != "" {
+		return strings.TrimSpace(info.Name + " " + info.Version)
+	}
+	if s.config.lspAddress != "" {
+		return s.config.lspAddress
+	}
+	return filepath.Base(s.config.lspCommand)
+}
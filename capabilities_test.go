@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// allCapabilities advertises every capability registerTools checks
+const allCapabilities = `{
+	"definitionProvider": true,
+	"workspaceSymbolProvider": true,
+	"referencesProvider": true,
+	"hoverProvider": true,
+	"renameProvider": true,
+	"codeActionProvider": {"codeActionKinds": ["quickfix", "refactor", "source.organizeImports", "source.fixAll"]},
+	"signatureHelpProvider": {},
+	"completionProvider": {},
+	"documentSymbolProvider": true,
+	"callHierarchyProvider": true,
+	"typeHierarchyProvider": true,
+	"monikerProvider": true,
+	"documentLinkProvider": {},
+	"colorProvider": true,
+	"executeCommandProvider": {"commands": []},
+	"codeLensProvider": {}
+}`
+
+// registeredTools returns the names of the tools registerTools registers for caps
+func registeredTools(t *testing.T, caps *protocol.ServerCapabilities) map[string]bool {
+	t.Helper()
+	// clangd, so that macro_expansion is registered too
+	s, err := newServer(&config{lspCommand: "clangd"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(s.cancelFunc)
+	// Some tools describe themselves using the client's capabilities
+	mock := lsptesting.NewMockServer()
+	t.Cleanup(func() { _ = mock.Close() })
+	mock.Client.SetServerCapabilities(caps)
+	s.lspClient = mock.Client
+	s.mcpServer = server.NewMCPServer("test", "v0.0.0", server.WithToolCapabilities(true))
+	if err := s.registerTools(caps); err != nil {
+		t.Fatalf("Failed to register tools: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for name := range s.toolStats {
+		names[name] = true
+	}
+	return names
+}
+
+func TestToolCapabilitiesCoverRegisteredTools(t *testing.T) {
+	var caps protocol.ServerCapabilities
+	if err := json.Unmarshal([]byte(allCapabilities), &caps); err != nil {
+		t.Fatalf("Failed to parse capabilities: %v", err)
+	}
+	core := registeredTools(t, nil)
+	all := registeredTools(t, &caps)
+
+	for name := range all {
+		_, gated := toolCapabilities[name]
+		if !core[name] && !gated {
+			t.Errorf("Tool %s depends on a server capability but has no entry in toolCapabilities", name)
+		}
+		if core[name] && gated {
+			t.Errorf("Tool %s is registered without capabilities but has an entry in toolCapabilities", name)
+		}
+	}
+	for name := range toolCapabilities {
+		if !all[name] {
+			t.Errorf("toolCapabilities has an entry for %s, which is not a registered tool", name)
+		}
+	}
+}
+
+func TestUnsupportedToolResult(t *testing.T) {
+	mock := lsptesting.NewMockServer()
+	t.Cleanup(func() { _ = mock.Close() })
+	mock.Client.SetServerCapabilities(&protocol.ServerCapabilities{
+		HoverProvider: &protocol.Or_ServerCapabilities_hoverProvider{Value: true},
+	})
+
+	s, err := newServer(&config{lspCommand: "/usr/local/bin/clangd"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(s.cancelFunc)
+	s.lspClient = mock.Client
+
+	tests := []struct {
+		tool     string
+		expected string // Empty if the tool is supported
+	}{
+		{tool: "hover"},
+		{tool: "read_range"},
+		{tool: "call_hierarchy", expected: "This language server (clangd) does not advertise call hierarchy support."},
+		{tool: "fix_all", expected: "This language server (clangd) does not advertise fix all (source.fixAll code actions) support."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			result := s.unsupportedToolResult(tt.tool)
+			if tt.expected == "" {
+				if result != nil {
+					t.Errorf("Expected %s to be supported, got %#v", tt.tool, result)
+				}
+				return
+			}
+			if result == nil || !result.IsError || len(result.Content) == 0 {
+				t.Fatalf("Expected an error result for %s, got %#v", tt.tool, result)
+			}
+			if text, _ := result.Content[0].(mcp.TextContent); text.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, text.Text)
+			}
+		})
+	}
+}
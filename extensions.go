@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// serverExtensions are the file extensions handled by well-known language servers, matched
+// by a substring of the server's name or command
+var serverExtensions = []struct {
+	server     string
+	extensions []string
+}{
+	{"gopls", []string{".go", ".mod", ".sum", ".work"}},
+	{"rust-analyzer", []string{".rs"}},
+	{"pyright", []string{".py", ".pyi"}},
+	{"pylsp", []string{".py", ".pyi"}},
+	{"jedi", []string{".py", ".pyi"}},
+	{"typescript", []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}},
+	{"clangd", []string{".c", ".h", ".cc", ".cpp", ".cxx", ".c++", ".hh", ".hpp", ".hxx", ".h++", ".inc", ".m", ".mm", ".cu", ".cuh"}},
+}
+
+// textTools read or write files without asking the language server, so they accept files
+// of any type. syntax_tree checks files against its own grammars instead.
+var textTools = map[string]bool{
+	"edit_file":   true,
+	"read_range":  true,
+	"syntax_tree": true,
+}
+
+// parseExtensions parses the comma separated --extensions flag, adding missing leading dots.
+// "*" accepts every file.
+func parseExtensions(value string) []string {
+	var extensions []string
+	for _, extension := range strings.Split(value, ",") {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension == "" {
+			continue
+		}
+		if extension != "*" && !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		extensions = append(extensions, extension)
+	}
+	return extensions
+}
+
+// fileExtensions returns the file extensions the language server handles: those given with
+// --extensions, or the defaults for a well-known server identified by its serverInfo name
+// or command. It returns nil if they are unknown, and then every file is accepted.
+func (s *mcpServer) fileExtensions() []string {
+	if len(s.config.extensions) > 0 {
+		if s.config.extensions[0] == "*" {
+			return nil
+		}
+		return s.config.extensions
+	}
+
+	var names []string
+	if info := s.lspServerInfo(); info != nil && info.Name != "" {
+		names = append(names, strings.ToLower(info.Name))
+	}
+	if s.config.lspCommand != "" {
+		names = append(names, strings.ToLower(filepath.Base(s.config.lspCommand)))
+	}
+	for _, name := range names {
+		for _, defaults := range serverExtensions {
+			if strings.Contains(name, defaults.server) {
+				return defaults.extensions
+			}
+		}
+	}
+	return nil
+}
+
+// unsupportedExtensionResult returns an error result if a tool that sends filePath to the
+// language server is called with a file type the server doesn't handle, or nil
+func (s *mcpServer) unsupportedExtensionResult(toolName, filePath string) *mcp.CallToolResult {
+	extensions := s.fileExtensions()
+	if textTools[toolName] || len(extensions) == 0 {
+		return nil
+	}
+
+	extension := strings.ToLower(filepath.Ext(filePath))
+	for _, supported := range extensions {
+		if extension == supported {
+			return nil
+		}
+	}
+
+	fileType := fmt.Sprintf("extension %q", extension)
+	if extension == "" {
+		fileType = "no extension"
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("%s has %s, which this language server (%s) does not handle. Supported extensions: %s. Pass --extensions to change them.",
+		filePath, fileType, s.serverDescription(), strings.Join(extensions, ", ")))
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestUnsupportedExtensionResult(t *testing.T) {
+	s, err := newServer(&config{lspCommand: "/usr/bin/gopls"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(s.cancelFunc)
+
+	tests := []struct {
+		name     string
+		tool     string
+		filePath string
+		rejected bool
+	}{
+		{name: "handled extension", tool: "hover", filePath: "/src/main.go"},
+		{name: "unhandled extension", tool: "hover", filePath: "/src/point.c", rejected: true},
+		{name: "text tool", tool: "read_range", filePath: "/src/notes.txt"},
+		// Parsed locally, so gopls not handling C doesn't matter
+		{name: "syntax tree", tool: "syntax_tree", filePath: "/src/point.c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.unsupportedExtensionResult(tt.tool, tt.filePath)
+			if rejected := result != nil; rejected != tt.rejected {
+				t.Errorf("unsupportedExtensionResult(%q, %q) rejected = %v, expected %v", tt.tool, tt.filePath, rejected, tt.rejected)
+			}
+		})
+	}
+}
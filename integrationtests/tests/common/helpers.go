@@ -1,10 +1,12 @@
 package common
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -200,11 +202,47 @@ func FindRepoRoot() (string, error) {
 	}
 }
 
+// updateSnapshots rewrites snapshot files with the actual tool output, e.g.
+// go test ./integrationtests/tests/go/... -update
+var updateSnapshots = flag.Bool("update", false, "update snapshot files with the actual tool output")
+
 // SnapshotTest compares the actual result against an expected result file
-// If the file doesn't exist or UPDATE_SNAPSHOTS=true env var is set, it will update the snapshot
+// If the -update flag or UPDATE_SNAPSHOTS=true env var is set, it will write the snapshot instead.
+// A missing snapshot fails the test otherwise.
 func SnapshotTest(t *testing.T, languageName, toolName, testName, actualResult string) {
 	// Normalize paths in the result to avoid system-specific paths in snapshots
 	actualResult = normalizePaths(t, actualResult, languageName)
+	compareSnapshot(t, languageName, toolName, testName, actualResult)
+}
+
+// GoldenTest compares a tool's output against its snapshot like SnapshotTest, but replaces
+// exactly the suite's workspace directory with /TEST_OUTPUT/workspace, so the text around
+// paths (e.g. "Diagnostics in /abs/path/main.go:") is kept and snapshots stay portable
+func GoldenTest(t *testing.T, suite *TestSuite, toolName, testName, actualResult string) {
+	actualResult = normalizeWorkspacePaths(actualResult, suite.WorkspaceDir)
+	compareSnapshot(t, suite.LanguageName, toolName, testName, actualResult)
+}
+
+// normalizeWorkspacePaths replaces workspaceDir, and its symlink-resolved form, in output
+// with a placeholder
+func normalizeWorkspacePaths(output, workspaceDir string) string {
+	const placeholder = "/TEST_OUTPUT/workspace"
+
+	dirs := []string{filepath.Clean(workspaceDir)}
+	if resolved, err := filepath.EvalSymlinks(workspaceDir); err == nil && resolved != dirs[0] {
+		dirs = append(dirs, resolved)
+	}
+	// Replace the longer path first in case one contains the other, e.g. /tmp and /private/tmp
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		output = strings.ReplaceAll(output, dir, placeholder)
+	}
+	return output
+}
+
+// compareSnapshot compares actualResult against integrationtests/snapshots/<language>/<tool>/<test>.snap
+func compareSnapshot(t *testing.T, languageName, toolName, testName, actualResult string) {
+	t.Helper()
 
 	// Get the absolute path to the snapshots directory
 	repoRoot, err := FindRepoRoot()
@@ -214,28 +252,32 @@ func SnapshotTest(t *testing.T, languageName, toolName, testName, actualResult s
 
 	// Build path based on language/tool/testName hierarchy
 	snapshotDir := filepath.Join(repoRoot, "integrationtests", "snapshots", languageName, toolName)
-	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
-		t.Fatalf("Failed to create snapshots directory: %v", err)
-	}
 
 	snapshotFile := filepath.Join(snapshotDir, testName+".snap")
 
 	// Use a package-level flag to control snapshot updates
-	updateFlag := os.Getenv("UPDATE_SNAPSHOTS") == "true"
-
-	// If snapshot doesn't exist or update flag is set, write the snapshot
-	_, err = os.Stat(snapshotFile)
-	if os.IsNotExist(err) || updateFlag {
+	updateFlag := *updateSnapshots || os.Getenv("UPDATE_SNAPSHOTS") == "true"
+
+	// Write the snapshot if the update flag is set. A missing snapshot is a failure
+	// otherwise, so that a test can't pass by creating its own expectation.
+	_, statErr := os.Stat(snapshotFile)
+	if updateFlag {
+		if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+			t.Fatalf("Failed to create snapshots directory: %v", err)
+		}
 		if err := os.WriteFile(snapshotFile, []byte(actualResult), 0644); err != nil {
 			t.Fatalf("Failed to write snapshot: %v", err)
 		}
-		if os.IsNotExist(err) {
+		if os.IsNotExist(statErr) {
 			t.Logf("Created new snapshot: %s", snapshotFile)
 		} else {
 			t.Logf("Updated snapshot: %s", snapshotFile)
 		}
 		return
 	}
+	if os.IsNotExist(statErr) {
+		t.Fatalf("Snapshot %s does not exist, run the test with -update or UPDATE_SNAPSHOTS=true to create it.\nActual:\n%s", snapshotFile, actualResult)
+	}
 
 	// Read the expected result
 	expectedBytes, err := os.ReadFile(snapshotFile)
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeWorkspacePaths(t *testing.T) {
+	workspaceDir := "/home/user/src/mcp-language-server/integrationtests/test-output/go/TestHover/workspace"
+
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "path with surrounding text",
+			output:   "Diagnostics in " + workspaceDir + "/main.go:\n",
+			expected: "Diagnostics in /TEST_OUTPUT/workspace/main.go:\n",
+		},
+		{
+			name:     "several paths on one line",
+			output:   workspaceDir + "/a.go -> " + workspaceDir + "/b.go",
+			expected: "/TEST_OUTPUT/workspace/a.go -> /TEST_OUTPUT/workspace/b.go",
+		},
+		{
+			name:     "file URI",
+			output:   "file://" + workspaceDir + "/main.go",
+			expected: "file:///TEST_OUTPUT/workspace/main.go",
+		},
+		{
+			name:     "no paths",
+			output:   "No references found",
+			expected: "No references found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeWorkspacePaths(tt.output, workspaceDir); got != tt.expected {
+				t.Errorf("normalizeWorkspacePaths() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeWorkspacePathsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// The temp dir may itself be behind a symlink, e.g. /var -> /private/var on macOS
+	resolved, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := link + "/main.go and " + resolved + "/main.go"
+	expected := "/TEST_OUTPUT/workspace/main.go and /TEST_OUTPUT/workspace/main.go"
+	if got := normalizeWorkspacePaths(output, link); got != expected {
+		t.Errorf("normalizeWorkspacePaths() = %q, expected %q", got, expected)
+	}
+}
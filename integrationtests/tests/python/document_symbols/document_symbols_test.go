@@ -0,0 +1,106 @@
+package document_symbols_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/common"
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/python/internal"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/tools"
+)
+
+// TestDocumentSymbols tests the symbol outline returned by the Python language server
+func TestDocumentSymbols(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		opts           tools.DocumentSymbolsOptions
+		expectedText   []string // Text that should be in the outline
+		unexpectedText []string // Text that should NOT be in the outline (optional)
+		snapshotName   string
+	}{
+		{
+			name: "Outline",
+			file: "main.py",
+			expectedText: []string{
+				"test_function",
+				"TestClass",
+				"test_method",
+				"static_method",
+				"DerivedClass",
+				"TEST_CONSTANT",
+				"main",
+			},
+			snapshotName: "outline",
+		},
+		{
+			name: "Signatures",
+			file: "main.py",
+			opts: tools.DocumentSymbolsOptions{Detail: tools.SymbolDetailSignatures},
+			expectedText: []string{
+				"def test_function(name: str) -> str:",
+				"class DerivedClass(BaseClass):",
+			},
+			snapshotName: "signatures",
+		},
+		{
+			name: "ClassesOnly",
+			file: "main.py",
+			opts: tools.DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Class}},
+			expectedText: []string{
+				"TestClass",
+				"BaseClass",
+				"DerivedClass",
+			},
+			unexpectedText: []string{"test_function", "TEST_CONSTANT"},
+			snapshotName:   "classes-only",
+		},
+		{
+			name: "TopLevelOnly",
+			file: "helper.py",
+			opts: tools.DocumentSymbolsOptions{MaxDepth: 1},
+			expectedText: []string{
+				"helper_function",
+			},
+			snapshotName: "top-level-only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Get a test suite
+			suite := internal.GetTestSuite(t)
+
+			ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+			defer cancel()
+
+			filePath := filepath.Join(suite.WorkspaceDir, tt.file)
+			err := suite.Client.OpenFile(ctx, filePath)
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", tt.file, err)
+			}
+
+			result, err := tools.GetDocumentSymbols(ctx, suite.Client, filePath, tt.opts)
+			if err != nil {
+				t.Fatalf("GetDocumentSymbols failed: %v", err)
+			}
+
+			for _, expected := range tt.expectedText {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected document symbols to contain %q but got: %s", expected, result)
+				}
+			}
+			for _, unexpected := range tt.unexpectedText {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("Expected document symbols NOT to contain %q but it was found: %s", unexpected, result)
+				}
+			}
+
+			common.GoldenTest(t, suite, "document_symbols", tt.snapshotName, result)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package completions_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/common"
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/typescript/internal"
+	"github.com/isaacphi/mcp-language-server/internal/tools"
+)
+
+// completionIndex returns the 1-indexed number GetCompletions printed for the item with the given label
+func completionIndex(t *testing.T, output, label string) int {
+	re := regexp.MustCompile(fmt.Sprintf(`(?m)^(\d+)\. \[[^\]]*\] %s$`, regexp.QuoteMeta(label)))
+	match := re.FindStringSubmatch(output)
+	if match == nil {
+		t.Fatalf("Completion %q not found in: %s", label, output)
+	}
+	index, _ := strconv.Atoi(match[1])
+	return index
+}
+
+// TestCompletions tests completion and completionItem/resolve handling with the TypeScript language server
+func TestCompletions(t *testing.T) {
+	t.Run("MemberCompletion", func(t *testing.T) {
+		suite := internal.GetTestSuite(t)
+
+		ctx, cancel := context.WithTimeout(suite.Context, 15*time.Second)
+		defer cancel()
+
+		content := `import { SharedClass } from "./helper";
+
+const instance = new SharedClass("test");
+instance.
+`
+		if err := suite.WriteFile("member_completion.ts", content); err != nil {
+			t.Fatalf("Failed to create member_completion.ts: %v", err)
+		}
+		filePath := filepath.Join(suite.WorkspaceDir, "member_completion.ts")
+		if err := suite.Client.OpenFile(ctx, filePath); err != nil {
+			t.Fatalf("Failed to open member_completion.ts: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+
+		// Cursor right after "instance."
+		result, err := tools.GetCompletions(ctx, suite.Client, filePath, 4, 10, 20, "", ".", []string{"."})
+		if err != nil {
+			t.Fatalf("GetCompletions failed: %v", err)
+		}
+
+		for _, expected := range []string{"getName", "getValue", "helperMethod"} {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Expected completions to contain %q but got: %s", expected, result)
+			}
+		}
+		if regexp.MustCompile(`(?m)\] name$`).MatchString(result) {
+			t.Errorf("Expected private member name not to be offered but got: %s", result)
+		}
+
+		common.GoldenTest(t, suite, "completions", "member", result)
+	})
+
+	t.Run("AutoImportOnResolve", func(t *testing.T) {
+		suite := internal.GetTestSuite(t)
+
+		ctx, cancel := context.WithTimeout(suite.Context, 15*time.Second)
+		defer cancel()
+
+		if err := suite.WriteFile("auto_import.ts", "const value = SharedConst\n"); err != nil {
+			t.Fatalf("Failed to create auto_import.ts: %v", err)
+		}
+		filePath := filepath.Join(suite.WorkspaceDir, "auto_import.ts")
+		for _, file := range []string{"helper.ts", "auto_import.ts"} {
+			if err := suite.Client.OpenFile(ctx, filepath.Join(suite.WorkspaceDir, file)); err != nil {
+				t.Fatalf("Failed to open %s: %v", file, err)
+			}
+		}
+		time.Sleep(3 * time.Second)
+
+		// Cursor right after "SharedConst". The import is only computed when the item is resolved.
+		list, err := tools.GetCompletions(ctx, suite.Client, filePath, 1, 26, 1000, "", "", nil)
+		if err != nil {
+			t.Fatalf("GetCompletions failed: %v", err)
+		}
+		index := completionIndex(t, list, "SharedConstant")
+
+		result, err := tools.ApplyCompletion(ctx, suite.Client, filePath, 1, 26, index, "")
+		if err != nil {
+			t.Fatalf("ApplyCompletion failed: %v", err)
+		}
+
+		if !strings.Contains(result, "Additional edits") {
+			t.Errorf("Expected the resolved item to add an import but got: %s", result)
+		}
+
+		fileContent, err := suite.ReadFile("auto_import.ts")
+		if err != nil {
+			t.Fatalf("Failed to read auto_import.ts: %v", err)
+		}
+		if !strings.Contains(fileContent, "const value = SharedConstant") {
+			t.Errorf("Expected the completion to be inserted but got: %s", fileContent)
+		}
+		if !strings.Contains(fileContent, "import { SharedConstant } from") || !strings.Contains(fileContent, "./helper") {
+			t.Errorf("Expected an import of SharedConstant from ./helper but got: %s", fileContent)
+		}
+
+		common.GoldenTest(t, suite, "completions", "auto-import", fileContent)
+	})
+}
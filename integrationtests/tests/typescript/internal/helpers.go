@@ -2,6 +2,7 @@
 package internal
 
 import (
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -24,6 +25,11 @@ func GetTestSuite(t *testing.T) *common.TestSuite {
 		InitializeTimeMs: 2000, // 2 seconds
 	}
 
+	// typescript-language-server is an optional dependency, skip rather than fail where it isn't installed
+	if _, err := exec.LookPath(config.Command); err != nil {
+		t.Skipf("%s not found in PATH, install typescript-language-server to run the TypeScript tests", config.Command)
+	}
+
 	// Create a test suite
 	suite := common.NewTestSuite(t, config)
 
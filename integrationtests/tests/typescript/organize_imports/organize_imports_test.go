@@ -0,0 +1,89 @@
+package organize_imports_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/common"
+	"github.com/isaacphi/mcp-language-server/integrationtests/tests/typescript/internal"
+	"github.com/isaacphi/mcp-language-server/internal/tools"
+)
+
+// TestOrganizeImports tests the source.organizeImports code action with the TypeScript language server
+func TestOrganizeImports(t *testing.T) {
+	t.Run("RemovesUnusedImports", func(t *testing.T) {
+		suite := internal.GetTestSuite(t)
+
+		ctx, cancel := context.WithTimeout(suite.Context, 15*time.Second)
+		defer cancel()
+
+		content := `import { TestFunction } from "./main";
+import { SharedFunction, SharedConstant } from "./helper";
+
+console.log(SharedFunction());
+`
+		if err := suite.WriteFile("unorganized_imports.ts", content); err != nil {
+			t.Fatalf("Failed to create unorganized_imports.ts: %v", err)
+		}
+		filePath := filepath.Join(suite.WorkspaceDir, "unorganized_imports.ts")
+		if err := suite.Client.OpenFile(ctx, filePath); err != nil {
+			t.Fatalf("Failed to open unorganized_imports.ts: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+
+		result, err := tools.OrganizeImports(ctx, suite.Client, filePath)
+		if err != nil {
+			t.Fatalf("OrganizeImports failed: %v", err)
+		}
+
+		if !strings.Contains(result, "Removed:") {
+			t.Errorf("Expected unused imports to be removed but got: %s", result)
+		}
+
+		common.GoldenTest(t, suite, "organize_imports", "removes-unused", result)
+
+		fileContent, err := suite.ReadFile("unorganized_imports.ts")
+		if err != nil {
+			t.Fatalf("Failed to read unorganized_imports.ts: %v", err)
+		}
+		if strings.Contains(fileContent, "TestFunction") || strings.Contains(fileContent, "SharedConstant") {
+			t.Errorf("Expected unused imports to be gone but got: %s", fileContent)
+		}
+		if !strings.Contains(fileContent, "SharedFunction") {
+			t.Errorf("Expected the used import to be kept but got: %s", fileContent)
+		}
+	})
+
+	t.Run("AlreadyOrganized", func(t *testing.T) {
+		suite := internal.GetTestSuite(t)
+
+		ctx, cancel := context.WithTimeout(suite.Context, 15*time.Second)
+		defer cancel()
+
+		filePath := filepath.Join(suite.WorkspaceDir, "clean.ts")
+		if err := suite.Client.OpenFile(ctx, filePath); err != nil {
+			t.Fatalf("Failed to open clean.ts: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+
+		before, err := suite.ReadFile("clean.ts")
+		if err != nil {
+			t.Fatalf("Failed to read clean.ts: %v", err)
+		}
+
+		if _, err := tools.OrganizeImports(ctx, suite.Client, filePath); err != nil {
+			t.Fatalf("OrganizeImports failed: %v", err)
+		}
+
+		after, err := suite.ReadFile("clean.ts")
+		if err != nil {
+			t.Fatalf("Failed to read clean.ts: %v", err)
+		}
+		if before != after {
+			t.Errorf("Expected clean.ts to be unchanged but got: %s", after)
+		}
+	})
+}
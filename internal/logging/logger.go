@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -87,18 +88,9 @@ func init() {
 	ComponentLevels[LSPWire] = DefaultMinLevel
 
 	// Parse log level from environment variable
-	if level := os.Getenv("LOG_LEVEL"); level != "" {
-		switch strings.ToUpper(level) {
-		case "DEBUG":
-			DefaultMinLevel = LevelDebug
-		case "INFO":
-			DefaultMinLevel = LevelInfo
-		case "WARN":
-			DefaultMinLevel = LevelWarn
-		case "ERROR":
-			DefaultMinLevel = LevelError
-		case "FATAL":
-			DefaultMinLevel = LevelFatal
+	if levelStr := os.Getenv("LOG_LEVEL"); levelStr != "" {
+		if level, err := ParseLevel(levelStr); err == nil {
+			DefaultMinLevel = level
 		}
 
 		// Set all components to this level by default
@@ -116,21 +108,8 @@ func init() {
 			}
 
 			comp := Component(strings.TrimSpace(compAndLevel[0]))
-			levelStr := strings.ToUpper(strings.TrimSpace(compAndLevel[1]))
-
-			var level LogLevel
-			switch levelStr {
-			case "DEBUG":
-				level = LevelDebug
-			case "INFO":
-				level = LevelInfo
-			case "WARN":
-				level = LevelWarn
-			case "ERROR":
-				level = LevelError
-			case "FATAL":
-				level = LevelFatal
-			default:
+			level, err := ParseLevel(compAndLevel[1])
+			if err != nil {
 				continue
 			}
 
@@ -151,6 +130,24 @@ func init() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
 }
 
+// ParseLevel converts a level name such as "debug" or "WARN" to a LogLevel
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	default:
+		return 0, fmt.Errorf("unknown log level: %s", name)
+	}
+}
+
 // Logger is the interface for component-specific logging
 type Logger interface {
 	Debug(format string, v ...any)
@@ -250,15 +247,43 @@ func SetGlobalLevel(level LogLevel) {
 	}
 }
 
-// SetWriter sets the writer for log output
+// SetWriter sets the writer for log output. Stdout carries the MCP protocol,
+// so logs are sent to stderr instead if w is os.Stdout.
 func SetWriter(w io.Writer) {
 	logMu.Lock()
 	defer logMu.Unlock()
 
+	if w == os.Stdout {
+		w = os.Stderr
+	}
 	Writer = w
 	log.SetOutput(Writer)
 }
 
+// Components returns the names of all components whose level can be set
+func Components() []Component {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	components := make([]Component, 0, len(ComponentLevels))
+	for comp := range ComponentLevels {
+		components = append(components, comp)
+	}
+	slices.Sort(components)
+	return components
+}
+
+// GetLevel returns the minimum log level of a component
+func GetLevel(component Component) LogLevel {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if level, ok := ComponentLevels[component]; ok {
+		return level
+	}
+	return DefaultMinLevel
+}
+
 // SetupFileLogging configures logging to a file in addition to stderr
 func SetupFileLogging(filePath string) error {
 	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -274,6 +299,21 @@ func SetupFileLogging(filePath string) error {
 	return nil
 }
 
+// RedirectToFile sends log output only to a file, keeping stderr clean
+func RedirectToFile(filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	Writer = file
+	log.SetOutput(Writer)
+	return nil
+}
+
 // SetupTestLogging configures logging for tests
 func SetupTestLogging(captureOutput io.Writer) {
 	logMu.Lock()
@@ -3,6 +3,7 @@ package logging
 import (
 	"bytes"
 	"maps"
+	"os"
 	"strings"
 	"testing"
 )
@@ -106,3 +107,46 @@ func TestLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LogLevel
+		wantErr  bool
+	}{
+		{input: "debug", expected: LevelDebug},
+		{input: "INFO", expected: LevelInfo},
+		{input: " Warn ", expected: LevelWarn},
+		{input: "warning", expected: LevelWarn},
+		{input: "error", expected: LevelError},
+		{input: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if level != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, level)
+			}
+		})
+	}
+}
+
+func TestSetWriterNeverUsesStdout(t *testing.T) {
+	originalWriter := Writer
+	defer SetWriter(originalWriter)
+
+	SetWriter(os.Stdout)
+	if Writer != os.Stderr {
+		t.Errorf("Expected logs to be redirected to stderr")
+	}
+}
@@ -1,6 +1,10 @@
 package lsp
 
-import "github.com/isaacphi/mcp-language-server/internal/protocol"
+import (
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
 
 // HasDefinitionSupport checks if the server supports textDocument/definition
 // AND workspace/symbol (both required by our definition tool implementation).
@@ -68,6 +72,20 @@ func HasCallHierarchySupport(caps *protocol.ServerCapabilities) bool {
 		caps.CallHierarchyProvider.Value != nil
 }
 
+// HasTypeHierarchySupport checks if the server supports type hierarchy
+// (textDocument/prepareTypeHierarchy, typeHierarchy/supertypes, typeHierarchy/subtypes).
+//
+// Type Hierarchy was added in LSP 3.17.0.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasTypeHierarchySupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.TypeHierarchyProvider != nil &&
+		caps.TypeHierarchyProvider.Value != nil
+}
+
 // HasWorkspaceSymbolSupport checks if the server supports workspace/symbol.
 //
 // Used by definition tool as a dependency check.
@@ -103,6 +121,54 @@ func HasCodeActionSupport(caps *protocol.ServerCapabilities) bool {
 	return caps.CodeActionProvider != nil
 }
 
+// HasCodeActionKindSupport checks if the server offers code actions of the given kind,
+// e.g. source.organizeImports.
+//
+// CodeActionProvider is interface{} type - can be bool or CodeActionOptions, which decodes
+// as a map. Servers that don't list codeActionKinds may offer any kind; a listed parent
+// kind (e.g. "source") covers its sub-kinds.
+func HasCodeActionKindSupport(caps *protocol.ServerCapabilities, kind protocol.CodeActionKind) bool {
+	if caps == nil {
+		return false
+	}
+
+	var kinds []string
+	switch v := caps.CodeActionProvider.(type) {
+	case bool:
+		return v
+	case protocol.CodeActionOptions:
+		if len(v.CodeActionKinds) == 0 {
+			return true
+		}
+		for _, k := range v.CodeActionKinds {
+			kinds = append(kinds, string(k))
+		}
+	case map[string]interface{}:
+		switch list := v["codeActionKinds"].(type) {
+		case []string:
+			kinds = list
+		case []interface{}:
+			for _, k := range list {
+				if str, ok := k.(string); ok {
+					kinds = append(kinds, str)
+				}
+			}
+		}
+		if _, listed := v["codeActionKinds"]; !listed {
+			return true
+		}
+	default:
+		return false
+	}
+
+	for _, k := range kinds {
+		if string(kind) == k || strings.HasPrefix(string(kind), k+".") {
+			return true
+		}
+	}
+	return false
+}
+
 // HasSignatureHelpSupport checks if the server supports textDocument/signatureHelp.
 //
 // SignatureHelpProvider is *SignatureHelpOptions type.
@@ -125,6 +191,159 @@ func HasCodeLensSupport(caps *protocol.ServerCapabilities) bool {
 	return caps.CodeLensProvider != nil
 }
 
+// HasCompletionSupport checks if the server supports textDocument/completion.
+//
+// CompletionProvider is *CompletionOptions type.
+// Simple nil check is sufficient (pointer type, not Or_* type).
+func HasCompletionSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.CompletionProvider != nil
+}
+
+// HasMonikerSupport checks if the server supports textDocument/moniker.
+//
+// Moniker was added in LSP 3.16.0.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasMonikerSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.MonikerProvider != nil &&
+		caps.MonikerProvider.Value != nil
+}
+
+// HasSelectionRangeSupport checks if the server supports textDocument/selectionRange.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasSelectionRangeSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.SelectionRangeProvider != nil &&
+		caps.SelectionRangeProvider.Value != nil
+}
+
+// HasDocumentLinkSupport checks if the server supports textDocument/documentLink.
+//
+// DocumentLinkProvider is *DocumentLinkOptions type.
+// Simple nil check is sufficient (pointer type, not Or_* type).
+func HasDocumentLinkSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.DocumentLinkProvider != nil
+}
+
+// HasDocumentColorSupport checks if the server supports textDocument/documentColor.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasDocumentColorSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.ColorProvider != nil &&
+		caps.ColorProvider.Value != nil
+}
+
+// HasOnTypeFormattingSupport checks if the server supports textDocument/onTypeFormatting.
+//
+// DocumentOnTypeFormattingProvider is *DocumentOnTypeFormattingOptions type.
+// Simple nil check is sufficient (pointer type, not Or_* type).
+func HasOnTypeFormattingSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.DocumentOnTypeFormattingProvider != nil
+}
+
+// HasExecuteCommandSupport checks if the server supports workspace/executeCommand.
+//
+// ExecuteCommandProvider is *ExecuteCommandOptions type.
+// Simple nil check is sufficient (pointer type, not Or_* type).
+func HasExecuteCommandSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.ExecuteCommandProvider != nil
+}
+
+// HasPullDiagnosticsSupport checks if the server supports textDocument/diagnostic.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasPullDiagnosticsSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.DiagnosticProvider != nil &&
+		caps.DiagnosticProvider.Value != nil
+}
+
+// TextDocumentSyncKind returns how the server wants document changes sent in didChange.
+//
+// TextDocumentSync is interface{} type - can be a bare TextDocumentSyncKind or
+// TextDocumentSyncOptions, which decode as a number and a map respectively.
+func TextDocumentSyncKind(caps *protocol.ServerCapabilities) protocol.TextDocumentSyncKind {
+	if caps == nil {
+		return protocol.None
+	}
+
+	switch v := caps.TextDocumentSync.(type) {
+	case float64:
+		return protocol.TextDocumentSyncKind(v)
+	case protocol.TextDocumentSyncKind:
+		return v
+	case protocol.TextDocumentSyncOptions:
+		return v.Change
+	case *protocol.TextDocumentSyncOptions:
+		if v != nil {
+			return v.Change
+		}
+	case map[string]interface{}:
+		if change, ok := v["change"].(float64); ok {
+			return protocol.TextDocumentSyncKind(change)
+		}
+	}
+	return protocol.None
+}
+
+// TextDocumentSaveOptions returns the server's textDocumentSync.save options, or nil if the
+// server does not want textDocument/didSave notifications.
+//
+// save is only present when TextDocumentSync is TextDocumentSyncOptions, and can itself be
+// bool or SaveOptions (decoded as a map).
+func TextDocumentSaveOptions(caps *protocol.ServerCapabilities) *protocol.SaveOptions {
+	if caps == nil {
+		return nil
+	}
+
+	var save interface{}
+	switch v := caps.TextDocumentSync.(type) {
+	case protocol.TextDocumentSyncOptions:
+		return v.Save
+	case *protocol.TextDocumentSyncOptions:
+		if v != nil {
+			return v.Save
+		}
+		return nil
+	case map[string]interface{}:
+		save = v["save"]
+	}
+
+	switch v := save.(type) {
+	case bool:
+		if v {
+			return &protocol.SaveOptions{}
+		}
+	case map[string]interface{}:
+		includeText, _ := v["includeText"].(bool)
+		return &protocol.SaveOptions{IncludeText: includeText}
+	}
+	return nil
+}
+
 // AlwaysSupported returns true for core tools that don't require capability checks.
 //
 // Core tools:
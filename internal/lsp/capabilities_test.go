@@ -183,6 +183,43 @@ func TestHasDocumentSymbolSupport(t *testing.T) {
 	}
 }
 
+func TestHasSelectionRangeSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "selection ranges supported",
+			caps: &protocol.ServerCapabilities{
+				SelectionRangeProvider: &protocol.Or_ServerCapabilities_selectionRangeProvider{
+					Value: true,
+				},
+			},
+			expected: true,
+		},
+		{
+			name:     "selection ranges not advertised",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasSelectionRangeSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasSelectionRangeSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestHasCallHierarchySupport(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -219,6 +256,47 @@ func TestHasCallHierarchySupport(t *testing.T) {
 	}
 }
 
+func TestHasTypeHierarchySupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "type hierarchy supported",
+			caps: &protocol.ServerCapabilities{
+				TypeHierarchyProvider: &protocol.Or_ServerCapabilities_typeHierarchyProvider{
+					Value: true,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "type hierarchy Value nil",
+			caps: &protocol.ServerCapabilities{
+				TypeHierarchyProvider: &protocol.Or_ServerCapabilities_typeHierarchyProvider{
+					Value: nil,
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "type hierarchy missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasTypeHierarchySupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasTypeHierarchySupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestHasWorkspaceSymbolSupport(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -396,3 +474,430 @@ func TestHasCodeLensSupport(t *testing.T) {
 		})
 	}
 }
+
+func TestHasCompletionSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "completion supported",
+			caps: &protocol.ServerCapabilities{
+				CompletionProvider: &protocol.CompletionOptions{},
+			},
+			expected: true,
+		},
+		{
+			name: "completion not supported",
+			caps: &protocol.ServerCapabilities{
+				CompletionProvider: nil,
+			},
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasCompletionSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasCompletionSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasMonikerSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "moniker supported",
+			caps: &protocol.ServerCapabilities{
+				MonikerProvider: &protocol.Or_ServerCapabilities_monikerProvider{
+					Value: true,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "moniker Value nil",
+			caps: &protocol.ServerCapabilities{
+				MonikerProvider: &protocol.Or_ServerCapabilities_monikerProvider{
+					Value: nil,
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "moniker missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasMonikerSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasMonikerSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasDocumentLinkSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "documentlink supported",
+			caps: &protocol.ServerCapabilities{
+				DocumentLinkProvider: &protocol.DocumentLinkOptions{},
+			},
+			expected: true,
+		},
+		{
+			name:     "documentlink missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasDocumentLinkSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasDocumentLinkSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasDocumentColorSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "documentcolor supported",
+			caps: &protocol.ServerCapabilities{
+				ColorProvider: &protocol.Or_ServerCapabilities_colorProvider{
+					Value: true,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "documentcolor Value nil",
+			caps: &protocol.ServerCapabilities{
+				ColorProvider: &protocol.Or_ServerCapabilities_colorProvider{
+					Value: nil,
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "documentcolor missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasDocumentColorSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasDocumentColorSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasOnTypeFormattingSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "ontypeformatting supported",
+			caps: &protocol.ServerCapabilities{
+				DocumentOnTypeFormattingProvider: &protocol.DocumentOnTypeFormattingOptions{
+					FirstTriggerCharacter: "}",
+				},
+			},
+			expected: true,
+		},
+		{
+			name:     "ontypeformatting missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasOnTypeFormattingSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasOnTypeFormattingSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasExecuteCommandSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "executecommand supported",
+			caps: &protocol.ServerCapabilities{
+				ExecuteCommandProvider: &protocol.ExecuteCommandOptions{},
+			},
+			expected: true,
+		},
+		{
+			name:     "executecommand missing",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasExecuteCommandSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasExecuteCommandSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasCodeActionKindSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		kind     protocol.CodeActionKind
+		expected bool
+	}{
+		{
+			name:     "code actions as bool",
+			caps:     &protocol.ServerCapabilities{CodeActionProvider: true},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name:     "code actions disabled",
+			caps:     &protocol.ServerCapabilities{CodeActionProvider: false},
+			kind:     protocol.SourceOrganizeImports,
+			expected: false,
+		},
+		{
+			name: "kind listed",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"codeActionKinds": []interface{}{"quickfix", "source.organizeImports"}},
+			},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name: "parent kind listed",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"codeActionKinds": []string{"source"}},
+			},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name: "kind not listed",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"codeActionKinds": []interface{}{"quickfix", "source.fixAll"}},
+			},
+			kind:     protocol.SourceOrganizeImports,
+			expected: false,
+		},
+		{
+			name:     "options without kinds",
+			caps:     &protocol.ServerCapabilities{CodeActionProvider: map[string]interface{}{"resolveProvider": true}},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name:     "code actions not supported",
+			caps:     &protocol.ServerCapabilities{},
+			kind:     protocol.SourceOrganizeImports,
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			kind:     protocol.SourceOrganizeImports,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasCodeActionKindSupport(tt.caps, tt.kind)
+			if result != tt.expected {
+				t.Errorf("HasCodeActionKindSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasPullDiagnosticsSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "diagnostic options",
+			caps: &protocol.ServerCapabilities{
+				DiagnosticProvider: &protocol.Or_ServerCapabilities_diagnosticProvider{
+					Value: protocol.DiagnosticOptions{InterFileDependencies: true},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "nil value",
+			caps: &protocol.ServerCapabilities{
+				DiagnosticProvider: &protocol.Or_ServerCapabilities_diagnosticProvider{},
+			},
+			expected: false,
+		},
+		{
+			name:     "not advertised",
+			caps:     &protocol.ServerCapabilities{},
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasPullDiagnosticsSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasPullDiagnosticsSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextDocumentSyncKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected protocol.TextDocumentSyncKind
+	}{
+		{
+			name:     "bare kind",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: float64(2)},
+			expected: protocol.Incremental,
+		},
+		{
+			name:     "sync options",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: map[string]interface{}{"openClose": true, "change": float64(1)}},
+			expected: protocol.Full,
+		},
+		{
+			name:     "typed sync options",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: protocol.TextDocumentSyncOptions{Change: protocol.Incremental}},
+			expected: protocol.Incremental,
+		},
+		{
+			name:     "options without change",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: map[string]interface{}{"openClose": true}},
+			expected: protocol.None,
+		},
+		{
+			name:     "not advertised",
+			caps:     &protocol.ServerCapabilities{},
+			expected: protocol.None,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: protocol.None,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TextDocumentSyncKind(tt.caps)
+			if result != tt.expected {
+				t.Errorf("TextDocumentSyncKind() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextDocumentSaveOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected *protocol.SaveOptions
+	}{
+		{
+			name:     "save requested",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: map[string]interface{}{"save": true}},
+			expected: &protocol.SaveOptions{},
+		},
+		{
+			name:     "save with text",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: map[string]interface{}{"save": map[string]interface{}{"includeText": true}}},
+			expected: &protocol.SaveOptions{IncludeText: true},
+		},
+		{
+			name:     "save disabled",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: map[string]interface{}{"save": false}},
+			expected: nil,
+		},
+		{
+			name:     "typed sync options",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: protocol.TextDocumentSyncOptions{Save: &protocol.SaveOptions{IncludeText: true}}},
+			expected: &protocol.SaveOptions{IncludeText: true},
+		},
+		{
+			name:     "bare sync kind",
+			caps:     &protocol.ServerCapabilities{TextDocumentSync: float64(1)},
+			expected: nil,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TextDocumentSaveOptions(tt.caps)
+			if (result == nil) != (tt.expected == nil) {
+				t.Fatalf("TextDocumentSaveOptions() = %v, expected %v", result, tt.expected)
+			}
+			if result != nil && result.IncludeText != tt.expected.IncludeText {
+				t.Errorf("IncludeText = %v, expected %v", result.IncludeText, tt.expected.IncludeText)
+			}
+		})
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,6 +24,18 @@ type Client struct {
 	stdin  io.WriteCloser
 	stdout *bufio.Reader
 	stderr io.ReadCloser
+	// Serializes writes to stdin so that messages sent concurrently don't interleave
+	writeMu sync.Mutex
+
+	// Recent stderr output, window/logMessage and $/logTrace messages of the server, see ServerLogs
+	logLines   *lineBuffer
+	stderrDone chan struct{} // Closed once stderr has been read to the end
+
+	// Recent window/showMessage and window/showMessageRequest messages, see ServerMessages
+	messages *lineBuffer
+
+	// Trace level last sent with $/setTrace, see SetTraceLevel
+	traceLevel atomic.Value
 
 	// Request ID counter
 	nextID atomic.Int32
@@ -35,25 +50,107 @@ type Client struct {
 
 	// Notification handlers
 	notificationHandlers map[string]NotificationHandler
+	orderedNotifications map[string]bool // Handled in the message loop rather than concurrently
 	notificationMu       sync.RWMutex
 
+	// Capabilities from the initialize result and dynamic registrations by ID, see
+	// ServerCapabilities
+	serverCapabilities  *protocol.ServerCapabilities
+	registrations       map[string]protocol.Registration
+	capabilitiesChanged func()
+	capabilitiesMu      sync.RWMutex
+
+	// Recent workspace/symbol results by query, see CachedSymbol
+	symbolCache           map[string]symbolCacheEntry
+	symbolCacheTTL        time.Duration
+	symbolCacheSize       int
+	symbolCacheGeneration int // Incremented when the cache is invalidated
+	symbolCacheMu         sync.Mutex
+
+	// Server operations in progress by token, see HandleProgress
+	progress        map[string]*Progress
+	progressUpdated chan struct{} // Closed and replaced on every change
+	progressBegun   int           // Number of operations that began so far
+	progressMu      sync.Mutex
+
+	// Waiting for the initial indexing, see StartIndexingWait. Guarded by progressMu.
+	indexingWait     *IndexingWait
+	indexingWaitDone chan struct{}
+
+	// Requests streaming partial results by partialResultToken, see streamRequest
+	partialResults         map[string]*partialResultStream
+	nextPartialResultToken atomic.Int32
+	partialResultsMu       sync.Mutex
+
 	// Diagnostic cache
-	diagnostics   map[protocol.DocumentUri][]protocol.Diagnostic
-	diagnosticsMu sync.RWMutex
+	diagnostics        map[protocol.DocumentUri][]protocol.Diagnostic
+	diagnosticsCounts  map[protocol.DocumentUri]int    // Number of publishes received per URI
+	diagnosticsVersion map[protocol.DocumentUri]int32  // Document version of the last publish, 0 if not reported
+	diagnosticsUpdated chan struct{}                   // Closed and replaced on every publish
+	diagnosticsResults map[protocol.DocumentUri]string // resultId of the last pulled report, see PullDiagnostics
+	diagnosticsMu      sync.RWMutex
+
+	// Whether the server supports textDocument/diagnostic, see SetDiagnosticPull
+	diagnosticPull atomic.Bool
+
+	// Workspace edits applied on behalf of the server via workspace/applyEdit
+	appliedEdits   []AppliedEdit
+	appliedEditsMu sync.Mutex
 
 	// Files are currently opened by the LSP
 	openFiles   map[string]*OpenFileInfo
 	openFilesMu sync.RWMutex
+	// Serializes OpenFile so concurrent tool calls don't send duplicate didOpen notifications
+	openFileMu sync.Mutex
+	// Serializes didChange notifications so each is computed against the text the server has
+	changeMu sync.Mutex
+
+	// The server's textDocumentSync.change kind, see NotifyEdits
+	syncKind atomic.Int32
+
+	// Number of times document requests are retried, see RetryDocumentRequest
+	requestRetries atomic.Int32
+
+	// Workspace roots sent to the server, the primary root first
+	workspaceFolders   []string
+	workspaceFoldersMu sync.RWMutex
+
+	// Settings returned for workspace/configuration requests, see SetSettings
+	settings   map[string]any
+	settingsMu sync.RWMutex
+
+	// Closed once the initialize/initialized handshake has completed
+	initialized     chan struct{}
+	initializedOnce sync.Once
+
+	// Closed when the connection to the server ends, e.g. because the process exited
+	done chan struct{}
 
 	// Close synchronization
 	closeOnce sync.Once
 	closeErr  error
 }
 
+// ProcessOptions configures the language server process started by NewClientWithOptions
+type ProcessOptions struct {
+	// Env holds extra "KEY=value" environment variables for the server, e.g. GOFLAGS or a
+	// PATH that finds the right compiler. They override variables inherited from this process.
+	Env []string
+	// Dir is the working directory of the server. Empty means the current directory.
+	Dir string
+}
+
 func NewClient(command string, args ...string) (*Client, error) {
+	return NewClientWithOptions(command, args, ProcessOptions{})
+}
+
+// NewClientWithOptions starts the language server command with the given environment and
+// working directory and returns a client connected to it
+func NewClientWithOptions(command string, args []string, opts ProcessOptions) (*Client, error) {
 	cmd := exec.Command(command, args...)
-	// Copy env
-	cmd.Env = os.Environ()
+	// Copy env. When a key is repeated the last value wins, so opts.Env overrides.
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Dir = opts.Dir
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -70,17 +167,10 @@ func NewClient(command string, args ...string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	client := &Client{
-		Cmd:                   cmd,
-		stdin:                 stdin,
-		stdout:                bufio.NewReader(stdout),
-		stderr:                stderr,
-		handlers:              make(map[string]chan *Message),
-		notificationHandlers:  make(map[string]NotificationHandler),
-		serverRequestHandlers: make(map[string]ServerRequestHandler),
-		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
-		openFiles:             make(map[string]*OpenFileInfo),
-	}
+	client := newClient(stdin, stdout)
+	client.Cmd = cmd
+	client.stderr = stderr
+	client.stderrDone = make(chan struct{})
 
 	// Start the LSP server process
 	if err := cmd.Start(); err != nil {
@@ -88,16 +178,7 @@ func NewClient(command string, args ...string) (*Client, error) {
 	}
 
 	// Handle stderr in a separate goroutine with proper logging
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			processLogger.Info("%s", line)
-		}
-		if err := scanner.Err(); err != nil {
-			lspLogger.Error("Error reading LSP server stderr: %v", err)
-		}
-	}()
+	go client.captureStderr(stderr)
 
 	// Start message handling loop
 	go client.handleMessages()
@@ -105,10 +186,78 @@ func NewClient(command string, args ...string) (*Client, error) {
 	return client, nil
 }
 
+// NewClientFromStreams creates a client that speaks LSP over the given streams instead of
+// a server process, e.g. an in-process mock server in tests. Cmd is nil for such clients.
+func NewClientFromStreams(in io.Reader, out io.WriteCloser) *Client {
+	client := newClient(out, in)
+	go client.handleMessages()
+	return client
+}
+
+// NewClientFromConn creates a client for a language server that is already running and
+// reachable over conn, e.g. a daemon listening on a TCP port. Closing the client closes conn.
+func NewClientFromConn(conn net.Conn) *Client {
+	return NewClientFromStreams(conn, conn)
+}
+
+// DialClient connects to a running language server at address, which is "host:port" for
+// TCP or "unix:/path/to/socket" for a unix domain socket
+func DialClient(ctx context.Context, address string) (*Client, error) {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		network, address = "unix", path
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LSP server: %w", err)
+	}
+	return NewClientFromConn(conn), nil
+}
+
+func newClient(stdin io.WriteCloser, stdout io.Reader) *Client {
+	client := &Client{
+		stdin:                 stdin,
+		stdout:                bufio.NewReader(stdout),
+		handlers:              make(map[string]chan *Message),
+		notificationHandlers:  make(map[string]NotificationHandler),
+		orderedNotifications:  make(map[string]bool),
+		progress:              make(map[string]*Progress),
+		progressUpdated:       make(chan struct{}),
+		partialResults:        make(map[string]*partialResultStream),
+		serverRequestHandlers: make(map[string]ServerRequestHandler),
+		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticsCounts:     make(map[protocol.DocumentUri]int),
+		diagnosticsVersion:    make(map[protocol.DocumentUri]int32),
+		diagnosticsUpdated:    make(chan struct{}),
+		diagnosticsResults:    make(map[protocol.DocumentUri]string),
+		openFiles:             make(map[string]*OpenFileInfo),
+		initialized:           make(chan struct{}),
+		done:                  make(chan struct{}),
+		logLines:              newLineBuffer(DefaultServerLogLines),
+		messages:              newLineBuffer(serverMessageLines),
+	}
+	client.SetRequestRetries(requestRetriesFromEnv())
+	client.SetSymbolCache(symbolCacheFromEnv())
+	return client
+}
+
 func (c *Client) RegisterNotificationHandler(method string, handler NotificationHandler) {
 	c.notificationMu.Lock()
 	defer c.notificationMu.Unlock()
 	c.notificationHandlers[method] = handler
+	delete(c.orderedNotifications, method)
+}
+
+// RegisterOrderedNotificationHandler registers a handler that runs in the message loop, so that
+// notifications of the method are handled one at a time in the order they were sent. The
+// handler must be quick and must not wait for the server.
+func (c *Client) RegisterOrderedNotificationHandler(method string, handler NotificationHandler) {
+	c.notificationMu.Lock()
+	defer c.notificationMu.Unlock()
+	c.notificationHandlers[method] = handler
+	c.orderedNotifications[method] = true
 }
 
 func (c *Client) RegisterServerRequestHandler(method string, handler ServerRequestHandler) {
@@ -117,15 +266,12 @@ func (c *Client) RegisterServerRequestHandler(method string, handler ServerReque
 	c.serverRequestHandlers[method] = handler
 }
 
-func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (*protocol.InitializeResult, error) {
+// InitializeLSPClient performs the initialize handshake. workspaceDir is sent as the root URI;
+// it and any additional folders are sent as workspace folders so that every root is indexed.
+func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string, additionalFolders ...string) (*protocol.InitializeResult, error) {
 	initParams := &protocol.InitializeParams{
 		WorkspaceFoldersInitializeParams: protocol.WorkspaceFoldersInitializeParams{
-			WorkspaceFolders: []protocol.WorkspaceFolder{
-				{
-					URI:  protocol.URI("file://" + workspaceDir),
-					Name: workspaceDir,
-				},
-			},
+			WorkspaceFolders: c.setWorkspaceFolders(append([]string{workspaceDir}, additionalFolders...)),
 		},
 
 		XInitializeParams: protocol.XInitializeParams{
@@ -138,7 +284,8 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 			RootURI:  protocol.DocumentUri("file://" + workspaceDir),
 			Capabilities: protocol.ClientCapabilities{
 				Workspace: protocol.WorkspaceClientCapabilities{
-					Configuration: true,
+					Configuration:    true,
+					WorkspaceFolders: true,
 					DidChangeConfiguration: protocol.DidChangeConfigurationClientCapabilities{
 						DynamicRegistration: true,
 					},
@@ -153,13 +300,35 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						DidSave:             true,
 					},
 					Completion: protocol.CompletionClientCapabilities{
-						CompletionItem: protocol.ClientCompletionItemOptions{},
+						CompletionItem: protocol.ClientCompletionItemOptions{
+							// Auto-import edits may only be computed when an item is resolved
+							ResolveSupport: &protocol.ClientCompletionItemResolveOptions{
+								Properties: []string{"additionalTextEdits"},
+							},
+						},
+						ContextSupport: true,
+						CompletionList: &protocol.CompletionListCapabilities{
+							ItemDefaults: []string{"commitCharacters", "editRange", "insertTextFormat", "insertTextMode", "data"},
+						},
+					},
+					SignatureHelp: &protocol.SignatureHelpClientCapabilities{
+						ContextSupport: true,
 					},
 					CodeLens: &protocol.CodeLensClientCapabilities{
 						DynamicRegistration: true,
 					},
+					Definition: &protocol.DefinitionClientCapabilities{
+						DynamicRegistration: true,
+					},
+					References: &protocol.ReferenceClientCapabilities{
+						DynamicRegistration: true,
+					},
+					Rename: &protocol.RenameClientCapabilities{
+						DynamicRegistration: true,
+					},
 					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{},
 					CodeAction: protocol.CodeActionClientCapabilities{
+						DynamicRegistration: true,
 						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
 							CodeActionKind: protocol.ClientCodeActionKindOptions{
 								ValueSet: []protocol.CodeActionKind{},
@@ -167,7 +336,13 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						},
 					},
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
-						VersionSupport: true,
+						VersionSupport:          true,
+						DiagnosticsCapabilities: diagnosticsCapabilities,
+					},
+					Diagnostic: &protocol.DiagnosticClientCapabilities{
+						DynamicRegistration:     true,
+						RelatedDocumentSupport:  true,
+						DiagnosticsCapabilities: diagnosticsCapabilities,
 					},
 					SemanticTokens: protocol.SemanticTokensClientCapabilities{
 						Requests: protocol.ClientSemanticTokensRequestOptions{
@@ -179,7 +354,10 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						Formats:        []protocol.TokenFormat{},
 					},
 				},
-				Window: protocol.WindowClientCapabilities{},
+				Window: protocol.WindowClientCapabilities{
+					WorkDoneProgress: true,
+					ShowMessage:      &protocol.ShowMessageRequestClientCapabilities{},
+				},
 			},
 			InitializationOptions: map[string]any{
 				"codelenses": map[string]bool{
@@ -195,20 +373,43 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 		},
 	}
 
+	// Servers may report progress and send messages as soon as they get the initialize request
+	c.RegisterServerRequestHandler("window/workDoneProgress/create",
+		func(params json.RawMessage) (any, error) { return nil, nil })
+	c.RegisterOrderedNotificationHandler("$/progress",
+		func(params json.RawMessage) { HandleProgress(c, params) })
+	c.RegisterOrderedNotificationHandler("window/logMessage",
+		func(params json.RawMessage) { HandleLogMessage(c, params) })
+	c.RegisterOrderedNotificationHandler("$/logTrace",
+		func(params json.RawMessage) { HandleLogTrace(c, params) })
+	c.RegisterOrderedNotificationHandler("window/showMessage",
+		func(params json.RawMessage) { HandleServerMessage(c, params) })
+	c.RegisterServerRequestHandler("window/showMessageRequest",
+		func(params json.RawMessage) (any, error) { return HandleShowMessageRequest(c, params) })
+
 	var result protocol.InitializeResult
 	if err := c.Call(ctx, "initialize", initParams, &result); err != nil {
 		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
+	c.SetServerCapabilities(&result.Capabilities)
+	c.SetSyncKind(TextDocumentSyncKind(&result.Capabilities))
+	c.SetDiagnosticPull(HasPullDiagnosticsSupport(&result.Capabilities))
 
 	if err := c.Notify(ctx, "initialized", struct{}{}); err != nil {
 		return nil, fmt.Errorf("initialized notification failed: %w", err)
 	}
 
 	// Register handlers
-	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
-	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
-	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
-	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
+	c.RegisterServerRequestHandler("workspace/applyEdit",
+		func(params json.RawMessage) (any, error) { return HandleApplyEdit(c, params) })
+	c.RegisterServerRequestHandler("workspace/configuration",
+		func(params json.RawMessage) (any, error) { return HandleWorkspaceConfiguration(c, params) })
+	c.RegisterServerRequestHandler("workspace/workspaceFolders",
+		func(params json.RawMessage) (any, error) { return HandleWorkspaceFolders(c) })
+	c.RegisterServerRequestHandler("client/registerCapability",
+		func(params json.RawMessage) (any, error) { return HandleRegisterCapability(c, params) })
+	c.RegisterServerRequestHandler("client/unregisterCapability",
+		func(params json.RawMessage) (any, error) { return HandleUnregisterCapability(c, params) })
 	c.RegisterNotificationHandler("textDocument/publishDiagnostics",
 		func(params json.RawMessage) { HandleDiagnostics(c, params) })
 
@@ -219,7 +420,10 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 	}
 
 	// LSP sepecific Initialization
-	path := strings.ToLower(c.Cmd.Path)
+	var path string
+	if c.Cmd != nil {
+		path = strings.ToLower(c.Cmd.Path)
+	}
 	switch {
 	case strings.Contains(path, "typescript-language-server"):
 		err := initializeTypescriptLanguageServer(ctx, c, workspaceDir)
@@ -228,9 +432,70 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 		}
 	}
 
+	c.markInitialized()
 	return &result, nil
 }
 
+// ErrServerStarting is returned by WaitForInitialized when the handshake does not complete in time
+var ErrServerStarting = errors.New("language server still starting, try again shortly")
+
+// ErrServerExited is returned by requests that are pending or sent after the connection to
+// the server ended
+var ErrServerExited = errors.New("language server exited")
+
+// Done returns a channel that is closed when the connection to the server ends, because the
+// process exited, the socket was closed or the client was closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Exited reports whether the connection to the server has ended, see Done
+func (c *Client) Exited() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) markInitialized() {
+	c.initializedOnce.Do(func() { close(c.initialized) })
+}
+
+// IsInitialized reports whether the initialize/initialized handshake has completed
+func (c *Client) IsInitialized() bool {
+	select {
+	case <-c.initialized:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForInitialized blocks until the initialize/initialized handshake has completed.
+// It returns ErrServerStarting if that does not happen within timeout, ErrServerExited if
+// the connection ends first, or the context's error if ctx is done first.
+func (c *Client) WaitForInitialized(ctx context.Context, timeout time.Duration) error {
+	if c.IsInitialized() {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.initialized:
+		return nil
+	case <-timer.C:
+		return ErrServerStarting
+	case <-c.done:
+		return ErrServerExited
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (c *Client) Close() error {
 	c.closeOnce.Do(func() {
 		// Try to close all open files first
@@ -240,6 +505,12 @@ func (c *Client) Close() error {
 		// Attempt to close files but continue shutdown regardless
 		c.CloseAllFiles(ctx)
 
+		// Clients created from streams have no process to wait for
+		if c.Cmd == nil {
+			c.closeErr = c.stdin.Close()
+			return
+		}
+
 		// Force kill the LSP process if it doesn't exit within timeout
 		forcedKill := make(chan struct{})
 		var killOnce sync.Once
@@ -267,6 +538,12 @@ func (c *Client) Close() error {
 			lspLogger.Error("Failed to close stdin: %v", err)
 		}
 
+		// Let the stderr reader finish before Wait closes the pipe, unless the process hangs
+		select {
+		case <-c.stderrDone:
+		case <-forcedKill:
+		}
+
 		// Wait for process to exit
 		c.closeErr = c.Cmd.Wait()
 		killOnce.Do(func() { close(forcedKill) }) // Stop the force kill goroutine
@@ -292,11 +569,18 @@ func (c *Client) WaitForServerReady(ctx context.Context) error {
 type OpenFileInfo struct {
 	Version int32
 	URI     protocol.DocumentUri
+	// Number of diagnostics publishes received for the file when it was last opened or changed
+	PublishCountAtChange int
+	// Text the server has for the file, as of the last didOpen or didChange
+	Content string
 }
 
 func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
+	c.openFileMu.Lock()
+	defer c.openFileMu.Unlock()
+
 	c.openFilesMu.Lock()
 	if _, exists := c.openFiles[uri]; exists {
 		c.openFilesMu.Unlock()
@@ -323,10 +607,14 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 		return err
 	}
 
+	publishCount := c.DiagnosticsPublishCount(protocol.DocumentUri(uri))
+
 	c.openFilesMu.Lock()
 	c.openFiles[uri] = &OpenFileInfo{
-		Version: 1,
-		URI:     protocol.DocumentUri(uri),
+		Version:              1,
+		URI:                  protocol.DocumentUri(uri),
+		PublishCountAtChange: publishCount,
+		Content:              string(content),
 	}
 	c.openFilesMu.Unlock()
 
@@ -335,45 +623,6 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 	return nil
 }
 
-func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
-	uri := fmt.Sprintf("file://%s", filepath)
-
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("error reading file: %w", err)
-	}
-
-	c.openFilesMu.Lock()
-	fileInfo, isOpen := c.openFiles[uri]
-	if !isOpen {
-		c.openFilesMu.Unlock()
-		return fmt.Errorf("cannot notify change for unopened file: %s", filepath)
-	}
-
-	// Increment version
-	fileInfo.Version++
-	version := fileInfo.Version
-	c.openFilesMu.Unlock()
-
-	params := protocol.DidChangeTextDocumentParams{
-		TextDocument: protocol.VersionedTextDocumentIdentifier{
-			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
-				URI: protocol.DocumentUri(uri),
-			},
-			Version: version,
-		},
-		ContentChanges: []protocol.TextDocumentContentChangeEvent{
-			{
-				Value: protocol.TextDocumentContentChangeWholeDocument{
-					Text: string(content),
-				},
-			},
-		},
-	}
-
-	return c.Notify(ctx, "textDocument/didChange", params)
-}
-
 func (c *Client) CloseFile(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
@@ -409,6 +658,19 @@ func (c *Client) IsFileOpen(filepath string) bool {
 	return exists
 }
 
+// OpenFilePaths returns the paths of the files currently open in the server, sorted
+func (c *Client) OpenFilePaths() []string {
+	c.openFilesMu.RLock()
+	defer c.openFilesMu.RUnlock()
+
+	paths := make([]string, 0, len(c.openFiles))
+	for uri := range c.openFiles {
+		paths = append(paths, strings.TrimPrefix(uri, "file://"))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // CloseAllFiles closes all currently open files
 func (c *Client) CloseAllFiles(ctx context.Context) {
 	c.openFilesMu.Lock()
@@ -433,9 +695,112 @@ func (c *Client) CloseAllFiles(ctx context.Context) {
 	lspLogger.Debug("Closed %d files", len(filesToClose))
 }
 
+// AppliedEdit is a workspace edit the server asked the client to apply, e.g. while
+// executing a command
+type AppliedEdit struct {
+	Label string   // Optional description provided by the server
+	Files []string // Paths of the files that were changed, created, renamed or deleted
+}
+
+// recordAppliedEdit remembers a workspace edit applied on behalf of the server
+func (c *Client) recordAppliedEdit(edit AppliedEdit) {
+	c.appliedEditsMu.Lock()
+	defer c.appliedEditsMu.Unlock()
+
+	c.appliedEdits = append(c.appliedEdits, edit)
+}
+
+// AppliedEditCount returns how many workspace edits the server has had the client apply.
+// Pass it to AppliedEditsSince after a request to find out which edits the request caused.
+func (c *Client) AppliedEditCount() int {
+	c.appliedEditsMu.Lock()
+	defer c.appliedEditsMu.Unlock()
+
+	return len(c.appliedEdits)
+}
+
+// AppliedEditsSince returns the workspace edits applied after the first n
+func (c *Client) AppliedEditsSince(n int) []AppliedEdit {
+	c.appliedEditsMu.Lock()
+	defer c.appliedEditsMu.Unlock()
+
+	if n >= len(c.appliedEdits) {
+		return nil
+	}
+	return append([]AppliedEdit(nil), c.appliedEdits[n:]...)
+}
+
 func (c *Client) GetFileDiagnostics(uri protocol.DocumentUri) []protocol.Diagnostic {
 	c.diagnosticsMu.RLock()
 	defer c.diagnosticsMu.RUnlock()
 
-	return c.diagnostics[uri]
+	return c.diagnostics[diagnosticsKey(uri)]
+}
+
+// DiagnosticsPublishCount returns how many times the server has published diagnostics for uri
+func (c *Client) DiagnosticsPublishCount(uri protocol.DocumentUri) int {
+	c.diagnosticsMu.RLock()
+	defer c.diagnosticsMu.RUnlock()
+
+	return c.diagnosticsCounts[diagnosticsKey(uri)]
+}
+
+// DiagnosticsUpToDate reports whether the cached diagnostics for uri reflect the latest
+// content sent to the server, along with the document version and the version the
+// diagnostics were published for (0 if the server didn't report one).
+//
+// Servers that report versions are compared by version. For servers that don't,
+// diagnostics are considered current if any were published after the last change.
+// Files that aren't open are always considered up to date.
+func (c *Client) DiagnosticsUpToDate(uri protocol.DocumentUri) (upToDate bool, documentVersion, diagnosticsVersion int32) {
+	uri = diagnosticsKey(uri)
+	c.openFilesMu.RLock()
+	fileInfo, isOpen := c.openFiles[string(uri)]
+	var publishCountAtChange int
+	if isOpen {
+		documentVersion = fileInfo.Version
+		publishCountAtChange = fileInfo.PublishCountAtChange
+	}
+	c.openFilesMu.RUnlock()
+
+	c.diagnosticsMu.RLock()
+	diagnosticsVersion = c.diagnosticsVersion[uri]
+	publishCount := c.diagnosticsCounts[uri]
+	c.diagnosticsMu.RUnlock()
+
+	if !isOpen {
+		return true, documentVersion, diagnosticsVersion
+	}
+	if diagnosticsVersion != 0 {
+		return diagnosticsVersion >= documentVersion, documentVersion, diagnosticsVersion
+	}
+	return publishCount > publishCountAtChange, documentVersion, diagnosticsVersion
+}
+
+// WaitForDiagnostics blocks until the server has published diagnostics for uri more than
+// after times in total, the timeout elapses, or ctx is done. It returns true if diagnostics
+// were published in time.
+func (c *Client) WaitForDiagnostics(ctx context.Context, uri protocol.DocumentUri, after int, timeout time.Duration) bool {
+	uri = diagnosticsKey(uri)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.diagnosticsMu.RLock()
+		count := c.diagnosticsCounts[uri]
+		updated := c.diagnosticsUpdated
+		c.diagnosticsMu.RUnlock()
+
+		if count > after {
+			return true
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
 }
@@ -0,0 +1,229 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitForInitialized(t *testing.T) {
+	client := &Client{initialized: make(chan struct{})}
+
+	if client.IsInitialized() {
+		t.Fatal("Expected client to not be initialized")
+	}
+	if err := client.WaitForInitialized(context.Background(), 10*time.Millisecond); !errors.Is(err, ErrServerStarting) {
+		t.Errorf("Expected ErrServerStarting, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.WaitForInitialized(ctx, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		client.markInitialized()
+	}()
+	if err := client.WaitForInitialized(context.Background(), time.Second); err != nil {
+		t.Errorf("Expected handshake to complete, got %v", err)
+	}
+	if !client.IsInitialized() {
+		t.Error("Expected client to be initialized")
+	}
+
+	// Marking twice must not panic
+	client.markInitialized()
+}
+
+func TestWaitForInitializedServerExited(t *testing.T) {
+	client := &Client{initialized: make(chan struct{}), done: make(chan struct{})}
+	close(client.done)
+
+	if err := client.WaitForInitialized(context.Background(), time.Second); !errors.Is(err, ErrServerExited) {
+		t.Errorf("Expected ErrServerExited, got %v", err)
+	}
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(t.TempDir(), "out")
+
+	client, err := NewClientWithOptions("sh", []string{"-c", `printf "%s %s" "$LSP_TEST_VAR" "$(pwd)" > "$LSP_TEST_OUT"`}, ProcessOptions{
+		Env: []string{"LSP_TEST_VAR=hello", "LSP_TEST_OUT=" + out},
+		Dir: dir,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Server process failed: %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("Failed to resolve %s: %v", dir, err)
+	}
+	if expected := "hello " + resolvedDir; string(content) != expected {
+		t.Errorf("Expected server to see %q, got %q", expected, string(content))
+	}
+}
+
+func TestDialClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+	}{
+		{"tcp", "tcp", "127.0.0.1:0"},
+		{"unix socket", "unix", filepath.Join(t.TempDir(), "lsp.sock")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen(tt.network, tt.address)
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			defer func() { _ = listener.Close() }()
+
+			// Answer one request, then wait for the client to disconnect
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer func() { _ = conn.Close() }()
+				reader := bufio.NewReader(conn)
+				msg, err := ReadMessage(reader)
+				if err != nil {
+					return
+				}
+				_ = WriteMessage(conn, &Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`"pong"`)})
+				_, _ = ReadMessage(reader)
+			}()
+
+			address := listener.Addr().String()
+			if tt.network == "unix" {
+				address = "unix:" + address
+			}
+			client, err := DialClient(t.Context(), address)
+			if err != nil {
+				t.Fatalf("DialClient failed: %v", err)
+			}
+
+			var result string
+			if err := client.Call(t.Context(), "test/ping", nil, &result); err != nil {
+				t.Fatalf("Call failed: %v", err)
+			}
+			if result != "pong" {
+				t.Errorf("Expected pong, got %q", result)
+			}
+			if err := client.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+		})
+	}
+
+	t.Run("nothing listening", func(t *testing.T) {
+		if _, err := DialClient(t.Context(), "unix:"+filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+			t.Error("Expected an error connecting to a missing socket")
+		}
+	})
+}
+
+func TestServerExit(t *testing.T) {
+	// The server reads the first line of the request, then exits without answering
+	client, err := NewClient("sh", "-c", "read line; exit 3")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Call(ctx, "test/pending", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Errorf("Expected ErrServerExited for the pending request, got %v", err)
+	}
+
+	select {
+	case <-client.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected Done to be closed after the server exited")
+	}
+	if !client.Exited() {
+		t.Error("Expected client to report that the server exited")
+	}
+	if err := client.Call(ctx, "test/after", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Errorf("Expected ErrServerExited for a request after the exit, got %v", err)
+	}
+
+	var exitErr *exec.ExitError
+	if err := client.Close(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected exit status 3, got %v", err)
+	}
+}
+
+func TestCallCancelRequest(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	client := NewClientFromStreams(clientIn, clientOut)
+	defer func() {
+		_ = serverOut.Close()
+		_ = client.Close()
+	}()
+
+	// The server never answers, it only reads what the client sends
+	received := make(chan *Message, 2)
+	go func() {
+		reader := bufio.NewReader(serverIn)
+		for {
+			msg, err := ReadMessage(reader)
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- client.Call(ctx, "test/slow", nil, nil) }()
+
+	request := <-received
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.Method != "$/cancelRequest" {
+			t.Fatalf("Expected $/cancelRequest, got %s", msg.Method)
+		}
+		var params struct {
+			ID int32 `json:"id"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatalf("Failed to unmarshal params: %v", err)
+		}
+		if request.ID.String() != fmt.Sprint(params.ID) {
+			t.Errorf("Expected cancellation of request %s, got %d", request.ID, params.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a $/cancelRequest notification")
+	}
+}
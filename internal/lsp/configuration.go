@@ -0,0 +1,47 @@
+package lsp
+
+import "strings"
+
+// SetSettings sets the settings returned when the server asks for its configuration with
+// workspace/configuration, usually keyed by section name, e.g. "gopls" or "rust-analyzer".
+// They are not sent to the server, see DidChangeConfiguration.
+func (c *Client) SetSettings(settings map[string]any) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	c.settings = settings
+}
+
+// Settings returns the settings set with SetSettings
+func (c *Client) Settings() map[string]any {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.settings
+}
+
+// configurationSection returns the value of a section of settings, or nil if it is not
+// configured. Sections may be dotted, e.g. "python.analysis", and are looked up both as
+// flat keys, as in VS Code settings files, and as nested objects. An empty section asks for
+// all the settings.
+func configurationSection(settings map[string]any, section string) any {
+	if settings == nil {
+		return nil
+	}
+	if section == "" {
+		return settings
+	}
+	if value, ok := settings[section]; ok {
+		return value
+	}
+
+	// Look up the longest configured prefix, then the rest of the section inside it
+	for i := strings.LastIndex(section, "."); i > 0; i = strings.LastIndex(section[:i], ".") {
+		nested, ok := settings[section[:i]].(map[string]any)
+		if !ok {
+			continue
+		}
+		if value := configurationSection(nested, section[i+1:]); value != nil {
+			return value
+		}
+	}
+	return nil
+}
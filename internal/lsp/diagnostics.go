@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// diagnosticsCapabilities is advertised for both published and pulled diagnostics
+var diagnosticsCapabilities = protocol.DiagnosticsCapabilities{
+	TagSupport: &protocol.ClientDiagnosticsTagOptions{
+		ValueSet: []protocol.DiagnosticTag{protocol.Unnecessary, protocol.Deprecated},
+	},
+}
+
+// diagnosticsKey is the key of uri in the diagnostics and open files maps. Servers escape the
+// URIs they send, e.g. a space as %20, whereas files are opened as "file://" and their path.
+func diagnosticsKey(uri protocol.DocumentUri) protocol.DocumentUri {
+	return protocol.DocumentUri("file://" + utilities.URIToPath(uri))
+}
+
+// SetDiagnosticPull records whether the server supports textDocument/diagnostic, in which
+// case diagnostics are pulled instead of waiting for publishDiagnostics
+func (c *Client) SetDiagnosticPull(enabled bool) {
+	c.diagnosticPull.Store(enabled)
+}
+
+// DiagnosticPull reports whether the server supports textDocument/diagnostic
+func (c *Client) DiagnosticPull() bool {
+	return c.diagnosticPull.Load()
+}
+
+// DocumentVersion returns the version of the content last sent to the server for uri,
+// or 0 if the file isn't open
+func (c *Client) DocumentVersion(uri protocol.DocumentUri) int32 {
+	c.openFilesMu.RLock()
+	defer c.openFilesMu.RUnlock()
+
+	if fileInfo, ok := c.openFiles[string(diagnosticsKey(uri))]; ok {
+		return fileInfo.Version
+	}
+	return 0
+}
+
+// DiagnosticResultID returns the resultId of the last pulled diagnostic report for uri,
+// sent as previousResultId so the server can answer "unchanged"
+func (c *Client) DiagnosticResultID(uri protocol.DocumentUri) string {
+	c.diagnosticsMu.RLock()
+	defer c.diagnosticsMu.RUnlock()
+
+	return c.diagnosticsResults[diagnosticsKey(uri)]
+}
+
+// StorePulledDiagnostics caches diagnostics pulled with textDocument/diagnostic as if they
+// had been published for the given document version (0 if unknown)
+func (c *Client) StorePulledDiagnostics(uri protocol.DocumentUri, version int32, resultID string, diagnostics []protocol.Diagnostic) {
+	c.diagnosticsMu.Lock()
+	c.diagnosticsResults[diagnosticsKey(uri)] = resultID
+	c.diagnosticsMu.Unlock()
+
+	c.storeDiagnostics(uri, version, diagnostics)
+}
+
+// storeDiagnostics saves diagnostics for uri and wakes up anyone waiting for them
+func (c *Client) storeDiagnostics(uri protocol.DocumentUri, version int32, diagnostics []protocol.Diagnostic) {
+	uri = diagnosticsKey(uri)
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+
+	c.diagnostics[uri] = diagnostics
+	c.diagnosticsCounts[uri]++
+	c.diagnosticsVersion[uri] = version
+	close(c.diagnosticsUpdated)
+	c.diagnosticsUpdated = make(chan struct{})
+}
@@ -0,0 +1,155 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+func newDiagnosticsTestClient() *Client {
+	return &Client{
+		diagnostics:        make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticsCounts:  make(map[protocol.DocumentUri]int),
+		diagnosticsVersion: make(map[protocol.DocumentUri]int32),
+		diagnosticsUpdated: make(chan struct{}),
+		diagnosticsResults: make(map[protocol.DocumentUri]string),
+		openFiles:          make(map[string]*OpenFileInfo),
+	}
+}
+
+func publishDiagnostics(t *testing.T, client *Client, uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+	publishVersionedDiagnostics(t, client, uri, 0, diagnostics)
+}
+
+func publishVersionedDiagnostics(t *testing.T, client *Client, uri protocol.DocumentUri, version int32, diagnostics []protocol.Diagnostic) {
+	params, err := json.Marshal(protocol.PublishDiagnosticsParams{
+		URI:         uri,
+		Version:     version,
+		Diagnostics: diagnostics,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal diagnostics: %v", err)
+	}
+	HandleDiagnostics(client, params)
+}
+
+// TestWaitForDiagnosticsDelayedPublish verifies that waiting returns as soon as a
+// delayed publishDiagnostics notification arrives for the file
+func TestWaitForDiagnosticsDelayedPublish(t *testing.T) {
+	client := newDiagnosticsTestClient()
+	uri := protocol.DocumentUri("file:///workspace/main.go")
+	other := protocol.DocumentUri("file:///workspace/other.go")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		// Diagnostics for other files must not end the wait
+		publishDiagnostics(t, client, other, nil)
+		time.Sleep(50 * time.Millisecond)
+		publishDiagnostics(t, client, uri, []protocol.Diagnostic{{Message: "unused variable"}})
+	}()
+
+	start := time.Now()
+	if !client.WaitForDiagnostics(context.Background(), uri, 0, 5*time.Second) {
+		t.Fatal("WaitForDiagnostics() = false, expected true")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("WaitForDiagnostics() took %v, expected it to return once diagnostics arrived", elapsed)
+	}
+
+	diagnostics := client.GetFileDiagnostics(uri)
+	if len(diagnostics) != 1 || diagnostics[0].Message != "unused variable" {
+		t.Errorf("GetFileDiagnostics() = %v, expected the published diagnostic", diagnostics)
+	}
+	if count := client.DiagnosticsPublishCount(uri); count != 1 {
+		t.Errorf("DiagnosticsPublishCount() = %d, expected 1", count)
+	}
+}
+
+// TestWaitForDiagnosticsTimeout verifies that waiting gives up after the timeout
+func TestWaitForDiagnosticsTimeout(t *testing.T) {
+	client := newDiagnosticsTestClient()
+	uri := protocol.DocumentUri("file:///workspace/main.go")
+
+	// An earlier publish doesn't count once the caller has seen it
+	publishDiagnostics(t, client, uri, nil)
+
+	start := time.Now()
+	if client.WaitForDiagnostics(context.Background(), uri, 1, 100*time.Millisecond) {
+		t.Fatal("WaitForDiagnostics() = true, expected false")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("WaitForDiagnostics() returned after %v, expected to wait for the timeout", elapsed)
+	}
+
+	// ...but it is returned immediately to a caller that hasn't seen it
+	if !client.WaitForDiagnostics(context.Background(), uri, 0, 100*time.Millisecond) {
+		t.Error("WaitForDiagnostics() = false for an already published update, expected true")
+	}
+}
+
+func TestDiagnosticsUpToDate(t *testing.T) {
+	uri := protocol.DocumentUri("file:///workspace/main.go")
+
+	t.Run("file not open", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		if upToDate, _, _ := client.DiagnosticsUpToDate(uri); !upToDate {
+			t.Error("DiagnosticsUpToDate() = false, expected true for unopened file")
+		}
+	})
+
+	t.Run("versioned publishes", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		client.openFiles[string(uri)] = &OpenFileInfo{Version: 2, URI: uri}
+
+		publishVersionedDiagnostics(t, client, uri, 1, nil)
+		upToDate, documentVersion, diagnosticsVersion := client.DiagnosticsUpToDate(uri)
+		if upToDate || documentVersion != 2 || diagnosticsVersion != 1 {
+			t.Errorf("DiagnosticsUpToDate() = %v, %d, %d, expected false, 2, 1", upToDate, documentVersion, diagnosticsVersion)
+		}
+
+		publishVersionedDiagnostics(t, client, uri, 2, nil)
+		if upToDate, _, _ := client.DiagnosticsUpToDate(uri); !upToDate {
+			t.Error("DiagnosticsUpToDate() = false after publish for current version, expected true")
+		}
+	})
+
+	t.Run("unversioned publishes", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		publishDiagnostics(t, client, uri, nil)
+		// File changed after the first publish
+		client.openFiles[string(uri)] = &OpenFileInfo{Version: 2, URI: uri, PublishCountAtChange: 1}
+
+		if upToDate, _, _ := client.DiagnosticsUpToDate(uri); upToDate {
+			t.Error("DiagnosticsUpToDate() = true before a publish following the change, expected false")
+		}
+
+		publishDiagnostics(t, client, uri, nil)
+		if upToDate, _, _ := client.DiagnosticsUpToDate(uri); !upToDate {
+			t.Error("DiagnosticsUpToDate() = false after a publish following the change, expected true")
+		}
+	})
+}
+
+// TestDiagnosticsEscapedURI verifies that diagnostics published for the escaped URI of a path
+// with a space are found by the URI files are opened with
+func TestDiagnosticsEscapedURI(t *testing.T) {
+	client := newDiagnosticsTestClient()
+	uri := protocol.DocumentUri("file:///my project/main.go")
+	client.openFiles[string(uri)] = &OpenFileInfo{Version: 1, URI: uri}
+
+	params := json.RawMessage(`{"uri": "file:///my%20project/main.go", "version": 1, "diagnostics": [{"range": {"start": {"line": 0, "character": 0}, "end": {"line": 0, "character": 1}}, "message": "unused variable"}]}`)
+	HandleDiagnostics(client, params)
+
+	if diagnostics := client.GetFileDiagnostics(uri); len(diagnostics) != 1 {
+		t.Errorf("GetFileDiagnostics() = %v, expected the published diagnostic", diagnostics)
+	}
+	if upToDate, _, _ := client.DiagnosticsUpToDate(uri); !upToDate {
+		t.Error("DiagnosticsUpToDate() = false, expected true")
+	}
+	if !client.WaitForDiagnostics(context.Background(), uri, 0, 100*time.Millisecond) {
+		t.Error("WaitForDiagnostics() = false, expected true")
+	}
+}
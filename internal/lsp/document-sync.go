@@ -0,0 +1,195 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// SetSyncKind records the server's advertised textDocumentSync.change kind, which decides
+// whether NotifyEdits may send incremental changes
+func (c *Client) SetSyncKind(kind protocol.TextDocumentSyncKind) {
+	c.syncKind.Store(int32(kind))
+}
+
+// SyncKind returns the server's textDocumentSync.change kind
+func (c *Client) SyncKind() protocol.TextDocumentSyncKind {
+	return protocol.TextDocumentSyncKind(c.syncKind.Load())
+}
+
+// NotifyChange sends the full content of filepath to the server. Nothing is sent if the
+// server already has that content.
+func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	return c.sendChange(ctx, filepath, string(content), []protocol.TextDocumentContentChangeEvent{
+		{
+			Value: protocol.TextDocumentContentChangeWholeDocument{
+				Text: string(content),
+			},
+		},
+	})
+}
+
+// NotifyEdits tells the server about edits that were just written to filepath. If the server
+// supports incremental sync only the edited ranges are sent. Otherwise, or if the edits don't
+// turn the text the server has into the file's current content, the full document is sent.
+func (c *Client) NotifyEdits(ctx context.Context, filepath string, edits []protocol.TextEdit) error {
+	if c.SyncKind() != protocol.Incremental || len(edits) == 0 {
+		return c.NotifyChange(ctx, filepath)
+	}
+
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	uri := fmt.Sprintf("file://%s", filepath)
+	c.openFilesMu.RLock()
+	fileInfo, isOpen := c.openFiles[uri]
+	var synced string
+	if isOpen {
+		synced = fileInfo.Content
+	}
+	c.openFilesMu.RUnlock()
+	if !isOpen {
+		return fmt.Errorf("cannot notify change for unopened file: %s", filepath)
+	}
+
+	changes, ok := incrementalChanges(synced, string(content), edits)
+	if !ok {
+		lspLogger.Debug("Edits do not account for the content of %s, sending the full document", filepath)
+		changes = []protocol.TextDocumentContentChangeEvent{
+			{
+				Value: protocol.TextDocumentContentChangeWholeDocument{
+					Text: string(content),
+				},
+			},
+		}
+	}
+
+	return c.sendChange(ctx, filepath, string(content), changes)
+}
+
+// sendChange sends a didChange notification for an open file and records content as the text
+// the server now has. The caller must hold changeMu.
+func (c *Client) sendChange(ctx context.Context, filepath string, content string, changes []protocol.TextDocumentContentChangeEvent) error {
+	uri := fmt.Sprintf("file://%s", filepath)
+
+	publishCount := c.DiagnosticsPublishCount(protocol.DocumentUri(uri))
+
+	c.openFilesMu.Lock()
+	fileInfo, isOpen := c.openFiles[uri]
+	if !isOpen {
+		c.openFilesMu.Unlock()
+		return fmt.Errorf("cannot notify change for unopened file: %s", filepath)
+	}
+
+	// The server is already up to date, e.g. the watcher saw a write that was sent incrementally
+	if fileInfo.Content == content {
+		c.openFilesMu.Unlock()
+		return nil
+	}
+
+	// Increment version
+	fileInfo.Version++
+	fileInfo.PublishCountAtChange = publishCount
+	version := fileInfo.Version
+	c.openFilesMu.Unlock()
+
+	params := protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri(uri),
+			},
+			Version: version,
+		},
+		ContentChanges: changes,
+	}
+
+	if err := c.Notify(ctx, "textDocument/didChange", params); err != nil {
+		return err
+	}
+	c.InvalidateSymbolCache()
+
+	c.openFilesMu.Lock()
+	fileInfo.Content = content
+	c.openFilesMu.Unlock()
+
+	return nil
+}
+
+// incrementalChanges converts edits to range content changes that the server applies, in
+// order, to oldText. Edits are sent bottom to top so earlier changes never shift the ranges
+// of later ones. ok is false if the edits overlap or applying them does not yield newText.
+func incrementalChanges(oldText, newText string, edits []protocol.TextEdit) (changes []protocol.TextDocumentContentChangeEvent, ok bool) {
+	sorted := make([]protocol.TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range.Start, sorted[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line > b.Line
+		}
+		return a.Character > b.Character
+	})
+
+	text := oldText
+	for i, edit := range sorted {
+		if i > 0 && utilities.RangesOverlap(sorted[i-1].Range, edit.Range) {
+			return nil, false
+		}
+
+		start := byteOffsetAt(text, edit.Range.Start)
+		end := byteOffsetAt(text, edit.Range.End)
+		if end < start {
+			return nil, false
+		}
+		text = text[:start] + edit.NewText + text[end:]
+
+		rng := edit.Range
+		changes = append(changes, protocol.TextDocumentContentChangeEvent{
+			Value: protocol.TextDocumentContentChangePartial{
+				Range: &rng,
+				Text:  edit.NewText,
+			},
+		})
+	}
+
+	return changes, text == newText
+}
+
+// byteOffsetAt converts an LSP position to a byte offset in text. As the specification
+// requires, a character past the end of its line means the end of that line and a line
+// past the end of the document means the end of the document.
+func byteOffsetAt(text string, pos protocol.Position) int {
+	lineStart := 0
+	for i := uint32(0); i < pos.Line; i++ {
+		next := strings.IndexByte(text[lineStart:], '\n')
+		if next == -1 {
+			return len(text)
+		}
+		lineStart += next + 1
+	}
+
+	lineEnd := len(text)
+	if next := strings.IndexByte(text[lineStart:], '\n'); next != -1 {
+		lineEnd = lineStart + next
+	}
+	line := strings.TrimSuffix(text[lineStart:lineEnd], "\r")
+
+	return lineStart + utilities.UTF16OffsetToByteOffset(line, int(pos.Character))
+}
@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+func textEdit(startLine, startChar, endLine, endChar uint32, newText string) protocol.TextEdit {
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		},
+		NewText: newText,
+	}
+}
+
+func TestIncrementalChanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldText     string
+		newText     string
+		edits       []protocol.TextEdit
+		expectOK    bool
+		expectTexts []string // Change texts, in the order they are sent
+	}{
+		{
+			name:        "single replacement",
+			oldText:     "package main\n\nfunc a() {}\n",
+			newText:     "package main\n\nfunc b() {}\n",
+			edits:       []protocol.TextEdit{textEdit(2, 5, 2, 6, "b")},
+			expectOK:    true,
+			expectTexts: []string{"b"},
+		},
+		{
+			name:        "edits are sent bottom to top",
+			oldText:     "one\ntwo\nthree\n",
+			newText:     "ONE\ntwo\nTHREE\n",
+			edits:       []protocol.TextEdit{textEdit(0, 0, 0, 3, "ONE"), textEdit(2, 0, 2, 5, "THREE")},
+			expectOK:    true,
+			expectTexts: []string{"THREE", "ONE"},
+		},
+		{
+			name:        "character past end of line",
+			oldText:     "one\ntwo\n",
+			newText:     "one\n2\n",
+			edits:       []protocol.TextEdit{textEdit(1, 0, 1, 99, "2")},
+			expectOK:    true,
+			expectTexts: []string{"2"},
+		},
+		{
+			name:        "utf-16 columns",
+			oldText:     "s := \"héllo\" + x\n",
+			newText:     "s := \"héllo\" + y\n",
+			edits:       []protocol.TextEdit{textEdit(0, 15, 0, 16, "y")},
+			expectOK:    true,
+			expectTexts: []string{"y"},
+		},
+		{
+			name:        "crlf line endings",
+			oldText:     "one\r\ntwo\r\n",
+			newText:     "one\r\n2\r\n",
+			edits:       []protocol.TextEdit{textEdit(1, 0, 1, 3, "2")},
+			expectOK:    true,
+			expectTexts: []string{"2"},
+		},
+		{
+			name:     "edits do not produce the new content",
+			oldText:  "one\ntwo\nthree\n",
+			newText:  "one\nthree\n",
+			edits:    []protocol.TextEdit{textEdit(1, 0, 1, 3, "")},
+			expectOK: false,
+		},
+		{
+			name:     "overlapping edits",
+			oldText:  "one two\n",
+			newText:  "1\n",
+			edits:    []protocol.TextEdit{textEdit(0, 0, 0, 5, "1"), textEdit(0, 3, 0, 7, "")},
+			expectOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, ok := incrementalChanges(tt.oldText, tt.newText, tt.edits)
+			if ok != tt.expectOK {
+				t.Fatalf("incrementalChanges() ok = %v, expected %v", ok, tt.expectOK)
+			}
+			if !ok {
+				return
+			}
+			if len(changes) != len(tt.expectTexts) {
+				t.Fatalf("got %d changes, expected %d", len(changes), len(tt.expectTexts))
+			}
+			for i, change := range changes {
+				partial, isPartial := change.Value.(protocol.TextDocumentContentChangePartial)
+				if !isPartial {
+					t.Fatalf("change %d is %T, expected a range change", i, change.Value)
+				}
+				if partial.Range == nil {
+					t.Errorf("change %d has no range", i)
+				}
+				if partial.Text != tt.expectTexts[i] {
+					t.Errorf("change %d text = %q, expected %q", i, partial.Text, tt.expectTexts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestByteOffsetAt(t *testing.T) {
+	text := "ab\r\nhé x\nlast"
+	tests := []struct {
+		name     string
+		pos      protocol.Position
+		expected int
+	}{
+		{"start of document", protocol.Position{Line: 0, Character: 0}, 0},
+		{"past end of crlf line", protocol.Position{Line: 0, Character: 10}, 2},
+		{"after multi-byte character", protocol.Position{Line: 1, Character: 2}, 7},
+		{"last line without newline", protocol.Position{Line: 2, Character: 4}, 14},
+		{"past end of document", protocol.Position{Line: 5, Character: 0}, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := byteOffsetAt(text, tt.pos); got != tt.expected {
+				t.Errorf("byteOffsetAt(%v) = %d, expected %d", tt.pos, got, tt.expected)
+			}
+		})
+	}
+}
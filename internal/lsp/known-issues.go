@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// KnownIssue is a behavior of some versions of a language server that affects a tool
+type KnownIssue struct {
+	// Server is matched against the server's name, like the file extension defaults
+	Server string
+	// IntroducedIn is the first affected version, or empty if every version before FixedIn is
+	IntroducedIn string
+	// FixedIn is the first version without the issue, or empty if it is not fixed yet
+	FixedIn     string
+	Tools       []string
+	Description string
+}
+
+// knownIssues lists the issues of known language server versions. Add an entry when a
+// server release turns out to break or lack something a tool relies on.
+var knownIssues = []KnownIssue{
+	{
+		Server:      "gopls",
+		FixedIn:     "0.5.1",
+		Tools:       []string{"call_hierarchy"},
+		Description: "gopls supports call hierarchy since v0.5.1",
+	},
+	{
+		Server:      "gopls",
+		FixedIn:     "0.16.0",
+		Tools:       []string{"type_hierarchy"},
+		Description: "gopls supports type hierarchy since v0.16.0",
+	},
+	{
+		Server:      "clangd",
+		FixedIn:     "12.0.0",
+		Tools:       []string{"rename_symbol"},
+		Description: "clangd before 12 only renames within the current file unless started with --cross-file-rename",
+	},
+	{
+		Server:      "clangd",
+		FixedIn:     "20.0.0",
+		Tools:       []string{"call_hierarchy"},
+		Description: "clangd before 20 does not implement outgoing calls, only incoming calls are listed",
+	},
+}
+
+// versionPattern matches the first dotted version number in a version string, e.g. 17.0.6
+// in "clangd version 17.0.6 (https://github.com/llvm/llvm-project ...)"
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// KnownIssues returns the known issues of the language server described by info, or nil if
+// its version is not known
+func KnownIssues(info *protocol.ServerInfo) []KnownIssue {
+	if info == nil {
+		return nil
+	}
+	version, ok := parseServerVersion(info.Version)
+	if !ok {
+		return nil
+	}
+	name := strings.ToLower(info.Name)
+
+	var issues []KnownIssue
+	for _, issue := range knownIssues {
+		if !strings.Contains(name, issue.Server) {
+			continue
+		}
+		if introduced, ok := parseServerVersion(issue.IntroducedIn); ok && compareVersions(version, introduced) < 0 {
+			continue
+		}
+		if fixed, ok := parseServerVersion(issue.FixedIn); ok && compareVersions(version, fixed) >= 0 {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// parseServerVersion returns the major, minor and patch numbers of a server's version.
+// gopls reports its build information as JSON, with the version of its module in Main.
+func parseServerVersion(version string) ([3]int, bool) {
+	var buildInfo struct {
+		Main struct {
+			Version string
+		}
+	}
+	if json.Unmarshal([]byte(version), &buildInfo) == nil {
+		version = buildInfo.Main.Version
+	}
+
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return [3]int{}, false
+	}
+	var parsed [3]int
+	for i, part := range match[1:] {
+		parsed[i], _ = strconv.Atoi(part) // A missing patch number is 0
+	}
+	return parsed, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
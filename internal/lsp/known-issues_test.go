@@ -0,0 +1,66 @@
+package lsp
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected [3]int
+		ok       bool
+	}{
+		{version: "v0.16.1", expected: [3]int{0, 16, 1}, ok: true},
+		{version: "clangd version 17.0.6 (https://github.com/llvm/llvm-project 6009708b4367171ccdbf4b5905cb6a803753fe18)", expected: [3]int{17, 0, 6}, ok: true},
+		{version: "4.3", expected: [3]int{4, 3, 0}, ok: true},
+		{version: `{"GoVersion":"go1.22.1","Path":"golang.org/x/tools/gopls","Main":{"Path":"golang.org/x/tools/gopls","Version":"v0.15.2"}}`, expected: [3]int{0, 15, 2}, ok: true},
+		{version: `{"GoVersion":"go1.22.1","Path":"golang.org/x/tools/gopls","Main":{"Path":"golang.org/x/tools/gopls","Version":"(devel)"}}`},
+		{version: ""},
+	}
+
+	for _, tt := range tests {
+		version, ok := parseServerVersion(tt.version)
+		if ok != tt.ok || version != tt.expected {
+			t.Errorf("parseServerVersion(%q) = %v, %v, want %v, %v", tt.version, version, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestKnownIssues(t *testing.T) {
+	original := knownIssues
+	t.Cleanup(func() { knownIssues = original })
+	knownIssues = []KnownIssue{
+		{Server: "clangd", FixedIn: "12.0.0", Tools: []string{"rename_symbol"}},
+		{Server: "clangd", IntroducedIn: "17.0.0", FixedIn: "17.0.4", Tools: []string{"hover"}},
+		{Server: "gopls", IntroducedIn: "0.18.0", Tools: []string{"completions"}},
+	}
+
+	tests := []struct {
+		name     string
+		info     *protocol.ServerInfo
+		expected []string
+	}{
+		{name: "old version", info: &protocol.ServerInfo{Name: "clangd", Version: "clangd version 11.1.0"}, expected: []string{"rename_symbol"}},
+		{name: "in affected range", info: &protocol.ServerInfo{Name: "clangd", Version: "clangd version 17.0.2"}, expected: []string{"hover"}},
+		{name: "fixed version", info: &protocol.ServerInfo{Name: "clangd", Version: "clangd version 17.0.4"}},
+		{name: "unfixed issue", info: &protocol.ServerInfo{Name: "gopls", Version: "v0.19.0"}, expected: []string{"completions"}},
+		{name: "other server", info: &protocol.ServerInfo{Name: "rust-analyzer", Version: "0.3.1850"}},
+		{name: "unknown version", info: &protocol.ServerInfo{Name: "clangd"}},
+		{name: "no server info", info: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tools []string
+			for _, issue := range KnownIssues(tt.info) {
+				tools = append(tools, issue.Tools...)
+			}
+			if !slices.Equal(tools, tt.expected) {
+				t.Errorf("KnownIssues() affects %v, want %v", tools, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// partialResultStream collects the partial results a server reports with $/progress for one
+// request, see streamRequest
+type partialResultStream struct {
+	pending []json.RawMessage
+	notify  chan struct{} // Signalled when results are added to pending
+	mu      sync.Mutex
+}
+
+func (s *partialResultStream) add(value json.RawMessage) {
+	s.mu.Lock()
+	s.pending = append(s.pending, value)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *partialResultStream) take() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = nil
+	return pending
+}
+
+// handlePartialResult passes the value of a $/progress notification to the request that
+// uses token as its partialResultToken. It returns false if no request does.
+func (c *Client) handlePartialResult(token string, value json.RawMessage) bool {
+	c.partialResultsMu.Lock()
+	stream, ok := c.partialResults[token]
+	c.partialResultsMu.Unlock()
+
+	if ok {
+		stream.add(value)
+	}
+	return ok
+}
+
+// newPartialResultToken returns a token unique to this client for a request's
+// partialResultToken
+func (c *Client) newPartialResultToken() string {
+	return fmt.Sprintf("partial-%d", c.nextPartialResultToken.Add(1))
+}
+
+// streamRequest sends a request whose params have token as their partialResultToken and calls
+// onPartial with each partial result the server reports, in order, and finally with the
+// result of the response. If onPartial returns false the request is abandoned and
+// streamRequest returns nil without waiting for the response.
+func streamRequest[T any](ctx context.Context, c *Client, method string, params any, token string, onPartial func(T) bool) error {
+	stream := &partialResultStream{notify: make(chan struct{}, 1)}
+	c.partialResultsMu.Lock()
+	c.partialResults[token] = stream
+	c.partialResultsMu.Unlock()
+	defer func() {
+		c.partialResultsMu.Lock()
+		delete(c.partialResults, token)
+		c.partialResultsMu.Unlock()
+	}()
+
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var final json.RawMessage
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Call(callCtx, method, params, &final)
+	}()
+
+	// deliver passes the pending partial results on, returning false to stop early
+	deliver := func() (bool, error) {
+		for _, value := range stream.take() {
+			var partial T
+			if err := json.Unmarshal(value, &partial); err != nil {
+				return false, fmt.Errorf("failed to unmarshal partial result: %w", err)
+			}
+			if !onPartial(partial) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	for {
+		select {
+		case <-stream.notify:
+			if more, err := deliver(); !more {
+				return err
+			}
+		case err := <-done:
+			if err != nil {
+				return err
+			}
+			// $/progress is handled in the message loop, so every partial result arrived
+			// before the response
+			if more, err := deliver(); !more {
+				return err
+			}
+
+			var result T
+			if len(final) > 0 && string(final) != "null" {
+				if err := json.Unmarshal(final, &result); err != nil {
+					return fmt.Errorf("failed to unmarshal result: %w", err)
+				}
+			}
+			onPartial(result)
+			return nil
+		}
+	}
+}
+
+// ReferencesWithPartialResults sends a textDocument/references request that lets the server
+// stream its results. onPartial is called with each batch of locations as it arrives and may
+// return false to stop once it has seen enough. The locations received are returned.
+func (c *Client) ReferencesWithPartialResults(ctx context.Context, params protocol.ReferenceParams, onPartial func([]protocol.Location) bool) ([]protocol.Location, error) {
+	token := c.newPartialResultToken()
+	params.PartialResultToken = &protocol.ProgressToken{Value: token}
+
+	var locations []protocol.Location
+	err := streamRequest(ctx, c, "textDocument/references", params, token, func(batch []protocol.Location) bool {
+		locations = append(locations, batch...)
+		return onPartial == nil || onPartial(batch)
+	})
+	return locations, err
+}
+
+// SymbolWithPartialResults sends a workspace/symbol request like Symbol, but lets the server
+// stream its results and returns them all combined
+func (c *Client) SymbolWithPartialResults(ctx context.Context, params protocol.WorkspaceSymbolParams) (protocol.Or_Result_workspace_symbol, error) {
+	token := c.newPartialResultToken()
+	params.PartialResultToken = &protocol.ProgressToken{Value: token}
+
+	var symbols []protocol.SymbolInformation
+	var workspaceSymbols []protocol.WorkspaceSymbol
+	err := streamRequest(ctx, c, "workspace/symbol", params, token, func(batch protocol.Or_Result_workspace_symbol) bool {
+		switch v := batch.Value.(type) {
+		case []protocol.SymbolInformation:
+			symbols = append(symbols, v...)
+		case []protocol.WorkspaceSymbol:
+			workspaceSymbols = append(workspaceSymbols, v...)
+		}
+		return true
+	})
+
+	// Servers answer with one of the two types, an empty batch may decode as either
+	var result protocol.Or_Result_workspace_symbol
+	if len(workspaceSymbols) > 0 {
+		result.Value = workspaceSymbols
+	} else if symbols != nil {
+		result.Value = symbols
+	}
+	return result, err
+}
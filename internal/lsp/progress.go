@@ -0,0 +1,223 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Progress is a long-running server operation reported with $/progress, such as indexing
+type Progress struct {
+	Token   string
+	Title   string
+	Message string
+	// Percentage is in [0, 100], or -1 if the server doesn't report one
+	Percentage int
+	Started    time.Time
+}
+
+// HandleProgress processes $/progress notifications for work done progress and partial
+// results. It must run in order with the other notifications (see
+// RegisterOrderedNotificationHandler) so that an operation's end isn't seen before its begin
+// and partial results arrive before the response of their request.
+func HandleProgress(client *Client, params json.RawMessage) {
+	var progress struct {
+		Token protocol.ProgressToken `json:"token"`
+		Value json.RawMessage        `json:"value"`
+	}
+	if err := json.Unmarshal(params, &progress); err != nil {
+		lspLogger.Error("Error unmarshaling progress params: %v", err)
+		return
+	}
+
+	token := fmt.Sprint(progress.Token.Value)
+	if client.handlePartialResult(token, progress.Value) {
+		return
+	}
+
+	var value struct {
+		Kind       string  `json:"kind"`
+		Title      string  `json:"title"`
+		Message    string  `json:"message"`
+		Percentage *uint32 `json:"percentage"`
+	}
+	if err := json.Unmarshal(progress.Value, &value); err != nil {
+		return // Not work done progress
+	}
+
+	client.progressMu.Lock()
+	defer client.progressMu.Unlock()
+
+	switch value.Kind {
+	case "begin":
+		state := &Progress{Token: token, Title: value.Title, Message: value.Message, Percentage: -1, Started: time.Now()}
+		if value.Percentage != nil {
+			state.Percentage = int(*value.Percentage)
+		}
+		client.progress[token] = state
+		client.progressBegun++
+		lspLogger.Info("Server started %s: %s", value.Title, value.Message)
+	case "report":
+		state, ok := client.progress[token]
+		if !ok {
+			return
+		}
+		// An unset message means the previous one still applies
+		if value.Message != "" {
+			state.Message = value.Message
+		}
+		if value.Percentage != nil {
+			state.Percentage = int(*value.Percentage)
+		}
+	case "end":
+		state, ok := client.progress[token]
+		if !ok {
+			return
+		}
+		delete(client.progress, token)
+		lspLogger.Info("Server finished %s after %s", state.Title, time.Since(state.Started).Round(time.Millisecond))
+	default:
+		return
+	}
+
+	close(client.progressUpdated)
+	client.progressUpdated = make(chan struct{})
+}
+
+// ActiveProgress returns the server operations currently in progress, oldest first
+func (c *Client) ActiveProgress() []Progress {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	active := make([]Progress, 0, len(c.progress))
+	for _, state := range c.progress {
+		active = append(active, *state)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Started.Before(active[j].Started)
+	})
+	return active
+}
+
+// WaitForProgress blocks until the server has no operations in progress, the timeout elapses,
+// or ctx is done. It returns true if no operations were left in progress.
+func (c *Client) WaitForProgress(ctx context.Context, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.progressMu.Lock()
+		active := len(c.progress)
+		updated := c.progressUpdated
+		c.progressMu.Unlock()
+
+		if active == 0 {
+			return true
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// indexingStartGrace is how long StartIndexingWait waits for a server that hasn't reported
+// any progress yet to start indexing before assuming it has nothing to index
+const indexingStartGrace = 2 * time.Second
+
+// IndexingWait describes waiting for a server's initial indexing, see StartIndexingWait
+type IndexingWait struct {
+	Timeout time.Duration
+	Started time.Time
+	// Done is set once the wait has ended, Complete if that was because all reported
+	// progress ended rather than the timeout
+	Done     bool
+	Complete bool
+	Elapsed  time.Duration
+}
+
+// StartIndexingWait starts waiting up to timeout for the server to finish indexing, that is
+// for all the progress it reports after starting to end, see IndexingWaitDone
+func (c *Client) StartIndexingWait(ctx context.Context, timeout time.Duration) {
+	c.progressMu.Lock()
+	c.indexingWait = &IndexingWait{Timeout: timeout, Started: time.Now()}
+	c.indexingWaitDone = make(chan struct{})
+	c.progressMu.Unlock()
+
+	go func() {
+		complete := c.waitForIndexing(ctx, timeout)
+
+		c.progressMu.Lock()
+		defer c.progressMu.Unlock()
+		c.indexingWait.Done = true
+		c.indexingWait.Complete = complete
+		c.indexingWait.Elapsed = time.Since(c.indexingWait.Started)
+		close(c.indexingWaitDone)
+		if complete {
+			lspLogger.Info("Language server finished indexing after %s", c.indexingWait.Elapsed.Round(time.Millisecond))
+		} else {
+			lspLogger.Warn("Language server still indexing after %s, results may be incomplete", timeout)
+		}
+	}()
+}
+
+// waitForIndexing is WaitForProgress, but first gives the server some time to begin
+// reporting progress if it hasn't yet
+func (c *Client) waitForIndexing(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	grace := time.NewTimer(min(indexingStartGrace, timeout))
+	defer grace.Stop()
+
+	for {
+		c.progressMu.Lock()
+		begun := c.progressBegun
+		updated := c.progressUpdated
+		c.progressMu.Unlock()
+
+		if begun > 0 {
+			return c.WaitForProgress(ctx, time.Until(deadline))
+		}
+
+		select {
+		case <-updated:
+		case <-grace.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// IndexingWaitDone returns a channel that is closed once the wait started by
+// StartIndexingWait has ended. It is closed already if no wait was started.
+func (c *Client) IndexingWaitDone() <-chan struct{} {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	if c.indexingWaitDone == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return c.indexingWaitDone
+}
+
+// IndexingStatus returns the state of the wait started by StartIndexingWait, or nil if none was
+func (c *Client) IndexingStatus() *IndexingWait {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	if c.indexingWait == nil {
+		return nil
+	}
+	status := *c.indexingWait
+	return &status
+}
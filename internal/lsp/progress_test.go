@@ -0,0 +1,68 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newProgressTestClient() *Client {
+	return &Client{
+		progress:        make(map[string]*Progress),
+		progressUpdated: make(chan struct{}),
+	}
+}
+
+func TestHandleProgress(t *testing.T) {
+	client := newProgressTestClient()
+
+	HandleProgress(client, []byte(`{"token": "index", "value": {"kind": "begin", "title": "Indexing", "message": "0/10 files"}}`))
+	HandleProgress(client, []byte(`{"token": 2, "value": {"kind": "begin", "title": "Loading", "percentage": 0}}`))
+	HandleProgress(client, []byte(`{"token": "index", "value": {"kind": "report", "percentage": 50}}`))
+	// Partial results and unknown tokens are ignored
+	HandleProgress(client, []byte(`{"token": "partial", "value": [{"name": "main"}]}`))
+	HandleProgress(client, []byte(`{"token": "unknown", "value": {"kind": "report", "message": "ignored"}}`))
+
+	active := client.ActiveProgress()
+	if len(active) != 2 {
+		t.Fatalf("Expected 2 operations in progress, got %d: %v", len(active), active)
+	}
+	if active[0].Token != "index" || active[0].Title != "Indexing" || active[0].Message != "0/10 files" || active[0].Percentage != 50 {
+		t.Errorf("Unexpected indexing progress: %+v", active[0])
+	}
+	if active[1].Token != "2" || active[1].Title != "Loading" || active[1].Percentage != 0 {
+		t.Errorf("Unexpected loading progress: %+v", active[1])
+	}
+
+	HandleProgress(client, []byte(`{"token": "index", "value": {"kind": "end", "message": "done"}}`))
+	if active := client.ActiveProgress(); len(active) != 1 || active[0].Token != "2" {
+		t.Errorf("Expected only loading in progress, got %v", active)
+	}
+}
+
+func TestWaitForProgress(t *testing.T) {
+	client := newProgressTestClient()
+	if !client.WaitForProgress(t.Context(), time.Second) {
+		t.Error("Expected no wait without progress")
+	}
+
+	HandleProgress(client, []byte(`{"token": 1, "value": {"kind": "begin", "title": "Indexing"}}`))
+	if client.WaitForProgress(t.Context(), 10*time.Millisecond) {
+		t.Error("Expected timeout while indexing")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if client.WaitForProgress(ctx, time.Second) {
+		t.Error("Expected cancelled wait to report progress still active")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		HandleProgress(client, []byte(`{"token": 1, "value": {"kind": "report", "percentage": 90}}`))
+		HandleProgress(client, []byte(`{"token": 1, "value": {"kind": "end"}}`))
+	}()
+	if !client.WaitForProgress(t.Context(), 5*time.Second) {
+		t.Error("Expected wait to return once indexing ended")
+	}
+}
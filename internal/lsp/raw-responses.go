@@ -0,0 +1,65 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// RawResponse is the unformatted result, or error, a server returned for a request
+type RawResponse struct {
+	Method string
+	Result json.RawMessage
+}
+
+// RawResponseRecorder collects the responses to the requests made with a context returned by
+// WithRawResponses, e.g. to show what the server returned for a tool call
+type RawResponseRecorder struct {
+	responses []RawResponse
+	mu        sync.Mutex
+}
+
+type rawResponsesKey struct{}
+
+// WithRawResponses returns a context that records the response to every request made with it
+// in recorder
+func WithRawResponses(ctx context.Context, recorder *RawResponseRecorder) context.Context {
+	return context.WithValue(ctx, rawResponsesKey{}, recorder)
+}
+
+// RawResponsesFrom returns the recorder of a context returned by WithRawResponses, or nil
+func RawResponsesFrom(ctx context.Context) *RawResponseRecorder {
+	recorder, _ := ctx.Value(rawResponsesKey{}).(*RawResponseRecorder)
+	return recorder
+}
+
+// Responses returns the recorded responses in the order they arrived
+func (r *RawResponseRecorder) Responses() []RawResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RawResponse(nil), r.responses...)
+}
+
+func (r *RawResponseRecorder) record(method string, result json.RawMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses = append(r.responses, RawResponse{Method: method, Result: result})
+}
+
+// recordRawResponse adds the response to a request to the recorder of ctx, if any
+func recordRawResponse(ctx context.Context, method string, resp *Message) {
+	recorder := RawResponsesFrom(ctx)
+	if recorder == nil {
+		return
+	}
+	result := resp.Result
+	if resp.Error != nil {
+		var err error
+		if result, err = json.Marshal(map[string]any{"error": resp.Error}); err != nil {
+			return
+		}
+	}
+	recorder.record(method, result)
+}
@@ -0,0 +1,32 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestRecordRawResponse(t *testing.T) {
+	// Without a recorder nothing is recorded
+	recordRawResponse(context.Background(), "textDocument/hover", &Message{Result: json.RawMessage(`null`)})
+
+	recorder := &RawResponseRecorder{}
+	ctx := WithRawResponses(context.Background(), recorder)
+	if RawResponsesFrom(ctx) != recorder {
+		t.Fatalf("Expected the recorder of the context")
+	}
+
+	recordRawResponse(ctx, "textDocument/hover", &Message{Result: json.RawMessage(`{"contents": "func main()"}`)})
+	recordRawResponse(ctx, "textDocument/rename", &Message{Error: &ResponseError{Code: -32803, Message: "no identifier found"}})
+
+	responses := recorder.Responses()
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Method != "textDocument/hover" || string(responses[0].Result) != `{"contents": "func main()"}` {
+		t.Errorf("Unexpected first response: %s %s", responses[0].Method, responses[0].Result)
+	}
+	if responses[1].Method != "textDocument/rename" || string(responses[1].Result) != `{"error":{"code":-32803,"message":"no identifier found"}}` {
+		t.Errorf("Unexpected second response: %s %s", responses[1].Method, responses[1].Result)
+	}
+}
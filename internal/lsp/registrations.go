@@ -0,0 +1,147 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SetServerCapabilities records the capabilities the server announced in its initialize
+// result. Capabilities registered later with client/registerCapability are added to them,
+// see ServerCapabilities.
+func (c *Client) SetServerCapabilities(caps *protocol.ServerCapabilities) {
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+	c.serverCapabilities = caps
+}
+
+// SetCapabilitiesChangedHandler sets a function called after the server registers or
+// unregisters capabilities, e.g. to offer tools the server now supports. It runs in its own
+// goroutine.
+func (c *Client) SetCapabilitiesChangedHandler(handler func()) {
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+	c.capabilitiesChanged = handler
+}
+
+// ServerCapabilities returns the server's static capabilities merged with the ones it has
+// registered dynamically and not unregistered since, so that the Has*Support helpers see
+// both. It returns nil before initialization.
+func (c *Client) ServerCapabilities() *protocol.ServerCapabilities {
+	c.capabilitiesMu.RLock()
+	defer c.capabilitiesMu.RUnlock()
+
+	if c.serverCapabilities == nil {
+		return nil
+	}
+	merged := *c.serverCapabilities
+	for _, reg := range c.registrations {
+		mergeRegistration(&merged, reg)
+	}
+	return &merged
+}
+
+// mergeRegistration sets the capability a dynamic registration stands for. Methods that no
+// tool depends on are ignored.
+func mergeRegistration(caps *protocol.ServerCapabilities, reg protocol.Registration) {
+	// Or_* providers and interface{} providers accept either true or the options
+	var value any = true
+	if reg.RegisterOptions != nil {
+		value = reg.RegisterOptions
+	}
+
+	switch reg.Method {
+	case "textDocument/definition":
+		caps.DefinitionProvider = &protocol.Or_ServerCapabilities_definitionProvider{Value: value}
+	case "workspace/symbol":
+		caps.WorkspaceSymbolProvider = &protocol.Or_ServerCapabilities_workspaceSymbolProvider{Value: value}
+	case "textDocument/references":
+		caps.ReferencesProvider = &protocol.Or_ServerCapabilities_referencesProvider{Value: value}
+	case "textDocument/hover":
+		caps.HoverProvider = &protocol.Or_ServerCapabilities_hoverProvider{Value: value}
+	case "textDocument/documentSymbol":
+		caps.DocumentSymbolProvider = &protocol.Or_ServerCapabilities_documentSymbolProvider{Value: value}
+	case "textDocument/prepareCallHierarchy":
+		caps.CallHierarchyProvider = &protocol.Or_ServerCapabilities_callHierarchyProvider{Value: value}
+	case "textDocument/prepareTypeHierarchy":
+		caps.TypeHierarchyProvider = &protocol.Or_ServerCapabilities_typeHierarchyProvider{Value: value}
+	case "textDocument/moniker":
+		caps.MonikerProvider = &protocol.Or_ServerCapabilities_monikerProvider{Value: value}
+	case "textDocument/documentColor":
+		caps.ColorProvider = &protocol.Or_ServerCapabilities_colorProvider{Value: value}
+	case "textDocument/diagnostic":
+		caps.DiagnosticProvider = &protocol.Or_ServerCapabilities_diagnosticProvider{Value: value}
+	case "textDocument/rename":
+		caps.RenameProvider = value
+	case "textDocument/codeAction":
+		// Decoded options are a map, which HasCodeActionKindSupport reads codeActionKinds from
+		caps.CodeActionProvider = value
+	case "textDocument/signatureHelp":
+		caps.SignatureHelpProvider = &protocol.SignatureHelpOptions{}
+		decodeRegisterOptions(reg, caps.SignatureHelpProvider)
+	case "textDocument/completion":
+		caps.CompletionProvider = &protocol.CompletionOptions{}
+		decodeRegisterOptions(reg, caps.CompletionProvider)
+	case "textDocument/codeLens":
+		caps.CodeLensProvider = &protocol.CodeLensOptions{}
+		decodeRegisterOptions(reg, caps.CodeLensProvider)
+	case "textDocument/documentLink":
+		caps.DocumentLinkProvider = &protocol.DocumentLinkOptions{}
+		decodeRegisterOptions(reg, caps.DocumentLinkProvider)
+	case "textDocument/onTypeFormatting":
+		caps.DocumentOnTypeFormattingProvider = &protocol.DocumentOnTypeFormattingOptions{}
+		decodeRegisterOptions(reg, caps.DocumentOnTypeFormattingProvider)
+	case "workspace/executeCommand":
+		caps.ExecuteCommandProvider = &protocol.ExecuteCommandOptions{}
+		decodeRegisterOptions(reg, caps.ExecuteCommandProvider)
+	case "textDocument/didSave":
+		save := &protocol.SaveOptions{}
+		decodeRegisterOptions(reg, save)
+		caps.TextDocumentSync = protocol.TextDocumentSyncOptions{
+			OpenClose: true,
+			Change:    TextDocumentSyncKind(caps),
+			Save:      save,
+		}
+	}
+}
+
+// decodeRegisterOptions decodes the registration options into options, leaving the fields
+// the server didn't send at their zero value
+func decodeRegisterOptions(reg protocol.Registration, options any) {
+	if reg.RegisterOptions == nil {
+		return
+	}
+	data, err := json.Marshal(reg.RegisterOptions)
+	if err == nil {
+		err = json.Unmarshal(data, options)
+	}
+	if err != nil {
+		lspLogger.Warn("Invalid options in %s registration: %v", reg.Method, err)
+	}
+}
+
+// updateRegistrations adds and removes dynamic registrations by ID, then notifies the
+// capabilities changed handler
+func (c *Client) updateRegistrations(added []protocol.Registration, removed []protocol.Unregistration) {
+	c.capabilitiesMu.Lock()
+	if c.registrations == nil {
+		c.registrations = make(map[string]protocol.Registration)
+	}
+	for _, reg := range added {
+		c.registrations[reg.ID] = reg
+	}
+	for _, unreg := range removed {
+		delete(c.registrations, unreg.ID)
+	}
+	handler := c.capabilitiesChanged
+	c.capabilitiesMu.Unlock()
+
+	// Pulling diagnostics may have been registered or unregistered
+	if caps := c.ServerCapabilities(); caps != nil {
+		c.SetDiagnosticPull(HasPullDiagnosticsSupport(caps))
+	}
+
+	if handler != nil {
+		go handler()
+	}
+}
@@ -0,0 +1,102 @@
+package lsp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// TestLateRenameRegistration simulates a server that only registers rename after
+// initialization and later unregisters it
+func TestLateRenameRegistration(t *testing.T) {
+	client := &Client{}
+	if caps := client.ServerCapabilities(); caps != nil {
+		t.Fatalf("Expected no capabilities before initialization, got %+v", caps)
+	}
+
+	client.SetServerCapabilities(&protocol.ServerCapabilities{
+		HoverProvider: &protocol.Or_ServerCapabilities_hoverProvider{Value: true},
+	})
+	changed := make(chan struct{}, 2)
+	client.SetCapabilitiesChangedHandler(func() { changed <- struct{}{} })
+
+	if HasRenameSupport(client.ServerCapabilities()) {
+		t.Fatal("Expected rename to be unsupported before registration")
+	}
+
+	_, err := HandleRegisterCapability(client, []byte(`{"registrations": [
+		{"id": "rename-1", "method": "textDocument/rename", "registerOptions": {"prepareProvider": true}}
+	]}`))
+	if err != nil {
+		t.Fatalf("HandleRegisterCapability failed: %v", err)
+	}
+	waitForCapabilitiesChanged(t, changed)
+
+	caps := client.ServerCapabilities()
+	if !HasRenameSupport(caps) {
+		t.Error("Expected rename to be supported after registration")
+	}
+	if !HasHoverSupport(caps) {
+		t.Error("Expected static hover support to be kept")
+	}
+
+	_, err = HandleUnregisterCapability(client, []byte(`{"unregisterations": [{"id": "rename-1", "method": "textDocument/rename"}]}`))
+	if err != nil {
+		t.Fatalf("HandleUnregisterCapability failed: %v", err)
+	}
+	waitForCapabilitiesChanged(t, changed)
+
+	caps = client.ServerCapabilities()
+	if HasRenameSupport(caps) {
+		t.Error("Expected rename to be unsupported after unregistration")
+	}
+	if !HasHoverSupport(caps) {
+		t.Error("Expected static hover support to survive unregistration")
+	}
+}
+
+func TestDynamicRegistrationOptions(t *testing.T) {
+	client := &Client{}
+	client.SetServerCapabilities(&protocol.ServerCapabilities{TextDocumentSync: float64(protocol.Incremental)})
+
+	_, err := HandleRegisterCapability(client, []byte(`{"registrations": [
+		{"id": "1", "method": "textDocument/codeAction", "registerOptions": {"codeActionKinds": ["quickfix", "source.organizeImports"]}},
+		{"id": "2", "method": "workspace/executeCommand", "registerOptions": {"commands": ["gopls.tidy"]}},
+		{"id": "3", "method": "textDocument/didSave", "registerOptions": {"includeText": true}},
+		{"id": "4", "method": "textDocument/diagnostic", "registerOptions": {"interFileDependencies": true}},
+		{"id": "5", "method": "workspace/didChangeWatchedFiles", "registerOptions": {"watchers": []}}
+	]}`))
+	if err != nil {
+		t.Fatalf("HandleRegisterCapability failed: %v", err)
+	}
+
+	caps := client.ServerCapabilities()
+	if !HasCodeActionKindSupport(caps, protocol.SourceOrganizeImports) {
+		t.Error("Expected organize imports code actions to be supported")
+	}
+	if HasCodeActionKindSupport(caps, protocol.SourceFixAll) {
+		t.Error("Expected fix all code actions to be unsupported")
+	}
+	if caps.ExecuteCommandProvider == nil || len(caps.ExecuteCommandProvider.Commands) != 1 || caps.ExecuteCommandProvider.Commands[0] != "gopls.tidy" {
+		t.Errorf("Unexpected execute command provider: %+v", caps.ExecuteCommandProvider)
+	}
+	if save := TextDocumentSaveOptions(caps); save == nil || !save.IncludeText {
+		t.Errorf("Expected save notifications with text, got %+v", save)
+	}
+	if kind := TextDocumentSyncKind(caps); kind != protocol.Incremental {
+		t.Errorf("Expected the sync kind to be kept, got %v", kind)
+	}
+	if !client.DiagnosticPull() {
+		t.Error("Expected diagnostics to be pulled after registration")
+	}
+}
+
+func waitForCapabilitiesChanged(t *testing.T, changed <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Capabilities changed handler was not called")
+	}
+}
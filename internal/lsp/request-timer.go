@@ -0,0 +1,49 @@
+package lsp
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// RequestTimer adds up the number of requests made with a context returned by
+// WithRequestTimer and the time they spent waiting for the server, e.g. to tell how much of
+// a tool call is spent in the server rather than formatting its responses
+type RequestTimer struct {
+	requests atomic.Int64
+	elapsed  atomic.Int64 // Nanoseconds
+}
+
+type requestTimerKey struct{}
+
+// WithRequestTimer returns a context that adds every request made with it to timer
+func WithRequestTimer(ctx context.Context, timer *RequestTimer) context.Context {
+	return context.WithValue(ctx, requestTimerKey{}, timer)
+}
+
+// RequestTimerFrom returns the timer of a context returned by WithRequestTimer, or nil
+func RequestTimerFrom(ctx context.Context) *RequestTimer {
+	timer, _ := ctx.Value(requestTimerKey{}).(*RequestTimer)
+	return timer
+}
+
+// Requests returns the number of requests timed so far
+func (t *RequestTimer) Requests() int64 {
+	return t.requests.Load()
+}
+
+// Elapsed returns the total time of the requests timed so far. Requests made concurrently
+// are all counted, so this may exceed the time they took together.
+func (t *RequestTimer) Elapsed() time.Duration {
+	return time.Duration(t.elapsed.Load())
+}
+
+// timeRequest adds a request that started at start to the timer of ctx, if any
+func timeRequest(ctx context.Context, start time.Time) {
+	timer := RequestTimerFrom(ctx)
+	if timer == nil {
+		return
+	}
+	timer.requests.Add(1)
+	timer.elapsed.Add(int64(time.Since(start)))
+}
@@ -0,0 +1,28 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeRequest(t *testing.T) {
+	// Without a timer nothing is timed
+	timeRequest(context.Background(), time.Now())
+
+	timer := &RequestTimer{}
+	ctx := WithRequestTimer(context.Background(), timer)
+	if RequestTimerFrom(ctx) != timer {
+		t.Fatalf("Expected the timer of the context")
+	}
+
+	timeRequest(ctx, time.Now().Add(-2*time.Second))
+	timeRequest(ctx, time.Now().Add(-time.Second))
+
+	if timer.Requests() != 2 {
+		t.Errorf("Expected 2 requests, got %d", timer.Requests())
+	}
+	if elapsed := timer.Elapsed(); elapsed < 3*time.Second || elapsed > 4*time.Second {
+		t.Errorf("Expected about 3s elapsed, got %s", elapsed)
+	}
+}
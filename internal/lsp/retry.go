@@ -0,0 +1,96 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRequestRetries is how many times a document request is retried after reopening the
+// document, unless overridden by LSP_REQUEST_RETRIES
+const DefaultRequestRetries = 1
+
+// requestRetryBackoff is the delay before the first retry; later retries wait longer
+const requestRetryBackoff = 500 * time.Millisecond
+
+// requestRetriesFromEnv reads the retry count from LSP_REQUEST_RETRIES
+func requestRetriesFromEnv() int {
+	if envRetries := os.Getenv("LSP_REQUEST_RETRIES"); envRetries != "" {
+		if val, err := strconv.Atoi(envRetries); err == nil && val >= 0 {
+			return val
+		}
+	}
+	return DefaultRequestRetries
+}
+
+// SetRequestRetries sets how many times RetryDocumentRequest retries a request. 0 disables retries.
+func (c *Client) SetRequestRetries(retries int) {
+	c.requestRetries.Store(int32(max(retries, 0)))
+}
+
+// ReopenFile closes and reopens a file so the server reads it afresh
+func (c *Client) ReopenFile(ctx context.Context, filepath string) error {
+	if err := c.CloseFile(ctx, filepath); err != nil {
+		return err
+	}
+	return c.OpenFile(ctx, filepath)
+}
+
+// RetryDocumentRequest runs a textDocument request for filePath. Servers that are still
+// indexing may report the document as unknown or return nothing right after it is opened,
+// so if the request fails with an unknown document error or isEmpty reports an empty result,
+// the document is reopened and the request retried after a short backoff, up to the
+// configured number of retries.
+func RetryDocumentRequest[T any](ctx context.Context, c *Client, filePath string, isEmpty func(T) bool, request func() (T, error)) (T, error) {
+	result, err := request()
+	retries := int(c.requestRetries.Load())
+
+	for attempt := 1; attempt <= retries; attempt++ {
+		if err != nil && !isUnknownDocumentError(err) {
+			return result, err
+		}
+		if err == nil && !isEmpty(result) {
+			return result, nil
+		}
+
+		reason := "empty result"
+		if err != nil {
+			reason = err.Error()
+		}
+		lspLogger.Debug("Retrying request for %s (attempt %d of %d) after %s", filePath, attempt, retries, reason)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(requestRetryBackoff * time.Duration(attempt)):
+		}
+
+		if reopenErr := c.ReopenFile(ctx, filePath); reopenErr != nil {
+			lspLogger.Warn("Failed to reopen %s before retrying: %v", filePath, reopenErr)
+		}
+		result, err = request()
+	}
+
+	return result, err
+}
+
+// isUnknownDocumentError reports whether err means the server does not know the document yet
+func isUnknownDocumentError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"unknown document",
+		"document not found",
+		"file not found",
+		"not open",
+		"no views",
+		"no package metadata",
+		"no such document",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
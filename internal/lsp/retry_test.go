@@ -0,0 +1,93 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRetryDocumentRequest(t *testing.T) {
+	isEmpty := func(r []string) bool { return len(r) == 0 }
+	// A file that does not exist, so reopening fails without talking to a server
+	const filePath = "/nonexistent/retry_test.go"
+
+	tests := []struct {
+		name          string
+		retries       int
+		responses     []error
+		results       [][]string
+		expectedCalls int
+		expectErr     bool
+		expectResult  bool
+	}{
+		{
+			name:          "success on first try",
+			retries:       1,
+			results:       [][]string{{"ok"}},
+			responses:     []error{nil},
+			expectedCalls: 1,
+			expectResult:  true,
+		},
+		{
+			name:          "empty result is retried",
+			retries:       1,
+			results:       [][]string{nil, {"ok"}},
+			responses:     []error{nil, nil},
+			expectedCalls: 2,
+			expectResult:  true,
+		},
+		{
+			name:          "unknown document error is retried",
+			retries:       1,
+			results:       [][]string{nil, {"ok"}},
+			responses:     []error{errors.New("no views for file:///a.go"), nil},
+			expectedCalls: 2,
+			expectResult:  true,
+		},
+		{
+			name:          "other errors are not retried",
+			retries:       1,
+			results:       [][]string{nil},
+			responses:     []error{errors.New("internal error")},
+			expectedCalls: 1,
+			expectErr:     true,
+		},
+		{
+			name:          "gives up after the configured retries",
+			retries:       2,
+			results:       [][]string{nil, nil, nil},
+			responses:     []error{nil, nil, nil},
+			expectedCalls: 3,
+		},
+		{
+			name:          "retries disabled",
+			retries:       0,
+			results:       [][]string{nil},
+			responses:     []error{nil},
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{openFiles: make(map[string]*OpenFileInfo)}
+			client.SetRequestRetries(tt.retries)
+
+			calls := 0
+			result, err := RetryDocumentRequest(context.Background(), client, filePath, isEmpty, func() ([]string, error) {
+				calls++
+				return tt.results[calls-1], tt.responses[calls-1]
+			})
+
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if (len(result) > 0) != tt.expectResult {
+				t.Errorf("Unexpected result: %v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// DefaultServerLogLines is the number of stderr and window/logMessage lines kept from the
+// language server
+const DefaultServerLogLines = 1000
+
+// lineBuffer is a bounded ring buffer of text lines that keeps the most recent ones
+type lineBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int // Index the next line is written to once the buffer is full
+	full  bool
+}
+
+func newLineBuffer(capacity int) *lineBuffer {
+	return &lineBuffer{lines: make([]string, 0, capacity)}
+}
+
+func (b *lineBuffer) add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		b.lines = append(b.lines, line)
+		b.full = len(b.lines) == cap(b.lines)
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+}
+
+// last returns up to n of the most recent lines, oldest first. n <= 0 returns all of them.
+func (b *lineBuffer) last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := append(append([]string{}, b.lines[b.next:]...), b.lines[:b.next]...)
+	if n > 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	return ordered
+}
+
+// captureStderr logs every line the server writes to stderr and keeps the most recent ones
+// for ServerLogs. It returns when stderr is closed, then closes stderrDone.
+func (c *Client) captureStderr(stderr io.Reader) {
+	defer close(c.stderrDone)
+
+	scanner := bufio.NewScanner(stderr)
+	// Panics and crash reports can have long lines
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		c.logLines.add(line)
+		processLogger.Info("%s", line)
+	}
+	if err := scanner.Err(); err != nil {
+		lspLogger.Error("Error reading LSP server stderr: %v", err)
+	}
+}
+
+// ServerLogs returns up to n of the most recent lines the language server wrote to stderr or
+// sent with window/logMessage or $/logTrace, oldest first. n <= 0 returns every line kept.
+func (c *Client) ServerLogs(n int) []string {
+	if c.logLines == nil {
+		return nil
+	}
+	return c.logLines.last(n)
+}
@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineBuffer(t *testing.T) {
+	buffer := newLineBuffer(3)
+	if lines := buffer.last(0); len(lines) != 0 {
+		t.Errorf("Expected no lines, got %v", lines)
+	}
+
+	buffer.add("one")
+	buffer.add("two")
+	if lines := buffer.last(0); !reflect.DeepEqual(lines, []string{"one", "two"}) {
+		t.Errorf("Expected [one two], got %v", lines)
+	}
+
+	// Once full, the oldest lines are dropped
+	buffer.add("three")
+	buffer.add("four")
+	buffer.add("five")
+	if lines := buffer.last(0); !reflect.DeepEqual(lines, []string{"three", "four", "five"}) {
+		t.Errorf("Expected [three four five], got %v", lines)
+	}
+	if lines := buffer.last(2); !reflect.DeepEqual(lines, []string{"four", "five"}) {
+		t.Errorf("Expected [four five], got %v", lines)
+	}
+	if lines := buffer.last(10); !reflect.DeepEqual(lines, []string{"three", "four", "five"}) {
+		t.Errorf("Expected [three four five], got %v", lines)
+	}
+}
+
+func TestServerLogs(t *testing.T) {
+	client, err := NewClient("sh", "-c", "echo starting >&2; echo 'panic: boom' >&2")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Server process failed: %v", err)
+	}
+
+	// Close waits for stderr to be read to the end
+	if lines := client.ServerLogs(0); !reflect.DeepEqual(lines, []string{"starting", "panic: boom"}) {
+		t.Errorf("Expected both stderr lines, got %v", lines)
+	}
+	if lines := client.ServerLogs(1); !reflect.DeepEqual(lines, []string{"panic: boom"}) {
+		t.Errorf("Expected the last stderr line, got %v", lines)
+	}
+}
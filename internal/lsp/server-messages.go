@@ -0,0 +1,76 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// serverMessageLines is the number of window/showMessage(Request) messages kept
+const serverMessageLines = 20
+
+// HandleShowMessageRequest answers window/showMessageRequest with the first action, or null
+// if there are none, as if the user picked the default. There is no user to ask, and some
+// servers block until they get an answer.
+func HandleShowMessageRequest(client *Client, params json.RawMessage) (any, error) {
+	var msg protocol.ShowMessageRequestParams
+	if err := json.Unmarshal(params, &msg); err != nil {
+		lspLogger.Error("Error unmarshaling message request: %v", err)
+		return nil, err
+	}
+
+	var answer *protocol.MessageActionItem
+	if len(msg.Actions) > 0 {
+		answer = &msg.Actions[0]
+	}
+
+	text := msg.Message
+	if answer != nil {
+		titles := make([]string, len(msg.Actions))
+		for i, action := range msg.Actions {
+			titles[i] = action.Title
+		}
+		text = fmt.Sprintf("%s (actions: %s; answered %q)", msg.Message, strings.Join(titles, ", "), answer.Title)
+	}
+	lspLogger.Info("Server request: %s", text)
+	client.recordMessage(msg.Type, text)
+
+	return answer, nil
+}
+
+// recordMessage keeps a message shown to the user for ServerMessages and in the server logs
+func (c *Client) recordMessage(messageType protocol.MessageType, message string) {
+	line := formatServerMessage(messageType, message)
+	c.messages.add(line)
+	c.logLines.add(line)
+}
+
+// ServerMessages returns the most recent messages the server asked to show to the user with
+// window/showMessage or window/showMessageRequest, oldest first
+func (c *Client) ServerMessages() []string {
+	if c.messages == nil {
+		return nil
+	}
+	return c.messages.last(0)
+}
+
+func formatServerMessage(messageType protocol.MessageType, message string) string {
+	var level string
+	switch messageType {
+	case protocol.Error:
+		level = "error"
+	case protocol.Warning:
+		level = "warning"
+	case protocol.Info:
+		level = "info"
+	case protocol.Log:
+		level = "log"
+	case protocol.Debug:
+		level = "debug"
+	default:
+		level = fmt.Sprintf("type %d", messageType)
+	}
+	return fmt.Sprintf("[%s] %s", level, message)
+}
@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+func newMessagesTestClient() *Client {
+	return &Client{
+		logLines: newLineBuffer(DefaultServerLogLines),
+		messages: newLineBuffer(serverMessageLines),
+	}
+}
+
+func TestHandleShowMessageRequest(t *testing.T) {
+	client := newMessagesTestClient()
+
+	// The first action is picked so the server does not wait for an answer
+	result, err := HandleShowMessageRequest(client, []byte(`{"type": 3, "message": "Download the toolchain?", "actions": [{"title": "Yes"}, {"title": "No"}]}`))
+	if err != nil {
+		t.Fatalf("HandleShowMessageRequest failed: %v", err)
+	}
+	if action, ok := result.(*protocol.MessageActionItem); !ok || action.Title != "Yes" {
+		t.Errorf("Expected the first action, got %#v", result)
+	}
+
+	// Without actions the answer is null
+	result, err = HandleShowMessageRequest(client, []byte(`{"type": 2, "message": "No build configuration found"}`))
+	if err != nil {
+		t.Fatalf("HandleShowMessageRequest failed: %v", err)
+	}
+	if action, ok := result.(*protocol.MessageActionItem); !ok || action != nil {
+		t.Errorf("Expected a nil action, got %#v", result)
+	}
+
+	expected := []string{
+		`[info] Download the toolchain? (actions: Yes, No; answered "Yes")`,
+		"[warning] No build configuration found",
+	}
+	if messages := client.ServerMessages(); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, messages)
+	}
+	if lines := client.ServerLogs(0); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected logs %v, got %v", expected, lines)
+	}
+}
+
+func TestHandleMessages(t *testing.T) {
+	client := newMessagesTestClient()
+
+	HandleLogMessage(client, []byte(`{"type": 4, "message": "Loaded 12 packages"}`))
+	HandleServerMessage(client, []byte(`{"type": 1, "message": "Failed to load workspace"}`))
+
+	// Only shown messages are kept as messages, both are logs
+	if messages := client.ServerMessages(); !reflect.DeepEqual(messages, []string{"[error] Failed to load workspace"}) {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+	expected := []string{"[log] Loaded 12 packages", "[error] Failed to load workspace"}
+	if lines := client.ServerLogs(0); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected logs %v, got %v", expected, lines)
+	}
+}
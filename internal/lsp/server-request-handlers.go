@@ -20,16 +20,31 @@ func RegisterFileWatchHandler(handler FileWatchHandler) {
 
 // Requests
 
-func HandleWorkspaceConfiguration(params json.RawMessage) (any, error) {
-	return []map[string]any{{}}, nil
+// HandleWorkspaceConfiguration answers the server with the configured settings of each
+// requested section, in the order asked, or null for sections that aren't configured
+func HandleWorkspaceConfiguration(c *Client, params json.RawMessage) (any, error) {
+	var configParams protocol.ConfigurationParams
+	if err := json.Unmarshal(params, &configParams); err != nil {
+		lspLogger.Error("Error unmarshaling configuration params: %v", err)
+		return nil, err
+	}
+
+	settings := c.Settings()
+	result := make([]any, len(configParams.Items))
+	for i, item := range configParams.Items {
+		result[i] = configurationSection(settings, item.Section)
+		lspLogger.Debug("Configuration requested for section %q, configured: %v", item.Section, result[i] != nil)
+	}
+	return result, nil
 }
 
-func HandleRegisterCapability(params json.RawMessage) (any, error) {
+func HandleRegisterCapability(c *Client, params json.RawMessage) (any, error) {
 	var registerParams protocol.RegistrationParams
 	if err := json.Unmarshal(params, &registerParams); err != nil {
 		lspLogger.Error("Error unmarshaling registration params: %v", err)
 		return nil, err
 	}
+	c.updateRegistrations(registerParams.Registrations, nil)
 
 	for _, reg := range registerParams.Registrations {
 		lspLogger.Info("Registration received for method: %s, id: %s", reg.Method, reg.ID)
@@ -60,7 +75,22 @@ func HandleRegisterCapability(params json.RawMessage) (any, error) {
 	return nil, nil
 }
 
-func HandleApplyEdit(params json.RawMessage) (any, error) {
+func HandleUnregisterCapability(c *Client, params json.RawMessage) (any, error) {
+	var unregisterParams protocol.UnregistrationParams
+	if err := json.Unmarshal(params, &unregisterParams); err != nil {
+		lspLogger.Error("Error unmarshaling unregistration params: %v", err)
+		return nil, err
+	}
+
+	for _, unreg := range unregisterParams.Unregisterations {
+		lspLogger.Info("Unregistration received for method: %s, id: %s", unreg.Method, unreg.ID)
+	}
+	c.updateRegistrations(nil, unregisterParams.Unregisterations)
+
+	return nil, nil
+}
+
+func HandleApplyEdit(c *Client, params json.RawMessage) (any, error) {
 	var workspaceEdit protocol.ApplyWorkspaceEditParams
 	if err := json.Unmarshal(params, &workspaceEdit); err != nil {
 		return protocol.ApplyWorkspaceEditResult{Applied: false}, err
@@ -68,6 +98,7 @@ func HandleApplyEdit(params json.RawMessage) (any, error) {
 
 	// Apply the edits
 	err := utilities.ApplyWorkspaceEdit(workspaceEdit.Edit)
+	c.InvalidateSymbolCache()
 	if err != nil {
 		lspLogger.Error("Error applying workspace edit: %v", err)
 		return protocol.ApplyWorkspaceEditResult{
@@ -76,6 +107,11 @@ func HandleApplyEdit(params json.RawMessage) (any, error) {
 		}, nil
 	}
 
+	c.recordAppliedEdit(AppliedEdit{
+		Label: workspaceEdit.Label,
+		Files: utilities.WorkspaceEditFiles(workspaceEdit.Edit),
+	})
+
 	return protocol.ApplyWorkspaceEditResult{
 		Applied: true,
 	}, nil
@@ -91,7 +127,7 @@ func workspaceEditFailure(err error) string {
 // Notifications
 
 // HandleServerMessage processes window/showMessage notifications from the server
-func HandleServerMessage(params json.RawMessage) {
+func HandleServerMessage(client *Client, params json.RawMessage) {
 	var msg protocol.ShowMessageParams
 	if err := json.Unmarshal(params, &msg); err != nil {
 		lspLogger.Error("Error unmarshaling server message: %v", err)
@@ -109,6 +145,22 @@ func HandleServerMessage(params json.RawMessage) {
 	default:
 		lspLogger.Debug("Server message: %s", msg.Message)
 	}
+
+	client.recordMessage(msg.Type, msg.Message)
+}
+
+// HandleLogMessage processes window/logMessage notifications, which servers use for the
+// same kind of output as stderr
+func HandleLogMessage(client *Client, params json.RawMessage) {
+	var msg protocol.LogMessageParams
+	if err := json.Unmarshal(params, &msg); err != nil {
+		lspLogger.Error("Error unmarshaling log message: %v", err)
+		return
+	}
+
+	line := formatServerMessage(msg.Type, msg.Message)
+	client.logLines.add(line)
+	processLogger.Debug("%s", line)
 }
 
 // HandleDiagnostics processes textDocument/publishDiagnostics notifications
@@ -119,10 +171,7 @@ func HandleDiagnostics(client *Client, params json.RawMessage) {
 		return
 	}
 
-	// Save diagnostics in client
-	client.diagnosticsMu.Lock()
-	client.diagnostics[diagParams.URI] = diagParams.Diagnostics
-	client.diagnosticsMu.Unlock()
+	client.storeDiagnostics(diagParams.URI, diagParams.Version, diagParams.Diagnostics)
 
 	lspLogger.Info("Received diagnostics for %s: %d items", diagParams.URI, len(diagParams.Diagnostics))
 }
@@ -0,0 +1,119 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// TestHandleApplyEditRecordsEdits verifies that edits the server asks the client to apply
+// are written to disk and recorded so tools can report them
+func TestHandleApplyEditRecordsEdits(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	client := &Client{}
+	before := client.AppliedEditCount()
+
+	params, err := json.Marshal(protocol.ApplyWorkspaceEditParams{
+		Label: "Generate test",
+		Edit: protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				protocol.DocumentUri("file://" + filePath): {{
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 0, Character: 8},
+						End:   protocol.Position{Line: 0, Character: 12},
+					},
+					NewText: "app",
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal params: %v", err)
+	}
+
+	result, err := HandleApplyEdit(client, params)
+	if err != nil {
+		t.Fatalf("HandleApplyEdit failed: %v", err)
+	}
+	if !result.(protocol.ApplyWorkspaceEditResult).Applied {
+		t.Fatalf("Expected edit to be applied")
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "package app\n" {
+		t.Errorf("Unexpected file content: %q", content)
+	}
+
+	edits := client.AppliedEditsSince(before)
+	if len(edits) != 1 {
+		t.Fatalf("Expected 1 applied edit, got %d", len(edits))
+	}
+	if edits[0].Label != "Generate test" || len(edits[0].Files) != 1 || edits[0].Files[0] != filePath {
+		t.Errorf("Unexpected applied edit: %+v", edits[0])
+	}
+	if len(client.AppliedEditsSince(client.AppliedEditCount())) != 0 {
+		t.Errorf("Expected no edits after the current count")
+	}
+}
+
+// TestHandleWorkspaceConfiguration verifies that configuration requests are answered with
+// the configured settings of each section, and null for sections that aren't configured
+func TestHandleWorkspaceConfiguration(t *testing.T) {
+	client := &Client{}
+	request := func(sections ...string) []any {
+		t.Helper()
+		items := make([]protocol.ConfigurationItem, len(sections))
+		for i, section := range sections {
+			items[i] = protocol.ConfigurationItem{Section: section}
+		}
+		params, err := json.Marshal(protocol.ConfigurationParams{Items: items})
+		if err != nil {
+			t.Fatalf("Failed to marshal params: %v", err)
+		}
+		result, err := HandleWorkspaceConfiguration(client, params)
+		if err != nil {
+			t.Fatalf("HandleWorkspaceConfiguration failed: %v", err)
+		}
+		return result.([]any)
+	}
+
+	if result := request("gopls"); len(result) != 1 || result[0] != nil {
+		t.Errorf("Expected null without settings, got %v", result)
+	}
+
+	client.SetSettings(map[string]any{
+		"gopls":                        map[string]any{"staticcheck": true},
+		"python":                       map[string]any{"analysis": map[string]any{"typeCheckingMode": "strict"}},
+		"rust-analyzer.cargo.features": "all",
+	})
+	result := request("gopls", "python.analysis", "rust-analyzer.cargo.features", "clangd", "python.venvPath")
+	if len(result) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(result))
+	}
+	if gopls, ok := result[0].(map[string]any); !ok || gopls["staticcheck"] != true {
+		t.Errorf("Unexpected gopls section: %v", result[0])
+	}
+	if analysis, ok := result[1].(map[string]any); !ok || analysis["typeCheckingMode"] != "strict" {
+		t.Errorf("Unexpected python.analysis section: %v", result[1])
+	}
+	if result[2] != "all" {
+		t.Errorf("Unexpected rust-analyzer.cargo.features section: %v", result[2])
+	}
+	if result[3] != nil || result[4] != nil {
+		t.Errorf("Expected null for unknown sections, got %v and %v", result[3], result[4])
+	}
+
+	if all, ok := request("")[0].(map[string]any); !ok || len(all) != 3 {
+		t.Errorf("Expected all settings for an empty section, got %v", all)
+	}
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultSymbolCacheTTL is how long workspace/symbol results are reused, unless overridden
+// by LSP_SYMBOL_CACHE_TTL
+const DefaultSymbolCacheTTL = 10 * time.Second
+
+// DefaultSymbolCacheSize is the number of queries whose results are kept, unless overridden
+// by LSP_SYMBOL_CACHE_SIZE
+const DefaultSymbolCacheSize = 100
+
+// symbolCacheEntry is a cached workspace/symbol result
+type symbolCacheEntry struct {
+	result  protocol.Or_Result_workspace_symbol
+	fetched time.Time
+}
+
+// symbolCacheFromEnv reads the cache TTL (a duration or a number of seconds, 0 disables the
+// cache) from LSP_SYMBOL_CACHE_TTL and the size from LSP_SYMBOL_CACHE_SIZE
+func symbolCacheFromEnv() (time.Duration, int) {
+	ttl, size := DefaultSymbolCacheTTL, DefaultSymbolCacheSize
+	if env := os.Getenv("LSP_SYMBOL_CACHE_TTL"); env != "" {
+		if d, err := time.ParseDuration(env); err == nil && d >= 0 {
+			ttl = d
+		} else if secs, err := strconv.Atoi(env); err == nil && secs >= 0 {
+			ttl = time.Duration(secs) * time.Second
+		}
+	}
+	if env := os.Getenv("LSP_SYMBOL_CACHE_SIZE"); env != "" {
+		if val, err := strconv.Atoi(env); err == nil && val >= 0 {
+			size = val
+		}
+	}
+	return ttl, size
+}
+
+// SetSymbolCache sets how long workspace/symbol results are reused and for how many queries.
+// A ttl or size of 0 disables the cache.
+func (c *Client) SetSymbolCache(ttl time.Duration, size int) {
+	c.symbolCacheMu.Lock()
+	defer c.symbolCacheMu.Unlock()
+
+	c.symbolCacheTTL = ttl
+	c.symbolCacheSize = size
+	c.symbolCache = make(map[string]symbolCacheEntry)
+}
+
+// InvalidateSymbolCache drops all cached workspace/symbol results, e.g. after files are
+// edited and the positions in them may be stale
+func (c *Client) InvalidateSymbolCache() {
+	c.symbolCacheMu.Lock()
+	defer c.symbolCacheMu.Unlock()
+
+	c.symbolCacheGeneration++
+	if len(c.symbolCache) > 0 {
+		c.symbolCache = make(map[string]symbolCacheEntry)
+	}
+}
+
+// CachedSymbol sends a workspace/symbol request like SymbolWithPartialResults, but reuses the
+// result of an earlier request with the same query if it is recent enough and no file was
+// edited since
+func (c *Client) CachedSymbol(ctx context.Context, params protocol.WorkspaceSymbolParams) (protocol.Or_Result_workspace_symbol, error) {
+	c.symbolCacheMu.Lock()
+	ttl, size, generation := c.symbolCacheTTL, c.symbolCacheSize, c.symbolCacheGeneration
+	if ttl <= 0 || size <= 0 {
+		c.symbolCacheMu.Unlock()
+		return c.SymbolWithPartialResults(ctx, params)
+	}
+	if entry, ok := c.symbolCache[params.Query]; ok && time.Since(entry.fetched) < ttl {
+		c.symbolCacheMu.Unlock()
+		lspLogger.Debug("Using cached workspace/symbol result for %q", params.Query)
+		return entry.result, nil
+	}
+	c.symbolCacheMu.Unlock()
+
+	result, err := c.SymbolWithPartialResults(ctx, params)
+	if err != nil {
+		return result, err
+	}
+
+	c.symbolCacheMu.Lock()
+	defer c.symbolCacheMu.Unlock()
+
+	// Files edited while the request was pending may not be reflected in the result
+	if generation != c.symbolCacheGeneration {
+		return result, nil
+	}
+	if _, ok := c.symbolCache[params.Query]; !ok && len(c.symbolCache) >= size {
+		c.evictOldestSymbol()
+	}
+	c.symbolCache[params.Query] = symbolCacheEntry{result: result, fetched: time.Now()}
+	return result, nil
+}
+
+// evictOldestSymbol removes the least recently fetched cache entry. The caller must hold
+// symbolCacheMu.
+func (c *Client) evictOldestSymbol() {
+	var oldestQuery string
+	var oldest time.Time
+	for query, entry := range c.symbolCache {
+		if oldest.IsZero() || entry.fetched.Before(oldest) {
+			oldestQuery, oldest = query, entry.fetched
+		}
+	}
+	delete(c.symbolCache, oldestQuery)
+}
@@ -0,0 +1,162 @@
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// RequestHandler answers a request sent to the mock server. Returning an *lsp.ResponseError
+// sends that error code, any other error is sent as an internal error (-32603).
+type RequestHandler func(params json.RawMessage) (any, error)
+
+// ReceivedMessage is a request or notification the client sent to the mock server
+type ReceivedMessage struct {
+	Method string
+	Params json.RawMessage
+}
+
+// MockServer is an in-process language server that answers requests with scripted responses,
+// so tools can be tested without a real server binary. Requests without a handler fail with
+// "method not found", as a server lacking the capability would answer.
+type MockServer struct {
+	// Client is connected to the mock server
+	Client *lsp.Client
+
+	mu       sync.Mutex
+	handlers map[string]RequestHandler
+	received []ReceivedMessage
+
+	// Server side of the connection
+	reader  *bufio.Reader
+	writer  io.WriteCloser
+	writeMu sync.Mutex
+
+	done chan struct{}
+}
+
+// NewMockServer starts a mock server and connects a client to it. Call Close when done.
+func NewMockServer() *MockServer {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	s := &MockServer{
+		handlers: make(map[string]RequestHandler),
+		reader:   bufio.NewReader(serverReader),
+		writer:   serverWriter,
+		done:     make(chan struct{}),
+	}
+	s.Client = lsp.NewClientFromStreams(clientReader, clientWriter)
+
+	go s.serve()
+	return s
+}
+
+// Handle registers a handler for requests of the given method
+func (s *MockServer) Handle(method string, handler RequestHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+}
+
+// Respond answers every request of the given method with result
+func (s *MockServer) Respond(method string, result any) {
+	s.Handle(method, func(json.RawMessage) (any, error) {
+		return result, nil
+	})
+}
+
+// RespondRaw answers every request of the given method with the JSON text result verbatim,
+// e.g. to script unusually shaped or wrongly typed responses. result must be valid JSON.
+func (s *MockServer) RespondRaw(method string, result string) {
+	s.Respond(method, json.RawMessage(result))
+}
+
+// RespondError answers every request of the given method with a JSON-RPC error
+func (s *MockServer) RespondError(method string, code int, message string) {
+	s.Handle(method, func(json.RawMessage) (any, error) {
+		return nil, &lsp.ResponseError{Code: code, Message: message}
+	})
+}
+
+// Notify sends a notification from the server to the client, e.g. textDocument/publishDiagnostics
+func (s *MockServer) Notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal params: %w", err)
+	}
+	return s.write(&lsp.Message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// Received returns the params of every request and notification of the given method the
+// client has sent so far, in order
+func (s *MockServer) Received(method string) []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var params []json.RawMessage
+	for _, msg := range s.received {
+		if msg.Method == method {
+			params = append(params, msg.Params)
+		}
+	}
+	return params
+}
+
+// Close disconnects the client and stops the mock server
+func (s *MockServer) Close() error {
+	err := s.Client.Close()
+	_ = s.writer.Close()
+	<-s.done
+	return err
+}
+
+func (s *MockServer) serve() {
+	defer close(s.done)
+	for {
+		msg, err := lsp.ReadMessage(s.reader)
+		if err != nil {
+			return
+		}
+		if msg.Method == "" {
+			continue // Response to a server request, not used by the mock
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, ReceivedMessage{Method: msg.Method, Params: msg.Params})
+		handler, ok := s.handlers[msg.Method]
+		s.mu.Unlock()
+
+		if msg.ID == nil || msg.ID.Value == nil {
+			continue // Notification
+		}
+
+		response := &lsp.Message{JSONRPC: "2.0", ID: msg.ID}
+		if !ok {
+			response.Error = &lsp.ResponseError{Code: -32601, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+		} else if result, err := handler(msg.Params); err != nil {
+			var responseErr *lsp.ResponseError
+			if !errors.As(err, &responseErr) {
+				responseErr = &lsp.ResponseError{Code: -32603, Message: err.Error()}
+			}
+			response.Error = responseErr
+		} else if response.Result, err = json.Marshal(result); err != nil {
+			response.Error = &lsp.ResponseError{Code: -32603, Message: err.Error()}
+		}
+
+		if err := s.write(response); err != nil {
+			return
+		}
+	}
+}
+
+func (s *MockServer) write(msg *lsp.Message) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return lsp.WriteMessage(s.writer, msg)
+}
@@ -0,0 +1,42 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SetTraceLevel asks the server to trace its handling of requests at the given level with
+// $/setTrace. Traces arrive as $/logTrace notifications and are kept with the server logs.
+func (c *Client) SetTraceLevel(ctx context.Context, value protocol.TraceValue) error {
+	if err := c.SetTrace(ctx, protocol.SetTraceParams{Value: value}); err != nil {
+		return err
+	}
+	c.traceLevel.Store(string(value))
+	return nil
+}
+
+// TraceLevel returns the trace level last set with SetTraceLevel, "off" if none was set
+func (c *Client) TraceLevel() protocol.TraceValue {
+	if level, ok := c.traceLevel.Load().(string); ok {
+		return protocol.TraceValue(level)
+	}
+	return protocol.Off
+}
+
+// HandleLogTrace processes $/logTrace notifications, which servers send while tracing is on
+func HandleLogTrace(client *Client, params json.RawMessage) {
+	var trace protocol.LogTraceParams
+	if err := json.Unmarshal(params, &trace); err != nil {
+		lspLogger.Error("Error unmarshaling log trace: %v", err)
+		return
+	}
+
+	line := "[trace] " + trace.Message
+	if trace.Verbose != "" {
+		line += "\n" + trace.Verbose
+	}
+	client.logLines.add(line)
+	processLogger.Debug("%s", line)
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/logging"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
 // Create component-specific loggers
@@ -29,19 +31,23 @@ func WriteMessage(w io.Writer, msg *Message) error {
 	// Wire protocol log (more detailed)
 	wireLogger.Debug("-> Sending: %s", string(data))
 
-	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(data))
-	if err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
+	// Write the header and body at once, so a frame is never split across writes
+	frame := append([]byte(fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))), data...)
+	if _, err := w.Write(frame); err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
 	}
 
 	return nil
 }
 
+// writeMessage sends msg to the server. Requests are sent from concurrent tool calls, so
+// writes are serialized to keep each frame whole on the stream.
+func (c *Client) writeMessage(msg *Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return WriteMessage(c.stdin, msg)
+}
+
 // ReadMessage reads a single LSP message from the given reader
 func ReadMessage(r *bufio.Reader) (*Message, error) {
 	// Read headers
@@ -96,6 +102,8 @@ func ReadMessage(r *bufio.Reader) (*Message, error) {
 
 // handleMessages reads and dispatches messages in a loop
 func (c *Client) handleMessages() {
+	defer close(c.done)
+
 	for {
 		msg, err := ReadMessage(c.stdout)
 		if err != nil {
@@ -150,7 +158,7 @@ func (c *Client) handleMessages() {
 			}
 
 			// Send response back to server
-			if err := WriteMessage(c.stdin, response); err != nil {
+			if err := c.writeMessage(response); err != nil {
 				lspLogger.Error("Error sending response to server: %v", err)
 			}
 
@@ -161,11 +169,16 @@ func (c *Client) handleMessages() {
 		if msg.Method != "" && (msg.ID == nil || msg.ID.Value == nil) {
 			c.notificationMu.RLock()
 			handler, ok := c.notificationHandlers[msg.Method]
+			ordered := c.orderedNotifications[msg.Method]
 			c.notificationMu.RUnlock()
 
 			if ok {
 				lspLogger.Debug("Handling notification: %s", msg.Method)
-				go handler(msg.Params)
+				if ordered {
+					handler(msg.Params)
+				} else {
+					go handler(msg.Params)
+				}
 			} else {
 				lspLogger.Debug("No handler for notification: %s", msg.Method)
 			}
@@ -196,6 +209,7 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 	id := c.nextID.Add(1)
 
 	lspLogger.Debug("Making call: method=%s id=%v", method, id)
+	defer timeRequest(ctx, time.Now())
 
 	msg, err := NewRequest(id, method, params)
 	if err != nil {
@@ -217,16 +231,38 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 	}()
 
 	// Send request
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	if c.Exited() {
+		return ErrServerExited
+	}
+	if err := c.writeMessage(msg); err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
 	lspLogger.Debug("Waiting for response to request ID: %v", msg.ID)
 
-	// Wait for response
-	resp := <-ch
+	// Wait for response, giving up if the caller's context is cancelled or times out
+	var resp *Message
+	select {
+	case resp = <-ch:
+	case <-c.done:
+		// The message loop delivers a response before it stops, so check for one first
+		select {
+		case resp = <-ch:
+		default:
+			lspLogger.Warn("Request %s (ID: %v) failed: language server exited", method, msg.ID)
+			return ErrServerExited
+		}
+	case <-ctx.Done():
+		lspLogger.Warn("Request %s (ID: %v) abandoned: %v", method, msg.ID, context.Cause(ctx))
+		// Tell the server so that it stops working on the request
+		if err := c.Notify(context.Background(), "$/cancelRequest", protocol.CancelParams{ID: id}); err != nil {
+			lspLogger.Debug("Failed to cancel request %v: %v", msg.ID, err)
+		}
+		return context.Cause(ctx)
+	}
 
 	lspLogger.Debug("Received response for request ID: %v", msg.ID)
+	recordRawResponse(ctx, method, resp)
 
 	if resp.Error != nil {
 		lspLogger.Error("Request failed: %s (code: %d)", resp.Error.Message, resp.Error.Code)
@@ -258,7 +294,7 @@ func (c *Client) Notify(ctx context.Context, method string, params any) error {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
 
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	if err := c.writeMessage(msg); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
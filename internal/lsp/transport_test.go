@@ -0,0 +1,59 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCallRespectsContextTimeout verifies that Call returns once its context expires
+// instead of waiting forever on a server that never responds
+func TestCallRespectsContextTimeout(t *testing.T) {
+	// A process that reads stdin but never writes a response
+	client, err := NewClient("sleep", "5")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	timeoutErr := errors.New("language server timed out after 100ms")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 100*time.Millisecond, timeoutErr)
+	defer cancel()
+
+	start := time.Now()
+	err = client.Call(ctx, "textDocument/hover", map[string]any{}, nil)
+	if !errors.Is(err, timeoutErr) {
+		t.Fatalf("Call() error = %v, expected %v", err, timeoutErr)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Call() took %v, expected it to return shortly after the timeout", elapsed)
+	}
+}
+
+// recordingWriter records the size of each Write call
+type recordingWriter struct {
+	writes []int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return len(p), nil
+}
+
+// TestWriteMessageSingleWrite verifies that a message's header and body are written at once,
+// so that messages sent concurrently can't interleave within a frame
+func TestWriteMessageSingleWrite(t *testing.T) {
+	msg, err := NewNotification("initialized", map[string]any{})
+	if err != nil {
+		t.Fatalf("Failed to create notification: %v", err)
+	}
+
+	var w recordingWriter
+	if err := WriteMessage(&w, msg); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if len(w.writes) != 1 {
+		t.Errorf("WriteMessage() made %d writes, expected 1", len(w.writes))
+	}
+}
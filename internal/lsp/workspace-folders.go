@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// workspaceFolder returns the protocol representation of an absolute directory
+func workspaceFolder(dir string) protocol.WorkspaceFolder {
+	return protocol.WorkspaceFolder{
+		URI:  protocol.URI("file://" + dir),
+		Name: dir,
+	}
+}
+
+// setWorkspaceFolders records the roots sent in the initialize request. The first is the
+// primary root used as rootUri; duplicates are dropped.
+func (c *Client) setWorkspaceFolders(dirs []string) []protocol.WorkspaceFolder {
+	c.workspaceFoldersMu.Lock()
+	defer c.workspaceFoldersMu.Unlock()
+
+	c.workspaceFolders = nil
+	folders := make([]protocol.WorkspaceFolder, 0, len(dirs))
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if slices.Contains(c.workspaceFolders, dir) {
+			continue
+		}
+		c.workspaceFolders = append(c.workspaceFolders, dir)
+		folders = append(folders, workspaceFolder(dir))
+	}
+	return folders
+}
+
+// WorkspaceFolders returns the workspace roots known to the server, primary root first
+func (c *Client) WorkspaceFolders() []string {
+	c.workspaceFoldersMu.RLock()
+	defer c.workspaceFoldersMu.RUnlock()
+	return append([]string(nil), c.workspaceFolders...)
+}
+
+// AddWorkspaceFolders adds roots to the workspace and notifies the server with
+// workspace/didChangeWorkspaceFolders. Roots that are already known are ignored.
+func (c *Client) AddWorkspaceFolders(ctx context.Context, dirs ...string) error {
+	c.workspaceFoldersMu.Lock()
+	defer c.workspaceFoldersMu.Unlock()
+
+	var added []protocol.WorkspaceFolder
+	var addedDirs []string
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("workspace folder must be an absolute path: %s", dir)
+		}
+		dir = filepath.Clean(dir)
+		if slices.Contains(c.workspaceFolders, dir) || slices.Contains(addedDirs, dir) {
+			continue
+		}
+		added = append(added, workspaceFolder(dir))
+		addedDirs = append(addedDirs, dir)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	err := c.DidChangeWorkspaceFolders(ctx, protocol.DidChangeWorkspaceFoldersParams{
+		Event: protocol.WorkspaceFoldersChangeEvent{
+			Added:   added,
+			Removed: []protocol.WorkspaceFolder{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify server of new workspace folders: %w", err)
+	}
+
+	c.workspaceFolders = append(c.workspaceFolders, addedDirs...)
+	return nil
+}
+
+// RemoveWorkspaceFolders removes roots from the workspace and notifies the server.
+// The primary root cannot be removed.
+func (c *Client) RemoveWorkspaceFolders(ctx context.Context, dirs ...string) error {
+	c.workspaceFoldersMu.Lock()
+	defer c.workspaceFoldersMu.Unlock()
+
+	var removed []protocol.WorkspaceFolder
+	var removedDirs []string
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if len(c.workspaceFolders) > 0 && dir == c.workspaceFolders[0] {
+			return fmt.Errorf("cannot remove the primary workspace folder %s", dir)
+		}
+		if !slices.Contains(c.workspaceFolders, dir) {
+			return fmt.Errorf("%s is not a workspace folder", dir)
+		}
+		if slices.Contains(removedDirs, dir) {
+			continue
+		}
+		removed = append(removed, workspaceFolder(dir))
+		removedDirs = append(removedDirs, dir)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	err := c.DidChangeWorkspaceFolders(ctx, protocol.DidChangeWorkspaceFoldersParams{
+		Event: protocol.WorkspaceFoldersChangeEvent{
+			Added:   []protocol.WorkspaceFolder{},
+			Removed: removed,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify server of removed workspace folders: %w", err)
+	}
+
+	remaining := c.workspaceFolders[:0]
+	for _, dir := range c.workspaceFolders {
+		if !slices.Contains(removedDirs, dir) {
+			remaining = append(remaining, dir)
+		}
+	}
+	c.workspaceFolders = remaining
+	return nil
+}
+
+// RootForPath returns the innermost workspace folder containing path. Relative paths are
+// resolved against the current directory. If no roots are configured every path is accepted.
+func (c *Client) RootForPath(path string) (string, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	c.workspaceFoldersMu.RLock()
+	defer c.workspaceFoldersMu.RUnlock()
+
+	if len(c.workspaceFolders) == 0 {
+		return "", true
+	}
+
+	root := ""
+	for _, dir := range c.workspaceFolders {
+		if (absPath == dir || strings.HasPrefix(absPath, dir+string(filepath.Separator))) && len(dir) > len(root) {
+			root = dir
+		}
+	}
+	return root, root != ""
+}
+
+// HandleWorkspaceFolders answers workspace/workspaceFolders requests with the current roots
+func HandleWorkspaceFolders(c *Client) (any, error) {
+	dirs := c.WorkspaceFolders()
+	folders := make([]protocol.WorkspaceFolder, 0, len(dirs))
+	for _, dir := range dirs {
+		folders = append(folders, workspaceFolder(dir))
+	}
+	return folders, nil
+}
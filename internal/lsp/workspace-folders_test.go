@@ -0,0 +1,77 @@
+package lsp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRootForPath(t *testing.T) {
+	client := &Client{}
+
+	if _, ok := client.RootForPath("/anywhere/file.go"); !ok {
+		t.Error("Expected every path to be accepted when no roots are configured")
+	}
+
+	client.setWorkspaceFolders([]string{"/repo", "/repo/services/api", "/other/", "/repo"})
+	if folders := client.WorkspaceFolders(); !reflect.DeepEqual(folders, []string{"/repo", "/repo/services/api", "/other"}) {
+		t.Errorf("Unexpected workspace folders: %v", folders)
+	}
+
+	tests := []struct {
+		path         string
+		expectedRoot string
+		expectedOk   bool
+	}{
+		{"/repo/main.go", "/repo", true},
+		{"/repo", "/repo", true},
+		{"/repo/services/api/handler.go", "/repo/services/api", true},
+		{"/other/lib/lib.go", "/other", true},
+		{"/repository/main.go", "", false},
+		{"/tmp/main.go", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			root, ok := client.RootForPath(tt.path)
+			if root != tt.expectedRoot || ok != tt.expectedOk {
+				t.Errorf("RootForPath(%q) = %q, %v; expected %q, %v", tt.path, root, ok, tt.expectedRoot, tt.expectedOk)
+			}
+		})
+	}
+}
+
+func TestAddRemoveWorkspaceFolders(t *testing.T) {
+	// A process that accepts notifications on stdin without responding
+	client, err := NewClient("sleep", "5")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	client.setWorkspaceFolders([]string{"/repo"})
+
+	if err := client.AddWorkspaceFolders(ctx, "relative/dir"); err == nil {
+		t.Error("Expected an error for a relative workspace folder")
+	}
+	if err := client.AddWorkspaceFolders(ctx, "/lib", "/repo", "/lib/"); err != nil {
+		t.Fatalf("AddWorkspaceFolders failed: %v", err)
+	}
+	if folders := client.WorkspaceFolders(); !reflect.DeepEqual(folders, []string{"/repo", "/lib"}) {
+		t.Errorf("Unexpected workspace folders after add: %v", folders)
+	}
+
+	if err := client.RemoveWorkspaceFolders(ctx, "/repo"); err == nil {
+		t.Error("Expected an error when removing the primary workspace folder")
+	}
+	if err := client.RemoveWorkspaceFolders(ctx, "/unknown"); err == nil {
+		t.Error("Expected an error when removing an unknown workspace folder")
+	}
+	if err := client.RemoveWorkspaceFolders(ctx, "/lib"); err != nil {
+		t.Fatalf("RemoveWorkspaceFolders failed: %v", err)
+	}
+	if folders := client.WorkspaceFolders(); !reflect.DeepEqual(folders, []string{"/repo"}) {
+		t.Errorf("Unexpected workspace folders after remove: %v", folders)
+	}
+}
@@ -1,6 +1,10 @@
 package protocol
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
 
 // TextEditResult is an interface for types that represent workspace symbols
 type WorkspaceSymbolResult interface {
@@ -115,3 +119,72 @@ func (e Or_TextDocumentEdit_edits_Elem) AsTextEdit() (TextEdit, error) {
 		return TextEdit{}, fmt.Errorf("unknown text edit type: %T", e.Value)
 	}
 }
+
+// UnmarshalJSON accepts the deprecated MarkedString and MarkedString[] hover contents sent by
+// older servers in addition to MarkupContent, converting them to markdown MarkupContent
+func (h *Hover) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var raw struct {
+		Contents json.RawMessage `json:"contents"`
+		Range    Range           `json:"range,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	h.Range = raw.Range
+	if len(raw.Contents) == 0 || string(raw.Contents) == "null" {
+		return nil
+	}
+
+	var markup MarkupContent
+	if err := json.Unmarshal(raw.Contents, &markup); err == nil && markup.Kind != "" {
+		h.Contents = markup
+		return nil
+	}
+
+	var marked []Or_MarkedString
+	if err := json.Unmarshal(raw.Contents, &marked); err != nil {
+		var single Or_MarkedString
+		if err := json.Unmarshal(raw.Contents, &single); err != nil {
+			return fmt.Errorf("unexpected hover contents: %s", raw.Contents)
+		}
+		marked = []Or_MarkedString{single}
+	}
+
+	parts := make([]string, 0, len(marked))
+	for _, m := range marked {
+		switch v := m.Value.(type) {
+		case string:
+			parts = append(parts, v)
+		case MarkedStringWithLanguage:
+			parts = append(parts, fmt.Sprintf("```%s\n%s\n```", v.Language, v.Value))
+		}
+	}
+	h.Contents = MarkupContent{
+		Kind:  Markdown,
+		Value: strings.Join(parts, "\n\n"),
+	}
+	return nil
+}
+
+// MarshalJSON encodes a parameter label offset pair as the [start, end] array the
+// specification uses, rather than an object of its generated fields
+func (t Tuple_ParameterInformation_label_Item1) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]uint32{t.Fld0, t.Fld1})
+}
+
+// UnmarshalJSON decodes a parameter label offset pair sent as a [start, end] array
+func (t *Tuple_ParameterInformation_label_Item1) UnmarshalJSON(data []byte) error {
+	var offsets []uint32
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return err
+	}
+	if len(offsets) != 2 {
+		return fmt.Errorf("expected [start, end] parameter label offsets, got %s", data)
+	}
+	t.Fld0, t.Fld1 = offsets[0], offsets[1]
+	return nil
+}
@@ -28,3 +28,33 @@ var TableKindMap = map[SymbolKind]string{
 	Operator:      "Operator",
 	TypeParameter: "TypeParameter",
 }
+
+// TableKindNameMap maps lowercase user-facing names to symbol kinds, the inverse of TableKindMap
+var TableKindNameMap = map[string]SymbolKind{
+	"file":          File,
+	"module":        Module,
+	"namespace":     Namespace,
+	"package":       Package,
+	"class":         Class,
+	"method":        Method,
+	"property":      Property,
+	"field":         Field,
+	"constructor":   Constructor,
+	"enum":          Enum,
+	"interface":     Interface,
+	"function":      Function,
+	"variable":      Variable,
+	"constant":      Constant,
+	"string":        String,
+	"number":        Number,
+	"boolean":       Boolean,
+	"array":         Array,
+	"object":        Object,
+	"key":           Key,
+	"null":          Null,
+	"enummember":    EnumMember,
+	"struct":        Struct,
+	"event":         Event,
+	"operator":      Operator,
+	"typeparameter": TypeParameter,
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// ApplyCompletion applies a completion to the file. itemIndex is the 1-indexed number of
+// the item as listed by GetCompletions without a filter, and triggerCharacter must be the one
+// the list was requested with, as the server may offer other items for it. The item is
+// resolved first so that its additionalTextEdits (e.g. auto-imports) are known, then the
+// primary edit and the additional edits are written to the file in a single update.
+func ApplyCompletion(ctx context.Context, client *lsp.Client, filePath string, line, column, itemIndex int, triggerCharacter string) (string, error) {
+	items, _, err := requestCompletionItems(ctx, client, filePath, line, column, triggerCharacter)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("no completions available at %s:%d:%d", filePath, line, column)
+	}
+	if itemIndex < 1 || itemIndex > len(items) {
+		return "", fmt.Errorf("completion %d is out of range, there are %d completions at this position", itemIndex, len(items))
+	}
+	item := items[itemIndex-1]
+
+	// Servers may only compute additional edits and the text edit on resolve
+	resolved, err := client.ResolveCompletionItem(ctx, item)
+	if err != nil {
+		toolsLogger.Warn("failed to resolve completion item %s: %v", item.Label, err)
+	} else {
+		if resolved.TextEdit == nil {
+			resolved.TextEdit = item.TextEdit
+		}
+		if resolved.InsertTextFormat == nil {
+			resolved.InsertTextFormat = item.InsertTextFormat
+		}
+		// Some servers send the auto-imports with the item and leave them out on resolve
+		if len(resolved.AdditionalTextEdits) == 0 {
+			resolved.AdditionalTextEdits = item.AdditionalTextEdits
+		}
+		item = resolved
+	}
+
+	// Snippet syntax must not end up in the file, so tabstops are removed and placeholders
+	// and choices are replaced with their defaults
+	newText, isSnippet := getCompletionInsertText(item)
+	var primaryRange protocol.Range
+	if editRange := getCompletionEditRange(item); editRange != nil {
+		primaryRange = *editRange
+	} else {
+		// Without a text edit the completion replaces the word before the position
+		primaryRange, err = wordRangeBefore(filePath, protocol.Position{
+			Line:      uint32(line - 1),
+			Character: uint32(column - 1),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	edits := append([]protocol.TextEdit{{Range: primaryRange, NewText: newText}}, item.AdditionalTextEdits...)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	ordered, err := orderCompletionEdits(content, edits)
+	if err != nil {
+		return "", fmt.Errorf("cannot apply completion %s, nothing was changed: %v", item.Label, err)
+	}
+	for _, edit := range ordered {
+		if content, err = utilities.ApplyTextEditsToContent(content, []protocol.TextEdit{edit}); err != nil {
+			return "", fmt.Errorf("failed to apply completion: %v", err)
+		}
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %v", err)
+	}
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		toolsLogger.Warn("failed to notify server of completion edit: %v", err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Applied completion %s at L%d:C%d - L%d:C%d: %s\n", item.Label,
+		primaryRange.Start.Line+1, primaryRange.Start.Character+1,
+		primaryRange.End.Line+1, primaryRange.End.Character+1,
+		newText))
+	if isSnippet {
+		result.WriteString("The completion was a snippet, its placeholders were filled with their default text.\n")
+	}
+	if len(item.AdditionalTextEdits) > 0 {
+		result.WriteString("\nAdditional edits (e.g. imports):\n")
+		for _, edit := range item.AdditionalTextEdits {
+			result.WriteString(fmt.Sprintf("- L%d:C%d: %s\n", edit.Range.Start.Line+1, edit.Range.Start.Character+1,
+				strings.ReplaceAll(strings.TrimSpace(edit.NewText), "\n", " ")))
+		}
+	}
+	return result.String(), nil
+}
+
+// orderCompletionEdits checks the primary edit of a completion, edits[0], and its additional
+// edits and returns them in the order to apply them one at a time: the last in the file
+// first. Servers sometimes send additional edits out of order or touching the primary edit,
+// e.g. an insertion right where it starts, which is fine, but edits that truly overlap
+// would corrupt the file and are an error. Edits at the same position end up in the file
+// in the order they were given.
+func orderCompletionEdits(content []byte, edits []protocol.TextEdit) ([]protocol.TextEdit, error) {
+	describe := func(i int) string {
+		rng := edits[i].Range
+		where := fmt.Sprintf("L%d:C%d-L%d:C%d", rng.Start.Line+1, rng.Start.Character+1, rng.End.Line+1, rng.End.Character+1)
+		if i == 0 {
+			return "the completion's edit at " + where
+		}
+		return fmt.Sprintf("additional edit %d at %s", i, where)
+	}
+
+	for i, edit := range edits {
+		if err := checkEditRanges(content, []protocol.TextEdit{edit}); err != nil {
+			return nil, fmt.Errorf("%s: %v", describe(i), err)
+		}
+	}
+	for i := range edits {
+		for j := i + 1; j < len(edits); j++ {
+			if editsConflict(edits[i].Range, edits[j].Range) {
+				return nil, fmt.Errorf("%s overlaps %s", describe(i), describe(j))
+			}
+		}
+	}
+
+	order := make([]int, len(edits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := edits[order[a]].Range, edits[order[b]].Range
+		if c := comparePositions(ra.Start, rb.Start); c != 0 {
+			return c > 0
+		}
+		// A replacement starting where an insertion is made goes first, so that the
+		// insertion ends up before it
+		if c := comparePositions(ra.End, rb.End); c != 0 {
+			return c > 0
+		}
+		return order[a] > order[b]
+	})
+
+	ordered := make([]protocol.TextEdit, len(edits))
+	for i, index := range order {
+		ordered[i] = edits[index]
+	}
+	return ordered, nil
+}
+
+// editsConflict reports whether two ranges share more than a boundary, including an empty
+// range inside another one. Unlike utilities.RangesOverlap, touching ranges don't conflict.
+func editsConflict(a, b protocol.Range) bool {
+	return comparePositions(a.Start, b.End) < 0 && comparePositions(b.Start, a.End) < 0
+}
+
+// comparePositions returns -1, 0 or 1 if a is before, at or after b
+func comparePositions(a, b protocol.Position) int {
+	if c := cmp.Compare(a.Line, b.Line); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Character, b.Character)
+}
+
+// wordRangeBefore returns the range of the identifier characters that end at position. The
+// line is scanned in bytes, positions are in UTF-16 code units.
+func wordRangeBefore(filePath string, position protocol.Position) (protocol.Range, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return protocol.Range{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if int(position.Line) >= len(lines) {
+		return protocol.Range{}, fmt.Errorf("line %d is past the end of the file", position.Line+1)
+	}
+
+	lineText := lines[position.Line]
+	end := utilities.UTF16OffsetToByteOffset(lineText, int(position.Character))
+	start := end
+	for start > 0 && isIdentifierByte(lineText, start-1) {
+		start--
+	}
+	return protocol.Range{
+		Start: protocol.Position{Line: position.Line, Character: uint32(utilities.ByteOffsetToUTF16Offset(lineText, start))},
+		End:   protocol.Position{Line: position.Line, Character: uint32(utilities.ByteOffsetToUTF16Offset(lineText, end))},
+	}, nil
+}
@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordRangeBefore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	err := os.WriteFile(filePath, []byte("package main\n\nfunc main() {\n\tfmt.Prin\n}\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		position protocol.Position
+		expected protocol.Range
+	}{
+		{
+			name:     "partial word",
+			position: protocol.Position{Line: 3, Character: 9},
+			expected: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 5},
+				End:   protocol.Position{Line: 3, Character: 9},
+			},
+		},
+		{
+			name:     "after a dot",
+			position: protocol.Position{Line: 3, Character: 5},
+			expected: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 5},
+				End:   protocol.Position{Line: 3, Character: 5},
+			},
+		},
+		{
+			name:     "past the end of the line",
+			position: protocol.Position{Line: 3, Character: 40},
+			expected: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 5},
+				End:   protocol.Position{Line: 3, Character: 9},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng, err := wordRangeBefore(filePath, tt.position)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rng)
+		})
+	}
+
+	// Positions are in UTF-16 code units: "é" is one, "😀" two
+	textPath := writeTestFile(t, "text.go", "x := \"é😀\" + nam\n")
+	rng, err := wordRangeBefore(textPath, protocol.Position{Line: 0, Character: 16})
+	assert.NoError(t, err)
+	assert.Equal(t, protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 13},
+		End:   protocol.Position{Line: 0, Character: 16},
+	}, rng)
+
+	_, err = wordRangeBefore(filePath, protocol.Position{Line: 10})
+	assert.Error(t, err)
+}
+
+func TestApplyCompletionSnippet(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\tm := ma\n}\n")
+
+	server.RespondRaw("textDocument/completion", `{"isIncomplete": false, "items": [{
+		"label": "make",
+		"insertTextFormat": 2,
+		"textEdit": {
+			"newText": "make(${1:map[${2:string}]${3|int,bool|}})$0",
+			"range": {"start": {"line": 3, "character": 6}, "end": {"line": 3, "character": 8}}
+		}
+	}]}`)
+
+	result, err := ApplyCompletion(t.Context(), server.Client, filePath, 4, 9, 1, "")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Applied completion make at L4:C7 - L4:C9: make(map[string]int)")
+	assert.Contains(t, result, "placeholders were filled with their default text")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\tm := make(map[string]int)\n}\n", string(content))
+}
+
+func TestOrderCompletionEdits(t *testing.T) {
+	content := []byte("package main\n\nfunc main() {\n\tx := ptr\n}\n")
+	edit := func(startLine, startChar, endLine, endChar uint32, text string) protocol.TextEdit {
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+			NewText: text,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		edits    []protocol.TextEdit
+		expected string // Content after applying the ordered edits
+		err      string
+	}{
+		{
+			name:     "additional edits out of order",
+			edits:    []protocol.TextEdit{edit(3, 6, 3, 9, "p"), edit(1, 0, 1, 0, "import \"a\"\n"), edit(1, 0, 1, 0, "import \"b\"\n")},
+			expected: "package main\nimport \"a\"\nimport \"b\"\n\nfunc main() {\n\tx := p\n}\n",
+		},
+		{
+			name:     "insertion where the primary edit starts",
+			edits:    []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(3, 6, 3, 6, "&")},
+			expected: "package main\n\nfunc main() {\n\tx := &value\n}\n",
+		},
+		{
+			name:     "insertion where the primary edit ends",
+			edits:    []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(3, 9, 3, 9, "()")},
+			expected: "package main\n\nfunc main() {\n\tx := value()\n}\n",
+		},
+		{
+			name:  "overlapping the primary edit",
+			edits: []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(3, 1, 3, 7, "y = ")},
+			err:   "the completion's edit at L4:C7-L4:C10 overlaps additional edit 1 at L4:C2-L4:C8",
+		},
+		{
+			name:  "insertion inside the primary edit",
+			edits: []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(3, 7, 3, 7, "*")},
+			err:   "overlaps additional edit 1 at L4:C8-L4:C8",
+		},
+		{
+			name:  "additional edits overlapping each other",
+			edits: []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(0, 0, 0, 7, "p"), edit(0, 3, 0, 5, "q")},
+			err:   "additional edit 1 at L1:C1-L1:C8 overlaps additional edit 2 at L1:C4-L1:C6",
+		},
+		{
+			name:  "outside the file",
+			edits: []protocol.TextEdit{edit(3, 6, 3, 9, "value"), edit(9, 0, 9, 0, "import \"a\"\n")},
+			err:   "additional edit 1 at L10:C1-L10:C1: range L10:C1-L10:C1 is outside the file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := orderCompletionEdits(content, tt.edits)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			result := content
+			for _, edit := range ordered {
+				result, err = utilities.ApplyTextEditsToContent(result, []protocol.TextEdit{edit})
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestApplyCompletionConflictingEdits(t *testing.T) {
+	server := newMockServer(t)
+	original := "package main\n\nfunc main() {\n\tx := ptr\n}\n"
+	filePath := writeTestFile(t, "main.go", original)
+
+	server.RespondRaw("textDocument/completion", `{"isIncomplete": false, "items": [{
+		"label": "pointer",
+		"textEdit": {"newText": "pointer", "range": {"start": {"line": 3, "character": 6}, "end": {"line": 3, "character": 9}}},
+		"additionalTextEdits": [{"newText": "y", "range": {"start": {"line": 3, "character": 1}, "end": {"line": 3, "character": 7}}}]
+	}]}`)
+
+	_, err := ApplyCompletion(t.Context(), server.Client, filePath, 4, 10, 1, "")
+	assert.ErrorContains(t, err, "cannot apply completion pointer, nothing was changed: the completion's edit at L4:C7-L4:C10 overlaps additional edit 1")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestApplyCompletionKeepsUnresolvedAdditionalEdits(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\tx := Tri\n}\n")
+
+	server.RespondRaw("textDocument/completion", `{"isIncomplete": false, "items": [{
+		"label": "TrimSpace",
+		"textEdit": {"newText": "strings.TrimSpace", "range": {"start": {"line": 3, "character": 6}, "end": {"line": 3, "character": 9}}},
+		"additionalTextEdits": [{"newText": "\nimport \"strings\"\n", "range": {"start": {"line": 0, "character": 12}, "end": {"line": 0, "character": 12}}}],
+		"data": 1
+	}]}`)
+	// The resolved item only adds documentation
+	server.RespondRaw("completionItem/resolve", `{
+		"label": "TrimSpace",
+		"documentation": "TrimSpace returns s without leading and trailing white space.",
+		"data": 1
+	}`)
+
+	result, err := ApplyCompletion(t.Context(), server.Client, filePath, 4, 10, 1, "")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Additional edits")
+	assert.Len(t, server.Received("completionItem/resolve"), 1)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\nimport \"strings\"\n\n\nfunc main() {\n\tx := strings.TrimSpace\n}\n", string(content))
+}
+
+func TestApplyCompletionTriggerCharacter(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\tp.\n}\n")
+
+	server.RespondRaw("textDocument/completion", `{"isIncomplete": false, "items": [{
+		"label": "X",
+		"textEdit": {"newText": "X", "range": {"start": {"line": 3, "character": 3}, "end": {"line": 3, "character": 3}}}
+	}]}`)
+
+	_, err := ApplyCompletion(t.Context(), server.Client, filePath, 4, 4, 1, ".")
+	require.NoError(t, err)
+
+	// The list is requested the same way as the one the item was picked from
+	requests := server.Received("textDocument/completion")
+	require.Len(t, requests, 1)
+	var params protocol.CompletionParams
+	require.NoError(t, json.Unmarshal(requests[0], &params))
+	assert.Equal(t, protocol.TriggerCharacter, params.Context.TriggerKind)
+	assert.Equal(t, ".", params.Context.TriggerCharacter)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\tp.X\n}\n", string(content))
+}
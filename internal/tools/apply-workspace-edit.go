@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// ApplyWorkspaceEdit applies a WorkspaceEdit supplied by the caller, e.g. one taken from a
+// code action, and reports what was done to each file. Nothing is written if a file is
+// outside the workspace, and the edit is applied as a whole or not at all, see
+// applyWorkspaceEditAtomically.
+func ApplyWorkspaceEdit(ctx context.Context, client *lsp.Client, edit protocol.WorkspaceEdit) (string, error) {
+	files := utilities.WorkspaceEditFiles(edit)
+	if len(files) == 0 {
+		return "The workspace edit is empty, nothing was changed.", nil
+	}
+	for _, file := range files {
+		if _, ok := client.RootForPath(file); !ok {
+			return "", fmt.Errorf("%s is outside the workspace folders", file)
+		}
+	}
+
+	staged, err := applyWorkspaceEditAtomically(edit)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the server's view of open documents in sync for follow-up requests
+	for _, file := range files {
+		if client.IsFileOpen(file) {
+			if err := client.NotifyChange(ctx, file); err != nil {
+				toolsLogger.Warn("failed to notify server of change to %s: %v", file, err)
+			}
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Applied workspace edit to %d files:\n", len(files)))
+	for _, line := range staged.results {
+		result.WriteString("- " + line + "\n")
+	}
+	return result.String(), nil
+}
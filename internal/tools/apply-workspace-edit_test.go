@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWorkspaceEdit(t *testing.T) {
+	server := newMockServer(t)
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "main.go")
+	utilPath := filepath.Join(dir, "util.go")
+	require.NoError(t, os.WriteFile(mainPath, []byte("package main\n\nfunc main() {\n\told()\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(utilPath, []byte("package main\n\nfunc old() {}\n"), 0644))
+
+	var edit protocol.WorkspaceEdit
+	require.NoError(t, json.Unmarshal(fmt.Appendf(nil, `{"documentChanges": [
+		{"textDocument": {"uri": "file://%[1]s", "version": null}, "edits": [
+			{"range": {"start": {"line": 3, "character": 1}, "end": {"line": 3, "character": 4}}, "newText": "helper"}
+		]},
+		{"textDocument": {"uri": "file://%[2]s", "version": null}, "edits": [
+			{"range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}, "newText": "helper"}
+		]},
+		{"kind": "rename", "oldUri": "file://%[2]s", "newUri": "file://%[3]s/helper.go"},
+		{"kind": "create", "uri": "file://%[3]s/doc.go"},
+		{"textDocument": {"uri": "file://%[3]s/doc.go", "version": null}, "edits": [
+			{"range": {"start": {"line": 0, "character": 0}, "end": {"line": 0, "character": 0}}, "newText": "package main\n"}
+		]}
+	]}`, mainPath, utilPath, dir), &edit))
+
+	result, err := ApplyWorkspaceEdit(t.Context(), server.Client, edit)
+	require.NoError(t, err)
+	assert.Equal(t, "Applied workspace edit to 4 files:\n"+
+		"- Edited "+mainPath+" (1 edits)\n"+
+		"- Edited "+utilPath+" (1 edits)\n"+
+		"- Renamed "+utilPath+" -> "+dir+"/helper.go\n"+
+		"- Created "+dir+"/doc.go\n"+
+		"- Edited "+dir+"/doc.go (1 edits)\n", result)
+
+	content, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\thelper()\n}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "helper.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc helper() {}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "doc.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+	assert.NoFileExists(t, utilPath)
+}
+
+func TestApplyWorkspaceEditValidation(t *testing.T) {
+	original := "package main\n\nfunc old() {}\n"
+	edit := func(line, character uint32) []protocol.TextEdit {
+		position := protocol.Position{Line: line, Character: character}
+		return []protocol.TextEdit{{Range: protocol.Range{Start: position, End: position}, NewText: "x"}}
+	}
+
+	tests := []struct {
+		name     string
+		edit     func(first, second string) protocol.WorkspaceEdit
+		expected string
+	}{
+		{
+			name: "line out of bounds",
+			edit: func(first, second string) protocol.WorkspaceEdit {
+				return protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+					protocol.DocumentUri("file://" + first):  edit(0, 0),
+					protocol.DocumentUri("file://" + second): edit(10, 0),
+				}}
+			},
+			expected: "range L11:C1-L11:C1 is outside the file, which has 4 lines",
+		},
+		{
+			name: "character out of bounds",
+			edit: func(first, second string) protocol.WorkspaceEdit {
+				return protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+					protocol.DocumentUri("file://" + first):  edit(0, 0),
+					protocol.DocumentUri("file://" + second): edit(2, 14),
+				}}
+			},
+			expected: "range L3:C15-L3:C15 is outside the file",
+		},
+		{
+			name: "missing file",
+			edit: func(first, second string) protocol.WorkspaceEdit {
+				missing := filepath.Join(filepath.Dir(first), "missing.go")
+				return protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+					protocol.DocumentUri("file://" + first):   edit(0, 0),
+					protocol.DocumentUri("file://" + missing): edit(0, 0),
+				}}
+			},
+			expected: "missing.go: file does not exist",
+		},
+		{
+			name: "create existing file",
+			edit: func(first, second string) protocol.WorkspaceEdit {
+				return protocol.WorkspaceEdit{DocumentChanges: []protocol.DocumentChange{
+					{TextDocumentEdit: &protocol.TextDocumentEdit{
+						TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + first)}},
+						Edits:        []protocol.Or_TextDocumentEdit_edits_Elem{{Value: edit(0, 0)[0]}},
+					}},
+					{CreateFile: &protocol.CreateFile{Kind: "create", URI: protocol.DocumentUri("file://" + second)}},
+				}}
+			},
+			expected: "b.go: file already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			dir := t.TempDir()
+			first, second := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")
+			require.NoError(t, os.WriteFile(first, []byte(original), 0644))
+			require.NoError(t, os.WriteFile(second, []byte(original), 0644))
+
+			_, err := ApplyWorkspaceEdit(t.Context(), server.Client, tt.edit(first, second))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "nothing was changed")
+			assert.Contains(t, err.Error(), tt.expected)
+
+			// The valid edit to the first file was not written either
+			for _, path := range []string{first, second} {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.Equal(t, original, string(content))
+			}
+		})
+	}
+}
+
+func TestApplyWorkspaceEditOutsideWorkspace(t *testing.T) {
+	server := newMockServer(t)
+	require.NoError(t, server.Client.AddWorkspaceFolders(t.Context(), t.TempDir()))
+	outsidePath := writeTestFile(t, "main.go", "package main\n")
+
+	_, err := ApplyWorkspaceEdit(t.Context(), server.Client, protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			protocol.DocumentUri("file://" + outsidePath): {{NewText: "// Package main\n"}},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), outsidePath+" is outside the workspace folders")
+
+	content, err := os.ReadFile(outsidePath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// callGraph collects the symbols and calls found by expanding a call hierarchy, numbering
+// the symbols in the order they are found
+type callGraph struct {
+	ids   map[string]int
+	nodes []protocol.CallHierarchyItem
+	edges [][2]int // caller, callee
+	seen  map[[2]int]bool
+}
+
+func (g *callGraph) node(item protocol.CallHierarchyItem) int {
+	key := callHierarchyItemKey(item)
+	if id, ok := g.ids[key]; ok {
+		return id
+	}
+	g.ids[key] = len(g.nodes)
+	g.nodes = append(g.nodes, item)
+	return g.ids[key]
+}
+
+func (g *callGraph) addEdge(caller, callee int) {
+	edge := [2]int{caller, callee}
+	if !g.seen[edge] {
+		g.seen[edge] = true
+		g.edges = append(g.edges, edge)
+	}
+}
+
+// GetCallGraph expands the incoming or outgoing calls of the symbol at a position like
+// GetCallHierarchy, but renders them as a Graphviz DOT digraph. Nodes are symbols labeled
+// with their name and file:line, edges point from caller to callee in both directions.
+func GetCallGraph(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string, depth int) (string, error) {
+	if direction != "incoming" && direction != "outgoing" {
+		return "", fmt.Errorf("direction must be 'incoming' or 'outgoing', got: %s", direction)
+	}
+	if depth < 1 {
+		depth = 1
+	}
+
+	item, ok, err := prepareCallHierarchy(ctx, client, filePath, line, column)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return fmt.Sprintf("No symbol found at %s:%d:%d", filePath, line, column), nil
+	}
+
+	graph := &callGraph{ids: make(map[string]int), seen: make(map[[2]int]bool)}
+	root := graph.node(item)
+
+	// Breadth-first, so that each symbol is expanded at its shallowest level
+	frontier := []protocol.CallHierarchyItem{item}
+	expanded := map[int]bool{root: true}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var next []protocol.CallHierarchyItem
+		for _, current := range frontier {
+			calls, err := getCalls(ctx, client, current, direction)
+			if err != nil {
+				if level == 1 {
+					return "", err
+				}
+				toolsLogger.Warn("Failed to expand calls for %s: %v", current.Name, err)
+				continue
+			}
+
+			from := graph.node(current)
+			for _, call := range calls {
+				to := graph.node(call.item)
+				if direction == "incoming" {
+					graph.addEdge(to, from)
+				} else {
+					graph.addEdge(from, to)
+				}
+				if !expanded[to] {
+					expanded[to] = true
+					next = append(next, call.item)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var result strings.Builder
+	result.WriteString("digraph calls {\n")
+	result.WriteString("  rankdir=LR;\n")
+	result.WriteString("  node [shape=box];\n")
+	for id, node := range graph.nodes {
+		label := fmt.Sprintf("%s\\n%s:%d", dotEscape(node.Name),
+			dotEscape(utilities.URIToPath(node.URI)), node.Range.Start.Line+1)
+		attributes := ""
+		if id == root {
+			attributes = ", style=bold"
+		}
+		result.WriteString(fmt.Sprintf("  n%d [label=\"%s\"%s];\n", id, label, attributes))
+	}
+	for _, edge := range graph.edges {
+		result.WriteString(fmt.Sprintf("  n%d -> n%d;\n", edge[0], edge[1]))
+	}
+	result.WriteString("}\n")
+	return result.String(), nil
+}
+
+// dotEscape escapes text for a double-quoted DOT string
+func dotEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text)
+}
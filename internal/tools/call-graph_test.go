@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callGraphItem(name string, line int) map[string]any {
+	rng := map[string]any{
+		"start": map[string]any{"line": line, "character": 0},
+		"end":   map[string]any{"line": line, "character": 1},
+	}
+	return map[string]any{
+		"name": name, "kind": 12, "uri": "file:///workspace/main.go",
+		"range": rng, "selectionRange": rng,
+	}
+}
+
+// mockCallGraph serves main -> helper -> format, where helper also calls itself. The quotes
+// in helper's name check that labels are escaped.
+func mockCallGraph(t *testing.T) (*lsptesting.MockServer, string) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\thelper()\n}\n\nfunc helper() {\n\tformat()\n}\n")
+
+	const helper = `helper "v2"`
+	items := map[string]map[string]any{
+		"main":   callGraphItem("main", 2),
+		helper:   callGraphItem(helper, 6),
+		"format": callGraphItem("format", 10),
+	}
+	callees := map[string][]string{
+		"main": {helper},
+		helper: {"format", helper},
+	}
+	callers := map[string][]string{
+		helper:   {"main", helper},
+		"format": {helper},
+	}
+	calls := func(params json.RawMessage, edges map[string][]string, key string) (any, error) {
+		var p struct {
+			Item protocol.CallHierarchyItem `json:"item"`
+		}
+		require.NoError(t, json.Unmarshal(params, &p))
+		result := []map[string]any{}
+		for _, name := range edges[p.Item.Name] {
+			result = append(result, map[string]any{key: items[name], "fromRanges": []any{}})
+		}
+		return result, nil
+	}
+
+	server.Handle("textDocument/prepareCallHierarchy", func(json.RawMessage) (any, error) {
+		return []any{items[helper]}, nil
+	})
+	server.Handle("callHierarchy/outgoingCalls", func(params json.RawMessage) (any, error) {
+		return calls(params, callees, "to")
+	})
+	server.Handle("callHierarchy/incomingCalls", func(params json.RawMessage) (any, error) {
+		return calls(params, callers, "from")
+	})
+	return server, filePath
+}
+
+func TestGetCallGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		depth     int
+		expected  string
+	}{
+		{
+			name:      "outgoing",
+			direction: "outgoing",
+			depth:     2,
+			expected: `digraph calls {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="helper \"v2\"\n/workspace/main.go:7", style=bold];
+  n1 [label="format\n/workspace/main.go:11"];
+  n0 -> n1;
+  n0 -> n0;
+}
+`,
+		},
+		{
+			name:      "incoming edges point from caller to callee",
+			direction: "incoming",
+			depth:     3,
+			expected: `digraph calls {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="helper \"v2\"\n/workspace/main.go:7", style=bold];
+  n1 [label="main\n/workspace/main.go:3"];
+  n1 -> n0;
+  n0 -> n0;
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, filePath := mockCallGraph(t)
+			result, err := GetCallGraph(t.Context(), server.Client, filePath, 7, 6, tt.direction, tt.depth)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
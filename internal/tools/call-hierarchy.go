@@ -7,20 +7,73 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
+// MaxCallHierarchyDepth is the largest depth the call_hierarchy tool expands calls to
+const MaxCallHierarchyDepth = 10
+
 // GetCallHierarchy returns incoming or outgoing calls for a symbol at the given position
-// direction should be "incoming" or "outgoing"
-func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (string, error) {
+// direction should be "incoming" or "outgoing". depth > 1 recursively expands the calls of
+// each caller or callee up to that many levels, rendered as an indented tree.
+func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string, depth int) (string, error) {
 	// Validate direction parameter
 	if direction != "incoming" && direction != "outgoing" {
 		return "", fmt.Errorf("direction must be 'incoming' or 'outgoing', got: %s", direction)
 	}
+	if depth < 1 {
+		depth = 1
+	}
+
+	item, ok, err := prepareCallHierarchy(ctx, client, filePath, line, column)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return fmt.Sprintf("No symbol found at %s:%d:%d", filePath, line, column), nil
+	}
+
+	calls, err := getCalls(ctx, client, item, direction)
+	if err != nil {
+		return "", err
+	}
+
+	// Format the output
+	var result strings.Builder
+	if direction == "incoming" {
+		result.WriteString(fmt.Sprintf("Incoming calls to: %s", item.Name))
+	} else {
+		result.WriteString(fmt.Sprintf("Outgoing calls from: %s", item.Name))
+	}
+	if item.Detail != "" {
+		result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
+	}
+	result.WriteString(fmt.Sprintf(" at %s:%d\n\n",
+		utilities.URIToPath(item.URI),
+		item.Range.Start.Line+1))
+
+	if len(calls) == 0 {
+		result.WriteString(fmt.Sprintf("No %s calls found\n", direction))
+		return result.String(), nil
+	}
+
+	visited := map[string]bool{callHierarchyItemKey(item): true}
+	writeCalls(ctx, client, &result, calls, direction, "", 1, depth, visited)
+
+	return result.String(), nil
+}
+
+// prepareCallHierarchy returns the call hierarchy item for the symbol at a 1-indexed
+// position, or false if there is none
+func prepareCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int) (protocol.CallHierarchyItem, bool, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return protocol.CallHierarchyItem{}, false, err
+	}
 
 	// Open the file first
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return protocol.CallHierarchyItem{}, false, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	// Create URI from file path
@@ -33,126 +86,116 @@ func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 				URI: uri,
 			},
 			Position: protocol.Position{
-				Line:      uint32(line - 1),      // Convert from 1-indexed to 0-indexed
-				Character: uint32(column - 1),    // Convert from 1-indexed to 0-indexed
+				Line:      uint32(line - 1),   // Convert from 1-indexed to 0-indexed
+				Character: uint32(column - 1), // Convert from 1-indexed to 0-indexed
 			},
 		},
 	}
 
 	// Call PrepareCallHierarchy to get CallHierarchyItem
-	items, err := client.PrepareCallHierarchy(ctx, params)
+	items, err := lsp.RetryDocumentRequest(ctx, client, filePath,
+		func(r []protocol.CallHierarchyItem) bool { return len(r) == 0 },
+		func() ([]protocol.CallHierarchyItem, error) { return client.PrepareCallHierarchy(ctx, params) })
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare call hierarchy: %w", err)
+		return protocol.CallHierarchyItem{}, false, fmt.Errorf("failed to prepare call hierarchy: %w", err)
 	}
 
 	// Check if we found any symbol at the position
 	if len(items) == 0 {
-		return fmt.Sprintf("No symbol found at %s:%d:%d", filePath, line, column), nil
+		return protocol.CallHierarchyItem{}, false, nil
 	}
 
 	// Take the first item (most relevant)
-	item := items[0]
+	return items[0], true, nil
+}
 
-	// Get calls based on direction
-	var result strings.Builder
+// hierarchyCall is an incoming or outgoing call: the item at the other end of the call
+// and the ranges of the call sites
+type hierarchyCall struct {
+	item       protocol.CallHierarchyItem
+	fromRanges []protocol.Range
+}
 
+// getCalls returns the callers (incoming) or callees (outgoing) of item
+func getCalls(ctx context.Context, client *lsp.Client, item protocol.CallHierarchyItem, direction string) ([]hierarchyCall, error) {
+	var calls []hierarchyCall
 	if direction == "incoming" {
-		// Get incoming calls (callers)
-		incomingParams := protocol.CallHierarchyIncomingCallsParams{
+		incomingCalls, err := client.IncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{
 			Item: item,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get incoming calls: %w", err)
 		}
-
-		incomingCalls, err := client.IncomingCalls(ctx, incomingParams)
+		for _, call := range incomingCalls {
+			calls = append(calls, hierarchyCall{item: call.From, fromRanges: call.FromRanges})
+		}
+	} else {
+		outgoingCalls, err := client.OutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{
+			Item: item,
+		})
 		if err != nil {
-			return "", fmt.Errorf("failed to get incoming calls: %w", err)
+			return nil, fmt.Errorf("failed to get outgoing calls: %w", err)
+		}
+		for _, call := range outgoingCalls {
+			calls = append(calls, hierarchyCall{item: call.To, fromRanges: call.FromRanges})
 		}
+	}
+	return calls, nil
+}
 
-		// Format the output
-		result.WriteString(fmt.Sprintf("Incoming calls to: %s", item.Name))
-		if item.Detail != "" {
-			result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
+// writeCalls writes a numbered list of calls at the given indentation and, while level < depth,
+// expands each one in turn. Items already in visited are not expanded again, which stops
+// recursive functions from looping forever.
+func writeCalls(ctx context.Context, client *lsp.Client, result *strings.Builder, calls []hierarchyCall, direction, indent string, level, depth int, visited map[string]bool) {
+	rangesLabel := "Call sites"
+	if direction == "outgoing" {
+		rangesLabel = "Called at"
+	}
+
+	for i, call := range calls {
+		result.WriteString(fmt.Sprintf("%s%d. %s", indent, i+1, call.item.Name))
+		if call.item.Detail != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", call.item.Detail))
 		}
-		result.WriteString(fmt.Sprintf(" at %s:%d\n\n",
-			strings.TrimPrefix(string(item.URI), "file://"),
-			item.Range.Start.Line+1))
-
-		if len(incomingCalls) == 0 {
-			result.WriteString("No incoming calls found\n")
-		} else {
-			for i, call := range incomingCalls {
-				// Format caller information
-				callerFile := strings.TrimPrefix(string(call.From.URI), "file://")
-				callerLine := call.From.Range.Start.Line + 1
-
-				result.WriteString(fmt.Sprintf("%d. %s", i+1, call.From.Name))
-				if call.From.Detail != "" {
-					result.WriteString(fmt.Sprintf(" (%s)", call.From.Detail))
-				}
-				result.WriteString(fmt.Sprintf(" at %s:%d\n",
-					callerFile,
-					callerLine))
-
-				// Show the ranges where the calls occur
-				if len(call.FromRanges) > 0 {
-					var ranges []string
-					for _, r := range call.FromRanges {
-						ranges = append(ranges, fmt.Sprintf("L%d:C%d",
-							r.Start.Line+1,
-							r.Start.Character+1))
-					}
-					result.WriteString(fmt.Sprintf("   Call sites: %s\n", strings.Join(ranges, ", ")))
-				}
+		result.WriteString(fmt.Sprintf(" at %s:%d\n",
+			utilities.URIToPath(call.item.URI),
+			call.item.Range.Start.Line+1))
+
+		// Show the ranges where the calls occur
+		if len(call.fromRanges) > 0 {
+			var ranges []string
+			for _, r := range call.fromRanges {
+				ranges = append(ranges, fmt.Sprintf("L%d:C%d",
+					r.Start.Line+1,
+					r.Start.Character+1))
 			}
-		}
-	} else {
-		// Get outgoing calls (callees)
-		outgoingParams := protocol.CallHierarchyOutgoingCallsParams{
-			Item: item,
+			result.WriteString(fmt.Sprintf("%s   %s: %s\n", indent, rangesLabel, strings.Join(ranges, ", ")))
 		}
 
-		outgoingCalls, err := client.OutgoingCalls(ctx, outgoingParams)
-		if err != nil {
-			return "", fmt.Errorf("failed to get outgoing calls: %w", err)
+		if level >= depth {
+			continue
 		}
 
-		// Format the output
-		result.WriteString(fmt.Sprintf("Outgoing calls from: %s", item.Name))
-		if item.Detail != "" {
-			result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
+		key := callHierarchyItemKey(call.item)
+		if visited[key] {
+			result.WriteString(fmt.Sprintf("%s   (already expanded, cycle or repeated call)\n", indent))
+			continue
 		}
-		result.WriteString(fmt.Sprintf(" at %s:%d\n\n",
-			strings.TrimPrefix(string(item.URI), "file://"),
-			item.Range.Start.Line+1))
-
-		if len(outgoingCalls) == 0 {
-			result.WriteString("No outgoing calls found\n")
-		} else {
-			for i, call := range outgoingCalls {
-				// Format callee information
-				calleeFile := strings.TrimPrefix(string(call.To.URI), "file://")
-				calleeLine := call.To.Range.Start.Line + 1
-
-				result.WriteString(fmt.Sprintf("%d. %s", i+1, call.To.Name))
-				if call.To.Detail != "" {
-					result.WriteString(fmt.Sprintf(" (%s)", call.To.Detail))
-				}
-				result.WriteString(fmt.Sprintf(" at %s:%d\n",
-					calleeFile,
-					calleeLine))
-
-				// Show the ranges where the calls are made from
-				if len(call.FromRanges) > 0 {
-					var ranges []string
-					for _, r := range call.FromRanges {
-						ranges = append(ranges, fmt.Sprintf("L%d:C%d",
-							r.Start.Line+1,
-							r.Start.Character+1))
-					}
-					result.WriteString(fmt.Sprintf("   Called at: %s\n", strings.Join(ranges, ", ")))
-				}
-			}
+		visited[key] = true
+
+		nested, err := getCalls(ctx, client, call.item, direction)
+		if err != nil {
+			toolsLogger.Warn("Failed to expand calls for %s: %v", call.item.Name, err)
+			result.WriteString(fmt.Sprintf("%s   (could not expand: %v)\n", indent, err))
+			continue
 		}
+		writeCalls(ctx, client, result, nested, direction, indent+"   ", level+1, depth, visited)
 	}
+}
 
-	return result.String(), nil
+// callHierarchyItemKey identifies a call hierarchy item by its URI and range
+func callHierarchyItemKey(item protocol.CallHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d-%d:%d", item.URI,
+		item.Range.Start.Line, item.Range.Start.Character,
+		item.Range.End.Line, item.Range.End.Character)
 }
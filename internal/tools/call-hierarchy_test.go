@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCallHierarchyRecursive(t *testing.T) {
+	// alpha and beta call each other: alpha -> beta -> alpha -> ...
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc alpha() {\n\tbeta()\n}\n\nfunc beta() {\n\talpha()\n}\n")
+
+	items := map[string]map[string]any{
+		"alpha": callGraphItem("alpha", 2),
+		"beta":  callGraphItem("beta", 6),
+	}
+	callees := map[string]string{"alpha": "beta", "beta": "alpha"}
+	callSites := map[string]int{"alpha": 3, "beta": 7}
+
+	server.Handle("textDocument/prepareCallHierarchy", func(json.RawMessage) (any, error) {
+		return []any{items["alpha"]}, nil
+	})
+	server.Handle("callHierarchy/outgoingCalls", func(params json.RawMessage) (any, error) {
+		var p struct {
+			Item protocol.CallHierarchyItem `json:"item"`
+		}
+		require.NoError(t, json.Unmarshal(params, &p))
+		line := callSites[p.Item.Name]
+		site := map[string]any{
+			"start": map[string]any{"line": line, "character": 1},
+			"end":   map[string]any{"line": line, "character": 5},
+		}
+		return []any{map[string]any{"to": items[callees[p.Item.Name]], "fromRanges": []any{site}}}, nil
+	})
+
+	tests := []struct {
+		name     string
+		depth    int
+		expected string
+	}{
+		{
+			name:  "depth 1 does not expand",
+			depth: 1,
+			expected: `Outgoing calls from: alpha at /workspace/main.go:3
+
+1. beta at /workspace/main.go:7
+   Called at: L4:C2
+`,
+		},
+		{
+			name:  "cycle is expanded once",
+			depth: 5,
+			expected: `Outgoing calls from: alpha at /workspace/main.go:3
+
+1. beta at /workspace/main.go:7
+   Called at: L4:C2
+   1. alpha at /workspace/main.go:3
+      Called at: L8:C2
+      (already expanded, cycle or repeated call)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetCallHierarchy(t.Context(), server.Client, filePath, 3, 6, "outgoing", tt.depth)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// Each item was asked for its calls once, however deep the tree was allowed to go
+	assert.Len(t, server.Received("callHierarchy/outgoingCalls"), 1+2)
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// GetCapabilities returns the ServerCapabilities negotiated with the language server as JSON,
+// including those it registered dynamically, e.g. to look up completion trigger characters,
+// code action kinds or the commands execute_command accepts. If section is not empty only
+// that top-level capability is returned, e.g. "completionProvider".
+func GetCapabilities(client *lsp.Client, section string) (string, error) {
+	caps := client.ServerCapabilities()
+	if caps == nil {
+		return "", fmt.Errorf("the language server has not reported its capabilities")
+	}
+
+	// Go through a map to select a section by its JSON name
+	raw, err := json.Marshal(caps)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+
+	var result strings.Builder
+	if section == "" {
+		encoding := "utf-16 (default)"
+		if caps.PositionEncoding != nil {
+			encoding = string(*caps.PositionEncoding)
+		}
+		result.WriteString(fmt.Sprintf("Position encoding: %s\n\n", encoding))
+		formatted, err := json.MarshalIndent(fields, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode capabilities: %w", err)
+		}
+		result.Write(formatted)
+		result.WriteString("\n")
+		return result.String(), nil
+	}
+
+	for name, value := range fields {
+		if !strings.EqualFold(name, section) {
+			continue
+		}
+		formatted, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode capabilities: %w", err)
+		}
+		result.WriteString(fmt.Sprintf("%s: %s\n", name, formatted))
+		return result.String(), nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return fmt.Sprintf("The language server does not advertise %s. Advertised capabilities: %s", section, strings.Join(names, ", ")), nil
+}
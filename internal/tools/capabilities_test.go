@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	server := newMockServer(t)
+	_, err := GetCapabilities(server.Client, "")
+	require.Error(t, err)
+
+	server.Client.SetServerCapabilities(&protocol.ServerCapabilities{
+		CompletionProvider: &protocol.CompletionOptions{TriggerCharacters: []string{".", ":"}},
+		ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
+			Commands: []string{"gopls.tidy"},
+		},
+	})
+
+	result, err := GetCapabilities(server.Client, "")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Position encoding: utf-16 (default)\n\n{\n")
+	assert.Contains(t, result, `"completionProvider": {`)
+	assert.Contains(t, result, `"gopls.tidy"`)
+
+	result, err = GetCapabilities(server.Client, "CompletionProvider")
+	require.NoError(t, err)
+	assert.Equal(t, "completionProvider: {\n  \"triggerCharacters\": [\n    \".\",\n    \":\"\n  ]\n}\n", result)
+
+	result, err = GetCapabilities(server.Client, "hoverProvider")
+	require.NoError(t, err)
+	assert.Contains(t, result, "The language server does not advertise hoverProvider. Advertised capabilities: completionProvider, executeCommandProvider")
+}
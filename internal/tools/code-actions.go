@@ -3,14 +3,20 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 // GetCodeActions returns available code actions for a range in a file
 func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int) (string, error) {
+	if err := validateRange(filePath, startLine, startColumn, endLine, endColumn); err != nil {
+		return "", err
+	}
+
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
@@ -124,3 +130,123 @@ func formatCodeActionKind(kind string) string {
 		return kind
 	}
 }
+
+// requestCodeActions returns the code actions for rng whose kind is one of kinds or a
+// sub-kind of one (e.g. refactor.extract.function for refactor.extract). Bare commands are
+// returned as code actions carrying that command. Disabled actions are skipped.
+func requestCodeActions(ctx context.Context, client *lsp.Client, filePath string, rng protocol.Range, kinds ...protocol.CodeActionKind) ([]protocol.CodeAction, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	diagnostics := client.GetFileDiagnostics(protocol.DocumentUri("file://" + filePath))
+	return requestCodeActionsForDiagnostics(ctx, client, filePath, rng, diagnostics, kinds...)
+}
+
+// requestCodeActionsForDiagnostics is requestCodeActions with the given diagnostics as the
+// context instead of all of the file's. The file must be open.
+func requestCodeActionsForDiagnostics(ctx context.Context, client *lsp.Client, filePath string, rng protocol.Range, diagnostics []protocol.Diagnostic, kinds ...protocol.CodeActionKind) ([]protocol.CodeAction, error) {
+	uri := protocol.DocumentUri("file://" + filePath)
+	items, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        rng,
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnostics,
+			Only:        kinds,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code actions: %v", err)
+	}
+
+	var actions []protocol.CodeAction
+	for _, item := range items {
+		var action protocol.CodeAction
+		switch v := item.Value.(type) {
+		case protocol.CodeAction:
+			action = v
+		case protocol.Command:
+			action = protocol.CodeAction{Title: v.Title, Command: &v}
+		default:
+			continue
+		}
+		if action.Disabled != nil {
+			continue
+		}
+		// Servers are free to ignore the requested kinds
+		if len(kinds) > 0 && !codeActionKindMatches(action.Kind, kinds) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// codeActionKindMatches reports whether kind is one of kinds or a sub-kind of one of them
+func codeActionKindMatches(kind protocol.CodeActionKind, kinds []protocol.CodeActionKind) bool {
+	for _, k := range kinds {
+		if kind == k || strings.HasPrefix(string(kind), string(k)+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCodeAction applies a code action's workspace edit and then runs its command, resolving
+// the action first if the server left both out. It returns the files that were changed,
+// including those changed through workspace/applyEdit requests while the command ran.
+func applyCodeAction(ctx context.Context, client *lsp.Client, action protocol.CodeAction) ([]string, error) {
+	if action.Edit == nil && action.Command == nil {
+		resolved, err := client.ResolveCodeAction(ctx, action)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve code action: %v", err)
+		}
+		action = resolved
+	}
+
+	var files []string
+	if action.Edit != nil {
+		_, err := applyWorkspaceEditAtomically(*action.Edit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply code action edit: %v", err)
+		}
+		files = append(files, utilities.WorkspaceEditFiles(*action.Edit)...)
+		// Keep the server's view of open documents in sync for follow-up requests
+		for _, file := range files {
+			if client.IsFileOpen(file) {
+				if err := client.NotifyChange(ctx, file); err != nil {
+					toolsLogger.Warn("failed to notify server of change to %s: %v", file, err)
+				}
+			}
+		}
+	}
+
+	if action.Command != nil {
+		appliedBefore := client.AppliedEditCount()
+		_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+			Command:   action.Command.Command,
+			Arguments: action.Command.Arguments,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute code action command: %v", err)
+		}
+		for _, edit := range client.AppliedEditsSince(appliedBefore) {
+			files = append(files, edit.Files...)
+		}
+	}
+
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// documentRange returns the range covering all of content
+func documentRange(content []byte) protocol.Range {
+	lines := strings.Split(string(content), "\n")
+	return protocol.Range{
+		End: protocol.Position{
+			Line:      uint32(len(lines) - 1),
+			Character: uint32(len(lines[len(lines)-1])),
+		},
+	}
+}
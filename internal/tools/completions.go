@@ -2,7 +2,9 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -12,16 +14,112 @@ import (
 
 // GetCompletions returns context-aware code completion suggestions
 // limit caps the number of results (default 20 if 0)
-func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, line, column, limit int) (string, error) {
+// filterPrefix, if not empty, keeps only items matching the prefix (case-insensitive)
+// triggerCharacter, if not empty, requests completion as if that character (e.g. ".") had just
+// been typed; it must be one of the triggerCharacters advertised by the server
+func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, line, column, limit int, filterPrefix, triggerCharacter string, triggerCharacters []string) (string, error) {
 	// Default limit
 	if limit <= 0 {
 		limit = 20
 	}
 
+	if err := validateTriggerCharacter(triggerCharacter, triggerCharacters); err != nil {
+		return "", err
+	}
+
+	items, isIncomplete, err := requestCompletionItems(ctx, client, filePath, line, column, triggerCharacter)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "No completions available", nil
+	}
+	totalCount := len(items)
+
+	// Filter by prefix, keeping the server's order within each match rank
+	if filterPrefix != "" {
+		items = filterCompletionItems(items, filterPrefix)
+		totalCount = len(items)
+		if len(items) == 0 {
+			return fmt.Sprintf("No completions matching %q", filterPrefix), nil
+		}
+	}
+
+	// Limit results
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	// Format output
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Completions (%d of %d):\n\n", len(items), totalCount))
+	if isIncomplete {
+		output.WriteString("Note: the server returned a partial list. Type a longer prefix before the cursor and request completions again for more results.\n\n")
+	}
+
+	for i, item := range items {
+		// Get kind string
+		kindStr := getCompletionKindString(item.Kind)
+
+		// Format: index. [Kind] Label
+		output.WriteString(fmt.Sprintf("%d. [%s] %s", i+1, kindStr, item.Label))
+
+		// Add detail if available (type information)
+		if item.Detail != "" {
+			output.WriteString(fmt.Sprintf("\n   Type: %s", item.Detail))
+		}
+
+		// Add the text that is actually inserted when it differs from the label
+		insertText, isSnippet := getCompletionInsertText(item)
+		if isSnippet {
+			output.WriteString(fmt.Sprintf("\n   Insert (snippet, placeholders stripped): %s", insertText))
+		} else if insertText != item.Label {
+			output.WriteString(fmt.Sprintf("\n   Insert: %s", insertText))
+		}
+
+		// Add the range replaced by the insert text if the server provided one
+		if editRange := getCompletionEditRange(item); editRange != nil {
+			output.WriteString(fmt.Sprintf("\n   Replaces: L%d:C%d - L%d:C%d",
+				editRange.Start.Line+1,
+				editRange.Start.Character+1,
+				editRange.End.Line+1,
+				editRange.End.Character+1))
+		}
+
+		// Add truncated documentation if available
+		if item.Documentation != nil {
+			docStr := extractDocumentation(item.Documentation)
+			if docStr != "" {
+				// Truncate to first line or 100 chars
+				lines := strings.Split(docStr, "\n")
+				if len(lines) > 0 && lines[0] != "" {
+					doc := lines[0]
+					if len(doc) > 100 {
+						doc = doc[:97] + "..."
+					}
+					output.WriteString(fmt.Sprintf("\n   Doc: %s", doc))
+				}
+			}
+		}
+
+		output.WriteString("\n\n")
+	}
+
+	return output.String(), nil
+}
+
+// requestCompletionItems requests completions at the given 1-indexed position and returns the
+// items with the list's itemDefaults applied, sorted by SortText or Label, and whether the
+// server reported the list as incomplete
+func requestCompletionItems(ctx context.Context, client *lsp.Client, filePath string, line, column int, triggerCharacter string) ([]protocol.CompletionItem, bool, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return nil, false, err
+	}
+
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, false, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Create completion parameters
@@ -37,27 +135,40 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 		URI: uri,
 	}
 	params.Position = position
+	params.Context = protocol.CompletionContext{TriggerKind: protocol.Invoked}
+	if triggerCharacter != "" {
+		params.Context = protocol.CompletionContext{
+			TriggerKind:      protocol.TriggerCharacter,
+			TriggerCharacter: triggerCharacter,
+		}
+	}
 
 	// Execute the completion request
 	completionResult, err := client.Completion(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get completions: %v", err)
+		return nil, false, fmt.Errorf("failed to get completions: %v", err)
 	}
 
 	// Handle the response which can be CompletionList or []CompletionItem
 	var items []protocol.CompletionItem
-	var totalCount int
+	var isIncomplete bool
+	var defaults *protocol.CompletionItemDefaults
 
 	// Extract items from completionResult
 	if completionResult.Value == nil {
-		return "No completions available", nil
+		return nil, false, nil
 	}
 
 	switch v := completionResult.Value.(type) {
+	case protocol.CompletionList:
+		items = append(items, v.Items...)
+		isIncomplete = v.IsIncomplete
+		defaults = v.ItemDefaults
+	case []protocol.CompletionItem:
+		items = append(items, v...)
 	case map[string]any:
 		// This is a CompletionList
 		if itemsRaw, ok := v["items"].([]any); ok {
-			totalCount = len(itemsRaw)
 			for _, itemRaw := range itemsRaw {
 				if itemMap, ok := itemRaw.(map[string]any); ok {
 					item := parseCompletionItem(itemMap)
@@ -65,9 +176,17 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 				}
 			}
 		}
+		isIncomplete, _ = v["isIncomplete"].(bool)
+		if defaultsRaw, ok := v["itemDefaults"]; ok {
+			if data, err := json.Marshal(defaultsRaw); err == nil {
+				var itemDefaults protocol.CompletionItemDefaults
+				if err := json.Unmarshal(data, &itemDefaults); err == nil {
+					defaults = &itemDefaults
+				}
+			}
+		}
 	case []any:
 		// This is []CompletionItem
-		totalCount = len(v)
 		for _, itemRaw := range v {
 			if itemMap, ok := itemRaw.(map[string]any); ok {
 				item := parseCompletionItem(itemMap)
@@ -75,15 +194,18 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 			}
 		}
 	default:
-		return "", fmt.Errorf("unexpected completion result type: %T", v)
+		return nil, false, fmt.Errorf("unexpected completion result type: %T", v)
 	}
 
-	if len(items) == 0 {
-		return "No completions available", nil
+	// Servers may leave fields shared by all items out of the items themselves
+	if defaults != nil {
+		for i := range items {
+			items[i] = applyCompletionItemDefaults(items[i], defaults)
+		}
 	}
 
-	// Sort by SortText or Label
-	sort.Slice(items, func(i, j int) bool {
+	// Sort by SortText or Label, stable so that indexes are reproducible across requests
+	sort.SliceStable(items, func(i, j int) bool {
 		// Use SortText if available, otherwise use Label
 		iSort := items[i].SortText
 		if iSort == "" {
@@ -96,47 +218,88 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 		return iSort < jSort
 	})
 
-	// Limit results
-	if len(items) > limit {
-		items = items[:limit]
-	}
+	return items, isIncomplete, nil
+}
 
-	// Format output
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Completions (%d of %d):\n\n", len(items), totalCount))
+// validateTriggerCharacter checks that triggerCharacter, if set, is one of the trigger
+// characters advertised by the server
+func validateTriggerCharacter(triggerCharacter string, advertised []string) error {
+	if triggerCharacter == "" || slices.Contains(advertised, triggerCharacter) {
+		return nil
+	}
+	if len(advertised) == 0 {
+		return fmt.Errorf("trigger character %q is not supported: the server does not advertise any trigger characters", triggerCharacter)
+	}
+	quoted := make([]string, len(advertised))
+	for i, char := range advertised {
+		quoted[i] = fmt.Sprintf("%q", char)
+	}
+	return fmt.Errorf("trigger character %q is not supported by the server. Available trigger characters: %s", triggerCharacter, strings.Join(quoted, ", "))
+}
 
-	for i, item := range items {
-		// Get kind string
-		kindStr := getCompletionKindString(item.Kind)
+// Completion match ranks used by filterCompletionItems, best first
+const (
+	completionMatchExactPrefix = iota
+	completionMatchPrefix
+	completionMatchFuzzy
+	completionMatchNone
+)
 
-		// Format: index. [Kind] Label
-		output.WriteString(fmt.Sprintf("%d. [%s] %s", i+1, kindStr, item.Label))
+// filterCompletionItems keeps items whose FilterText (or Label) matches prefix and orders
+// them exact-case prefix matches first, then case-insensitive prefix matches, then fuzzy
+// (in-order subsequence) matches. The relative order of items within a rank is preserved.
+func filterCompletionItems(items []protocol.CompletionItem, prefix string) []protocol.CompletionItem {
+	type rankedItem struct {
+		item protocol.CompletionItem
+		rank int
+	}
 
-		// Add detail if available (type information)
-		if item.Detail != "" {
-			output.WriteString(fmt.Sprintf("\n   Type: %s", item.Detail))
+	var ranked []rankedItem
+	for _, item := range items {
+		text := item.FilterText
+		if text == "" {
+			text = item.Label
 		}
-
-		// Add truncated documentation if available
-		if item.Documentation != nil {
-			docStr := extractDocumentation(item.Documentation)
-			if docStr != "" {
-				// Truncate to first line or 100 chars
-				lines := strings.Split(docStr, "\n")
-				if len(lines) > 0 && lines[0] != "" {
-					doc := lines[0]
-					if len(doc) > 100 {
-						doc = doc[:97] + "..."
-					}
-					output.WriteString(fmt.Sprintf("\n   Doc: %s", doc))
-				}
-			}
+		if rank := completionMatchRank(text, prefix); rank != completionMatchNone {
+			ranked = append(ranked, rankedItem{item: item, rank: rank})
 		}
+	}
 
-		output.WriteString("\n\n")
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
+	})
+
+	filtered := make([]protocol.CompletionItem, len(ranked))
+	for i, r := range ranked {
+		filtered[i] = r.item
 	}
+	return filtered
+}
 
-	return output.String(), nil
+// completionMatchRank returns how well text matches prefix
+func completionMatchRank(text, prefix string) int {
+	if strings.HasPrefix(text, prefix) {
+		return completionMatchExactPrefix
+	}
+
+	lowerText := strings.ToLower(text)
+	lowerPrefix := strings.ToLower(prefix)
+	if strings.HasPrefix(lowerText, lowerPrefix) {
+		return completionMatchPrefix
+	}
+
+	// Fuzzy: every character of the prefix appears in order
+	prefixRunes := []rune(lowerPrefix)
+	i := 0
+	for _, r := range lowerText {
+		if i < len(prefixRunes) && r == prefixRunes[i] {
+			i++
+		}
+	}
+	if i == len(prefixRunes) {
+		return completionMatchFuzzy
+	}
+	return completionMatchNone
 }
 
 // parseCompletionItem converts a map[string]any to CompletionItem
@@ -163,14 +326,268 @@ func parseCompletionItem(itemMap map[string]any) protocol.CompletionItem {
 		item.FilterText = filterText
 	}
 
+	if insertText, ok := itemMap["insertText"].(string); ok {
+		item.InsertText = insertText
+	}
+
+	if textEditText, ok := itemMap["textEditText"].(string); ok {
+		item.TextEditText = textEditText
+	}
+
+	if format, ok := itemMap["insertTextFormat"].(float64); ok {
+		insertTextFormat := protocol.InsertTextFormat(format)
+		item.InsertTextFormat = &insertTextFormat
+	}
+
+	// TextEdit can be TextEdit or InsertReplaceEdit, let the protocol types decide
+	if textEdit, ok := itemMap["textEdit"]; ok {
+		if data, err := json.Marshal(textEdit); err == nil {
+			var edit protocol.Or_CompletionItem_textEdit
+			if err := json.Unmarshal(data, &edit); err == nil && edit.Value != nil {
+				item.TextEdit = &edit
+			}
+		}
+	}
+
 	// Documentation can be string or MarkupContent
 	if doc, ok := itemMap["documentation"]; ok {
 		item.Documentation = &protocol.Or_CompletionItem_documentation{Value: doc}
 	}
 
+	if data, ok := itemMap["data"]; ok {
+		item.Data = data
+	}
+
+	return item
+}
+
+// applyCompletionItemDefaults fills in the fields of item that a CompletionList's itemDefaults
+// provides and the item itself leaves unset
+func applyCompletionItemDefaults(item protocol.CompletionItem, defaults *protocol.CompletionItemDefaults) protocol.CompletionItem {
+	if item.TextEdit == nil && defaults.EditRange != nil {
+		// The default range is combined with textEditText, falling back to the label
+		newText := item.TextEditText
+		if newText == "" {
+			newText = item.Label
+		}
+		switch v := defaults.EditRange.Value.(type) {
+		case protocol.Range:
+			item.TextEdit = &protocol.Or_CompletionItem_textEdit{Value: protocol.TextEdit{Range: v, NewText: newText}}
+		case protocol.EditRangeWithInsertReplace:
+			item.TextEdit = &protocol.Or_CompletionItem_textEdit{Value: protocol.InsertReplaceEdit{
+				NewText: newText,
+				Insert:  v.Insert,
+				Replace: v.Replace,
+			}}
+		}
+	}
+	if item.InsertTextFormat == nil && defaults.InsertTextFormat != nil {
+		insertTextFormat := *defaults.InsertTextFormat
+		item.InsertTextFormat = &insertTextFormat
+	}
+	if item.InsertTextMode == nil && defaults.InsertTextMode != nil {
+		insertTextMode := *defaults.InsertTextMode
+		item.InsertTextMode = &insertTextMode
+	}
+	if item.CommitCharacters == nil {
+		item.CommitCharacters = defaults.CommitCharacters
+	}
+	if item.Data == nil {
+		item.Data = defaults.Data
+	}
 	return item
 }
 
+// getCompletionInsertText returns the text a completion inserts, preferring textEdit over
+// insertText over label. Snippets have their placeholders stripped so the result can be
+// inserted literally; the second return value reports whether the item was a snippet.
+func getCompletionInsertText(item protocol.CompletionItem) (string, bool) {
+	text := item.Label
+	if item.InsertText != "" {
+		text = item.InsertText
+	}
+	if item.TextEdit != nil {
+		switch v := item.TextEdit.Value.(type) {
+		case protocol.TextEdit:
+			text = v.NewText
+		case protocol.InsertReplaceEdit:
+			text = v.NewText
+		}
+	}
+
+	if item.InsertTextFormat != nil && *item.InsertTextFormat == protocol.SnippetTextFormat {
+		return stripSnippetPlaceholders(text), true
+	}
+	return text, false
+}
+
+// getCompletionEditRange returns the range replaced by a completion's textEdit, if any.
+// For InsertReplaceEdit the replace range is used.
+func getCompletionEditRange(item protocol.CompletionItem) *protocol.Range {
+	if item.TextEdit == nil {
+		return nil
+	}
+	switch v := item.TextEdit.Value.(type) {
+	case protocol.TextEdit:
+		return &v.Range
+	case protocol.InsertReplaceEdit:
+		return &v.Replace
+	}
+	return nil
+}
+
+// stripSnippetPlaceholders converts LSP snippet syntax to plain text. Tabstops ($1, ${2})
+// are removed, placeholders (${1:name}) are replaced with their default text, also when
+// nested, choices (${1|a,b|}) with their first option, variables and their transforms are
+// removed and escaped characters are unescaped.
+func stripSnippetPlaceholders(snippet string) string {
+	text, _ := parseSnippet(snippet, 0, false)
+	return text
+}
+
+// parseSnippet parses snippet text starting at index i. When nested is true parsing stops
+// at the first unescaped '}', whose index is returned along with the plain text.
+func parseSnippet(snippet string, i int, nested bool) (string, int) {
+	var text strings.Builder
+	for i < len(snippet) {
+		c := snippet[i]
+		switch {
+		case c == '\\' && i+1 < len(snippet) && strings.IndexByte(`$}\`, snippet[i+1]) >= 0:
+			text.WriteByte(snippet[i+1])
+			i += 2
+		case c == '}' && nested:
+			return text.String(), i
+		case c == '$':
+			elementText, next, ok := parseSnippetElement(snippet, i)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+			text.WriteString(elementText)
+			i = next
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	return text.String(), i
+}
+
+// parseSnippetElement parses a tabstop, placeholder, choice or variable beginning with the
+// '$' at index i. It returns the plain text of the element, the index after it, and false
+// if the text at i is not a valid snippet element.
+func parseSnippetElement(snippet string, i int) (string, int, bool) {
+	j := i + 1
+	if j >= len(snippet) {
+		return "", i, false
+	}
+
+	// Simple tabstop ($1) or variable ($TM_FILENAME)
+	if isSnippetNameChar(snippet[j]) {
+		for j < len(snippet) && isSnippetNameChar(snippet[j]) {
+			j++
+		}
+		return "", j, true
+	}
+
+	if snippet[j] != '{' {
+		return "", i, false
+	}
+	j++
+	nameStart := j
+	for j < len(snippet) && isSnippetNameChar(snippet[j]) {
+		j++
+	}
+	if j == nameStart || j >= len(snippet) {
+		return "", i, false
+	}
+
+	switch snippet[j] {
+	case '}':
+		// ${1} or ${name}
+		return "", j + 1, true
+	case ':':
+		// ${1:default}, where the default may itself contain snippet elements
+		text, end := parseSnippet(snippet, j+1, true)
+		if end >= len(snippet) {
+			return "", i, false
+		}
+		return text, end + 1, true
+	case '|':
+		// ${1|one,two|} - use the first choice
+		return parseSnippetChoice(snippet, i, j+1)
+	case '/':
+		// ${TM_FILENAME/(.*)/${1:/upcase}/} - variables aren't known, so like a plain
+		// variable the transform inserts nothing
+		return skipSnippetTransform(snippet, i, j+1)
+	}
+	return "", i, false
+}
+
+// parseSnippetChoice parses the options of a choice starting at index j, after the opening
+// '|', and returns the first option. Options are separated by ',' and may escape ',', '|'
+// and the other snippet characters with a backslash.
+func parseSnippetChoice(snippet string, i, j int) (string, int, bool) {
+	var first strings.Builder
+	inFirst := true
+	for j < len(snippet) {
+		c := snippet[j]
+		switch {
+		case c == '\\' && j+1 < len(snippet) && strings.IndexByte(`$}\,|`, snippet[j+1]) >= 0:
+			if inFirst {
+				first.WriteByte(snippet[j+1])
+			}
+			j += 2
+		case c == '|' && j+1 < len(snippet) && snippet[j+1] == '}':
+			return first.String(), j + 2, true
+		case c == ',':
+			inFirst = false
+			j++
+		default:
+			if inFirst {
+				first.WriteByte(c)
+			}
+			j++
+		}
+	}
+	return "", i, false
+}
+
+// skipSnippetTransform skips the regex, format and options of a variable transform starting
+// at index j, after the first '/', and returns the index after the closing '}'. The format
+// may contain '/' inside ${1:/upcase} style elements.
+func skipSnippetTransform(snippet string, i, j int) (string, int, bool) {
+	slashes, depth := 0, 0
+	for j < len(snippet) {
+		c := snippet[j]
+		switch {
+		case c == '\\':
+			j += 2
+			continue
+		case slashes == 1 && c == '$' && j+1 < len(snippet) && snippet[j+1] == '{':
+			depth++
+			j += 2
+			continue
+		case c == '}' && depth > 0:
+			depth--
+		case c == '}':
+			if slashes < 2 {
+				return "", i, false
+			}
+			return "", j + 1, true
+		case c == '/' && depth == 0 && slashes < 2:
+			slashes++
+		}
+		j++
+	}
+	return "", i, false
+}
+
+func isSnippetNameChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
 // extractDocumentation extracts documentation string from Or_CompletionItem_documentation
 func extractDocumentation(doc *protocol.Or_CompletionItem_documentation) string {
 	if doc == nil || doc.Value == nil {
@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripSnippetPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		snippet  string
+		expected string
+	}{
+		{
+			name:     "plain text",
+			snippet:  "fmt.Println",
+			expected: "fmt.Println",
+		},
+		{
+			name:     "simple tabstops",
+			snippet:  "foo($1)$0",
+			expected: "foo()",
+		},
+		{
+			name:     "braced tabstop",
+			snippet:  "foo(${1})",
+			expected: "foo()",
+		},
+		{
+			name:     "placeholders",
+			snippet:  "foo(${1:x}, ${2:y})",
+			expected: "foo(x, y)",
+		},
+		{
+			name:     "escaped characters",
+			snippet:  `cost \$5 \} done`,
+			expected: "cost $5 } done",
+		},
+		{
+			name:     "dollar without element",
+			snippet:  "a $ b",
+			expected: "a $ b",
+		},
+		{
+			name:     "unterminated placeholder",
+			snippet:  "foo(${1:x",
+			expected: "foo(${1:x",
+		},
+		{
+			name:     "nested placeholders",
+			snippet:  "make(${1:map[${2:string}]${3:int}}, ${4:0})$0",
+			expected: "make(map[string]int, 0)",
+		},
+		{
+			name:     "tabstop in placeholder",
+			snippet:  "if ${1:err != nil$2} {\n\t$0\n}",
+			expected: "if err != nil {\n\t\n}",
+		},
+		{
+			name:     "choice",
+			snippet:  "log.${1|Println,Printf,Fatal|}(${2:msg})",
+			expected: "log.Println(msg)",
+		},
+		{
+			name:     "choice with escapes",
+			snippet:  `sep(${1|\,\|,;|})`,
+			expected: "sep(,|)",
+		},
+		{
+			name:     "choice in placeholder",
+			snippet:  "${1:x := ${2|true,false|}}",
+			expected: "x := true",
+		},
+		{
+			name:     "unterminated choice",
+			snippet:  "${1|a,b}",
+			expected: "${1|a,b}",
+		},
+		{
+			name:     "variable with default",
+			snippet:  "// ${TM_FILENAME:main.go}",
+			expected: "// main.go",
+		},
+		{
+			name:     "variable transform",
+			snippet:  "type ${TM_FILENAME_BASE/(.*)/${1:/capitalize}/} struct{}",
+			expected: "type  struct{}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stripSnippetPlaceholders(tt.snippet))
+		})
+	}
+}
+
+func TestGetCompletionInsertText(t *testing.T) {
+	snippet := protocol.SnippetTextFormat
+	plainText := protocol.PlainTextTextFormat
+
+	tests := []struct {
+		name            string
+		item            protocol.CompletionItem
+		expectedText    string
+		expectedSnippet bool
+	}{
+		{
+			name:         "label only",
+			item:         protocol.CompletionItem{Label: "foo"},
+			expectedText: "foo",
+		},
+		{
+			name:         "insert text",
+			item:         protocol.CompletionItem{Label: "foo(int x)", InsertText: "foo", InsertTextFormat: &plainText},
+			expectedText: "foo",
+		},
+		{
+			name:            "snippet insert text",
+			item:            protocol.CompletionItem{Label: "foo(int x)", InsertText: "foo(${1:x})", InsertTextFormat: &snippet},
+			expectedText:    "foo(x)",
+			expectedSnippet: true,
+		},
+		{
+			name: "text edit takes precedence",
+			item: protocol.CompletionItem{
+				Label:      "foo",
+				InsertText: "ignored",
+				TextEdit: &protocol.Or_CompletionItem_textEdit{
+					Value: protocol.TextEdit{NewText: "bar"},
+				},
+			},
+			expectedText: "bar",
+		},
+		{
+			name: "insert replace edit",
+			item: protocol.CompletionItem{
+				Label:            "foo",
+				InsertTextFormat: &snippet,
+				TextEdit: &protocol.Or_CompletionItem_textEdit{
+					Value: protocol.InsertReplaceEdit{NewText: "baz($0)"},
+				},
+			},
+			expectedText:    "baz()",
+			expectedSnippet: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isSnippet := getCompletionInsertText(tt.item)
+			assert.Equal(t, tt.expectedText, text)
+			assert.Equal(t, tt.expectedSnippet, isSnippet)
+		})
+	}
+}
+
+func TestParseCompletionItemTextEdit(t *testing.T) {
+	item := parseCompletionItem(map[string]any{
+		"label":            "Println",
+		"insertTextFormat": float64(2),
+		"textEdit": map[string]any{
+			"newText": "Println(${1:a ...any})",
+			"range": map[string]any{
+				"start": map[string]any{"line": float64(3), "character": float64(5)},
+				"end":   map[string]any{"line": float64(3), "character": float64(8)},
+			},
+		},
+	})
+
+	assert.NotNil(t, item.TextEdit)
+	text, isSnippet := getCompletionInsertText(item)
+	assert.Equal(t, "Println(a ...any)", text)
+	assert.True(t, isSnippet)
+
+	editRange := getCompletionEditRange(item)
+	if assert.NotNil(t, editRange) {
+		assert.Equal(t, uint32(3), editRange.Start.Line)
+		assert.Equal(t, uint32(8), editRange.End.Character)
+	}
+}
+
+func TestFilterCompletionItems(t *testing.T) {
+	items := []protocol.CompletionItem{
+		{Label: "Unmarshal"},
+		{Label: "marshalIndent"},
+		{Label: "MarshalIndent"},
+		{Label: "Marshal"},
+		{Label: "NewDecoder"},
+		{Label: "m_a_r", FilterText: "mar"},
+	}
+
+	filtered := filterCompletionItems(items, "Mar")
+
+	var labels []string
+	for _, item := range filtered {
+		labels = append(labels, item.Label)
+	}
+	// Exact-case prefix matches first, then case-insensitive prefix matches
+	// (including FilterText), then fuzzy matches
+	assert.Equal(t, []string{"MarshalIndent", "Marshal", "marshalIndent", "m_a_r", "Unmarshal"}, labels)
+}
+
+func TestCompletionMatchRank(t *testing.T) {
+	tests := []struct {
+		text     string
+		prefix   string
+		expected int
+	}{
+		{"Marshal", "Mar", completionMatchExactPrefix},
+		{"marshal", "Mar", completionMatchPrefix},
+		{"MarshalIndent", "mi", completionMatchFuzzy},
+		{"Decoder", "mar", completionMatchNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text+"/"+tt.prefix, func(t *testing.T) {
+			assert.Equal(t, tt.expected, completionMatchRank(tt.text, tt.prefix))
+		})
+	}
+}
+
+func TestApplyCompletionItemDefaults(t *testing.T) {
+	snippet := protocol.SnippetTextFormat
+	plainText := protocol.PlainTextTextFormat
+	defaultRange := protocol.Range{
+		Start: protocol.Position{Line: 2, Character: 4},
+		End:   protocol.Position{Line: 2, Character: 7},
+	}
+	defaults := &protocol.CompletionItemDefaults{
+		EditRange:        &protocol.Or_CompletionItemDefaults_editRange{Value: defaultRange},
+		InsertTextFormat: &snippet,
+		Data:             map[string]any{"id": float64(1)},
+	}
+
+	// Unset fields are taken from the defaults, using textEditText for the edit
+	item := applyCompletionItemDefaults(protocol.CompletionItem{
+		Label:        "Println",
+		TextEditText: "Println(${1:a})",
+	}, defaults)
+	text, isSnippet := getCompletionInsertText(item)
+	assert.Equal(t, "Println(a)", text)
+	assert.True(t, isSnippet)
+	assert.Equal(t, &defaultRange, getCompletionEditRange(item))
+	assert.Equal(t, map[string]any{"id": float64(1)}, item.Data)
+
+	// Fields set on the item win over the defaults
+	itemRange := protocol.Range{End: protocol.Position{Character: 1}}
+	item = applyCompletionItemDefaults(protocol.CompletionItem{
+		Label:            "x",
+		InsertTextFormat: &plainText,
+		TextEdit:         &protocol.Or_CompletionItem_textEdit{Value: protocol.TextEdit{Range: itemRange, NewText: "x"}},
+		Data:             "own",
+	}, defaults)
+	assert.Equal(t, &itemRange, getCompletionEditRange(item))
+	assert.Equal(t, protocol.PlainTextTextFormat, *item.InsertTextFormat)
+	assert.Equal(t, "own", item.Data)
+
+	// An insert/replace default range uses the replace range
+	item = applyCompletionItemDefaults(protocol.CompletionItem{Label: "y"}, &protocol.CompletionItemDefaults{
+		EditRange: &protocol.Or_CompletionItemDefaults_editRange{Value: protocol.EditRangeWithInsertReplace{
+			Insert:  protocol.Range{End: protocol.Position{Character: 1}},
+			Replace: defaultRange,
+		}},
+	})
+	assert.Equal(t, &defaultRange, getCompletionEditRange(item))
+	text, _ = getCompletionInsertText(item)
+	assert.Equal(t, "y", text)
+}
+
+func TestValidateTriggerCharacter(t *testing.T) {
+	assert.NoError(t, validateTriggerCharacter("", nil))
+	assert.NoError(t, validateTriggerCharacter(".", []string{".", ":"}))
+
+	err := validateTriggerCharacter("->", []string{".", ":"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Available trigger characters: ".", ":"`)
+	}
+
+	err = validateTriggerCharacter(".", nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not advertise any trigger characters")
+	}
+}
+
+func TestGetCompletionsResultShapes(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      string
+		contains    []string
+		expectedErr string
+	}{
+		{
+			name:     "completion list",
+			result:   `{"isIncomplete": false, "items": [{"label": "Println", "kind": 3, "detail": "func(a ...any)"}]}`,
+			contains: []string{"Completions (1 of 1)", "1. [Function] Println", "Type: func(a ...any)"},
+		},
+		{
+			name:     "incomplete list",
+			result:   `{"isIncomplete": true, "items": [{"label": "Println"}]}`,
+			contains: []string{"the server returned a partial list"},
+		},
+		{
+			name:     "item array",
+			result:   `[{"label": "Printf", "kind": 3}, {"label": "Println", "kind": 3}]`,
+			contains: []string{"Completions (2 of 2)", "1. [Function] Printf", "2. [Function] Println"},
+		},
+		{
+			name:     "list defaults",
+			result:   `{"isIncomplete": false, "itemDefaults": {"editRange": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 6}}}, "items": [{"label": "Println"}]}`,
+			contains: []string{"Replaces: L1:C5 - L1:C7"},
+		},
+		{
+			name:     "null result",
+			result:   `null`,
+			contains: []string{"No completions available"},
+		},
+		{
+			name:        "wrongly typed result",
+			result:      `"Println"`,
+			expectedErr: "failed to get completions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			server.RespondRaw("textDocument/completion", tt.result)
+			filePath := writeTestFile(t, "main.go", "fmt.Pr\n")
+
+			result, err := GetCompletions(t.Context(), server.Client, filePath, 1, 7, 0, "", "", nil)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			for _, expected := range tt.contains {
+				assert.Contains(t, result, expected)
+			}
+		})
+	}
+}
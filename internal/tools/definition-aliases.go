@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// MaxAliasHops is the number of aliases followAliases follows at most before giving up
+const MaxAliasHops = 5
+
+// aliasDeclarationPattern matches alias declarations such as Go and TypeScript `type A = B`,
+// Rust `pub type A = B;` and C++/C# `using A = B;`, as well as `A = B` in Go type groups and
+// Python. The name of the alias and the last part of the possibly qualified name it refers
+// to are captured.
+var aliasDeclarationPattern = regexp.MustCompile(`^\s*(?:(?:export\s+)?(?:declare\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:type|using)\s+)?([\p{L}_$][\p{L}\p{N}_$]*)\s*(?:<[^>]*>|\[[^\]]*\])?\s*=\s*(?:typeof\s+)?(?:[\p{L}_$][\p{L}\p{N}_$]*(?:\.|::))*([\p{L}_$][\p{L}\p{N}_$]*)\s*(?:[;<\[]|//|#|$)`)
+
+// followAliases resolves definitions that are aliases or re-exports to what they refer to.
+// From each definition another definition request is made, at the target of the alias if
+// the declaration looks like one, or else at the name itself, which servers like TypeScript's
+// resolve through re-exports. This stops when the server answers with the same location,
+// after MaxAliasHops, or when a location repeats. The chain of locations visited is returned,
+// starting with loc; it has a single element if loc is not an alias.
+func followAliases(ctx context.Context, client *lsp.Client, loc protocol.Location) []protocol.Location {
+	chain := []protocol.Location{loc}
+	seen := map[string]bool{aliasLocationKey(loc): true}
+	for len(chain) <= MaxAliasHops {
+		next, ok := aliasTarget(ctx, client, chain[len(chain)-1])
+		if !ok {
+			break
+		}
+		key := aliasLocationKey(next)
+		if seen[key] {
+			toolsLogger.Debug("Alias chain of %s loops back to %s", loc.URI, key)
+			break
+		}
+		seen[key] = true
+		chain = append(chain, next)
+	}
+	return chain
+}
+
+// aliasTarget asks the server for the definition of the alias at loc, returning false if it
+// has none other than loc itself
+func aliasTarget(ctx context.Context, client *lsp.Client, loc protocol.Location) (protocol.Location, bool) {
+	filePath := loc.URI.Path()
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		toolsLogger.Error("Error opening file: %v", err)
+		return protocol.Location{}, false
+	}
+
+	position := loc.Range.Start
+	if target, ok := aliasTargetPosition(filePath, position); ok {
+		position = target
+	}
+	defResult, err := client.Definition(ctx, protocol.DefinitionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+			Position:     position,
+		},
+	})
+	if err != nil {
+		toolsLogger.Debug("No definition for alias at %s: %v", aliasLocationKey(loc), err)
+		return protocol.Location{}, false
+	}
+	locations, err := extractDefinitionLocations(defResult)
+	if err != nil || len(locations) == 0 {
+		return protocol.Location{}, false
+	}
+
+	next := locations[0]
+	if next.URI == loc.URI && (next.Range == loc.Range || next.Range.Start == loc.Range.Start || rangeContains(next.Range, loc.Range)) {
+		return protocol.Location{}, false
+	}
+	return next, true
+}
+
+// aliasTargetPosition returns the position of the name an alias declared at position refers
+// to, e.g. of B in `type A = pkg.B`, or false if the declaration doesn't look like an alias
+func aliasTargetPosition(filePath string, position protocol.Position) (protocol.Position, bool) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return protocol.Position{}, false
+	}
+	lines := strings.Split(string(content), "\n")
+	if int(position.Line) >= len(lines) {
+		return protocol.Position{}, false
+	}
+
+	// The server may point at the name of the alias or at the start of its declaration
+	match := aliasDeclarationPattern.FindStringSubmatchIndex(strings.TrimSuffix(lines[position.Line], "\r"))
+	if match == nil || int(position.Character) > match[3] {
+		return protocol.Position{}, false
+	}
+	return protocol.Position{Line: position.Line, Character: uint32(match[4])}, true
+}
+
+func aliasLocationKey(loc protocol.Location) string {
+	return fmt.Sprintf("%s:L%d:C%d", loc.URI.Path(), loc.Range.Start.Line+1, loc.Range.Start.Character+1)
+}
+
+// formatAliasChain renders the hops of an alias chain, e.g. "a.ts:L1:C10 -> b.ts:L3:C17"
+func formatAliasChain(chain []protocol.Location) string {
+	hops := make([]string, len(chain))
+	for i, loc := range chain {
+		hops[i] = aliasLocationKey(loc)
+	}
+	return strings.Join(hops, " -> ")
+}
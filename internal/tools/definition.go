@@ -3,28 +3,109 @@ package tools
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
+// DefinitionOptions controls how ReadDefinitionWithOptions formats its output
+type DefinitionOptions struct {
+	// ContextLines is the number of lines to include before and after each definition,
+	// e.g. to pick up the doc comment directly above a function
+	ContextLines int
+	// Index selects a single definition (1-indexed) when several symbols match,
+	// as numbered in the output of a previous call. 0 returns all matches.
+	Index int
+	// Kind restricts matches to symbols of this kind. 0 matches any kind.
+	Kind protocol.SymbolKind
+	// Exact disables fuzzy matching: only symbols whose name is exactly symbolName match
+	Exact bool
+	// MaxLines truncates each definition body to this many lines. 0 means no limit.
+	MaxLines int
+	// FollowAliases resolves definitions that are aliases or re-exports to the definition
+	// they refer to, see followAliases
+	FollowAliases bool
+	// Format is OutputFormatText (the default if empty) or OutputFormatMarkdown, which
+	// shows the source in a code block with the file's language
+	Format string
+}
+
+// ParseSymbolKind converts a user-facing kind name such as "function", "Struct" or
+// "enum_member" to a protocol.SymbolKind
+func ParseSymbolKind(name string) (protocol.SymbolKind, error) {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+	kind, ok := protocol.TableKindNameMap[normalized]
+	if !ok {
+		return 0, fmt.Errorf("unknown symbol kind: %s", name)
+	}
+	return kind, nil
+}
+
 func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+	return ReadDefinitionWithOptions(ctx, client, symbolName, DefinitionOptions{})
+}
+
+// ReadDefinitionWithOptions returns the source of the definitions of a symbol, with lines
+// longer than MaxLineWidth wrapped in the text format
+func ReadDefinitionWithOptions(ctx context.Context, client *lsp.Client, symbolName string, opts DefinitionOptions) (string, error) {
+	if err := ValidateOutputFormat(opts.Format); err != nil {
+		return "", err
+	}
+	definitions, err := findDefinitions(ctx, client, symbolName, opts)
+	if err != nil {
+		return "", err
+	}
+	format, width := definitionMatch.format, MaxLineWidth()
+	if opts.Format == OutputFormatMarkdown {
+		// Clients wrap rendered markdown themselves, and wrapping code blocks would break them
+		format, width = definitionMatch.formatMarkdown, 0
+	}
+
+	if len(definitions) == 0 {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+
+	if opts.Index > 0 {
+		if opts.Index > len(definitions) {
+			return "", fmt.Errorf("index %d out of range: found %d definitions of %s", opts.Index, len(definitions), symbolName)
+		}
+		return wrapLines(format(definitions[opts.Index-1], 0, 0), width), nil
+	}
+
+	var result strings.Builder
+	for i, def := range definitions {
+		if len(definitions) > 1 {
+			result.WriteString(format(def, i+1, len(definitions)))
+		} else {
+			result.WriteString(format(def, 0, 0))
+		}
+	}
+
+	return wrapLines(result.String(), width), nil
+}
+
+// findDefinitions resolves symbolName to its definitions, sorted by location
+func findDefinitions(ctx context.Context, client *lsp.Client, symbolName string, opts DefinitionOptions) ([]definitionMatch, error) {
 	// First, use workspace/symbol to find where the symbol is referenced
 	// This gives us a starting position to query for the definition
-	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
+	symbolResult, err := client.CachedSymbol(ctx, protocol.WorkspaceSymbolParams{
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return nil, fmt.Errorf("failed to parse results: %v", err)
 	}
 
-	var definitions []string
+	var definitions []definitionMatch
 	seenLocations := make(map[string]bool) // Track unique locations to avoid duplicates
 
 	for _, symbol := range results {
@@ -41,13 +122,32 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 				container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
 			}
 
+			if opts.Kind != 0 && v.Kind != opts.Kind {
+				continue
+			}
+
 			// Check if this symbol matches what we're looking for
-			if !symbolMatches(symbolName, symbol.GetName(), v.Kind, v.ContainerName) {
+			if opts.Exact {
+				if !symbolMatchesExact(symbolName, symbol.GetName()) {
+					continue
+				}
+			} else if !symbolMatches(symbolName, symbol.GetName(), v.Kind, v.ContainerName) {
 				continue
 			}
 		default:
+			if opts.Kind != 0 {
+				ws, ok := symbol.(*protocol.WorkspaceSymbol)
+				if !ok || ws.Kind != opts.Kind {
+					continue
+				}
+			}
+
 			// For generic symbols without type information, use basic matching
-			if !symbolMatches(symbolName, symbol.GetName(), 0, "") {
+			if opts.Exact {
+				if !symbolMatchesExact(symbolName, symbol.GetName()) {
+					continue
+				}
+			} else if !symbolMatches(symbolName, symbol.GetName(), 0, "") {
 				continue
 			}
 		}
@@ -72,7 +172,9 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 			},
 		}
 
-		defResult, err := client.Definition(ctx, defParams)
+		defResult, err := lsp.RetryDocumentRequest(ctx, client, loc.URI.Path(),
+			func(r protocol.Or_Result_textDocument_definition) bool { return r.Value == nil },
+			func() (protocol.Or_Result_textDocument_definition, error) { return client.Definition(ctx, defParams) })
 		if err != nil {
 			toolsLogger.Error("Error getting definition: %v", err)
 			continue
@@ -87,6 +189,13 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 
 		// Process each definition location
 		for _, defLoc := range defLocations {
+			var aliasChain []protocol.Location
+			if opts.FollowAliases {
+				if chain := followAliases(ctx, client, defLoc); len(chain) > 1 {
+					aliasChain, defLoc = chain, chain[len(chain)-1]
+				}
+			}
+
 			// Create unique key for this location to avoid duplicates
 			locationKey := fmt.Sprintf("%s:%d:%d", defLoc.URI, defLoc.Range.Start.Line, defLoc.Range.Start.Character)
 			if seenLocations[locationKey] {
@@ -101,37 +210,214 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 				continue
 			}
 
-			banner := "---\n\n"
 			definition, finalLoc, err := GetFullDefinition(ctx, client, defLoc)
-			locationInfo := fmt.Sprintf(
-				"Symbol: %s\n"+
-					"File: %s\n"+
-					kind+
-					container+
-					"Range: L%d:C%d - L%d:C%d\n\n",
-				symbol.GetName(),
-				strings.TrimPrefix(string(finalLoc.URI), "file://"),
-				finalLoc.Range.Start.Line+1,
-				finalLoc.Range.Start.Character+1,
-				finalLoc.Range.End.Line+1,
-				finalLoc.Range.End.Character+1,
-			)
-
 			if err != nil {
 				toolsLogger.Error("Error getting full definition: %v", err)
 				continue
 			}
 
-			definition = addLineNumbers(definition, int(finalLoc.Range.Start.Line)+1)
-			definitions = append(definitions, banner+locationInfo+definition+"\n")
+			// The line the definition points at is the declaration itself, whereas the
+			// full definition range may start earlier (e.g. at a comment or impl block)
+			signature := ""
+			definitionLines := strings.Split(definition, "\n")
+			if idx := int(defLoc.Range.Start.Line) - int(finalLoc.Range.Start.Line); idx >= 0 && idx < len(definitionLines) {
+				signature = strings.TrimSuffix(strings.TrimSpace(definitionLines[idx]), "{")
+				signature = strings.TrimSpace(signature)
+			}
+
+			firstLine := int(finalLoc.Range.Start.Line) + 1
+			if opts.ContextLines > 0 {
+				definition, firstLine, err = addSurroundingLines(finalLoc, definition, opts.ContextLines)
+				if err != nil {
+					toolsLogger.Error("Error adding context lines: %v", err)
+					continue
+				}
+			}
+
+			// Giant definitions (generated code, huge classes) are cut short so that one
+			// match doesn't crowd out everything else
+			bodyLines := strings.Split(definition, "\n")
+			totalLines := len(bodyLines)
+			source := definition
+			body := addLineNumbers(definition, firstLine)
+			if opts.MaxLines > 0 && totalLines > opts.MaxLines {
+				source = strings.Join(bodyLines[:opts.MaxLines], "\n")
+				body = addLineNumbers(source, firstLine) +
+					fmt.Sprintf("... (%d more lines, use read_range to see full)", totalLines-opts.MaxLines)
+			}
+
+			definitions = append(definitions, definitionMatch{
+				name:        symbol.GetName(),
+				kind:        kind,
+				container:   container,
+				signature:   signature,
+				location:    finalLoc,
+				declaration: defLoc,
+				aliasChain:  aliasChain,
+				body:        body,
+				source:      source,
+				firstLine:   firstLine,
+				totalLines:  totalLines,
+				truncated:   opts.MaxLines > 0 && totalLines > opts.MaxLines,
+				score:       fuzzyScore(symbolName, symbol.GetName()),
+			})
 		}
 	}
 
-	if len(definitions) == 0 {
-		return fmt.Sprintf("%s not found", symbolName), nil
+	// List the best matches first, then sort by location so the index of each match is
+	// stable across calls
+	sort.SliceStable(definitions, func(i, j int) bool {
+		if definitions[i].score != definitions[j].score {
+			return definitions[i].score > definitions[j].score
+		}
+		a, b := definitions[i].location, definitions[j].location
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line < b.Range.Start.Line
+		}
+		return a.Range.Start.Character < b.Range.Start.Character
+	})
+
+	return definitions, nil
+}
+
+// definitionMatch is a single definition found by ReadDefinition
+type definitionMatch struct {
+	name      string
+	kind      string
+	container string
+	signature string
+	location  protocol.Location
+	// declaration is the location the server reported for the definition, which may be
+	// narrower than location (e.g. just the identifier)
+	declaration protocol.Location
+	// aliasChain is the locations visited from the alias the server first reported to the
+	// definition, with FollowAliases
+	aliasChain []protocol.Location
+	body       string
+	// source is the text of body without line numbers, starting at line firstLine
+	source    string
+	firstLine int
+	// totalLines is the length of the full body, which is truncated if it exceeded MaxLines
+	totalLines int
+	truncated  bool
+	// score is how well the name matches the query, see fuzzyScore
+	score int
+}
+
+// format renders the definition. If total > 0, the block is labelled with its index so that
+// overloaded or same-named symbols can be told apart and fetched individually.
+func (d definitionMatch) format(index, total int) string {
+	var result strings.Builder
+	result.WriteString("---\n\n")
+	if total > 0 {
+		result.WriteString(fmt.Sprintf("Definition %d of %d\n", index, total))
+	}
+	result.WriteString(fmt.Sprintf("Symbol: %s\n", d.name))
+	filePath := utilities.URIToPath(d.location.URI)
+	result.WriteString(fmt.Sprintf("File: %s\n", filePath))
+	if language := SnippetLanguage(filePath); language != "" {
+		result.WriteString(fmt.Sprintf("Language: %s\n", language))
+	}
+	result.WriteString(d.kind)
+	result.WriteString(d.container)
+	if total > 0 && d.signature != "" {
+		result.WriteString(fmt.Sprintf("Signature: %s\n", d.signature))
+	}
+	if len(d.aliasChain) > 1 {
+		result.WriteString(fmt.Sprintf("Alias chain: %s\n", formatAliasChain(d.aliasChain)))
+	}
+	result.WriteString(fmt.Sprintf("Range: L%d:C%d - L%d:C%d\n\n",
+		d.location.Range.Start.Line+1,
+		d.location.Range.Start.Character+1,
+		d.location.Range.End.Line+1,
+		d.location.Range.End.Character+1,
+	))
+	if d.truncated {
+		result.WriteString(fmt.Sprintf("Lines: %d (truncated)\n\n", d.totalLines))
+	}
+	result.WriteString(d.body)
+	result.WriteString("\n")
+	return result.String()
+}
+
+// formatMarkdown renders the definition like format, with the source in a code block
+func (d definitionMatch) formatMarkdown(index, total int) string {
+	filePath := utilities.URIToPath(d.location.URI)
+
+	var result strings.Builder
+	if total > 0 {
+		result.WriteString(fmt.Sprintf("### Definition %d of %d: %s\n\n", index, total, markdownCode(d.name)))
+	} else {
+		result.WriteString(fmt.Sprintf("### %s\n\n", markdownCode(d.name)))
+	}
+	result.WriteString(fmt.Sprintf("- **File:** %s\n", markdownCode(filePath)))
+	// kind and container are preformatted as "Kind: Function\n"
+	for _, field := range []string{d.kind, d.container} {
+		if name, value, ok := strings.Cut(strings.TrimSpace(field), ": "); ok {
+			result.WriteString(fmt.Sprintf("- **%s:** %s\n", name, value))
+		}
+	}
+	if total > 0 && d.signature != "" {
+		result.WriteString(fmt.Sprintf("- **Signature:** %s\n", markdownCode(d.signature)))
 	}
+	if len(d.aliasChain) > 1 {
+		result.WriteString(fmt.Sprintf("- **Alias chain:** %s\n", formatAliasChain(d.aliasChain)))
+	}
+	result.WriteString(fmt.Sprintf("- **Range:** L%d:C%d - L%d:C%d\n",
+		d.location.Range.Start.Line+1,
+		d.location.Range.Start.Character+1,
+		d.location.Range.End.Line+1,
+		d.location.Range.End.Character+1,
+	))
+	lastLine := d.firstLine + strings.Count(d.source, "\n")
+	if d.truncated {
+		result.WriteString(fmt.Sprintf("- **Lines:** %d-%d, truncated from %d lines (use read_range to see the rest)\n", d.firstLine, lastLine, d.totalLines))
+	} else {
+		result.WriteString(fmt.Sprintf("- **Lines:** %d-%d\n", d.firstLine, lastLine))
+	}
+	result.WriteString("\n")
+	result.WriteString(codeFence(d.source, filePath))
+	result.WriteString("\n")
+	return result.String()
+}
+
+// addSurroundingLines extends the definition text at loc with up to n lines before and after it,
+// clamped to the file boundaries. It returns the new text and its 1-indexed first line.
+func addSurroundingLines(loc protocol.Location, definition string, n int) (string, int, error) {
+	filePath := utilities.URIToPath(loc.URI)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	// Don't count the empty string after a trailing newline as a line
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	startLine := int(loc.Range.Start.Line)
+	endLine := int(loc.Range.End.Line)
 
-	return strings.Join(definitions, ""), nil
+	before := max(startLine-n, 0)
+	after := min(endLine+1+n, len(lines))
+
+	var result strings.Builder
+	if before < startLine && startLine <= len(lines) {
+		result.WriteString(strings.Join(lines[before:startLine], "\n"))
+		result.WriteString("\n")
+	}
+	result.WriteString(definition)
+	if endLine+1 < after {
+		result.WriteString("\n")
+		result.WriteString(strings.Join(lines[endLine+1:after], "\n"))
+	}
+
+	return result.String(), before + 1, nil
 }
 
 // extractDefinitionLocations extracts Location objects from a Definition result
@@ -172,6 +458,10 @@ func extractDefinitionLocations(defResult protocol.Or_Result_textDocument_defini
 // symbolMatches determines if a symbol matches the search query.
 // It handles various language conventions including C++ (::), Go/TypeScript (.), and plain names.
 func symbolMatches(query, symbolName string, symbolKind protocol.SymbolKind, containerName string) bool {
+	// gopls reports methods as "(*Type).Method" or "(Type).Method"; compare them as "Type.Method"
+	query = normalizeGoReceiver(query)
+	symbolName = normalizeGoReceiver(symbolName)
+
 	// Exact match is always accepted
 	if symbolName == query {
 		return true
@@ -227,3 +517,19 @@ func symbolMatches(query, symbolName string, symbolKind protocol.SymbolKind, con
 
 	return false
 }
+
+// symbolMatchesExact reports whether symbolName is exactly the queried name, with none of the
+// suffix, substring or case-insensitive matching done by symbolMatches. Go receiver syntax is
+// still normalized since "(*Type).Method" and "Type.Method" name the same symbol.
+func symbolMatchesExact(query, symbolName string) bool {
+	return normalizeGoReceiver(query) == normalizeGoReceiver(symbolName)
+}
+
+// goReceiverPattern matches a parenthesized Go method receiver such as "(*Type)." or "(Type)."
+var goReceiverPattern = regexp.MustCompile(`\(\*?([\p{L}_][\p{L}\p{N}_]*)\)\.`)
+
+// normalizeGoReceiver rewrites Go receiver method syntax to plain dotted form,
+// e.g. "(*MyType).MyMethod" becomes "MyType.MyMethod"
+func normalizeGoReceiver(name string) string {
+	return goReceiverPattern.ReplaceAllString(name, "$1.")
+}
@@ -0,0 +1,433 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSurroundingLines(t *testing.T) {
+	content := "package main\n\n// Foo does things\nfunc Foo() {\n\treturn\n}\n\nfunc Bar() {}\n"
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	loc := protocol.Location{
+		URI: protocol.DocumentUri("file://" + filePath),
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 3},
+			End:   protocol.Position{Line: 5, Character: 1},
+		},
+	}
+	definition := "func Foo() {\n\treturn\n}"
+
+	tests := []struct {
+		name              string
+		n                 int
+		expectedText      string
+		expectedFirstLine int
+	}{
+		{
+			name:              "one line of context",
+			n:                 1,
+			expectedText:      "// Foo does things\nfunc Foo() {\n\treturn\n}\n",
+			expectedFirstLine: 3,
+		},
+		{
+			name:              "clamped to file boundaries",
+			n:                 10,
+			expectedText:      "package main\n\n// Foo does things\nfunc Foo() {\n\treturn\n}\n\nfunc Bar() {}",
+			expectedFirstLine: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, firstLine, err := addSurroundingLines(loc, definition, tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedText, text)
+			assert.Equal(t, tt.expectedFirstLine, firstLine)
+		})
+	}
+}
+
+func TestParseSymbolKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected protocol.SymbolKind
+		wantErr  bool
+	}{
+		{name: "function", expected: protocol.Function},
+		{name: "Struct", expected: protocol.Struct},
+		{name: "enum_member", expected: protocol.EnumMember},
+		{name: "TypeParameter", expected: protocol.TypeParameter},
+		{name: "widget", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := ParseSymbolKind(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, kind)
+		})
+	}
+}
+
+func TestSymbolMatches(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		symbolName    string
+		symbolKind    protocol.SymbolKind
+		containerName string
+		expected      bool
+	}{
+		{
+			name:       "exact match",
+			query:      "FooBar",
+			symbolName: "FooBar",
+			symbolKind: protocol.Function,
+			expected:   true,
+		},
+		{
+			name:       "value receiver",
+			query:      "MyType.MyMethod",
+			symbolName: "MyType.MyMethod",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "parenthesized value receiver",
+			query:      "MyType.MyMethod",
+			symbolName: "(MyType).MyMethod",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "pointer receiver",
+			query:      "MyType.MyMethod",
+			symbolName: "(*MyType).MyMethod",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "pointer receiver in query",
+			query:      "(*MyType).MyMethod",
+			symbolName: "MyType.MyMethod",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "unqualified query matches pointer receiver method",
+			query:      "MyMethod",
+			symbolName: "(*MyType).MyMethod",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "different receiver type",
+			query:      "OtherType.MyMethod",
+			symbolName: "(*MyType).MyMethod",
+			symbolKind: protocol.Method,
+			expected:   false,
+		},
+		{
+			// gopls reports promoted methods only on the type that declares them
+			name:       "promoted method is reported on the embedded type",
+			query:      "Inner.Method",
+			symbolName: "(*Inner).Method",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "promoted method does not match the embedding type",
+			query:      "Outer.Method",
+			symbolName: "(*Inner).Method",
+			symbolKind: protocol.Method,
+			expected:   false,
+		},
+		{
+			name:       "C++ qualified method",
+			query:      "method",
+			symbolName: "TestClass::method",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+		{
+			name:       "C++ call operator is not treated as a receiver",
+			query:      "operator()",
+			symbolName: "operator()",
+			symbolKind: protocol.Method,
+			expected:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, symbolMatches(tt.query, tt.symbolName, tt.symbolKind, tt.containerName))
+		})
+	}
+}
+
+func TestSymbolMatchesExact(t *testing.T) {
+	tests := []struct {
+		query      string
+		symbolName string
+		expected   bool
+	}{
+		{"vector", "vector", true},
+		{"vector", "std::vector<int>", false},
+		{"vector", "IntVector", false},
+		{"vector", "Vector", false},
+		{"method", "TestClass::method", false},
+		{"MyType.MyMethod", "(*MyType).MyMethod", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.symbolName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, symbolMatchesExact(tt.query, tt.symbolName))
+		})
+	}
+}
+
+func TestReadDefinitionResultShapes(t *testing.T) {
+	const source = "package main\n\n// Foo returns one\nfunc Foo() int {\n\treturn 1\n}\n"
+	const nameRange = `{"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 8}}`
+
+	tests := []struct {
+		name   string
+		result string // Definition result, %[1]s is the file URI and %[2]s the name range
+	}{
+		{"location", `{"uri": "%[1]s", "range": %[2]s}`},
+		{"location array", `[{"uri": "%[1]s", "range": %[2]s}]`},
+		{"location links", `[{"targetUri": "%[1]s", "targetRange": %[2]s, "targetSelectionRange": %[2]s}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			filePath := writeTestFile(t, "main.go", source)
+			uri := "file://" + filePath
+
+			server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": %s}}]`, uri, nameRange))
+			server.RespondRaw("textDocument/definition", fmt.Sprintf(tt.result, uri, nameRange))
+			server.RespondRaw("textDocument/documentSymbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "range": {"start": {"line": 3, "character": 0}, "end": {"line": 5, "character": 1}}, "selectionRange": %s}]`, nameRange))
+
+			result, err := ReadDefinition(t.Context(), server.Client, "Foo")
+			require.NoError(t, err)
+			assert.Contains(t, result, "Symbol: Foo")
+			assert.Contains(t, result, "Range: L4:C1 - L6:C2")
+			assert.Contains(t, result, "4|func Foo() int {\n5|\treturn 1\n6|}")
+		})
+	}
+
+	t.Run("max lines", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+		uri := "file://" + filePath
+
+		server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": %s}}]`, uri, nameRange))
+		server.RespondRaw("textDocument/definition", fmt.Sprintf(`{"uri": "%s", "range": %s}`, uri, nameRange))
+		server.RespondRaw("textDocument/documentSymbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "range": {"start": {"line": 3, "character": 0}, "end": {"line": 5, "character": 1}}, "selectionRange": %s}]`, nameRange))
+
+		result, err := ReadDefinitionWithOptions(t.Context(), server.Client, "Foo", DefinitionOptions{MaxLines: 1})
+		require.NoError(t, err)
+		assert.Contains(t, result, "Lines: 3 (truncated)")
+		assert.Contains(t, result, "4|func Foo() int {\n... (2 more lines, use read_range to see full)\n")
+		assert.NotContains(t, result, "return 1")
+
+		// Definitions that fit are returned in full
+		result, err = ReadDefinitionWithOptions(t.Context(), server.Client, "Foo", DefinitionOptions{MaxLines: 3})
+		require.NoError(t, err)
+		assert.NotContains(t, result, "truncated")
+		assert.Contains(t, result, "4|func Foo() int {\n5|\treturn 1\n6|}")
+	})
+
+	t.Run("no symbols", func(t *testing.T) {
+		server := newMockServer(t)
+		server.RespondRaw("workspace/symbol", `[]`)
+
+		result, err := ReadDefinition(t.Context(), server.Client, "Foo")
+		require.NoError(t, err)
+		assert.Equal(t, "Foo not found", result)
+	})
+}
+
+func TestReadDefinitionRanksMatches(t *testing.T) {
+	const source = "package main\n\ntype ReaderOptions struct{}\n\ntype ConfigReader struct{}\n\ntype Reader struct{}\n"
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", source)
+	uri := "file://" + filePath
+
+	// The server lists the fuzzy matches in file order
+	symbols := ""
+	for i, name := range []string{"ReaderOptions", "ConfigReader", "Reader"} {
+		if i > 0 {
+			symbols += ", "
+		}
+		line := 2 + 2*i
+		symbols += fmt.Sprintf(`{"name": "%s", "kind": 23, "location": {"uri": "%s", "range": {"start": {"line": %d, "character": 5}, "end": {"line": %d, "character": %d}}}}`,
+			name, uri, line, line, 5+len(name))
+	}
+	server.RespondRaw("workspace/symbol", "["+symbols+"]")
+	server.Handle("textDocument/definition", func(params json.RawMessage) (any, error) {
+		var p protocol.DefinitionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return protocol.Location{URI: p.TextDocument.URI, Range: protocol.Range{Start: p.Position, End: p.Position}}, nil
+	})
+	server.RespondRaw("textDocument/documentSymbol", `[
+		{"name": "ReaderOptions", "kind": 23, "range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 27}}, "selectionRange": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 18}}},
+		{"name": "ConfigReader", "kind": 23, "range": {"start": {"line": 4, "character": 0}, "end": {"line": 4, "character": 26}}, "selectionRange": {"start": {"line": 4, "character": 5}, "end": {"line": 4, "character": 17}}},
+		{"name": "Reader", "kind": 23, "range": {"start": {"line": 6, "character": 0}, "end": {"line": 6, "character": 20}}, "selectionRange": {"start": {"line": 6, "character": 5}, "end": {"line": 6, "character": 11}}}
+	]`)
+
+	result, err := ReadDefinition(t.Context(), server.Client, "Reader")
+	require.NoError(t, err)
+
+	// The exact match comes first, then the prefix and substring matches
+	exact := strings.Index(result, "Symbol: Reader\n")
+	prefix := strings.Index(result, "Symbol: ReaderOptions\n")
+	substring := strings.Index(result, "Symbol: ConfigReader\n")
+	require.True(t, exact >= 0 && prefix >= 0 && substring >= 0, result)
+	assert.Less(t, exact, prefix)
+	assert.Less(t, prefix, substring)
+}
+
+func TestAliasTargetPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		position protocol.Position
+		expected *protocol.Position
+	}{
+		{name: "go type alias", line: "type Foo = pkg.Bar", position: protocol.Position{Character: 5}, expected: &protocol.Position{Character: 15}},
+		{name: "declaration start", line: "type Foo = pkg.Bar", position: protocol.Position{Character: 0}, expected: &protocol.Position{Character: 15}},
+		{name: "generic typescript alias", line: "export type Foo<T> = Bar<T>;", position: protocol.Position{Character: 12}, expected: &protocol.Position{Character: 21}},
+		{name: "rust alias", line: "pub type Foo = crate::bar::Bar;", position: protocol.Position{Character: 9}, expected: &protocol.Position{Character: 27}},
+		{name: "alias in type group", line: "\tFoo = Bar", position: protocol.Position{Character: 1}, expected: &protocol.Position{Character: 7}},
+		{name: "type definition", line: "type Foo struct{}", position: protocol.Position{Character: 5}},
+		{name: "call", line: "Foo = make_foo()", position: protocol.Position{Character: 0}},
+		{name: "position after the name", line: "type Foo = Bar", position: protocol.Position{Character: 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "main.go")
+			require.NoError(t, os.WriteFile(filePath, []byte(tt.line+"\n"), 0644))
+
+			position, ok := aliasTargetPosition(filePath, tt.position)
+			if tt.expected == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, *tt.expected, position)
+		})
+	}
+}
+
+func TestReadDefinitionFollowAliases(t *testing.T) {
+	// Every file declares its symbol on line 3, so that one documentSymbol response fits all
+	const declarationRange = `{"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 30}}`
+
+	setup := func(t *testing.T, sources map[string]string, definitions map[string]string) *lsptesting.MockServer {
+		server := newMockServer(t)
+		paths := make(map[string]string)
+		for name, source := range sources {
+			paths[name] = writeTestFile(t, name, source)
+		}
+		server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 5, "location": {"uri": "file://%s", "range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}}}]`, paths["a.go"]))
+		server.RespondRaw("textDocument/documentSymbol", fmt.Sprintf(`[{"name": "Foo", "kind": 5, "range": %s, "selectionRange": %s}]`, declarationRange, declarationRange))
+		// definitions maps "file:character" on line 3 to "file:character" of the definition
+		server.Handle("textDocument/definition", func(params json.RawMessage) (any, error) {
+			var p protocol.DefinitionParams
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			from := fmt.Sprintf("%s:%d", filepath.Base(p.TextDocument.URI.Path()), p.Position.Character)
+			target, ok := definitions[from]
+			if !ok {
+				return nil, nil
+			}
+			name, character, _ := strings.Cut(target, ":")
+			var c uint32
+			fmt.Sscan(character, &c)
+			position := protocol.Position{Line: 2, Character: c}
+			return protocol.Location{URI: protocol.DocumentUri("file://" + paths[name]), Range: protocol.Range{Start: position, End: position}}, nil
+		})
+		return server
+	}
+
+	t.Run("go type alias", func(t *testing.T) {
+		server := setup(t, map[string]string{
+			"a.go": "package main\n\ntype Foo = pkg.Bar\n",
+			"b.go": "package pkg\n\ntype Bar struct{ X int }\n",
+		}, map[string]string{
+			"a.go:5":  "a.go:5",
+			"a.go:15": "b.go:5",
+			"b.go:5":  "b.go:5",
+		})
+
+		result, err := ReadDefinitionWithOptions(t.Context(), server.Client, "Foo", DefinitionOptions{FollowAliases: true})
+		require.NoError(t, err)
+		assert.Contains(t, result, "/a.go:L3:C6 -> ")
+		assert.Contains(t, result, "/b.go:L3:C6\n")
+		assert.Contains(t, result, "3|type Bar struct{ X int }")
+
+		// Without the option the alias itself is returned
+		result, err = ReadDefinition(t.Context(), server.Client, "Foo")
+		require.NoError(t, err)
+		assert.NotContains(t, result, "Alias chain")
+		assert.Contains(t, result, "3|type Foo = pkg.Bar")
+	})
+
+	t.Run("re-export cycle", func(t *testing.T) {
+		server := setup(t, map[string]string{
+			"a.go": "export {\n\t// re-exported\n\tFoo } from './b'\n",
+			"b.go": "export {\n\t// re-exported\n\tFoo } from './a'\n",
+		}, map[string]string{
+			"a.go:5": "a.go:1",
+			"a.go:1": "b.go:1",
+			"b.go:1": "a.go:1",
+		})
+
+		result, err := ReadDefinitionWithOptions(t.Context(), server.Client, "Foo", DefinitionOptions{FollowAliases: true})
+		require.NoError(t, err)
+		assert.Contains(t, result, "/a.go:L3:C2 -> ")
+		assert.Contains(t, result, "/b.go:L3:C2\n")
+		assert.Len(t, server.Received("textDocument/definition"), 3)
+	})
+}
+
+func TestReadDefinitionEscapedURI(t *testing.T) {
+	server := newMockServer(t)
+	dir := filepath.Join(t.TempDir(), "my project")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	filePath := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("package main\n\n// Foo returns one\nfunc Foo() int {\n\treturn 1\n}\n"), 0644))
+
+	// Servers escape the space in the URIs they return
+	uri := "file://" + strings.ReplaceAll(filePath, " ", "%20")
+	const nameRange = `{"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 8}}`
+	server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": %s}}]`, uri, nameRange))
+	server.RespondRaw("textDocument/definition", fmt.Sprintf(`{"uri": "%s", "range": %s}`, uri, nameRange))
+	server.RespondRaw("textDocument/documentSymbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "range": {"start": {"line": 3, "character": 0}, "end": {"line": 5, "character": 1}}, "selectionRange": %s}]`, nameRange))
+
+	result, err := ReadDefinitionWithOptions(t.Context(), server.Client, "Foo", DefinitionOptions{ContextLines: 1})
+	require.NoError(t, err)
+	assert.Contains(t, result, "File: "+filePath+"\n")
+	assert.Contains(t, result, "3|// Foo returns one\n4|func Foo() int {")
+}
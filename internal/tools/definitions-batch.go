@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// DefaultDefinitionConcurrency is the number of definition lookups definitions_batch runs at once
+const DefaultDefinitionConcurrency = 4
+
+// ReadDefinitions looks up the definitions of several symbols concurrently, using at most
+// concurrency workers, and returns the results in input order, keyed by symbol name.
+// A failed lookup is reported in its section and does not fail the whole batch.
+func ReadDefinitions(ctx context.Context, client *lsp.Client, symbolNames []string, opts DefinitionOptions, concurrency int) (string, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultDefinitionConcurrency
+	}
+
+	// Drop duplicate names, keeping the first occurrence
+	seen := make(map[string]bool, len(symbolNames))
+	var names []string
+	for _, name := range symbolNames {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no symbol names provided")
+	}
+
+	results := make([]string, len(names))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				text, err := ReadDefinitionWithOptions(ctx, client, names[i], opts)
+				if err != nil {
+					toolsLogger.Error("Failed to get definition of %s: %v", names[i], err)
+					text = fmt.Sprintf("Error: %v\n", err)
+				}
+				results[i] = text
+			}
+		}()
+	}
+
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result strings.Builder
+	for i, name := range names {
+		result.WriteString(fmt.Sprintf("=== %s ===\n\n", name))
+		result.WriteString(strings.TrimRight(results[i], "\n"))
+		result.WriteString("\n\n")
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDefinitions(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc Alpha() {}\n\nfunc Beta() {}\n")
+	uri := protocol.DocumentUri("file://" + filePath)
+	declarations := map[string]protocol.Range{
+		"Alpha": {Start: protocol.Position{Line: 2, Character: 0}, End: protocol.Position{Line: 2, Character: 15}},
+		"Beta":  {Start: protocol.Position{Line: 4, Character: 0}, End: protocol.Position{Line: 4, Character: 14}},
+	}
+	server.Handle("workspace/symbol", func(params json.RawMessage) (any, error) {
+		var p protocol.WorkspaceSymbolParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Query == "Broken" {
+			return nil, errors.New("index not ready")
+		}
+		rng, ok := declarations[p.Query]
+		if !ok {
+			return []protocol.SymbolInformation{}, nil
+		}
+		return []protocol.SymbolInformation{{Name: p.Query, Kind: protocol.Function, Location: protocol.Location{URI: uri, Range: rng}}}, nil
+	})
+	server.Handle("textDocument/definition", func(params json.RawMessage) (any, error) {
+		var p protocol.DefinitionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return protocol.Location{URI: p.TextDocument.URI, Range: protocol.Range{Start: p.Position, End: p.Position}}, nil
+	})
+	server.RespondRaw("textDocument/documentSymbol", `[
+		{"name": "Alpha", "kind": 12, "range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 15}}, "selectionRange": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 10}}},
+		{"name": "Beta", "kind": 12, "range": {"start": {"line": 4, "character": 0}, "end": {"line": 4, "character": 14}}, "selectionRange": {"start": {"line": 4, "character": 5}, "end": {"line": 4, "character": 9}}}
+	]`)
+
+	result, err := ReadDefinitions(t.Context(), server.Client, []string{"Beta", "Broken", "Alpha", "Beta", "", "Missing"}, DefinitionOptions{}, 4)
+	require.NoError(t, err)
+
+	// Results keep the order of the names, each name once
+	headers := regexp.MustCompile(`(?m)^=== (.*) ===$`).FindAllStringSubmatch(result, -1)
+	var names []string
+	for _, header := range headers {
+		names = append(names, header[1])
+	}
+	assert.Equal(t, []string{"Beta", "Broken", "Alpha", "Missing"}, names)
+	assert.Len(t, server.Received("workspace/symbol"), 4)
+
+	assert.Contains(t, result, "=== Beta ===\n\n---\n\nSymbol: Beta\n")
+	assert.Contains(t, result, "5|func Beta() {}\n")
+	assert.Contains(t, result, "3|func Alpha() {}\n")
+	// A failing name doesn't fail the others
+	assert.Contains(t, result, "=== Broken ===\n\nError: failed to fetch symbol: request failed: index not ready")
+	assert.Contains(t, result, "=== Missing ===\n\nMissing not found\n")
+}
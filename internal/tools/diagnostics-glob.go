@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/watcher"
+)
+
+// DefaultMaxGlobFiles is the maximum number of files diagnostics_glob opens by default
+const DefaultMaxGlobFiles = 50
+
+// GetDiagnosticsForGlob opens every file in the workspace matching pattern (e.g. "internal/**/*.go"),
+// waits for the server to publish diagnostics for them, and returns an aggregated report.
+// At most maxFiles files are checked; a warning is included if the pattern matched more.
+func GetDiagnosticsForGlob(ctx context.Context, client *lsp.Client, workspaceDir, pattern string, maxFiles, contextLines int, showLineNumbers bool) (string, error) {
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxGlobFiles
+	}
+
+	matches, err := findGlobMatches(workspaceDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return fmt.Sprintf("No files match %s", pattern), nil
+	}
+
+	var result strings.Builder
+	if len(matches) > maxFiles {
+		result.WriteString(fmt.Sprintf("Warning: %s matched %d files, only the first %d were checked. Use a narrower pattern or raise maxFiles.\n\n",
+			pattern, len(matches), maxFiles))
+		matches = matches[:maxFiles]
+	}
+
+	// Open every file before waiting so the server can analyze them concurrently
+	publishCounts := make(map[string]int, len(matches))
+	wasOpen := make(map[string]bool, len(matches))
+	var opened []string
+	for _, filePath := range matches {
+		uri := protocol.DocumentUri("file://" + filePath)
+		publishCounts[filePath] = client.DiagnosticsPublishCount(uri)
+		wasOpen[filePath] = client.IsFileOpen(filePath)
+		if err := client.OpenFile(ctx, filePath); err != nil {
+			toolsLogger.Warn("Could not open %s: %v", filePath, err)
+			continue
+		}
+		opened = append(opened, filePath)
+	}
+
+	deadline := time.Now().Add(diagnosticsWaitTimeout())
+	for _, filePath := range opened {
+		uri := protocol.DocumentUri("file://" + filePath)
+		if client.DiagnosticPull() {
+			_, err := PullDiagnostics(ctx, client, filePath)
+			if err == nil {
+				continue
+			}
+			toolsLogger.Warn("Falling back to published diagnostics for %s: %v", filePath, err)
+		}
+		// Same rule as GetDiagnosticsForFile: already open files with diagnostics are up to date
+		if wasOpen[filePath] && publishCounts[filePath] > 0 {
+			continue
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 || !client.WaitForDiagnostics(ctx, uri, publishCounts[filePath], remaining) {
+			toolsLogger.Debug("No diagnostics published for %s in time", filePath)
+		}
+	}
+
+	var reports []string
+	for _, filePath := range opened {
+		if len(client.GetFileDiagnostics(protocol.DocumentUri("file://"+filePath))) == 0 {
+			continue
+		}
+		reports = append(reports, formatFileDiagnostics(ctx, client, filePath, contextLines, showLineNumbers))
+	}
+
+	result.WriteString(fmt.Sprintf("Checked %d files matching %s: %d with diagnostics\n", len(opened), pattern, len(reports)))
+	for _, report := range reports {
+		result.WriteString("\n---\n\n")
+		result.WriteString(report)
+	}
+
+	return result.String(), nil
+}
+
+// findGlobMatches returns the sorted absolute paths of files under workspaceDir matching pattern.
+// Relative patterns are matched against paths relative to workspaceDir.
+func findGlobMatches(workspaceDir, pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	if filepath.IsAbs(pattern) {
+		rel, err := filepath.Rel(workspaceDir, pattern)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("pattern %s is outside the workspace", pattern)
+		}
+		pattern = filepath.ToSlash(rel)
+	}
+	patterns := expandBraces(strings.TrimPrefix(pattern, "./"))
+	for _, p := range patterns {
+		if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %v", pattern, err)
+		}
+	}
+
+	excludedDirs := watcher.DefaultWatcherConfig().ExcludedDirs
+
+	var matches []string
+	err := filepath.WalkDir(workspaceDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if d.IsDir() {
+			if filePath != workspaceDir && excludedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(workspaceDir, filePath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range patterns {
+			if matchGlob(p, rel) {
+				matches = append(matches, filePath)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk workspace: %v", err)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matchGlob reports whether a slash-separated relative path matches pattern.
+// "**" matches any number of path segments, other segments use path.Match syntax.
+func matchGlob(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		// Match zero or more segments
+		for i := 0; i <= len(name); i++ {
+			if matchGlobSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+	matched, err := path.Match(pattern[0], name[0])
+	if err != nil || !matched {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], name[1:])
+}
+
+// expandBraces expands alternatives like "*.{go,mod}" into "*.go" and "*.mod"
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start == -1 {
+		return []string{pattern}
+	}
+	end := strings.Index(pattern[start:], "}")
+	if end == -1 {
+		return []string{pattern}
+	}
+	end += start
+
+	var expanded []string
+	for _, alternative := range strings.Split(pattern[start+1:end], ",") {
+		expanded = append(expanded, expandBraces(pattern[:start]+alternative+pattern[end+1:])...)
+	}
+	return expanded
+}
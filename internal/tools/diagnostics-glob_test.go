@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "internal/tools/main.go", true},
+		{"internal/**/*.go", "internal/main.go", true},
+		{"internal/**/*.go", "internal/tools/hover.go", true},
+		{"internal/**/*.go", "cmd/main.go", false},
+		{"internal/**", "internal/tools/hover.go", true},
+		{"internal/*/hover.go", "internal/tools/hover.go", true},
+		{"internal/*/hover.go", "internal/a/b/hover.go", false},
+		{"src/?.ts", "src/a.ts", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	assert.Equal(t, []string{"*.go"}, expandBraces("*.go"))
+	assert.Equal(t, []string{"*.go", "*.mod"}, expandBraces("*.{go,mod}"))
+	assert.Equal(t, []string{"a/x.c", "a/x.h", "b/x.c", "b/x.h"}, expandBraces("{a,b}/x.{c,h}"))
+}
+
+func TestFindGlobMatches(t *testing.T) {
+	workspace := t.TempDir()
+	for _, file := range []string{
+		"main.go",
+		"internal/tools/hover.go",
+		"internal/tools/hover_test.go",
+		"internal/README.md",
+		"node_modules/dep/index.go",
+	} {
+		filePath := filepath.Join(workspace, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(""), 0644))
+	}
+
+	matches, err := findGlobMatches(workspace, "internal/**/*.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(workspace, "internal/tools/hover.go"),
+		filepath.Join(workspace, "internal/tools/hover_test.go"),
+	}, matches)
+
+	// Excluded directories are skipped
+	matches, err = findGlobMatches(workspace, "**/*.go")
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+
+	// Absolute patterns inside the workspace are allowed
+	matches, err = findGlobMatches(workspace, filepath.Join(workspace, "*.go"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(workspace, "main.go")}, matches)
+
+	_, err = findGlobMatches(workspace, "/elsewhere/*.go")
+	assert.Error(t, err)
+
+	_, err = findGlobMatches(workspace, "[.go")
+	assert.Error(t, err)
+}
@@ -12,78 +12,156 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// DefaultDiagnosticsTimeout is how long GetDiagnosticsForFile waits for the server to
+// publish diagnostics for a file it has just opened
+const DefaultDiagnosticsTimeout = 2 * time.Second
+
+// DiagnosticsOptions controls how GetDiagnosticsForFileWithOptions formats its output
+type DiagnosticsOptions struct {
+	// ContextLines is the number of lines shown around each diagnostic, overridden by the
+	// LSP_CONTEXT_LINES environment variable
+	ContextLines int
+	// ShowLineNumbers includes the source lines of the diagnostics in the text format
+	ShowLineNumbers bool
+	// Format is OutputFormatText (the default if empty) or OutputFormatMarkdown, which
+	// lists the diagnostics in a table and always includes the source lines
+	Format string
+}
+
 // GetDiagnosticsForFile retrieves diagnostics for a specific file from the language server
 func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, contextLines int, showLineNumbers bool) (string, error) {
+	return GetDiagnosticsForFileWithOptions(ctx, client, filePath, DiagnosticsOptions{
+		ContextLines:    contextLines,
+		ShowLineNumbers: showLineNumbers,
+	})
+}
+
+func GetDiagnosticsForFileWithOptions(ctx context.Context, client *lsp.Client, filePath string, opts DiagnosticsOptions) (string, error) {
+	if err := ValidateOutputFormat(opts.Format); err != nil {
+		return "", err
+	}
+
 	// Override with environment variable if specified
+	contextLines := opts.ContextLines
 	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
 		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
 			contextLines = val
 		}
 	}
 
-	err := client.OpenFile(ctx, filePath)
-	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+	if err := waitForFileDiagnostics(ctx, client, filePath); err != nil {
+		return "", err
+	}
+
+	diagnostics := collectFileDiagnostics(ctx, client, filePath, contextLines)
+	if opts.Format == OutputFormatMarkdown {
+		return diagnostics.markdown(), nil
+	}
+	return diagnostics.text(opts.ShowLineNumbers), nil
+}
+
+// waitForFileDiagnostics opens filePath if needed and waits, bounded by diagnosticsWaitTimeout,
+// until the server has published diagnostics for its current content. Servers that support
+// textDocument/diagnostic are asked directly, falling back to published diagnostics on error.
+func waitForFileDiagnostics(ctx context.Context, client *lsp.Client, filePath string) error {
+	if client.DiagnosticPull() {
+		_, err := PullDiagnostics(ctx, client, filePath)
+		if err == nil {
+			return nil
+		}
+		toolsLogger.Warn("Falling back to published diagnostics for %s: %v", filePath, err)
 	}
 
-	// Wait for diagnostics
-	// TODO: wait for notification
-	time.Sleep(time.Second * 3)
+	waitTimeout := diagnosticsWaitTimeout()
 
 	// Convert the file path to URI format
 	uri := protocol.DocumentUri("file://" + filePath)
 
-	// Request fresh diagnostics
-	diagParams := protocol.DocumentDiagnosticParams{
-		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
-	}
-	_, err = client.Diagnostic(ctx, diagParams)
+	// Note how many publishes we've seen before opening, so that we only
+	// wait for diagnostics that arrive in response to this request
+	publishCount := client.DiagnosticsPublishCount(uri)
+	wasOpen := client.IsFileOpen(filePath)
+
+	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		toolsLogger.Error("Failed to get diagnostics: %v", err)
+		return fmt.Errorf("could not open file: %v", err)
 	}
 
-	// Get diagnostics from the cache
-	diagnostics := client.GetFileDiagnostics(uri)
+	// publishDiagnostics is asynchronous and usually arrives some time after didOpen.
+	// Files that were already open and have published diagnostics are up to date.
+	if !wasOpen || publishCount == 0 {
+		if !client.WaitForDiagnostics(ctx, uri, publishCount, waitTimeout) {
+			toolsLogger.Debug("No diagnostics published for %s within %s", filePath, waitTimeout)
+		}
+	}
 
-	if len(diagnostics) == 0 {
-		return "No diagnostics found for " + filePath, nil
+	// If the file has been edited since the last publish, give the server a chance to catch up
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		count := client.DiagnosticsPublishCount(uri)
+		if upToDate, _, _ := client.DiagnosticsUpToDate(uri); upToDate {
+			break
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 || !client.WaitForDiagnostics(ctx, uri, count, remaining) {
+			break
+		}
 	}
 
-	// Format file header
-	fileInfo := fmt.Sprintf("%s\nDiagnostics in File: %d\n",
-		filePath,
-		len(diagnostics),
-	)
+	return nil
+}
 
-	// Create a summary of all the diagnostics
-	var diagSummaries []string
-	var diagLocations []protocol.Location
+// diagnosticsWaitTimeout returns how long to wait for published diagnostics,
+// overridden by the LSP_DIAGNOSTICS_TIMEOUT environment variable
+func diagnosticsWaitTimeout() time.Duration {
+	if envTimeout := os.Getenv("LSP_DIAGNOSTICS_TIMEOUT"); envTimeout != "" {
+		if val, err := time.ParseDuration(envTimeout); err == nil && val >= 0 {
+			return val
+		}
+	}
+	return DefaultDiagnosticsTimeout
+}
 
-	for _, diag := range diagnostics {
-		severity := getSeverityString(diag.Severity)
-		location := fmt.Sprintf("L%d:C%d",
-			diag.Range.Start.Line+1,
-			diag.Range.Start.Character+1)
+// formatFileDiagnostics formats the cached diagnostics for an open file
+func formatFileDiagnostics(ctx context.Context, client *lsp.Client, filePath string, contextLines int, showLineNumbers bool) string {
+	return collectFileDiagnostics(ctx, client, filePath, contextLines).text(showLineNumbers)
+}
 
-		summary := fmt.Sprintf("%s at %s: %s",
-			severity,
-			location,
-			diag.Message)
+// fileDiagnostics are the cached diagnostics of a file with the source lines to show
+// around them, as rendered by text and markdown
+type fileDiagnostics struct {
+	filePath    string
+	staleNote   string
+	diagnostics []protocol.Diagnostic
+	lines       []string
+	lineRanges  []LineRange
+	readErr     error // Set if the source could not be read
+}
 
-		// Add source and code if available
-		if diag.Source != "" {
-			summary += fmt.Sprintf(" (Source: %s", diag.Source)
-			if diag.Code != nil {
-				summary += fmt.Sprintf(", Code: %v", diag.Code)
-			}
-			summary += ")"
-		} else if diag.Code != nil {
-			summary += fmt.Sprintf(" (Code: %v)", diag.Code)
+// collectFileDiagnostics gets the cached diagnostics for an open file and the lines within
+// contextLines of them
+func collectFileDiagnostics(ctx context.Context, client *lsp.Client, filePath string, contextLines int) fileDiagnostics {
+	uri := protocol.DocumentUri("file://" + filePath)
+	f := fileDiagnostics{filePath: filePath}
+
+	if upToDate, documentVersion, diagnosticsVersion := client.DiagnosticsUpToDate(uri); !upToDate {
+		if diagnosticsVersion != 0 {
+			f.staleNote = fmt.Sprintf("Note: diagnostics may be stale - they were published for version %d of the file, which is now at version %d\n",
+				diagnosticsVersion, documentVersion)
+		} else {
+			f.staleNote = "Note: diagnostics may be stale - the file has changed since they were published\n"
 		}
+	}
 
-		diagSummaries = append(diagSummaries, summary)
+	// Get diagnostics from the cache
+	f.diagnostics = client.GetFileDiagnostics(uri)
+	if len(f.diagnostics) == 0 {
+		return f
+	}
 
-		// Create a location for this diagnostic to use with line ranges
+	// Create a location for each diagnostic to use with line ranges
+	var diagLocations []protocol.Location
+	for _, diag := range f.diagnostics {
 		diagLocations = append(diagLocations, protocol.Location{
 			URI:   uri,
 			Range: diag.Range,
@@ -93,33 +171,80 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 	// Format content with context
 	fileContent, err := os.ReadFile(filePath)
 	if err != nil {
-		return fileInfo + "\nError reading file: " + err.Error(), nil
+		f.readErr = err
+		return f
 	}
 
-	lines := strings.Split(string(fileContent), "\n")
+	f.lines = strings.Split(string(fileContent), "\n")
 
 	// Collect lines to display
 	var linesToShow map[int]bool
 	if contextLines > 0 {
 		// Use GetLineRangesToDisplay for context
-		linesToShow, err = GetLineRangesToDisplay(ctx, client, diagLocations, len(lines), contextLines)
+		linesToShow, err = GetLineRangesToDisplay(ctx, client, diagLocations, len(f.lines), contextLines)
 		if err != nil {
 			// If error, just show the diagnostic lines
 			linesToShow = make(map[int]bool)
-			for _, diag := range diagnostics {
+			for _, diag := range f.diagnostics {
 				linesToShow[int(diag.Range.Start.Line)] = true
 			}
 		}
 	} else {
 		// Just show the diagnostic lines
 		linesToShow = make(map[int]bool)
-		for _, diag := range diagnostics {
+		for _, diag := range f.diagnostics {
 			linesToShow[int(diag.Range.Start.Line)] = true
 		}
 	}
 
 	// Convert to line ranges
-	lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+	f.lineRanges = ConvertLinesToRanges(linesToShow, len(f.lines))
+	return f
+}
+
+// text renders the diagnostics as plain text, with the source lines if showLineNumbers
+func (f fileDiagnostics) text(showLineNumbers bool) string {
+	if len(f.diagnostics) == 0 {
+		return f.staleNote + "No diagnostics found for " + f.filePath
+	}
+
+	// Format file header
+	fileInfo := fmt.Sprintf("%s\nDiagnostics in File: %d\n%s",
+		f.filePath,
+		len(f.diagnostics),
+		f.staleNote,
+	)
+	if f.readErr != nil {
+		return fileInfo + "\nError reading file: " + f.readErr.Error()
+	}
+
+	// Create a summary of all the diagnostics
+	var diagSummaries []string
+	for _, diag := range f.diagnostics {
+		severity := getSeverityString(diag.Severity)
+		location := fmt.Sprintf("L%d:C%d",
+			diag.Range.Start.Line+1,
+			diag.Range.Start.Character+1)
+
+		summary := fmt.Sprintf("%s at %s: %s",
+			severity,
+			location,
+			diag.Message)
+
+		// Add source and code if available
+		if diag.Source != "" {
+			summary += fmt.Sprintf(" (Source: %s", diag.Source)
+			if diag.Code != nil {
+				summary += fmt.Sprintf(", Code: %v", diag.Code)
+			}
+			summary += ")"
+		} else if diag.Code != nil {
+			summary += fmt.Sprintf(" (Code: %v)", diag.Code)
+		}
+		summary += formatDiagnosticTags(diag.Tags)
+
+		diagSummaries = append(diagSummaries, summary)
+	}
 
 	// Format with diagnostics summary in header
 	result := fileInfo
@@ -129,10 +254,62 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 
 	// Format the content with ranges
 	if showLineNumbers {
-		result += "\n" + FormatLinesWithRanges(lines, lineRanges)
+		result += "\n" + FormatLinesWithRanges(f.lines, f.lineRanges)
 	}
 
-	return result, nil
+	return result
+}
+
+// markdown renders the diagnostics as a table followed by the source lines in a code block
+func (f fileDiagnostics) markdown() string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("### Diagnostics of %s\n\n", markdownCode(f.filePath)))
+	if f.staleNote != "" {
+		result.WriteString("> " + f.staleNote + "\n")
+	}
+	if len(f.diagnostics) == 0 {
+		result.WriteString("No diagnostics found.\n")
+		return result.String()
+	}
+
+	result.WriteString(markdownTableRow("Severity", "Location", "Message", "Source", "Code"))
+	result.WriteString(markdownTableRow("---", "---", "---", "---", "---"))
+	for _, diag := range f.diagnostics {
+		code := ""
+		if diag.Code != nil {
+			code = fmt.Sprintf("%v", diag.Code)
+		}
+		result.WriteString(markdownTableRow(
+			getSeverityString(diag.Severity),
+			fmt.Sprintf("L%d:C%d", diag.Range.Start.Line+1, diag.Range.Start.Character+1),
+			diag.Message+formatDiagnosticTags(diag.Tags),
+			diag.Source,
+			code,
+		))
+	}
+
+	if f.readErr != nil {
+		result.WriteString("\nError reading file: " + f.readErr.Error() + "\n")
+		return result.String()
+	}
+	result.WriteString("\n")
+	result.WriteString(codeFence(FormatLinesWithRanges(f.lines, f.lineRanges), f.filePath))
+	return result.String()
+}
+
+// formatDiagnosticTags renders diagnostic tags as a suffix like " [unnecessary]", so that
+// unused code and deprecated APIs stand out from other diagnostics
+func formatDiagnosticTags(tags []protocol.DiagnosticTag) string {
+	var suffix string
+	for _, tag := range tags {
+		switch tag {
+		case protocol.Unnecessary:
+			suffix += " [unnecessary]"
+		case protocol.Deprecated:
+			suffix += " [deprecated]"
+		}
+	}
+	return suffix
 }
 
 func getSeverityString(severity protocol.DiagnosticSeverity) string {
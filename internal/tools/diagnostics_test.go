@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDiagnosticTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []protocol.DiagnosticTag
+		expected string
+	}{
+		{name: "no tags", tags: nil, expected: ""},
+		{name: "unnecessary", tags: []protocol.DiagnosticTag{protocol.Unnecessary}, expected: " [unnecessary]"},
+		{name: "deprecated", tags: []protocol.DiagnosticTag{protocol.Deprecated}, expected: " [deprecated]"},
+		{name: "both", tags: []protocol.DiagnosticTag{protocol.Unnecessary, protocol.Deprecated}, expected: " [unnecessary] [deprecated]"},
+		{name: "unknown tags are ignored", tags: []protocol.DiagnosticTag{7}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatDiagnosticTags(tt.tags))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetDocumentColors returns the color literals in a file with their ranges and RGBA values.
+// If includePresentations is true, the alternative textual representations offered by the
+// server (e.g. hex, rgb(), hsl()) are listed for each color.
+func GetDocumentColors(ctx context.Context, client *lsp.Client, filePath string, includePresentations bool) (string, error) {
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	docIdentifier := protocol.TextDocumentIdentifier{
+		URI: protocol.DocumentUri("file://" + filePath),
+	}
+
+	colors, err := client.DocumentColor(ctx, protocol.DocumentColorParams{
+		TextDocument: docIdentifier,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document colors: %v", err)
+	}
+
+	if len(colors) == 0 {
+		return fmt.Sprintf("No colors found in %s", filePath), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Colors in %s:\n\n", filePath))
+	for i, info := range colors {
+		result.WriteString(fmt.Sprintf("%d. L%d:C%d - L%d:C%d: %s\n", i+1,
+			info.Range.Start.Line+1, info.Range.Start.Character+1,
+			info.Range.End.Line+1, info.Range.End.Character+1,
+			formatColor(info.Color)))
+
+		if !includePresentations {
+			continue
+		}
+
+		presentations, err := client.ColorPresentation(ctx, protocol.ColorPresentationParams{
+			TextDocument: docIdentifier,
+			Color:        info.Color,
+			Range:        info.Range,
+		})
+		if err != nil {
+			toolsLogger.Warn("failed to get color presentations: %v", err)
+			continue
+		}
+		if len(presentations) > 0 {
+			labels := make([]string, 0, len(presentations))
+			for _, presentation := range presentations {
+				labels = append(labels, presentation.Label)
+			}
+			result.WriteString(fmt.Sprintf("   Presentations: %s\n", strings.Join(labels, ", ")))
+		}
+	}
+
+	return result.String(), nil
+}
+
+// formatColor formats an LSP color (components in [0-1]) as "rgba(r, g, b, a) #rrggbbaa"
+func formatColor(color protocol.Color) string {
+	toByte := func(v float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	r, g, b, a := toByte(color.Red), toByte(color.Green), toByte(color.Blue), toByte(color.Alpha)
+	return fmt.Sprintf("rgba(%d, %d, %d, %.2f) #%02x%02x%02x%02x", r, g, b, color.Alpha, r, g, b, a)
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// GetDocumentLinks returns all links in a file (import targets, URLs in comments)
+// along with their ranges and resolved targets
+func GetDocumentLinks(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	params := protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	}
+
+	links, err := client.DocumentLink(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to get document links: %v", err)
+	}
+
+	if len(links) == 0 {
+		return fmt.Sprintf("No document links found in %s", filePath), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Document links in %s:\n\n", filePath))
+	for i, link := range links {
+		// Servers may leave the target empty and fill it in lazily on resolve
+		if link.Target == nil {
+			resolved, err := client.ResolveDocumentLink(ctx, link)
+			if err != nil {
+				toolsLogger.Warn("failed to resolve document link: %v", err)
+			} else {
+				link = resolved
+			}
+		}
+
+		target := "(unresolved)"
+		if link.Target != nil {
+			target = utilities.URIToPath(protocol.DocumentUri(*link.Target))
+		}
+
+		result.WriteString(fmt.Sprintf("%d. L%d:C%d - L%d:C%d -> %s\n", i+1,
+			link.Range.Start.Line+1, link.Range.Start.Character+1,
+			link.Range.End.Line+1, link.Range.End.Character+1,
+			target))
+		if link.Tooltip != "" {
+			result.WriteString(fmt.Sprintf("   Tooltip: %s\n", link.Tooltip))
+		}
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultEnrichDetailLimit is the number of symbols GetDocumentSymbols sends a hover request
+// for at most with EnrichDetail, as every symbol costs a request
+const DefaultEnrichDetailLimit = 50
+
+// enrichSymbolDetails fills in the Detail of the hierarchical symbols of results that have
+// none with the first line of their hover, e.g. a function's signature. Only the symbols
+// formatDocumentSymbol will show are hovered, in the order they are shown. It returns the
+// number of symbols that were left without detail because of DefaultEnrichDetailLimit.
+func enrichSymbolDetails(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, results []protocol.DocumentSymbolResult, opts DocumentSymbolsOptions) int {
+	hovered, skipped := 0, 0
+	var enrich func(symbol *protocol.DocumentSymbol, level int)
+	enrich = func(symbol *protocol.DocumentSymbol, level int) {
+		if opts.MaxDepth > 0 && level > opts.MaxDepth {
+			return
+		}
+		shown := opts.matchesKind(symbol.Kind)
+		if !shown && !opts.IncludeChildren {
+			return
+		}
+		if shown && symbol.Detail == "" {
+			if hovered == DefaultEnrichDetailLimit {
+				skipped++
+			} else {
+				hovered++
+				symbol.Detail = hoverDetail(ctx, client, uri, symbol)
+			}
+		}
+		for i := range symbol.Children {
+			enrich(&symbol.Children[i], level+1)
+		}
+	}
+
+	for _, result := range results {
+		if symbol, ok := result.(*protocol.DocumentSymbol); ok {
+			enrich(symbol, 1)
+		}
+	}
+	return skipped
+}
+
+// hoverDetail returns the first line of the plain text hover at the name of symbol, or an
+// empty string if the server has none
+func hoverDetail(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, symbol *protocol.DocumentSymbol) string {
+	hover, err := client.Hover(ctx, protocol.HoverParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position:     symbol.SelectionRange.Start,
+		},
+	})
+	if err != nil {
+		toolsLogger.Debug("No hover for symbol %s: %v", symbol.Name, err)
+		return ""
+	}
+	detail, _, _ := strings.Cut(hoverToPlainText(hover.Contents), "\n")
+	return strings.TrimSpace(detail)
+}
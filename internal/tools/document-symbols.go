@@ -3,14 +3,78 @@ package tools
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-// GetDocumentSymbols returns the hierarchical symbol outline of a file
-func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+// Detail levels for document symbols
+const (
+	// SymbolDetailOutline lists symbol names, kinds and ranges only
+	SymbolDetailOutline = "outline"
+	// SymbolDetailSignatures adds the source line declaring each symbol, e.g. a function signature
+	SymbolDetailSignatures = "signatures"
+	// SymbolDetailFull adds the full source of each symbol that has no child symbols
+	SymbolDetailFull = "full"
+)
+
+// DocumentSymbolsOptions controls how GetDocumentSymbols formats its output
+type DocumentSymbolsOptions struct {
+	// Detail is one of SymbolDetailOutline (the default if empty), SymbolDetailSignatures or SymbolDetailFull
+	Detail string
+	// Kinds restricts the output to symbols of these kinds. Empty shows all kinds.
+	Kinds []protocol.SymbolKind
+	// MaxDepth hides symbols nested deeper than this, where 1 is top-level symbols only. 0 shows all.
+	MaxDepth int
+	// IncludeChildren shows symbols matching Kinds even if their parent does not match,
+	// e.g. methods of a class when filtering by "method". Otherwise a parent that does not
+	// match is hidden along with its children.
+	IncludeChildren bool
+	// Collapse zooms out on large files: of the symbols at each level, only the Collapse
+	// symbols with the most nested symbols are shown, plus those matching NameFilter. The
+	// hidden symbols are counted in their place. 0 shows all symbols.
+	Collapse int
+	// NameFilter shows symbols whose name contains this, case-insensitively, along with the
+	// symbols they are nested in, and hides the rest as in Collapse
+	NameFilter string
+	// Format is OutputFormatText (the default if empty) or OutputFormatMarkdown, which puts
+	// the tree in a code block
+	Format string
+	// EnrichDetail fills in the detail of symbols the server reports none for with the first
+	// line of their hover, for at most DefaultEnrichDetailLimit symbols
+	EnrichDetail bool
+
+	// hidden counts the symbols Collapse and NameFilter hid below each symbol shown
+	hidden map[symbolKey]int
+}
+
+// symbolKey identifies a DocumentSymbol across copies of the symbol tree
+type symbolKey struct {
+	name string
+	rng  protocol.Range
+}
+
+// matchesKind reports whether a symbol of the given kind passes the Kinds filter
+func (o DocumentSymbolsOptions) matchesKind(kind protocol.SymbolKind) bool {
+	return len(o.Kinds) == 0 || slices.Contains(o.Kinds, kind)
+}
+
+// GetDocumentSymbols returns the hierarchical symbol outline of a file, with lines longer
+// than MaxLineWidth wrapped in the text format
+func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string, opts DocumentSymbolsOptions) (string, error) {
+	if opts.Detail == "" {
+		opts.Detail = SymbolDetailOutline
+	}
+	if opts.Detail != SymbolDetailOutline && opts.Detail != SymbolDetailSignatures && opts.Detail != SymbolDetailFull {
+		return "", fmt.Errorf("detail must be '%s', '%s' or '%s', got: %s", SymbolDetailOutline, SymbolDetailSignatures, SymbolDetailFull, opts.Detail)
+	}
+	if err := ValidateOutputFormat(opts.Format); err != nil {
+		return "", err
+	}
+
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
@@ -38,26 +102,80 @@ func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string
 		return "No symbols found", nil
 	}
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Document Symbols for %s:\n\n", filePath))
+	// Source lines are only needed to show signatures or bodies
+	var source *symbolSource
+	if opts.Detail != SymbolDetailOutline {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
+		source = &symbolSource{
+			lines: strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"),
+			full:  opts.Detail == SymbolDetailFull,
+		}
+	}
+
+	var symbols strings.Builder
+
+	// Collapsing only applies to hierarchical symbols, flat ones have no nesting to zoom out of
+	hiddenTopLevel := 0
+	if opts.Collapse > 0 || opts.NameFilter != "" {
+		results, hiddenTopLevel = collapseSymbolResults(results, &opts)
+	}
+
+	notEnriched := 0
+	if opts.EnrichDetail {
+		notEnriched = enrichSymbolDetails(ctx, client, params.TextDocument.URI, results, opts)
+	}
 
 	// Process results - could be DocumentSymbol[] (hierarchical) or SymbolInformation[] (flat)
 	for _, symbol := range results {
 		switch v := symbol.(type) {
 		case *protocol.DocumentSymbol:
 			// Hierarchical symbols with children
-			formatDocumentSymbol(&output, v, 0)
+			formatDocumentSymbol(&symbols, v, 0, 1, source, opts)
 		case *protocol.SymbolInformation:
 			// Flat symbol information
-			formatSymbolInformation(&output, v)
+			formatSymbolInformation(&symbols, v, source, results, opts)
 		}
 	}
+	if hiddenTopLevel > 0 {
+		symbols.WriteString(fmt.Sprintf("… %d symbols hidden\n", hiddenTopLevel))
+	}
+
+	if symbols.Len() == 0 {
+		return "No symbols match the given kinds and depth", nil
+	}
 
-	return output.String(), nil
+	var note string
+	if notEnriched > 0 {
+		note = fmt.Sprintf("\n%d more symbols have no detail, hovers are requested for at most %d.\n", notEnriched, DefaultEnrichDetailLimit)
+	}
+
+	if opts.Format == OutputFormatMarkdown {
+		// The tree only lines up in monospace
+		return fmt.Sprintf("### Document symbols of %s\n\n", markdownCode(filePath)) + codeFence(symbols.String(), "") + note, nil
+	}
+	return wrapLines(fmt.Sprintf("Document Symbols for %s:\n\n", filePath)+symbols.String()+note, MaxLineWidth()), nil
 }
 
-// formatDocumentSymbol formats a hierarchical DocumentSymbol with indentation
-func formatDocumentSymbol(output *strings.Builder, symbol *protocol.DocumentSymbol, depth int) {
+// formatDocumentSymbol formats a hierarchical DocumentSymbol with indentation, pruning symbols
+// filtered out by opts. level is the 1-indexed nesting level of the symbol in the file, which
+// differs from the rendered depth when filtered parents are skipped.
+func formatDocumentSymbol(output *strings.Builder, symbol *protocol.DocumentSymbol, depth, level int, source *symbolSource, opts DocumentSymbolsOptions) {
+	if opts.MaxDepth > 0 && level > opts.MaxDepth {
+		return
+	}
+	if !opts.matchesKind(symbol.Kind) {
+		if opts.IncludeChildren {
+			// Show matching descendants in place of the hidden parent
+			for _, child := range symbol.Children {
+				formatDocumentSymbol(output, &child, depth, level+1, source, opts)
+			}
+		}
+		return
+	}
+
 	indent := strings.Repeat("│   ", depth)
 	if depth > 0 {
 		indent = strings.Repeat("│   ", depth-1) + "├── "
@@ -83,14 +201,113 @@ func formatDocumentSymbol(output *strings.Builder, symbol *protocol.DocumentSymb
 
 	output.WriteString(line)
 
+	// Children of a symbol show their own source, so only leaves include their body
+	source.write(output, strings.Repeat("│   ", depth)+"    ",
+		symbol.SelectionRange.Start.Line, symbol.Range, len(symbol.Children) == 0)
+
 	// Recursively format children
 	for _, child := range symbol.Children {
-		formatDocumentSymbol(output, &child, depth+1)
+		formatDocumentSymbol(output, &child, depth+1, level+1, source, opts)
+	}
+	if hidden := opts.hidden[symbolKey{symbol.Name, symbol.Range}]; hidden > 0 && (opts.MaxDepth == 0 || level < opts.MaxDepth) {
+		output.WriteString(fmt.Sprintf("%s├── … %d symbols hidden\n", strings.Repeat("│   ", depth), hidden))
+	}
+}
+
+// collapseSymbolResults applies opts.Collapse and opts.NameFilter to the hierarchical
+// symbols of results, recording the number of hidden symbols below each symbol kept in
+// opts.hidden. It returns the symbols to show and the number of top-level symbols hidden,
+// including the symbols nested in them.
+func collapseSymbolResults(results []protocol.DocumentSymbolResult, opts *DocumentSymbolsOptions) ([]protocol.DocumentSymbolResult, int) {
+	var symbols []protocol.DocumentSymbol
+	var collapsed []protocol.DocumentSymbolResult
+	for _, result := range results {
+		if symbol, ok := result.(*protocol.DocumentSymbol); ok {
+			symbols = append(symbols, *symbol)
+		} else {
+			collapsed = append(collapsed, result)
+		}
+	}
+
+	opts.hidden = make(map[symbolKey]int)
+	kept, hidden := collapseSymbols(symbols, *opts)
+	for i := range kept {
+		collapsed = append(collapsed, &kept[i])
+	}
+	return collapsed, hidden
+}
+
+// collapseSymbols returns copies of the symbols to keep at one level of the tree, in their
+// original order, and the number of symbols hidden, counting those nested in them
+func collapseSymbols(symbols []protocol.DocumentSymbol, opts DocumentSymbolsOptions) ([]protocol.DocumentSymbol, int) {
+	sizes := make([]int, len(symbols))
+	for i, symbol := range symbols {
+		sizes[i] = countNestedSymbols(symbol)
+	}
+
+	// Keep the largest symbols that contain others, so that the view shows where most of
+	// the file's structure is
+	keep := make([]bool, len(symbols))
+	bySize := make([]int, len(symbols))
+	for i := range bySize {
+		bySize[i] = i
+	}
+	slices.SortStableFunc(bySize, func(a, b int) int { return sizes[b] - sizes[a] })
+	for _, i := range bySize[:min(opts.Collapse, len(bySize))] {
+		keep[i] = sizes[i] > 0
+	}
+
+	var kept []protocol.DocumentSymbol
+	hidden := 0
+	for i, symbol := range symbols {
+		if !keep[i] && !matchesSymbolName(symbol, opts.NameFilter) {
+			hidden += 1 + sizes[i]
+			continue
+		}
+		children, hiddenChildren := collapseSymbols(symbol.Children, opts)
+		symbol.Children = children
+		if hiddenChildren > 0 {
+			opts.hidden[symbolKey{symbol.Name, symbol.Range}] = hiddenChildren
+		}
+		kept = append(kept, symbol)
+	}
+	return kept, hidden
+}
+
+// countNestedSymbols returns the number of symbols nested in symbol at any depth
+func countNestedSymbols(symbol protocol.DocumentSymbol) int {
+	count := len(symbol.Children)
+	for _, child := range symbol.Children {
+		count += countNestedSymbols(child)
+	}
+	return count
+}
+
+// matchesSymbolName reports whether the name of symbol or of a symbol nested in it contains
+// filter, ignoring case. An empty filter matches nothing.
+func matchesSymbolName(symbol protocol.DocumentSymbol, filter string) bool {
+	if filter == "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(symbol.Name), strings.ToLower(filter)) {
+		return true
 	}
+	return slices.ContainsFunc(symbol.Children, func(child protocol.DocumentSymbol) bool {
+		return matchesSymbolName(child, filter)
+	})
 }
 
-// formatSymbolInformation formats a flat SymbolInformation
-func formatSymbolInformation(output *strings.Builder, symbol *protocol.SymbolInformation) {
+// formatSymbolInformation formats a flat SymbolInformation. The other symbols of the file
+// are used to tell whether symbol contains others, in which case its body is not shown.
+// Flat symbols have no tree, so symbols with a container name count as nested for opts.MaxDepth.
+func formatSymbolInformation(output *strings.Builder, symbol *protocol.SymbolInformation, source *symbolSource, all []protocol.DocumentSymbolResult, opts DocumentSymbolsOptions) {
+	if opts.MaxDepth == 1 && symbol.ContainerName != "" {
+		return
+	}
+	if !opts.matchesKind(symbol.Kind) {
+		return
+	}
+
 	kindStr := protocol.TableKindMap[symbol.Kind]
 	startLine := symbol.Location.Range.Start.Line + 1
 	endLine := symbol.Location.Range.End.Line + 1
@@ -109,4 +326,55 @@ func formatSymbolInformation(output *strings.Builder, symbol *protocol.SymbolInf
 	)
 
 	output.WriteString(line)
+
+	if source != nil {
+		isLeaf := true
+		for _, other := range all {
+			if info, ok := other.(*protocol.SymbolInformation); ok && info != symbol && rangeContains(symbol.Location.Range, info.Location.Range) {
+				isLeaf = false
+				break
+			}
+		}
+		source.write(output, "    ", symbol.Location.Range.Start.Line, symbol.Location.Range, isLeaf)
+	}
+}
+
+// symbolSource holds the lines of a file for showing symbol signatures or bodies
+type symbolSource struct {
+	lines []string
+	full  bool
+}
+
+// write adds the source of a symbol below its outline entry, prefixed with indent: the
+// declaration line, or the whole range if full output was requested and includeBody is set.
+// It does nothing if s is nil.
+func (s *symbolSource) write(output *strings.Builder, indent string, declarationLine uint32, rng protocol.Range, includeBody bool) {
+	if s == nil {
+		return
+	}
+
+	start, end := int(declarationLine), int(declarationLine)
+	if s.full && includeBody {
+		start, end = int(rng.Start.Line), int(rng.End.Line)
+	}
+
+	for i := start; i <= end && i < len(s.lines); i++ {
+		text := s.lines[i]
+		if start == end {
+			text = strings.TrimSpace(text)
+		}
+		output.WriteString(indent + text + "\n")
+	}
+}
+
+// rangeContains reports whether inner lies within outer and is not the same range
+func rangeContains(outer, inner protocol.Range) bool {
+	if outer == inner {
+		return false
+	}
+	startsAfter := inner.Start.Line > outer.Start.Line ||
+		(inner.Start.Line == outer.Start.Line && inner.Start.Character >= outer.Start.Character)
+	endsBefore := inner.End.Line < outer.End.Line ||
+		(inner.End.Line == outer.End.Line && inner.End.Character <= outer.End.Character)
+	return startsAfter && endsBefore
 }
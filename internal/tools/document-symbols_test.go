@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func symbolRange(startLine, startChar, endLine, endChar uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startChar},
+		End:   protocol.Position{Line: endLine, Character: endChar},
+	}
+}
+
+func TestFormatDocumentSymbolDetail(t *testing.T) {
+	lines := strings.Split("type Shape struct {\n\tName string\n}\n\nfunc (s *Shape) Area() float64 {\n\treturn 0\n}\n", "\n")
+	shape := &protocol.DocumentSymbol{
+		Name:           "Shape",
+		Kind:           protocol.Struct,
+		Range:          symbolRange(0, 0, 2, 1),
+		SelectionRange: symbolRange(0, 5, 0, 10),
+		Children: []protocol.DocumentSymbol{{
+			Name:           "Name",
+			Kind:           protocol.Field,
+			Range:          symbolRange(1, 1, 1, 12),
+			SelectionRange: symbolRange(1, 1, 1, 5),
+		}},
+	}
+	area := &protocol.DocumentSymbol{
+		Name:           "(*Shape).Area",
+		Kind:           protocol.Method,
+		Range:          symbolRange(4, 0, 6, 1),
+		SelectionRange: symbolRange(4, 16, 4, 20),
+	}
+
+	tests := []struct {
+		name     string
+		source   *symbolSource
+		expected string
+	}{
+		{
+			name:   "outline",
+			source: nil,
+			expected: "Struct Shape [1:1-3:2]\n" +
+				"├── Field Name [2:2-2:13]\n" +
+				"Method (*Shape).Area [5:1-7:2]\n",
+		},
+		{
+			name:   "signatures",
+			source: &symbolSource{lines: lines},
+			expected: "Struct Shape [1:1-3:2]\n" +
+				"    type Shape struct {\n" +
+				"├── Field Name [2:2-2:13]\n" +
+				"│       Name string\n" +
+				"Method (*Shape).Area [5:1-7:2]\n" +
+				"    func (s *Shape) Area() float64 {\n",
+		},
+		{
+			name:   "full shows the body of leaves only",
+			source: &symbolSource{lines: lines, full: true},
+			expected: "Struct Shape [1:1-3:2]\n" +
+				"    type Shape struct {\n" +
+				"├── Field Name [2:2-2:13]\n" +
+				"│       Name string\n" +
+				"Method (*Shape).Area [5:1-7:2]\n" +
+				"    func (s *Shape) Area() float64 {\n" +
+				"    \treturn 0\n" +
+				"    }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			formatDocumentSymbol(&output, shape, 0, 1, tt.source, DocumentSymbolsOptions{})
+			formatDocumentSymbol(&output, area, 0, 1, tt.source, DocumentSymbolsOptions{})
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestFormatSymbolInformationDetail(t *testing.T) {
+	lines := strings.Split("class Shape:\n    def area(self):\n        return 0\n", "\n")
+	shape := &protocol.SymbolInformation{
+		Name:     "Shape",
+		Kind:     protocol.Class,
+		Location: protocol.Location{Range: symbolRange(0, 0, 2, 16)},
+	}
+	area := &protocol.SymbolInformation{
+		Name:          "area",
+		Kind:          protocol.Method,
+		ContainerName: "Shape",
+		Location:      protocol.Location{Range: symbolRange(1, 4, 2, 16)},
+	}
+	all := []protocol.DocumentSymbolResult{shape, area}
+
+	var output strings.Builder
+	source := &symbolSource{lines: lines, full: true}
+	formatSymbolInformation(&output, shape, source, all, DocumentSymbolsOptions{})
+	formatSymbolInformation(&output, area, source, all, DocumentSymbolsOptions{})
+
+	assert.Equal(t, "• Class Shape [1:1-3:17]\n"+
+		"    class Shape:\n"+
+		"• Method area (in Shape) [2:5-3:17]\n"+
+		"        def area(self):\n"+
+		"            return 0\n", output.String())
+}
+
+func TestFormatDocumentSymbolFilter(t *testing.T) {
+	// module.py:
+	//   def helper(): ...
+	//   class Outer:
+	//       def method(self): ...
+	//       class Inner:
+	//           def inner_method(self): ...
+	symbols := []protocol.DocumentSymbol{
+		{Name: "helper", Kind: protocol.Function, Range: symbolRange(0, 0, 0, 17)},
+		{
+			Name:  "Outer",
+			Kind:  protocol.Class,
+			Range: symbolRange(1, 0, 5, 35),
+			Children: []protocol.DocumentSymbol{
+				{Name: "method", Kind: protocol.Method, Range: symbolRange(2, 4, 2, 25)},
+				{
+					Name:  "Inner",
+					Kind:  protocol.Class,
+					Range: symbolRange(3, 4, 5, 35),
+					Children: []protocol.DocumentSymbol{
+						{Name: "inner_method", Kind: protocol.Method, Range: symbolRange(4, 8, 4, 35)},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     DocumentSymbolsOptions
+		expected string
+	}{
+		{
+			name: "no filter",
+			opts: DocumentSymbolsOptions{},
+			expected: "Function helper [1:1-1:18]\n" +
+				"Class Outer [2:1-6:36]\n" +
+				"├── Method method [3:5-3:26]\n" +
+				"├── Class Inner [4:5-6:36]\n" +
+				"│   ├── Method inner_method [5:9-5:36]\n",
+		},
+		{
+			name:     "top-level functions",
+			opts:     DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Function}, MaxDepth: 1},
+			expected: "Function helper [1:1-1:18]\n",
+		},
+		{
+			name: "max depth",
+			opts: DocumentSymbolsOptions{MaxDepth: 2},
+			expected: "Function helper [1:1-1:18]\n" +
+				"Class Outer [2:1-6:36]\n" +
+				"├── Method method [3:5-3:26]\n" +
+				"├── Class Inner [4:5-6:36]\n",
+		},
+		{
+			name:     "pruned parent hides matching children",
+			opts:     DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Method}},
+			expected: "",
+		},
+		{
+			name: "include children of pruned parents",
+			opts: DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Method}, IncludeChildren: true},
+			expected: "Method method [3:5-3:26]\n" +
+				"Method inner_method [5:9-5:36]\n",
+		},
+		{
+			name:     "include children respects max depth",
+			opts:     DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Method}, IncludeChildren: true, MaxDepth: 2},
+			expected: "Method method [3:5-3:26]\n",
+		},
+		{
+			name: "classes keep matching nested classes",
+			opts: DocumentSymbolsOptions{Kinds: []protocol.SymbolKind{protocol.Class}},
+			expected: "Class Outer [2:1-6:36]\n" +
+				"├── Class Inner [4:5-6:36]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			for _, symbol := range symbols {
+				formatDocumentSymbol(&output, &symbol, 0, 1, nil, tt.opts)
+			}
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestGetDocumentSymbolsCollapse(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "module.py", "")
+	server.RespondRaw("textDocument/documentSymbol", `[
+		{"name": "helper", "kind": 12, "range": {"start": {"line": 0, "character": 0}, "end": {"line": 0, "character": 17}}, "selectionRange": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 10}}},
+		{"name": "Outer", "kind": 5, "range": {"start": {"line": 1, "character": 0}, "end": {"line": 5, "character": 35}}, "selectionRange": {"start": {"line": 1, "character": 6}, "end": {"line": 1, "character": 11}}, "children": [
+			{"name": "method", "kind": 6, "range": {"start": {"line": 2, "character": 4}, "end": {"line": 2, "character": 25}}, "selectionRange": {"start": {"line": 2, "character": 8}, "end": {"line": 2, "character": 14}}},
+			{"name": "Inner", "kind": 5, "range": {"start": {"line": 3, "character": 4}, "end": {"line": 5, "character": 35}}, "selectionRange": {"start": {"line": 3, "character": 10}, "end": {"line": 3, "character": 15}}, "children": [
+				{"name": "inner_method", "kind": 6, "range": {"start": {"line": 4, "character": 8}, "end": {"line": 4, "character": 35}}, "selectionRange": {"start": {"line": 4, "character": 12}, "end": {"line": 4, "character": 24}}}
+			]}
+		]},
+		{"name": "Tiny", "kind": 5, "range": {"start": {"line": 6, "character": 0}, "end": {"line": 7, "character": 20}}, "selectionRange": {"start": {"line": 6, "character": 6}, "end": {"line": 6, "character": 10}}, "children": [
+			{"name": "run", "kind": 6, "range": {"start": {"line": 7, "character": 4}, "end": {"line": 7, "character": 20}}, "selectionRange": {"start": {"line": 7, "character": 8}, "end": {"line": 7, "character": 11}}}
+		]},
+		{"name": "main", "kind": 12, "range": {"start": {"line": 8, "character": 0}, "end": {"line": 8, "character": 15}}, "selectionRange": {"start": {"line": 8, "character": 4}, "end": {"line": 8, "character": 8}}}
+	]`)
+
+	tests := []struct {
+		name     string
+		opts     DocumentSymbolsOptions
+		expected string
+	}{
+		{
+			name: "largest symbol at each level",
+			opts: DocumentSymbolsOptions{Collapse: 1},
+			expected: "Class Outer [2:1-6:36]\n" +
+				"├── Class Inner [4:5-6:36]\n" +
+				"│   ├── … 1 symbols hidden\n" +
+				"├── … 1 symbols hidden\n" +
+				"… 4 symbols hidden\n",
+		},
+		{
+			name: "leaves are not kept for their size",
+			opts: DocumentSymbolsOptions{Collapse: 3},
+			expected: "Class Outer [2:1-6:36]\n" +
+				"├── Class Inner [4:5-6:36]\n" +
+				"│   ├── … 1 symbols hidden\n" +
+				"├── … 1 symbols hidden\n" +
+				"Class Tiny [7:1-8:21]\n" +
+				"├── … 1 symbols hidden\n" +
+				"… 2 symbols hidden\n",
+		},
+		{
+			name: "name filter keeps the containing symbols",
+			opts: DocumentSymbolsOptions{NameFilter: "INNER_M"},
+			expected: "Class Outer [2:1-6:36]\n" +
+				"├── Class Inner [4:5-6:36]\n" +
+				"│   ├── Method inner_method [5:9-5:36]\n" +
+				"├── … 1 symbols hidden\n" +
+				"… 4 symbols hidden\n",
+		},
+		{
+			name: "hidden counts respect max depth",
+			opts: DocumentSymbolsOptions{Collapse: 1, MaxDepth: 1},
+			expected: "Class Outer [2:1-6:36]\n" +
+				"… 4 symbols hidden\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetDocumentSymbols(t.Context(), server.Client, filePath, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, "Document Symbols for "+filePath+":\n\n"+tt.expected, result)
+		})
+	}
+}
+
+func TestGetDocumentSymbolsEnrichDetail(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "module.py", "class Greeter:\n    def greet(self, name: str) -> str:\n        return name\n")
+	server.RespondRaw("textDocument/documentSymbol", `[
+		{"name": "Greeter", "kind": 5, "detail": "class", "range": {"start": {"line": 0, "character": 0}, "end": {"line": 2, "character": 19}}, "selectionRange": {"start": {"line": 0, "character": 6}, "end": {"line": 0, "character": 13}}, "children": [
+			{"name": "greet", "kind": 6, "range": {"start": {"line": 1, "character": 4}, "end": {"line": 2, "character": 19}}, "selectionRange": {"start": {"line": 1, "character": 8}, "end": {"line": 1, "character": 13}}}
+		]}
+	]`)
+	server.RespondRaw("textDocument/hover", `{"contents": {"kind": "markdown", "value": "`+"```python\\n(method) def greet(self, name: str) -> str\\n```\\n---\\nGreets someone"+`"}}`)
+
+	result, err := GetDocumentSymbols(t.Context(), server.Client, filePath, DocumentSymbolsOptions{EnrichDetail: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Document Symbols for "+filePath+":\n\n"+
+		"Class Greeter (class) [1:1-3:20]\n"+
+		"├── Method greet ((method) def greet(self, name: str) -> str) [2:5-3:20]\n", result)
+
+	// Only the symbol without detail is hovered, at the start of its name
+	hovers := server.Received("textDocument/hover")
+	require.Len(t, hovers, 1)
+	assert.Contains(t, string(hovers[0]), `"position":{"line":1,"character":8}`)
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ApplyTextEditsToFiles applies line based edits to several files at once. The edits of every
+// file are validated before anything is written and the files are written as a transaction
+// (see applyWorkspaceEditAtomically), so an invalid edit in one file leaves all of them
+// unchanged. Nothing is written if a file is outside the workspace.
+func ApplyTextEditsToFiles(ctx context.Context, client *lsp.Client, fileEdits map[string][]TextEdit) (string, error) {
+	if len(fileEdits) == 0 {
+		return "", fmt.Errorf("no files to edit")
+	}
+	paths := make([]string, 0, len(fileEdits))
+	for path := range fileEdits {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		if _, ok := client.RootForPath(path); !ok {
+			return "", fmt.Errorf("%s is outside the workspace folders (%s), nothing was changed", path, strings.Join(client.WorkspaceFolders(), ", "))
+		}
+	}
+
+	edit := protocol.WorkspaceEdit{Changes: make(map[protocol.DocumentUri][]protocol.TextEdit, len(paths))}
+	textEdits := make(map[string][]protocol.TextEdit, len(paths))
+	for _, path := range paths {
+		if len(fileEdits[path]) == 0 {
+			return "", fmt.Errorf("no edits given for %s", path)
+		}
+		if err := client.OpenFile(ctx, path); err != nil {
+			return "", fmt.Errorf("could not open %s: %v", path, err)
+		}
+		edits, err := lineTextEdits(path, fileEdits[path])
+		if err != nil {
+			return "", fmt.Errorf("%s: %v, nothing was changed", path, err)
+		}
+		textEdits[path] = edits
+		edit.Changes[protocol.DocumentUri("file://"+path)] = edits
+	}
+
+	_, err := applyWorkspaceEditAtomically(edit)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply text edits: %w", err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Successfully applied text edits to %d files:\n", len(paths)))
+	for _, path := range paths {
+		// Sync the server now, sending only the edited ranges if it supports incremental changes
+		if err := client.NotifyEdits(ctx, path, textEdits[path]); err != nil {
+			toolsLogger.Warn("failed to notify change to %s: %v", path, err)
+		}
+		removed, added := countEditedLines(fileEdits[path])
+		result.WriteString(fmt.Sprintf("- %s: %d edits, %d lines removed, %d lines added\n", path, len(fileEdits[path]), removed, added))
+	}
+	return result.String(), nil
+}
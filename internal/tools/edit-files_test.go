@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTextEditsToFiles(t *testing.T) {
+	server := newMockServer(t)
+	mainPath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\told()\n}\n")
+	utilPath := writeTestFile(t, "util.go", "package main\n\nfunc old() {}\n")
+
+	result, err := ApplyTextEditsToFiles(t.Context(), server.Client, map[string][]TextEdit{
+		mainPath: {{StartLine: 4, EndLine: 4, NewText: "\thelper()"}},
+		utilPath: {
+			{StartLine: 3, EndLine: 3, NewText: "func helper() {}"},
+			{StartLine: 1, EndLine: 1, NewText: "// Package main has helpers\npackage main"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully applied text edits to 2 files:\n"+
+		"- "+mainPath+": 1 edits, 1 lines removed, 1 lines added\n"+
+		"- "+utilPath+": 2 edits, 2 lines removed, 3 lines added\n", result)
+
+	content, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\thelper()\n}\n", string(content))
+	content, err = os.ReadFile(utilPath)
+	require.NoError(t, err)
+	assert.Equal(t, "// Package main has helpers\npackage main\n\nfunc helper() {}\n", string(content))
+
+	// Both files were opened and synced with the server
+	assert.Len(t, server.Received("textDocument/didOpen"), 2)
+}
+
+func TestApplyTextEditsToFilesIsAtomic(t *testing.T) {
+	const mainSource = "package main\n\nfunc main() {}\n"
+	const utilSource = "package main\n\nfunc old() {}\n"
+
+	tests := []struct {
+		name      string
+		utilEdits []TextEdit
+		expected  string
+	}{
+		{
+			name:      "overlapping edits",
+			utilEdits: []TextEdit{{StartLine: 1, EndLine: 2, NewText: "x"}, {StartLine: 2, EndLine: 3, NewText: "y"}},
+			expected:  "overlapping edits",
+		},
+		{
+			name:      "invalid line",
+			utilEdits: []TextEdit{{StartLine: 0, EndLine: 1, NewText: "x"}},
+			expected:  "start line must be >= 1",
+		},
+		{
+			name:      "no edits",
+			utilEdits: nil,
+			expected:  "no edits given for",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			mainPath := writeTestFile(t, "main.go", mainSource)
+			utilPath := writeTestFile(t, "util.go", utilSource)
+
+			_, err := ApplyTextEditsToFiles(t.Context(), server.Client, map[string][]TextEdit{
+				mainPath: {{StartLine: 3, EndLine: 3, NewText: "func main() { run() }"}},
+				utilPath: tt.utilEdits,
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+
+			// The valid edits to the other file were not written either
+			content, err := os.ReadFile(mainPath)
+			require.NoError(t, err)
+			assert.Equal(t, mainSource, string(content))
+			content, err = os.ReadFile(utilPath)
+			require.NoError(t, err)
+			assert.Equal(t, utilSource, string(content))
+		})
+	}
+}
+
+func TestApplyTextEditsToFilesOutsideWorkspace(t *testing.T) {
+	server := newMockServer(t)
+	workspace := t.TempDir()
+	require.NoError(t, server.Client.AddWorkspaceFolders(t.Context(), workspace))
+	insidePath := filepath.Join(workspace, "main.go")
+	require.NoError(t, os.WriteFile(insidePath, []byte("package main\n"), 0644))
+	outsidePath := writeTestFile(t, "util.go", "package main\n")
+
+	_, err := ApplyTextEditsToFiles(t.Context(), server.Client, map[string][]TextEdit{
+		insidePath:  {{StartLine: 1, EndLine: 1, NewText: "package lib"}},
+		outsidePath: {{StartLine: 1, EndLine: 1, NewText: "package lib"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), outsidePath+" is outside the workspace folders")
+
+	// Neither file was written
+	for _, path := range []string{insidePath, outsidePath} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n", string(content))
+	}
+}
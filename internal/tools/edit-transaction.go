@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// File operations used to commit staged edits, replaced in tests to inject failures
+var (
+	osWriteFile = os.WriteFile
+	osRemove    = os.Remove
+	osRemoveAll = os.RemoveAll
+)
+
+// stagedFile is the state of a file after the operations of a WorkspaceEdit seen so far, and
+// its state on disk before them
+type stagedFile struct {
+	content []byte
+	exists  bool
+
+	original []byte
+	existed  bool
+}
+
+// stagedWorkspaceEdit applies a WorkspaceEdit to copies of the files in memory, so that every
+// operation is checked against the state the earlier ones leave behind before anything is
+// written
+type stagedWorkspaceEdit struct {
+	files       map[string]*stagedFile
+	deletedDirs []string
+	results     []string // One line per operation, in order
+}
+
+// applyWorkspaceEditAtomically applies a WorkspaceEdit to several files as a transaction: all
+// of it is staged and validated in memory first, and if writing any file fails, the files
+// already written are restored, so that the workspace is never left half-edited. Deleting
+// directories can't be undone, so they are removed after every file was written.
+func applyWorkspaceEditAtomically(edit protocol.WorkspaceEdit) (*stagedWorkspaceEdit, error) {
+	staged, err := stageWorkspaceEdit(edit)
+	if err != nil {
+		return nil, fmt.Errorf("nothing was changed: %w", err)
+	}
+	if err := staged.commit(); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// stageWorkspaceEdit checks that every file a WorkspaceEdit edits, renames or deletes exists
+// and that every text edit is within its file, and returns the resulting files
+func stageWorkspaceEdit(edit protocol.WorkspaceEdit) (*stagedWorkspaceEdit, error) {
+	staged := &stagedWorkspaceEdit{files: make(map[string]*stagedFile)}
+
+	uris := make([]protocol.DocumentUri, 0, len(edit.Changes))
+	for uri := range edit.Changes {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+	for _, uri := range uris {
+		if err := staged.applyTextEdits(uri, edit.Changes[uri]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, change := range edit.DocumentChanges {
+		var err error
+		switch {
+		case change.TextDocumentEdit != nil:
+			edits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+			for j, e := range change.TextDocumentEdit.Edits {
+				if edits[j], err = e.AsTextEdit(); err != nil {
+					return nil, fmt.Errorf("document change %d: invalid edit type: %w", i+1, err)
+				}
+			}
+			err = staged.applyTextEdits(change.TextDocumentEdit.TextDocument.URI, edits)
+		case change.CreateFile != nil:
+			err = staged.createFile(*change.CreateFile)
+		case change.RenameFile != nil:
+			err = staged.renameFile(*change.RenameFile)
+		case change.DeleteFile != nil:
+			err = staged.deleteFile(*change.DeleteFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return staged, nil
+}
+
+// commit writes the staged files in order of their paths. If a write fails, the files already
+// written are restored to their original state.
+func (s *stagedWorkspaceEdit) commit() error {
+	paths := make([]string, 0, len(s.files))
+	for path, file := range s.files {
+		if file.exists != file.existed || !bytes.Equal(file.content, file.original) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	for i, path := range paths {
+		if err := s.files[path].write(path); err != nil {
+			if rollbackErr := s.rollback(paths[:i]); rollbackErr != nil {
+				return fmt.Errorf("%w, and restoring the files written before failed: %v", err, rollbackErr)
+			}
+			return fmt.Errorf("%w, the files written before were restored", err)
+		}
+	}
+
+	for _, dir := range s.deletedDirs {
+		if err := osRemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to delete directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// rollback restores the original state of files
+func (s *stagedWorkspaceEdit) rollback(paths []string) error {
+	var errs []error
+	for _, path := range paths {
+		file := s.files[path]
+		original := &stagedFile{content: file.original, exists: file.existed}
+		if err := original.write(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// write makes the file on disk match the staged state
+func (f *stagedFile) write(path string) error {
+	if !f.exists {
+		if err := osRemove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := osWriteFile(path, f.content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// file returns the staged state of path, reading it from disk the first time
+func (s *stagedWorkspaceEdit) file(path string) (*stagedFile, error) {
+	if file, ok := s.files[path]; ok {
+		return file, nil
+	}
+
+	file := &stagedFile{}
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		file.content, file.exists = content, true
+		file.original, file.existed = content, true
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	s.files[path] = file
+	return file, nil
+}
+
+func (s *stagedWorkspaceEdit) applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+	path := utilities.URIToPath(uri)
+	file, err := s.file(path)
+	if err != nil {
+		return err
+	}
+	if !file.exists {
+		return fmt.Errorf("cannot edit %s: file does not exist", path)
+	}
+	if err := checkEditRanges(file.content, edits); err != nil {
+		return fmt.Errorf("invalid edit to %s: %w", path, err)
+	}
+
+	content, err := utilities.ApplyTextEditsToContent(file.content, edits)
+	if err != nil {
+		return fmt.Errorf("failed to apply edits to %s: %w", path, err)
+	}
+	file.content = content
+	s.results = append(s.results, fmt.Sprintf("Edited %s (%d edits)", path, len(edits)))
+	return nil
+}
+
+func (s *stagedWorkspaceEdit) createFile(create protocol.CreateFile) error {
+	path := utilities.URIToPath(create.URI)
+	file, err := s.file(path)
+	if err != nil {
+		return err
+	}
+	if file.exists {
+		switch {
+		case create.Options != nil && create.Options.Overwrite:
+		case create.Options != nil && create.Options.IgnoreIfExists:
+			s.results = append(s.results, fmt.Sprintf("Skipped creating %s, it already exists", path))
+			return nil
+		default:
+			return fmt.Errorf("cannot create %s: file already exists", path)
+		}
+	}
+	file.content, file.exists = nil, true
+	s.results = append(s.results, fmt.Sprintf("Created %s", path))
+	return nil
+}
+
+func (s *stagedWorkspaceEdit) renameFile(rename protocol.RenameFile) error {
+	oldPath := utilities.URIToPath(rename.OldURI)
+	newPath := utilities.URIToPath(rename.NewURI)
+	from, err := s.file(oldPath)
+	if err != nil {
+		return err
+	}
+	to, err := s.file(newPath)
+	if err != nil {
+		return err
+	}
+	if !from.exists {
+		return fmt.Errorf("cannot rename %s: file does not exist", oldPath)
+	}
+	if to.exists {
+		switch {
+		case rename.Options != nil && rename.Options.Overwrite:
+		case rename.Options != nil && rename.Options.IgnoreIfExists:
+			s.results = append(s.results, fmt.Sprintf("Skipped renaming %s, %s already exists", oldPath, newPath))
+			return nil
+		default:
+			return fmt.Errorf("cannot rename %s: %s already exists", oldPath, newPath)
+		}
+	}
+	to.content, to.exists = from.content, true
+	from.content, from.exists = nil, false
+	s.results = append(s.results, fmt.Sprintf("Renamed %s -> %s", oldPath, newPath))
+	return nil
+}
+
+func (s *stagedWorkspaceEdit) deleteFile(del protocol.DeleteFile) error {
+	path := utilities.URIToPath(del.URI)
+	if _, staged := s.files[path]; !staged {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if del.Options == nil || !del.Options.Recursive {
+				return fmt.Errorf("cannot delete %s: it is a directory and the deletion is not recursive", path)
+			}
+			s.deletedDirs = append(s.deletedDirs, path)
+			s.results = append(s.results, fmt.Sprintf("Deleted directory %s", path))
+			return nil
+		}
+	}
+
+	file, err := s.file(path)
+	if err != nil {
+		return err
+	}
+	if !file.exists {
+		if del.Options != nil && del.Options.IgnoreIfNotExists {
+			s.results = append(s.results, fmt.Sprintf("Skipped deleting %s, it does not exist", path))
+			return nil
+		}
+		return fmt.Errorf("cannot delete %s: file does not exist", path)
+	}
+	file.content, file.exists = nil, false
+	s.results = append(s.results, fmt.Sprintf("Deleted %s", path))
+	return nil
+}
+
+// checkEditRanges returns an error if an edit starts after it ends or refers to a line or
+// character past the end of content. Characters are counted in bytes like in the rest of the
+// edit code.
+func checkEditRanges(content []byte, edits []protocol.TextEdit) error {
+	content = bytes.TrimPrefix(content, []byte("\uFEFF"))
+	lineEnding := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		lineEnding = "\r\n"
+	}
+	lines := strings.Split(string(content), lineEnding)
+
+	inBounds := func(position protocol.Position) bool {
+		return int(position.Line) < len(lines) && int(position.Character) <= len(lines[position.Line])
+	}
+	for _, edit := range edits {
+		start, end := edit.Range.Start, edit.Range.End
+		if !inBounds(start) || !inBounds(end) {
+			return fmt.Errorf("range L%d:C%d-L%d:C%d is outside the file, which has %d lines",
+				start.Line+1, start.Character+1, end.Line+1, end.Character+1, len(lines))
+		}
+		if start.Line > end.Line || start.Line == end.Line && start.Character > end.Character {
+			return fmt.Errorf("range L%d:C%d-L%d:C%d ends before it starts",
+				start.Line+1, start.Character+1, end.Line+1, end.Character+1)
+		}
+	}
+	return nil
+}
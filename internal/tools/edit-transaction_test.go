@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWorkspaceEditAtomicallyRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	original := map[string]string{
+		"a.go": "package main\n\nvar a = 1\n",
+		"b.go": "package main\n\nvar b = 2\n",
+		"c.go": "package main\n\nvar c = 3\n",
+		"d.go": "package main\n\nvar d = 4\n",
+	}
+	for name, content := range original {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	uri := func(name string) protocol.DocumentUri {
+		return protocol.DocumentUri("file://" + filepath.Join(dir, name))
+	}
+	replaceValue := protocol.Or_TextDocumentEdit_edits_Elem{Value: protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 2, Character: 8}, End: protocol.Position{Line: 2, Character: 9}},
+		NewText: "42",
+	}}
+
+	// Files are written in order of their paths, so a.go is edited and b.go, which is renamed
+	// to e.go, deleted before writing c.go fails. d.go and e.go are not reached.
+	edit := protocol.WorkspaceEdit{DocumentChanges: []protocol.DocumentChange{
+		{TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri("a.go")}},
+			Edits:        []protocol.Or_TextDocumentEdit_edits_Elem{replaceValue},
+		}},
+		{TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri("c.go")}},
+			Edits:        []protocol.Or_TextDocumentEdit_edits_Elem{replaceValue},
+		}},
+		{DeleteFile: &protocol.DeleteFile{Kind: "delete", URI: uri("d.go")}},
+		{RenameFile: &protocol.RenameFile{Kind: "rename", OldURI: uri("b.go"), NewURI: uri("e.go")}},
+	}}
+
+	failing := filepath.Join(dir, "c.go")
+	var written []string
+	osWriteFile = func(name string, data []byte, perm os.FileMode) error {
+		if name == failing {
+			return errors.New("disk full")
+		}
+		written = append(written, filepath.Base(name))
+		return os.WriteFile(name, data, perm)
+	}
+	t.Cleanup(func() { osWriteFile = os.WriteFile })
+
+	_, err := applyWorkspaceEditAtomically(edit)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.Contains(t, err.Error(), "the files written before were restored")
+
+	// a.go was written and then restored, b.go was deleted and restored, the rest untouched
+	assert.Equal(t, []string{"a.go", "a.go", "b.go"}, written)
+	for name, content := range original {
+		actual, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, content, string(actual), name)
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "e.go"))
+
+	// Without the failure the same edit applies completely
+	osWriteFile = os.WriteFile
+	staged, err := applyWorkspaceEditAtomically(edit)
+	require.NoError(t, err)
+	assert.Len(t, staged.results, 4)
+	content, err := os.ReadFile(filepath.Join(dir, "c.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nvar c = 42\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "e.go"))
+	require.NoError(t, err)
+	assert.Equal(t, original["b.go"], string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "b.go"))
+	assert.NoFileExists(t, filepath.Join(dir, "d.go"))
+}
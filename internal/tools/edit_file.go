@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"unicode/utf16"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
@@ -25,44 +26,97 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
 
-	// Create a sorted copy of edits for reporting
+	linesRemoved, linesAdded := countEditedLines(edits)
+	textEdits, err := lineTextEdits(filePath, edits)
+	if err != nil {
+		return "", err
+	}
+
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			protocol.DocumentUri(filePath): textEdits,
+		},
+	}
+
+	err = utilities.ApplyWorkspaceEdit(edit)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply text edits: %v", err)
+	}
+
+	// Sync the server now, sending only the edited ranges if it supports incremental changes
+	if err := client.NotifyEdits(ctx, filePath, textEdits); err != nil {
+		toolsLogger.Warn("failed to notify change: %v", err)
+	}
+
+	return fmt.Sprintf("Successfully applied text edits. %d lines removed, %d lines added.", linesRemoved, linesAdded), nil
+}
+
+// EditedContextLines is the number of lines around each edit EditedContent includes
+const EditedContextLines = 3
+
+// EditedContent returns the lines of filePath that edits, which were just applied, inserted,
+// with EditedContextLines lines around them, numbered as they are after the edits. Deleted
+// lines are shown as the lines around where they were.
+func EditedContent(filePath string, edits []TextEdit) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	// Don't count the empty string after a trailing newline as a line
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
 	sortedEdits := make([]TextEdit, len(edits))
 	copy(sortedEdits, edits)
 	sort.Slice(sortedEdits, func(i, j int) bool {
 		return sortedEdits[i].StartLine < sortedEdits[j].StartLine
 	})
 
-	// Track lines added and removed for sorted edits
-	linesRemovedSorted := 0
-	linesAddedSorted := 0
+	// Lines are 0-indexed here, and shifted by the lines the earlier edits added or removed
+	linesToShow := make(map[int]bool)
+	lineDelta := 0
 	for _, edit := range sortedEdits {
-		// Calculate lines removed: end - start + 1
-		removedLineCount := edit.EndLine - edit.StartLine + 1
-		linesRemovedSorted += removedLineCount
+		removed, added := countEditedLines([]TextEdit{edit})
+		start := min(edit.StartLine-1+lineDelta, len(lines))
+		for line := start - EditedContextLines; line < start+added+EditedContextLines; line++ {
+			linesToShow[line] = true
+		}
+		lineDelta += added - removed
+	}
+
+	return FormatLinesWithRanges(lines, ConvertLinesToRanges(linesToShow, len(lines))), nil
+}
 
-		// Calculate lines added: count newlines in the replacement text + 1
-		addedLineCount := 1
+// countEditedLines returns the number of lines edits remove and add
+func countEditedLines(edits []TextEdit) (int, int) {
+	removed, added := 0, 0
+	for _, edit := range edits {
+		removed += edit.EndLine - edit.StartLine + 1
 		if edit.NewText != "" {
-			addedLineCount = strings.Count(edit.NewText, "\n") + 1
-		} else if edit.NewText == "" {
-			addedLineCount = 0
+			added += strings.Count(edit.NewText, "\n") + 1
 		}
-		linesAddedSorted += addedLineCount
 	}
+	return removed, added
+}
 
-	// Sort edits by line number in descending order to process from bottom to top
-	// This way line numbers don't shift under us as we make edits
-	sort.Slice(edits, func(i, j int) bool {
-		return edits[i].StartLine > edits[j].StartLine
+// lineTextEdits converts line based edits to protocol.TextEdits covering the full lines they
+// replace in filePath, from the bottom of the file to the top so that applying them in order
+// doesn't shift the lines of the edits still to come
+func lineTextEdits(filePath string, edits []TextEdit) ([]protocol.TextEdit, error) {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartLine > sorted[j].StartLine
 	})
 
-	// Convert from input format to protocol.TextEdit
 	var textEdits []protocol.TextEdit
-	for _, edit := range edits {
+	for _, edit := range sorted {
 		// Get the range covering the requested lines
 		rng, err := getRange(edit.StartLine, edit.EndLine, filePath)
 		if err != nil {
-			return "", fmt.Errorf("invalid position: %v", err)
+			return nil, fmt.Errorf("invalid position: %v", err)
 		}
 
 		// Always do a replacement
@@ -71,18 +125,274 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 			NewText: edit.NewText,
 		})
 	}
+	return textEdits, nil
+}
 
-	edit := protocol.WorkspaceEdit{
-		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
-			protocol.DocumentUri(filePath): textEdits,
+// NotifySave sends textDocument/didSave for a file that was just edited, so that servers and
+// linters that only re-check saved files refresh their diagnostics. The file's content is
+// included if includeText is true, as requested by the server's save options.
+func NotifySave(ctx context.Context, client *lsp.Client, filePath string, includeText bool) error {
+	params := protocol.DidSaveTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
 		},
 	}
 
-	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
-		return "", fmt.Errorf("failed to apply text edits: %v", err)
+	if includeText {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		text := string(content)
+		params.Text = &text
+	}
+
+	return client.DidSave(ctx, params)
+}
+
+// onTypeTrigger is a position in the edited file where an inserted block ends in an
+// on-type formatting trigger character
+type onTypeTrigger struct {
+	Position protocol.Position
+	Ch       string
+}
+
+// FormatOnType asks the server to reformat the end of every inserted block that ends in
+// one of triggerCharacters (textDocument/onTypeFormatting) and applies the resulting edits.
+// edits must be the edits that were just applied by ApplyTextEdits. Returns the number of
+// formatting edits applied.
+func FormatOnType(ctx context.Context, client *lsp.Client, filePath string, edits []TextEdit, triggerCharacters []string) (int, error) {
+	triggers := onTypeFormattingTriggers(edits, triggerCharacters)
+	if len(triggers) == 0 {
+		return 0, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	options := detectFormattingOptions(content)
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	// Make sure the server sees the text we just wrote before asking it to format
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		return 0, fmt.Errorf("failed to notify change: %v", err)
+	}
+
+	// Process from bottom to top so formatting one block doesn't shift the positions of the others
+	applied := 0
+	for i := len(triggers) - 1; i >= 0; i-- {
+		formatEdits, err := client.OnTypeFormatting(ctx, protocol.DocumentOnTypeFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position:     triggers[i].Position,
+			Ch:           triggers[i].Ch,
+			Options:      options,
+		})
+		if err != nil {
+			return applied, fmt.Errorf("on type formatting failed: %v", err)
+		}
+		if len(formatEdits) == 0 {
+			continue
+		}
+
+		if err := utilities.ApplyTextEdits(uri, formatEdits); err != nil {
+			return applied, fmt.Errorf("failed to apply formatting edits: %v", err)
+		}
+		if err := client.NotifyEdits(ctx, filePath, formatEdits); err != nil {
+			return applied, fmt.Errorf("failed to notify change: %v", err)
+		}
+		applied += len(formatEdits)
+	}
+
+	return applied, nil
+}
+
+// onTypeFormattingTriggers returns, in ascending order, the post-edit position of the end
+// of each inserted block whose last character is one of triggerCharacters
+func onTypeFormattingTriggers(edits []TextEdit, triggerCharacters []string) []onTypeTrigger {
+	sortedEdits := make([]TextEdit, len(edits))
+	copy(sortedEdits, edits)
+	sort.Slice(sortedEdits, func(i, j int) bool {
+		return sortedEdits[i].StartLine < sortedEdits[j].StartLine
+	})
+
+	var triggers []onTypeTrigger
+	lineDelta := 0
+	for _, edit := range sortedEdits {
+		removed := edit.EndLine - edit.StartLine + 1
+		if edit.NewText == "" {
+			lineDelta -= removed
+			continue
+		}
+		added := strings.Count(edit.NewText, "\n") + 1
+
+		runes := []rune(edit.NewText)
+		ch := string(runes[len(runes)-1])
+		for _, trigger := range triggerCharacters {
+			if ch != trigger {
+				continue
+			}
+			lastLine := edit.NewText[strings.LastIndex(edit.NewText, "\n")+1:]
+			triggers = append(triggers, onTypeTrigger{
+				Position: protocol.Position{
+					Line:      uint32(edit.StartLine - 1 + lineDelta + added - 1),
+					Character: uint32(len(utf16.Encode([]rune(lastLine)))),
+				},
+				Ch: ch,
+			})
+			break
+		}
+
+		lineDelta += added - removed
+	}
+
+	return triggers
+}
+
+// detectFormattingOptions guesses tabs vs. spaces from the file's existing indentation
+func detectFormattingOptions(content []byte) protocol.FormattingOptions {
+	options := protocol.FormattingOptions{TabSize: 4, InsertSpaces: true}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			options.InsertSpaces = false
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			break
+		}
+	}
+	return options
+}
+
+// MatchIndentation re-indents the newText of each edit to match the lines it replaces: the
+// least indented line of newText gets the indentation of the least indented replaced line,
+// and deeper lines keep their relative depth, converted to the file's indentation style
+// (tabs or a number of spaces). When only blank lines are replaced, the indentation of the
+// closest non-blank line above is used. It returns the edits and how many were changed.
+func MatchIndentation(filePath string, edits []TextEdit) ([]TextEdit, int, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	unit := detectIndentUnit(lines)
+
+	matched := make([]TextEdit, len(edits))
+	changed := 0
+	for i, edit := range edits {
+		matched[i] = edit
+		if edit.NewText == "" {
+			continue
+		}
+		target, ok := replacedIndentation(lines, edit.StartLine, edit.EndLine)
+		if !ok {
+			continue
+		}
+		matched[i].NewText = reindent(edit.NewText, target, unit)
+		if matched[i].NewText != edit.NewText {
+			changed++
+		}
+	}
+	return matched, changed, nil
+}
+
+// replacedIndentation returns the smallest indentation of the non-blank lines between the
+// 1-indexed startLine and endLine, or of the closest non-blank line before them
+func replacedIndentation(lines []string, startLine, endLine int) (string, bool) {
+	start := max(startLine-1, 0)
+	end := min(endLine, len(lines))
+	var indentation string
+	found := false
+	for _, line := range lines[min(start, end):end] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndentation := leadingWhitespace(line)
+		if !found || indentWidth(lineIndentation) < indentWidth(indentation) {
+			indentation, found = lineIndentation, true
+		}
+	}
+	if found {
+		return indentation, true
+	}
+
+	for i := min(start, len(lines)) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return leadingWhitespace(lines[i]), true
+		}
+	}
+	return "", false
+}
+
+// reindent shifts the lines of text so that its least indented line starts with target, and
+// re-expresses the indentation of deeper lines in units of unit. Blank lines are emptied.
+func reindent(text, target, unit string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	// The indentation unit of the new text, so its nesting levels can be counted
+	textUnit := detectIndentUnit(lines)
+	baseLevel := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		level, _ := indentLevel(leadingWhitespace(line), textUnit)
+		if baseLevel == -1 || level < baseLevel {
+			baseLevel = level
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		whitespace := leadingWhitespace(line)
+		level, remainder := indentLevel(whitespace, textUnit)
+		lines[i] = target + strings.Repeat(unit, level-baseLevel) + strings.Repeat(" ", remainder) + line[len(whitespace):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detectIndentUnit returns "\t" if lines are indented with tabs, or else the smallest
+// indentation with spaces, between 2 and 8, defaulting to 4 spaces
+func detectIndentUnit(lines []string) string {
+	spaces := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return "\t"
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " ")); n > 0 && (spaces == 0 || n < spaces) {
+			spaces = n
+		}
 	}
+	if spaces == 0 {
+		return "    "
+	}
+	return strings.Repeat(" ", min(max(spaces, 2), 8))
+}
+
+// indentLevel counts the units in leading whitespace, with a tab counting as one level and
+// spaces counted in units of unit, and returns the spaces left over
+func indentLevel(whitespace, unit string) (int, int) {
+	if unit == "\t" {
+		tabs := strings.Count(whitespace, "\t")
+		return tabs + (len(whitespace)-tabs)/4, 0
+	}
+	width := indentWidth(whitespace)
+	return width / len(unit), width % len(unit)
+}
+
+// indentWidth is the width of leading whitespace with tabs counting as 4 columns
+func indentWidth(whitespace string) int {
+	return len(whitespace) + 3*strings.Count(whitespace, "\t")
+}
 
-	return fmt.Sprintf("Successfully applied text edits. %d lines removed, %d lines added.", linesRemovedSorted, linesAddedSorted), nil
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
 }
 
 // getRange creates a protocol.Range that covers the specified start and end lines
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnTypeFormattingTriggers(t *testing.T) {
+	tests := []struct {
+		name     string
+		edits    []TextEdit
+		triggers []string
+		expected []onTypeTrigger
+	}{
+		{
+			name:     "no trigger character",
+			edits:    []TextEdit{{StartLine: 1, EndLine: 1, NewText: "foo()"}},
+			triggers: []string{"}", ";"},
+			expected: nil,
+		},
+		{
+			name:     "single line ending in trigger",
+			edits:    []TextEdit{{StartLine: 3, EndLine: 3, NewText: "x := 1;"}},
+			triggers: []string{";"},
+			expected: []onTypeTrigger{
+				{Position: protocol.Position{Line: 2, Character: 7}, Ch: ";"},
+			},
+		},
+		{
+			name:     "multi-line block",
+			edits:    []TextEdit{{StartLine: 2, EndLine: 2, NewText: "if x {\n\ty()\n}"}},
+			triggers: []string{"}"},
+			expected: []onTypeTrigger{
+				{Position: protocol.Position{Line: 3, Character: 1}, Ch: "}"},
+			},
+		},
+		{
+			name:     "trailing newline",
+			edits:    []TextEdit{{StartLine: 1, EndLine: 1, NewText: "a\n"}},
+			triggers: []string{"\n"},
+			expected: []onTypeTrigger{
+				{Position: protocol.Position{Line: 1, Character: 0}, Ch: "\n"},
+			},
+		},
+		{
+			name: "earlier edits shift later positions",
+			edits: []TextEdit{
+				{StartLine: 10, EndLine: 10, NewText: "}"},
+				{StartLine: 1, EndLine: 1, NewText: "a\nb\nc"},
+				{StartLine: 5, EndLine: 6, NewText: ""},
+			},
+			triggers: []string{"}"},
+			expected: []onTypeTrigger{
+				// +2 lines from the first edit, -2 lines from the deletion
+				{Position: protocol.Position{Line: 9, Character: 1}, Ch: "}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, onTypeFormattingTriggers(tt.edits, tt.triggers))
+		})
+	}
+}
+
+func TestDetectFormattingOptions(t *testing.T) {
+	assert.False(t, detectFormattingOptions([]byte("func f() {\n\treturn\n}\n")).InsertSpaces)
+	assert.True(t, detectFormattingOptions([]byte("def f():\n    return\n")).InsertSpaces)
+	assert.True(t, detectFormattingOptions([]byte("x = 1\n")).InsertSpaces)
+}
+
+func TestMatchIndentation(t *testing.T) {
+	goFile := "package main\n\nfunc main() {\n\tif ok {\n\t\told()\n\t}\n\n}\n"
+	pyFile := "def main():\n  if ok:\n    old()\n\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		edit     TextEdit
+		expected string
+	}{
+		{
+			name:     "unindented text gets the replaced indentation",
+			content:  goFile,
+			edit:     TextEdit{StartLine: 5, EndLine: 5, NewText: "updated()\nagain()"},
+			expected: "\t\tupdated()\n\t\tagain()",
+		},
+		{
+			name:     "spaces are converted to tabs keeping nesting",
+			content:  goFile,
+			edit:     TextEdit{StartLine: 4, EndLine: 6, NewText: "    for {\n        step()\n\n    }\n"},
+			expected: "\tfor {\n\t\tstep()\n\n\t}\n",
+		},
+		{
+			name:     "tabs are converted to the file's spaces",
+			content:  pyFile,
+			edit:     TextEdit{StartLine: 3, EndLine: 3, NewText: "if again:\n\tnew()"},
+			expected: "    if again:\n      new()",
+		},
+		{
+			name:     "blank replaced lines use the line above",
+			content:  goFile,
+			edit:     TextEdit{StartLine: 7, EndLine: 7, NewText: "done()"},
+			expected: "\tdone()",
+		},
+		{
+			name:     "crlf in new text",
+			content:  goFile,
+			edit:     TextEdit{StartLine: 5, EndLine: 5, NewText: "a()\r\nb()"},
+			expected: "\t\ta()\n\t\tb()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTestFile(t, "main.go", tt.content)
+			edits, changed, err := MatchIndentation(filePath, []TextEdit{tt.edit})
+			require.NoError(t, err)
+			require.Len(t, edits, 1)
+			assert.Equal(t, tt.expected, edits[0].NewText)
+			assert.Equal(t, 1, changed)
+		})
+	}
+
+	// Text that already matches and deletions are left alone
+	filePath := writeTestFile(t, "main.go", goFile)
+	edits := []TextEdit{{StartLine: 5, EndLine: 5, NewText: "\t\tsame()"}, {StartLine: 7, EndLine: 7}}
+	matched, changed, err := MatchIndentation(filePath, edits)
+	require.NoError(t, err)
+	assert.Equal(t, edits, matched)
+	assert.Zero(t, changed)
+}
+
+func TestEditedContent(t *testing.T) {
+	var source strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&source, "line %d\n", i)
+	}
+
+	tests := []struct {
+		name     string
+		edits    []TextEdit
+		expected string
+	}{
+		{
+			name:     "single edit",
+			edits:    []TextEdit{{StartLine: 10, EndLine: 10, NewText: "new a\nnew b"}},
+			expected: " 7|line 7\n 8|line 8\n 9|line 9\n10|new a\n11|new b\n12|line 11\n13|line 12\n14|line 13\n",
+		},
+		{
+			name: "later edits are shifted",
+			edits: []TextEdit{
+				{StartLine: 18, EndLine: 18, NewText: "new end"},
+				{StartLine: 2, EndLine: 4, NewText: ""},
+			},
+			expected: "1|line 1\n2|line 5\n3|line 6\n4|line 7\n...\n" +
+				"12|line 15\n13|line 16\n14|line 17\n15|new end\n16|line 19\n17|line 20\n",
+		},
+		{
+			name: "nearby edits are merged",
+			edits: []TextEdit{
+				{StartLine: 5, EndLine: 5, NewText: "new 5"},
+				{StartLine: 8, EndLine: 8, NewText: "new 8"},
+			},
+			expected: " 2|line 2\n 3|line 3\n 4|line 4\n 5|new 5\n 6|line 6\n 7|line 7\n 8|new 8\n 9|line 9\n10|line 10\n11|line 11\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			filePath := writeTestFile(t, "main.txt", source.String())
+			_, err := ApplyTextEdits(t.Context(), server.Client, filePath, tt.edits)
+			require.NoError(t, err)
+
+			content, err := EditedContent(filePath, tt.edits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
@@ -55,7 +55,9 @@ func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, i
 		return "", fmt.Errorf("code lens has no command after resolution")
 	}
 
-	// Execute the command
+	// Execute the command. The server may send workspace/applyEdit requests
+	// while handling it, which the client applies before the command returns.
+	appliedBefore := client.AppliedEditCount()
 	_, err = client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
 		Command:   lens.Command.Command,
 		Arguments: lens.Command.Arguments,
@@ -64,5 +66,6 @@ func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, i
 		return "", fmt.Errorf("failed to execute code lens command: %v", err)
 	}
 
-	return fmt.Sprintf("Successfully executed code lens command: %s", lens.Command.Title), nil
+	return fmt.Sprintf("Successfully executed code lens command: %s", lens.Command.Title) +
+		formatAppliedEdits(client.AppliedEditsSince(appliedBefore)), nil
 }
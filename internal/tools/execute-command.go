@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ExecuteCommand runs a server-specific command through workspace/executeCommand, e.g.
+// gopls.tidy or rust-analyzer.reloadWorkspace. The command must be one of the commands
+// the server advertised. The raw result is returned along with a summary of any edits
+// the server applied while running the command.
+func ExecuteCommand(ctx context.Context, client *lsp.Client, command string, arguments []json.RawMessage, advertised []string) (string, error) {
+	if !slices.Contains(advertised, command) {
+		if len(advertised) == 0 {
+			return "", fmt.Errorf("command %s is not supported: the server does not advertise any commands", command)
+		}
+		sorted := slices.Clone(advertised)
+		slices.Sort(sorted)
+		return "", fmt.Errorf("command %s is not supported by the server. Available commands: %s", command, strings.Join(sorted, ", "))
+	}
+
+	appliedBefore := client.AppliedEditCount()
+	result, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+		Command:   command,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute command: %v", err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Executed command: %s\n", command))
+	if result != nil {
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to format result: %v", err)
+		}
+		output.WriteString(fmt.Sprintf("Result:\n%s\n", resultJSON))
+	} else {
+		output.WriteString("Result: null\n")
+	}
+	output.WriteString(strings.TrimPrefix(formatAppliedEdits(client.AppliedEditsSince(appliedBefore)), "\n"))
+
+	return output.String(), nil
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCommandRejectsUnadvertisedCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []string
+		expected   string
+	}{
+		{
+			name:       "no commands advertised",
+			advertised: nil,
+			expected:   "command gopls.tidy is not supported: the server does not advertise any commands",
+		},
+		{
+			name:       "lists available commands",
+			advertised: []string{"gopls.test", "gopls.generate"},
+			expected:   "command gopls.tidy is not supported by the server. Available commands: gopls.generate, gopls.test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The command is validated before the client is used
+			_, err := ExecuteCommand(context.Background(), nil, "gopls.tidy", nil, tt.advertised)
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// defaultExtractedNames are the names servers give to extracted functions and variables,
+// used to find the new symbol when there is more than one candidate
+var defaultExtractedNames = []string{
+	"newFunction", "newMethod", "newVar", "newLocal", "extracted", // gopls, typescript-language-server
+	"fun_name", "var_name", "new_var", // rust-analyzer, pyright
+}
+
+// Extract applies a refactor.extract code action to a range. If exactly one extract action
+// applies it is used; otherwise actionIndex (1-indexed) selects one and, when it is 0, the
+// available actions are listed. If newName is set the extracted symbol is renamed to it.
+func Extract(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int, actionIndex int, newName string) (string, error) {
+	if err := validateRange(filePath, startLine, startColumn, endLine, endColumn); err != nil {
+		return "", err
+	}
+
+	rng := protocol.Range{
+		Start: protocol.Position{Line: uint32(startLine - 1), Character: uint32(startColumn - 1)},
+		End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(endColumn - 1)},
+	}
+	actions, err := requestCodeActions(ctx, client, filePath, rng, protocol.RefactorExtract)
+	if err != nil {
+		return "", err
+	}
+	if len(actions) == 0 {
+		return fmt.Sprintf("No extract refactorings available for %s L%d:C%d - L%d:C%d", filePath, startLine, startColumn, endLine, endColumn), nil
+	}
+
+	if actionIndex == 0 && len(actions) > 1 {
+		var result strings.Builder
+		result.WriteString(fmt.Sprintf("%d extract refactorings are available, call extract again with one of these actions:\n\n", len(actions)))
+		for i, action := range actions {
+			result.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, action.Kind, action.Title))
+		}
+		return result.String(), nil
+	}
+	if actionIndex == 0 {
+		actionIndex = 1
+	}
+	if actionIndex < 1 || actionIndex > len(actions) {
+		return "", fmt.Errorf("action %d is out of range, there are %d extract refactorings", actionIndex, len(actions))
+	}
+	action := actions[actionIndex-1]
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	files, err := applyCodeAction(ctx, client, action)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Applied %s\n", action.Title))
+	if len(files) > 0 {
+		result.WriteString(fmt.Sprintf("Changed files: %s\n", strings.Join(files, ", ")))
+	}
+	if newName == "" {
+		return result.String(), nil
+	}
+
+	// Find the name the server chose by comparing identifiers before and after the edit
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	extractedName, offset := findExtractedName(string(before), string(after))
+	if extractedName == "" {
+		result.WriteString(fmt.Sprintf("\nCould not determine the name of the extracted symbol. Use rename_symbol to rename it to %s.\n", newName))
+		return result.String(), nil
+	}
+
+	line := strings.Count(string(after[:offset]), "\n") + 1
+	column := offset - strings.LastIndex(string(after[:offset]), "\n")
+	renameResult, err := RenameSymbol(ctx, client, filePath, line, column, newName)
+	if err != nil {
+		return "", fmt.Errorf("extracted %s but failed to rename it to %s: %v", extractedName, newName, err)
+	}
+	result.WriteString(fmt.Sprintf("\nRenamed %s to %s\n%s", extractedName, newName, renameResult))
+	return result.String(), nil
+}
+
+// findExtractedName returns the identifier introduced by an extract refactoring and the byte
+// offset of its first occurrence in after. When several identifiers are new, one of the
+// servers' default names is preferred; an empty name is returned if none can be chosen.
+func findExtractedName(before, after string) (string, int) {
+	existing := make(map[string]bool)
+	for _, ident := range scanIdentifiers(before) {
+		existing[ident.name] = true
+	}
+
+	var candidates []identifierOccurrence
+	for _, ident := range scanIdentifiers(after) {
+		if existing[ident.name] {
+			continue
+		}
+		existing[ident.name] = true
+		candidates = append(candidates, ident)
+	}
+
+	for _, candidate := range candidates {
+		if slices.Contains(defaultExtractedNames, strings.TrimRight(candidate.name, "0123456789")) {
+			return candidate.name, candidate.offset
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0].name, candidates[0].offset
+	}
+	return "", 0
+}
+
+type identifierOccurrence struct {
+	name   string
+	offset int
+}
+
+// scanIdentifiers returns every identifier in text in order of appearance
+func scanIdentifiers(text string) []identifierOccurrence {
+	var idents []identifierOccurrence
+	for i := 0; i < len(text); {
+		if !isIdentifierByte(text, i) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && isIdentifierByte(text, i) {
+			i++
+		}
+		if c := text[start]; c < '0' || c > '9' {
+			idents = append(idents, identifierOccurrence{name: text[start:i], offset: start})
+		}
+	}
+	return idents
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindExtractedName(t *testing.T) {
+	before := "func main() {\n\tx := 1 + 2\n\tfmt.Println(x)\n}\n"
+
+	tests := []struct {
+		name           string
+		after          string
+		expectedName   string
+		expectedOffset int
+	}{
+		{
+			name:           "default function name",
+			after:          "func main() {\n\tx := newFunction()\n\tfmt.Println(x)\n}\n\nfunc newFunction() int {\n\treturn 1 + 2\n}\n",
+			expectedName:   "newFunction",
+			expectedOffset: 20,
+		},
+		{
+			name:           "default name preferred over other new identifiers",
+			after:          "func main() {\n\tx := fun_name(total)\n}\n",
+			expectedName:   "fun_name",
+			expectedOffset: 20,
+		},
+		{
+			name:           "numbered default name",
+			after:          "func main() {\n\tx := newVar1\n}\n",
+			expectedName:   "newVar1",
+			expectedOffset: 20,
+		},
+		{
+			name:           "single new identifier",
+			after:          "func main() {\n\tsum := 1 + 2\n\tx := sum\n}\n",
+			expectedName:   "sum",
+			expectedOffset: 15,
+		},
+		{
+			name:  "ambiguous",
+			after: "func main() {\n\ta, b := 1, 2\n}\n",
+		},
+		{
+			name:  "nothing new",
+			after: before,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, offset := findExtractedName(before, tt.after)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedOffset, offset)
+		})
+	}
+}
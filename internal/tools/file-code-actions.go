@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetCodeActionsForFile lists the code actions the server offers for each diagnostic of a
+// file, the way an IDE's problems panel shows quick fixes. An action offered for several
+// diagnostics, e.g. a fix for all of them at once, is listed under the first only.
+func GetCodeActionsForFile(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	if err := waitForFileDiagnostics(ctx, client, filePath); err != nil {
+		return "", err
+	}
+
+	diagnostics := append([]protocol.Diagnostic(nil), client.GetFileDiagnostics(protocol.DocumentUri("file://"+filePath))...)
+	if len(diagnostics) == 0 {
+		return fmt.Sprintf("No diagnostics found for %s", filePath), nil
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Code actions for %d diagnostics in %s:\n", len(diagnostics), filePath))
+
+	seen := make(map[string]bool)
+	total := 0
+	for _, diagnostic := range diagnostics {
+		actions, err := requestCodeActionsForDiagnostics(ctx, client, filePath, diagnostic.Range, []protocol.Diagnostic{diagnostic})
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(fmt.Sprintf("\n%s at L%d:C%d: %s\n", getSeverityString(diagnostic.Severity),
+			diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1, diagnostic.Message))
+
+		listed := 0
+		for _, action := range actions {
+			key := codeActionKey(action)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			listed++
+
+			kind := "Unknown"
+			if action.Kind != "" {
+				kind = formatCodeActionKind(string(action.Kind))
+			}
+			result.WriteString(fmt.Sprintf("- [%s] %s", kind, action.Title))
+			if action.IsPreferred {
+				result.WriteString(" (preferred)")
+			}
+			result.WriteString("\n")
+		}
+		if listed == 0 {
+			result.WriteString("  No code actions\n")
+		}
+		total += listed
+	}
+
+	result.WriteString(fmt.Sprintf("\n%d code actions available.\n", total))
+	return result.String(), nil
+}
+
+// codeActionKey identifies a code action by what it does, so that the same action offered
+// for several diagnostics is recognized
+func codeActionKey(action protocol.CodeAction) string {
+	edit, _ := json.Marshal(action.Edit)
+	command, _ := json.Marshal(action.Command)
+	data, _ := json.Marshal(action.Data)
+	return strings.Join([]string{string(action.Kind), action.Title, string(edit), string(command), string(data)}, "\x00")
+}
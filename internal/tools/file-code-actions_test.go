@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCodeActionsForFile(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.SetDiagnosticPull(true)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\tx := 1\n\ty := 2\n\tz := 3\n}\n")
+
+	// Reported out of order, listed by position
+	server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": [
+		{"range": {"start": {"line": 4, "character": 1}, "end": {"line": 4, "character": 2}}, "severity": 1, "message": "declared and not used: y"},
+		{"range": {"start": {"line": 3, "character": 1}, "end": {"line": 3, "character": 2}}, "severity": 1, "message": "declared and not used: x"},
+		{"range": {"start": {"line": 5, "character": 1}, "end": {"line": 5, "character": 2}}, "severity": 2, "message": "z is odd"}
+	]}`)
+
+	fixAll := map[string]any{"title": "Remove all unused variables", "kind": "source.fixAll", "command": map[string]any{"title": "fix", "command": "gopls.fix_all"}}
+	server.Handle("textDocument/codeAction", func(params json.RawMessage) (any, error) {
+		var request protocol.CodeActionParams
+		require.NoError(t, json.Unmarshal(params, &request))
+		require.Len(t, request.Context.Diagnostics, 1)
+
+		message := request.Context.Diagnostics[0].Message
+		if !strings.HasPrefix(message, "declared and not used") {
+			return []any{}, nil
+		}
+		name := message[len(message)-1:]
+		return []any{
+			map[string]any{"title": "Remove variable " + name, "kind": "quickfix", "isPreferred": true,
+				"command": map[string]any{"title": "remove", "command": "gopls.remove", "arguments": []any{name}}},
+			fixAll,
+		}, nil
+	})
+
+	result, err := GetCodeActionsForFile(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "Code actions for 3 diagnostics in "+filePath+":\n"+
+		"\nERROR at L4:C2: declared and not used: x\n"+
+		"- [QuickFix] Remove variable x (preferred)\n"+
+		"- [Source.FixAll] Remove all unused variables\n"+
+		"\nERROR at L5:C2: declared and not used: y\n"+
+		"- [QuickFix] Remove variable y (preferred)\n"+
+		"\nWARNING at L6:C2: z is odd\n"+
+		"  No code actions\n"+
+		"\n3 code actions available.\n", result)
+	assert.Len(t, server.Received("textDocument/codeAction"), 3)
+}
+
+func TestGetCodeActionsForFileWithoutDiagnostics(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.SetDiagnosticPull(true)
+	server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": []}`)
+	filePath := writeTestFile(t, "main.go", "package main\n")
+
+	result, err := GetCodeActionsForFile(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "No diagnostics found for "+filePath, result)
+	assert.Empty(t, server.Received("textDocument/codeAction"))
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultFixAllIterations bounds how many rounds of source.fixAll actions FixAll applies
+const DefaultFixAllIterations = 5
+
+// FixAll repeatedly applies the server's source.fixAll code action to a file, re-reading
+// diagnostics after each round. It stops when no fix is offered, when the number of
+// diagnostics stops shrinking, or after maxIterations rounds.
+func FixAll(ctx context.Context, client *lsp.Client, filePath string, maxIterations int) (string, error) {
+	if maxIterations <= 0 {
+		maxIterations = DefaultFixAllIterations
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	if err := waitForFileDiagnostics(ctx, client, filePath); err != nil {
+		return "", err
+	}
+	initialCount := len(client.GetFileDiagnostics(uri))
+	count := initialCount
+
+	var fixes []string
+	stopReason := fmt.Sprintf("reached the maximum of %d iterations", maxIterations)
+	for i := 0; i < maxIterations; i++ {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
+		actions, err := requestCodeActions(ctx, client, filePath, documentRange(content), protocol.SourceFixAll)
+		if err != nil {
+			return "", err
+		}
+		if len(actions) == 0 {
+			stopReason = "no more fixes available"
+			break
+		}
+
+		action := actions[0]
+		if _, err := applyCodeAction(ctx, client, action); err != nil {
+			return "", err
+		}
+
+		if err := waitForFileDiagnostics(ctx, client, filePath); err != nil {
+			return "", err
+		}
+		newCount := len(client.GetFileDiagnostics(uri))
+		fixes = append(fixes, fmt.Sprintf("%s (diagnostics: %d -> %d)", action.Title, count, newCount))
+
+		shrunk := newCount < count
+		count = newCount
+		if count == 0 {
+			stopReason = "no diagnostics remain"
+			break
+		}
+		if !shrunk {
+			stopReason = "diagnostics stopped decreasing"
+			break
+		}
+	}
+
+	var result strings.Builder
+	if len(fixes) == 0 {
+		result.WriteString(fmt.Sprintf("No fixes available for %s\n", filePath))
+	} else {
+		result.WriteString(fmt.Sprintf("Applied %d fixes to %s:\n", len(fixes), filePath))
+		for i, fix := range fixes {
+			result.WriteString(fmt.Sprintf("%d. %s\n", i+1, fix))
+		}
+		result.WriteString(fmt.Sprintf("\nStopped: %s\n", stopReason))
+	}
+	result.WriteString(fmt.Sprintf("Diagnostics: %d before, %d remaining\n", initialCount, count))
+	return result.String(), nil
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		counts        []int // Number of diagnostics pulled before the first and after each round
+		offered       int   // Number of rounds the fix is offered in
+		maxIterations int
+		expected      string
+	}{
+		{
+			name:    "no actions",
+			counts:  []int{2},
+			offered: 0,
+			expected: "No fixes available for %s\n" +
+				"Diagnostics: 2 before, 2 remaining\n",
+		},
+		{
+			name:    "no diagnostics remain",
+			counts:  []int{3, 1, 0},
+			offered: 10,
+			expected: "Applied 2 fixes to %s:\n" +
+				"1. Fix all (diagnostics: 3 -> 1)\n" +
+				"2. Fix all (diagnostics: 1 -> 0)\n" +
+				"\nStopped: no diagnostics remain\n" +
+				"Diagnostics: 3 before, 0 remaining\n",
+		},
+		{
+			name:    "diagnostics stop decreasing",
+			counts:  []int{4, 2, 2},
+			offered: 10,
+			expected: "Applied 2 fixes to %s:\n" +
+				"1. Fix all (diagnostics: 4 -> 2)\n" +
+				"2. Fix all (diagnostics: 2 -> 2)\n" +
+				"\nStopped: diagnostics stopped decreasing\n" +
+				"Diagnostics: 4 before, 2 remaining\n",
+		},
+		{
+			name:    "no more fixes offered",
+			counts:  []int{3, 2},
+			offered: 1,
+			expected: "Applied 1 fixes to %s:\n" +
+				"1. Fix all (diagnostics: 3 -> 2)\n" +
+				"\nStopped: no more fixes available\n" +
+				"Diagnostics: 3 before, 2 remaining\n",
+		},
+		{
+			name:          "iteration cap",
+			counts:        []int{5, 4, 3, 2},
+			offered:       10,
+			maxIterations: 2,
+			expected: "Applied 2 fixes to %s:\n" +
+				"1. Fix all (diagnostics: 5 -> 4)\n" +
+				"2. Fix all (diagnostics: 4 -> 3)\n" +
+				"\nStopped: reached the maximum of 2 iterations\n" +
+				"Diagnostics: 5 before, 3 remaining\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			server.Client.SetDiagnosticPull(true)
+			filePath := writeTestFile(t, "main.go", "package main\n")
+
+			pulls := 0
+			server.Handle("textDocument/diagnostic", func(json.RawMessage) (any, error) {
+				count := tt.counts[min(pulls, len(tt.counts)-1)]
+				pulls++
+				items := make([]protocol.Diagnostic, count)
+				for i := range items {
+					items[i] = protocol.Diagnostic{Message: "unused variable"}
+				}
+				return map[string]any{"kind": "full", "items": items}, nil
+			})
+			rounds := 0
+			server.Handle("textDocument/codeAction", func(json.RawMessage) (any, error) {
+				rounds++
+				if rounds > tt.offered {
+					return []any{}, nil
+				}
+				return []protocol.CodeAction{{
+					Title:   "Fix all",
+					Kind:    protocol.SourceFixAll,
+					Command: &protocol.Command{Title: "Fix all", Command: "fixAll"},
+				}}, nil
+			})
+			server.RespondRaw("workspace/executeCommand", `null`)
+
+			result, err := FixAll(t.Context(), server.Client, filePath, tt.maxIterations)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf(tt.expected, filePath), result)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy match scores, from the most to the least likely intended symbol
+const (
+	scoreExact            = 100 // Same name
+	scoreExactUnqualified = 90  // Same name once the container is dropped, e.g. "Type.Method"
+	scoreExactFold        = 80  // Same name ignoring case
+	scorePrefix           = 60
+	scorePrefixFold       = 50
+	scoreCamelCase        = 40 // Query letters start words of the name in order, e.g. "RD" or "ReaDef"
+	scoreSubstring        = 20
+)
+
+// fuzzyScore rates how well a symbol name matches a query, so that the most likely intended
+// symbol can be listed first among many workspace/symbol results. 0 means no match.
+func fuzzyScore(query, symbolName string) int {
+	query = normalizeGoReceiver(query)
+	symbolName = normalizeGoReceiver(symbolName)
+	if query == "" {
+		return 0
+	}
+	if symbolName == query {
+		return scoreExact
+	}
+
+	score := fuzzyScoreName(query, symbolName)
+	// Rank qualified names by their last part too, unless the query is qualified itself
+	if !strings.ContainsAny(query, ".:") {
+		if i := strings.LastIndexAny(symbolName, ".:"); i >= 0 {
+			unqualified := symbolName[i+1:]
+			if unqualified == query {
+				return scoreExactUnqualified
+			}
+			score = max(score, fuzzyScoreName(query, unqualified))
+		}
+	}
+	return score
+}
+
+func fuzzyScoreName(query, name string) int {
+	switch {
+	case strings.EqualFold(name, query):
+		return scoreExactFold
+	case strings.HasPrefix(name, query):
+		return scorePrefix
+	case strings.HasPrefix(strings.ToLower(name), strings.ToLower(query)):
+		return scorePrefixFold
+	case camelCaseMatch(query, name):
+		return scoreCamelCase
+	case strings.Contains(strings.ToLower(name), strings.ToLower(query)):
+		return scoreSubstring
+	}
+	return 0
+}
+
+// camelCaseMatch reports whether the query letters appear in name in order, where each run of
+// letters starts a word of name: its first letter, an upper case letter following a lower
+// case one or ending an acronym, or a letter following '_' or a digit. "RDO" and "ReadDefOpt" both match
+// "ReadDefinitionOptions".
+func camelCaseMatch(query, name string) bool {
+	q := []rune(query)
+	n := []rune(name)
+
+	var match func(qi, ni int) bool
+	match = func(qi, ni int) bool {
+		if qi == len(q) {
+			return true
+		}
+		for i := ni; i < len(n); i++ {
+			if !isWordStart(n, i) || !equalFoldRune(q[qi], n[i]) {
+				continue
+			}
+			// Extend the match within this word as far as the query allows, backtracking to
+			// shorter runs if the rest of the query doesn't match further on
+			run := 1
+			for qi+run < len(q) && i+run < len(n) && !isWordStart(n, i+run) && equalFoldRune(q[qi+run], n[i+run]) {
+				run++
+			}
+			for ; run >= 1; run-- {
+				if match(qi+run, i+run) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return match(0, 0)
+}
+
+func isWordStart(name []rune, i int) bool {
+	if i == 0 {
+		return unicode.IsLetter(name[0]) || name[0] == '_'
+	}
+	prev, cur := name[i-1], name[i]
+	if !unicode.IsLetter(cur) && !unicode.IsDigit(cur) {
+		return false
+	}
+	// The last capital of an acronym starts the next word, e.g. the S in "HTTPServer"
+	acronymEnd := unicode.IsUpper(cur) && unicode.IsUpper(prev) && i+1 < len(name) && unicode.IsLower(name[i+1])
+	return unicode.IsUpper(cur) && !unicode.IsUpper(prev) || acronymEnd ||
+		!unicode.IsLetter(prev) && !unicode.IsDigit(prev) ||
+		unicode.IsDigit(prev) != unicode.IsDigit(cur)
+}
+
+func equalFoldRune(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query      string
+		symbolName string
+		expected   int
+	}{
+		{"Foo", "Foo", scoreExact},
+		{"(*Server).Start", "Server.Start", scoreExact},
+		{"Start", "(*Server).Start", scoreExactUnqualified},
+		{"method", "TestClass::method", scoreExactUnqualified},
+		{"foo", "Foo", scoreExactFold},
+		{"Read", "ReadDefinition", scorePrefix},
+		{"Start", "Server.StartAll", scorePrefix},
+		{"read", "ReadDefinition", scorePrefixFold},
+		{"RDO", "ReadDefinitionOptions", scoreCamelCase},
+		{"ReaDefOpt", "ReadDefinitionOptions", scoreCamelCase},
+		{"hs", "HTTPServer", scoreCamelCase},
+		{"gv", "get_value", scoreCamelCase},
+		{"Definition", "ReadDefinition", scoreCamelCase},
+		{"efin", "ReadDefinition", scoreSubstring},
+		{"adDef", "ReadDefinition", scoreSubstring},
+		{"Bar", "Foo", 0},
+		{"RDX", "ReadDefinition", 0},
+		{"", "Foo", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s in %s", tt.query, tt.symbolName), func(t *testing.T) {
+			assert.Equal(t, tt.expected, fuzzyScore(tt.query, tt.symbolName))
+		})
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	names := []string{"ConfigReader", "reader", "ReadConfig", "Reader", "ReaderOptions", "RetryAfterDelay", "Other"}
+	sort.SliceStable(names, func(i, j int) bool {
+		return fuzzyScore("Reader", names[i]) > fuzzyScore("Reader", names[j])
+	})
+	require.Equal(t, []string{"Reader", "reader", "ReaderOptions", "ConfigReader", "ReadConfig", "RetryAfterDelay", "Other"}, names)
+}
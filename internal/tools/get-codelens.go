@@ -1,10 +1,13 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
@@ -42,6 +45,17 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 	output.WriteString(fmt.Sprintf("Code Lens results for %s:\n\n", filePath))
 
 	for i, lens := range codeLensResult {
+		// Lenses may be returned without a command, which must then be resolved
+		// before we can tell what executing them would do
+		if lens.Command == nil {
+			resolvedLens, err := client.ResolveCodeLens(ctx, lens)
+			if err != nil {
+				toolsLogger.Warn("failed to resolve code lens: %v", err)
+			} else {
+				lens = resolvedLens
+			}
+		}
+
 		output.WriteString(fmt.Sprintf("[%d] Location: Lines %d-%d\n",
 			i+1,
 			lens.Range.Start.Line+1,
@@ -52,12 +66,14 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 			if lens.Command.Command != "" {
 				output.WriteString(fmt.Sprintf("    Command: %s\n", lens.Command.Command))
 			}
-			if lens.Command.Arguments != nil {
+			if len(lens.Command.Arguments) > 0 {
 				output.WriteString("    Arguments:\n")
 				for _, arg := range lens.Command.Arguments {
-					output.WriteString(fmt.Sprintf("%s\n", arg))
+					output.WriteString(fmt.Sprintf("      - %s\n", formatCommandArgument(arg)))
 				}
 			}
+		} else {
+			output.WriteString("    (unresolved: no command available)\n")
 		}
 
 		// Print any custom data that might help identify the provider
@@ -76,3 +92,23 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 
 	return output.String(), nil
 }
+
+// maxCommandArgumentLength is the length at which command arguments are cut off in listings
+const maxCommandArgumentLength = 200
+
+// formatCommandArgument renders a command argument as compact JSON, shortened if it is very long
+func formatCommandArgument(arg json.RawMessage) string {
+	var compacted bytes.Buffer
+	text := string(arg)
+	if err := json.Compact(&compacted, arg); err == nil {
+		text = compacted.String()
+	}
+	if len(text) > maxCommandArgumentLength {
+		cut := maxCommandArgumentLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCommandArgument(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		expected string
+	}{
+		{
+			name:     "compacts JSON",
+			arg:      "{\n  \"uri\": \"file:///a.go\",\n  \"tests\": [\"TestA\"]\n}",
+			expected: `{"uri":"file:///a.go","tests":["TestA"]}`,
+		},
+		{
+			name:     "keeps invalid JSON as is",
+			arg:      "{not json",
+			expected: "{not json",
+		},
+		{
+			name:     "short string",
+			arg:      `"gopls.test"`,
+			expected: `"gopls.test"`,
+		},
+		{
+			name:     "cuts off long arguments",
+			arg:      `"` + strings.Repeat("a", 300) + `"`,
+			expected: `"` + strings.Repeat("a", 199) + "...",
+		},
+		{
+			name: "does not cut a multi-byte character in half",
+			// The 200th byte is the second byte of an é
+			arg:      `"` + strings.Repeat("a", 198) + strings.Repeat("é", 10) + `"`,
+			expected: `"` + strings.Repeat("a", 198) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatCommandArgument(json.RawMessage(tt.arg)))
+		})
+	}
+}
+
+func TestGetCodeLensResolvesLensesWithoutCommand(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main_test.go", "package main\n\nfunc TestA(t *testing.T) {}\n\nfunc TestB(t *testing.T) {}\n")
+
+	lensRange := func(line uint32) protocol.Range {
+		return protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line, Character: 4}}
+	}
+	server.Respond("textDocument/codeLens", []protocol.CodeLens{
+		{
+			Range:   lensRange(0),
+			Command: &protocol.Command{Title: "run file benchmarks", Command: "gopls.run_tests"},
+		},
+		{Range: lensRange(2), Data: "TestA"},
+		{Range: lensRange(4), Data: "TestB"},
+	})
+	server.Handle("codeLens/resolve", func(params json.RawMessage) (any, error) {
+		var lens protocol.CodeLens
+		require.NoError(t, json.Unmarshal(params, &lens))
+		if lens.Data != "TestA" {
+			return nil, errors.New("nothing to resolve")
+		}
+		lens.Command = &protocol.Command{
+			Title:     "run test",
+			Command:   "gopls.run_tests",
+			Arguments: []json.RawMessage{json.RawMessage(`{"tests": ["TestA"]}`)},
+		}
+		return lens, nil
+	})
+
+	result, err := GetCodeLens(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "[1] Location: Lines 1-1\n    Title: run file benchmarks\n    Command: gopls.run_tests\n\n")
+	assert.Contains(t, result, "[2] Location: Lines 3-3\n    Title: run test\n    Command: gopls.run_tests\n    Arguments:\n      - {\"tests\":[\"TestA\"]}\n")
+	assert.Contains(t, result, "[3] Location: Lines 5-5\n    (unresolved: no command available)\n")
+	assert.Contains(t, result, "Found 3 code lens items.\n")
+
+	// Only the lenses without a command are resolved
+	assert.Len(t, server.Received("codeLens/resolve"), 2)
+}
@@ -3,14 +3,44 @@ package tools
 import (
 	"context"
 	"fmt"
+	"html"
+	"regexp"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// Hover output formats
+const (
+	// HoverFormatRaw returns the hover contents as sent by the server, usually markdown
+	HoverFormatRaw = "raw"
+	// HoverFormatPlaintext strips code fences and escapes from markdown hover contents
+	HoverFormatPlaintext = "plaintext"
+)
+
+// HoverOptions controls how GetHoverInfoWithOptions formats its output
+type HoverOptions struct {
+	// Format is HoverFormatRaw (the default if empty) or HoverFormatPlaintext
+	Format string
+	// IncludeRange prefixes the output with the range of the token the hover applies to,
+	// if the server reports one, so it can be passed on to range-taking tools
+	IncludeRange bool
+}
+
 // GetHoverInfo retrieves hover information (type, documentation) for a symbol at the specified position
 func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	return GetHoverInfoWithOptions(ctx, client, filePath, line, column, HoverOptions{})
+}
+
+func GetHoverInfoWithOptions(ctx context.Context, client *lsp.Client, filePath string, line, column int, opts HoverOptions) (string, error) {
+	if opts.Format != "" && opts.Format != HoverFormatRaw && opts.Format != HoverFormatPlaintext {
+		return "", fmt.Errorf("format must be '%s' or '%s', got: %s", HoverFormatRaw, HoverFormatPlaintext, opts.Format)
+	}
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
@@ -31,7 +61,9 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 	params.Position = position
 
 	// Execute the hover request
-	hoverResult, err := client.Hover(ctx, params)
+	hoverResult, err := lsp.RetryDocumentRequest(ctx, client, filePath,
+		func(r protocol.Hover) bool { return r.Contents.Value == "" },
+		func() (protocol.Hover, error) { return client.Hover(ctx, params) })
 	if err != nil {
 		return "", fmt.Errorf("failed to get hover information: %v", err)
 	}
@@ -59,8 +91,39 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 		}
 		result.WriteString(fmt.Sprintf("No hover information available for this position on the following line:\n%s", lineText))
 	} else {
-		result.WriteString(hoverResult.Contents.Value)
+		if opts.IncludeRange && hoverResult.Range != (protocol.Range{}) {
+			result.WriteString(fmt.Sprintf("Range: L%d:C%d - L%d:C%d\n\n",
+				hoverResult.Range.Start.Line+1, hoverResult.Range.Start.Character+1,
+				hoverResult.Range.End.Line+1, hoverResult.Range.End.Character+1))
+		}
+		if opts.Format == HoverFormatPlaintext {
+			result.WriteString(hoverToPlainText(hoverResult.Contents))
+		} else {
+			result.WriteString(hoverResult.Contents.Value)
+		}
 	}
 
 	return result.String(), nil
 }
+
+var (
+	// codeFencePattern matches markdown code fence lines such as "```go"
+	codeFencePattern = regexp.MustCompile("(?m)^[ \t]*(```|~~~).*$\n?")
+	// markdownEscapePattern matches backslash escapes of markdown punctuation such as "\_"
+	markdownEscapePattern = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!<>|~])")
+	// blankLinesPattern matches runs of more than one blank line
+	blankLinesPattern = regexp.MustCompile(`\n[ \t]*(\n[ \t]*){2,}`)
+)
+
+// hoverToPlainText converts markdown hover contents to plain text by removing code fences,
+// markdown escapes and HTML entities, and collapsing runs of blank lines
+func hoverToPlainText(contents protocol.MarkupContent) string {
+	text := strings.ReplaceAll(contents.Value, "\r\n", "\n")
+	if contents.Kind == protocol.Markdown {
+		text = codeFencePattern.ReplaceAllString(text, "")
+		text = markdownEscapePattern.ReplaceAllString(text, "$1")
+		text = html.UnescapeString(text)
+	}
+	text = blankLinesPattern.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
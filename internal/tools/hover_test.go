@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoverToPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents protocol.MarkupContent
+		expected string
+	}{
+		{
+			name: "gopls markdown",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "```go\nfunc HelperFunction() string\n```\n\nHelperFunction returns a \\*greeting\\* \\_string\\_.\n\n\n\n[`main.HelperFunction` on pkg.go.dev](https://pkg.go.dev/main#HelperFunction)",
+			},
+			expected: "func HelperFunction() string\n\nHelperFunction returns a *greeting* _string_.\n\n[`main.HelperFunction` on pkg.go.dev](https://pkg.go.dev/main#HelperFunction)",
+		},
+		{
+			name: "html entities",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "~~~cpp\nstd::vector&lt;int&gt; values\n~~~\nReturns a &quot;copy&quot; &amp; more",
+			},
+			expected: "std::vector<int> values\nReturns a \"copy\" & more",
+		},
+		{
+			name: "plaintext is left alone apart from blank lines",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.PlainText,
+				Value: "def area(self) -> float\n\n\n\nReturns \\*the area\\* &amp; nothing else\n",
+			},
+			expected: "def area(self) -> float\n\nReturns \\*the area\\* &amp; nothing else",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hoverToPlainText(tt.contents))
+		})
+	}
+}
+
+func TestGetHoverInfoResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      string // Raw JSON result, empty to answer with method not found
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "markup content",
+			result:   `{"contents": {"kind": "markdown", "value": "func Foo() int"}}`,
+			expected: "func Foo() int",
+		},
+		{
+			name:     "markup content with range",
+			result:   `{"contents": {"kind": "plaintext", "value": "x int"}, "range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}}`,
+			expected: "x int",
+		},
+		{
+			name:     "marked string",
+			result:   `{"contents": {"language": "go", "value": "var x int"}}`,
+			expected: "```go\nvar x int\n```",
+		},
+		{
+			name:     "null result",
+			result:   `null`,
+			expected: "No hover information available for this position on the following line:\nvar x = 1\n",
+		},
+		{
+			name:        "method not found",
+			expectedErr: "method not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			if tt.result != "" {
+				server.RespondRaw("textDocument/hover", tt.result)
+			}
+			filePath := writeTestFile(t, "main.go", "var x = 1\n")
+
+			result, err := GetHoverInfo(t.Context(), server.Client, filePath, 1, 5)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Len(t, server.Received("textDocument/didOpen"), 1)
+		})
+	}
+}
+
+func TestGetHoverInfoIncludeRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected string
+	}{
+		{
+			name:     "range reported",
+			result:   `{"contents": {"kind": "plaintext", "value": "x int"}, "range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}}`,
+			expected: "Range: L1:C5 - L1:C6\n\nx int",
+		},
+		{
+			name:     "no range reported",
+			result:   `{"contents": {"kind": "plaintext", "value": "x int"}}`,
+			expected: "x int",
+		},
+		{
+			name:     "null result",
+			result:   `null`,
+			expected: "No hover information available for this position on the following line:\nvar x = 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			server.RespondRaw("textDocument/hover", tt.result)
+			filePath := writeTestFile(t, "main.go", "var x = 1\n")
+
+			result, err := GetHoverInfoWithOptions(t.Context(), server.Client, filePath, 1, 5, HoverOptions{IncludeRange: true})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
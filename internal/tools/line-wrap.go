@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// minWrapWidth is the least room for text a wrapped line must leave after its indentation,
+// lines indented deeper than that are left as they are
+const minWrapWidth = 20
+
+// MaxLineWidth returns the width the results of document_symbols, signature_help and
+// definition are wrapped at, set with LSP_MAX_LINE_WIDTH, or 0 if they are not wrapped
+func MaxLineWidth() int {
+	if value := os.Getenv("LSP_MAX_LINE_WIDTH"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// wrapPrefixPattern matches the start of a line that its continuation lines are indented
+// by: the line number of a source line, indentation, symbol tree guides and markers
+var wrapPrefixPattern = regexp.MustCompile(`^(?:\s*\d+\|)?[\s│├└─▶•]*`)
+
+// continuationPrefix replaces the branches, markers and line number in the prefix of a
+// wrapped line, so the continuation lines line up under its text and the tree stays intact
+var continuationPrefix = strings.NewReplacer("├", "│", "└", " ", "─", " ", "▶", " ", "•", " ",
+	"0", " ", "1", " ", "2", " ", "3", " ", "4", " ", "5", " ", "6", " ", "7", " ", "8", " ", "9", " ")
+
+// wrapLines soft wraps the lines of text longer than width characters at spaces. Continuation
+// lines keep the indentation of the line they continue. Words longer than a line are not
+// broken. A width of 0 leaves text unchanged.
+func wrapLines(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	var result strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		if utf8.RuneCountInString(line) <= width {
+			result.WriteString(line)
+			continue
+		}
+		result.WriteString(wrapLine(line, width))
+	}
+	return result.String()
+}
+
+func wrapLine(line string, width int) string {
+	prefix := wrapPrefixPattern.FindString(line)
+	indent := continuationPrefix.Replace(prefix)
+	if width-utf8.RuneCountInString(indent) < minWrapWidth {
+		return line
+	}
+
+	var result strings.Builder
+	current := prefix
+	currentWidth := utf8.RuneCountInString(prefix)
+	empty := true // Whether current has no words yet
+	for _, word := range strings.Split(line[len(prefix):], " ") {
+		wordWidth := utf8.RuneCountInString(word)
+		if !empty && currentWidth+1+wordWidth > width {
+			result.WriteString(current)
+			result.WriteString("\n")
+			current, currentWidth, empty = indent, utf8.RuneCountInString(indent), true
+		}
+		if empty && word == "" {
+			continue // Spaces at a break are dropped
+		}
+		if !empty {
+			current += " "
+			currentWidth++
+		}
+		current += word
+		currentWidth += wordWidth
+		empty = false
+	}
+	result.WriteString(current)
+	return result.String()
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		width    int
+		expected string
+	}{
+		{
+			name:     "no wrapping by default",
+			text:     "func Process(ctx context.Context, items []Item, opts ProcessOptions) (Result, error)",
+			width:    0,
+			expected: "func Process(ctx context.Context, items []Item, opts ProcessOptions) (Result, error)",
+		},
+		{
+			name:  "signature",
+			text:  "▶ func Process(ctx context.Context, items []Item, opts ProcessOptions) (Result, error)\nshort line",
+			width: 40,
+			expected: "▶ func Process(ctx context.Context,\n" +
+				"  items []Item, opts ProcessOptions)\n" +
+				"  (Result, error)\n" +
+				"short line",
+		},
+		{
+			name:  "symbol tree",
+			text:  "│   ├── Method Process (func(ctx context.Context, items []Item) error) [3:1-9:2]",
+			width: 50,
+			expected: "│   ├── Method Process (func(ctx context.Context,\n" +
+				"│   │   items []Item) error) [3:1-9:2]",
+		},
+		{
+			name:  "last child of a symbol tree",
+			text:  "└── Function Process (func(ctx context.Context, items []Item) error)",
+			width: 40,
+			expected: "└── Function Process (func(ctx\n" +
+				"    context.Context, items []Item)\n" +
+				"    error)",
+		},
+		{
+			name:  "numbered source line",
+			text:  " 9|\treturn process(ctx, items, ProcessOptions{Workers: 4})",
+			width: 36,
+			expected: " 9|\treturn process(ctx, items,\n" +
+				"  |\tProcessOptions{Workers: 4})",
+		},
+		{
+			name:     "long words are not broken",
+			text:     "Symbol: a_very_long_identifier_that_does_not_fit_on_one_line",
+			width:    30,
+			expected: "Symbol:\na_very_long_identifier_that_does_not_fit_on_one_line",
+		},
+		{
+			name:     "too deeply indented to wrap",
+			text:     "│   │   │   │   ├── Field name (string) [1:1-1:12]",
+			width:    30,
+			expected: "│   │   │   │   ├── Field name (string) [1:1-1:12]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, wrapLines(tt.text, tt.width))
+		})
+	}
+}
+
+func TestReadDefinitionWrapsLines(t *testing.T) {
+	t.Setenv("LSP_MAX_LINE_WIDTH", "40")
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "process.go", "package main\n\nfunc Process(ctx context.Context, items []Item, opts ProcessOptions) error {\n\treturn nil\n}\n")
+	server.RespondRaw("workspace/symbol", `[{"name": "Process", "kind": 12, "location": {"uri": "file://`+filePath+`", "range": {"start": {"line": 2, "character": 0}, "end": {"line": 4, "character": 1}}}}]`)
+	server.RespondRaw("textDocument/definition", `[{"uri": "file://`+filePath+`", "range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 12}}}]`)
+	server.RespondRaw("textDocument/documentSymbol", `[{"name": "Process", "kind": 12, "range": {"start": {"line": 2, "character": 0}, "end": {"line": 4, "character": 1}}, "selectionRange": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 12}}}]`)
+
+	result, err := ReadDefinition(t.Context(), server.Client, "Process")
+	require.NoError(t, err)
+	assert.Contains(t, result, "3|func Process(ctx context.Context,\n |items []Item, opts ProcessOptions)\n |error {\n")
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// clangdExpansionMarker separates the macro definition from its expansion in clangd hovers
+const clangdExpansionMarker = "// Expands to"
+
+// GetMacroExpansion returns the definition and expansion of the C/C++ macro invocation at a
+// position, from clangd's hover. clangd 15 and later include the expansion of the invocation
+// under the cursor, earlier versions only the definition.
+func GetMacroExpansion(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	params := protocol.HoverParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+			Position:     protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)},
+		},
+	}
+	hoverResult, err := lsp.RetryDocumentRequest(ctx, client, filePath,
+		func(r protocol.Hover) bool { return r.Contents.Value == "" },
+		func() (protocol.Hover, error) { return client.Hover(ctx, params) })
+	if err != nil {
+		return "", fmt.Errorf("failed to get hover information: %v", err)
+	}
+
+	name, definition, expansion, ok := parseMacroHover(hoverToPlainText(hoverResult.Contents))
+	if !ok {
+		return fmt.Sprintf("No macro at L%d:C%d", line, column), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Macro: %s\n", name))
+	if definition != "" {
+		result.WriteString(fmt.Sprintf("\nDefinition:\n%s\n", definition))
+	}
+	if expansion != "" {
+		result.WriteString(fmt.Sprintf("\nExpansion:\n%s\n", expansion))
+	} else {
+		result.WriteString("\nThe server did not report an expansion. clangd 15 or later expands macro invocations; the definition of the macro itself has none.\n")
+	}
+	return result.String(), nil
+}
+
+// parseMacroHover splits a clangd macro hover, as plain text, into the macro name, its
+// #define and the expansion that follows "// Expands to". ok is false if the hover is not
+// about a macro.
+func parseMacroHover(text string) (name, definition, expansion string, ok bool) {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 0 {
+		return "", "", "", false
+	}
+
+	// The title is "macro FOO", or "### macro `FOO`" in markdown
+	title := strings.Trim(strings.TrimSpace(strings.TrimLeft(lines[0], "# ")), "`")
+	name, ok = strings.CutPrefix(title, "macro ")
+	if !ok {
+		return "", "", "", false
+	}
+	name = strings.Trim(strings.TrimSpace(name), "`")
+
+	var definitionLines, expansionLines []string
+	inDefinition, inExpansion := false, false
+	for _, l := range lines[1:] {
+		switch {
+		case strings.TrimSpace(l) == clangdExpansionMarker:
+			inDefinition, inExpansion = false, true
+		case inExpansion:
+			expansionLines = append(expansionLines, l)
+		case strings.HasPrefix(strings.TrimSpace(l), "#define"):
+			inDefinition = true
+			definitionLines = append(definitionLines, l)
+		case inDefinition:
+			definitionLines = append(definitionLines, l)
+		}
+	}
+
+	definition = strings.TrimSpace(strings.Join(definitionLines, "\n"))
+	expansion = strings.TrimSpace(strings.Join(expansionLines, "\n"))
+	return name, definition, expansion, true
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMacroExpansion(t *testing.T) {
+	const source = "#define SQUARE(x) ((x) * (x))\nint nine = SQUARE(3);\n"
+
+	tests := []struct {
+		name     string
+		contents protocol.MarkupContent
+		expected string
+	}{
+		{
+			name: "markdown hover with expansion",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "### macro `SQUARE`  \n\n---\n```cpp\n#define SQUARE(x) ((x) * (x))\n\n// Expands to\n((3) * (3))\n```",
+			},
+			expected: "Macro: SQUARE\n\nDefinition:\n#define SQUARE(x) ((x) * (x))\n\nExpansion:\n((3) * (3))\n",
+		},
+		{
+			name: "plaintext hover with expansion",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.PlainText,
+				Value: "macro SQUARE\n\n#define SQUARE(x) ((x) * (x))\n\n// Expands to\n((3) * (3))",
+			},
+			expected: "Macro: SQUARE\n\nDefinition:\n#define SQUARE(x) ((x) * (x))\n\nExpansion:\n((3) * (3))\n",
+		},
+		{
+			name: "hover without expansion",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "### macro `SQUARE`  \n\n---\n```cpp\n#define SQUARE(x) ((x) * (x))\n```",
+			},
+			expected: "Macro: SQUARE\n\nDefinition:\n#define SQUARE(x) ((x) * (x))\n\nThe server did not report an expansion.",
+		},
+		{
+			name: "not a macro",
+			contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "### variable `nine`  \n\n---\nType: `int`",
+			},
+			expected: "No macro at L2:C12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			filePath := writeTestFile(t, "main.c", source)
+			server.Respond("textDocument/hover", protocol.Hover{Contents: tt.contents})
+
+			result, err := GetMacroExpansion(t.Context(), server.Client, filePath, 2, 12)
+			require.NoError(t, err)
+			assert.Contains(t, result, tt.expected)
+
+			requests := server.Received("textDocument/hover")
+			require.Len(t, requests, 1)
+			var params protocol.HoverParams
+			require.NoError(t, json.Unmarshal(requests[0], &params))
+			assert.Equal(t, protocol.Position{Line: 1, Character: 11}, params.Position)
+		})
+	}
+}
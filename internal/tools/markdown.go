@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output formats of the tools that can render their results as markdown
+const (
+	// OutputFormatText is plain text, the default
+	OutputFormatText = "text"
+	// OutputFormatMarkdown puts source in fenced code blocks with a language hint and lists
+	// in tables, for clients that render markdown
+	OutputFormatMarkdown = "markdown"
+)
+
+// ValidateOutputFormat checks that format is one of the output formats, or empty for text
+func ValidateOutputFormat(format string) error {
+	if format != "" && format != OutputFormatText && format != OutputFormatMarkdown {
+		return fmt.Errorf("format must be '%s' or '%s', got: %s", OutputFormatText, OutputFormatMarkdown, format)
+	}
+	return nil
+}
+
+// codeFence wraps text in a fenced code block whose info string is the language of
+// filePath, see SnippetLanguage. The fence is longer than any run of backticks in text.
+func codeFence(text, filePath string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s%s\n%s\n%s\n", fence, SnippetLanguage(filePath), strings.TrimSuffix(text, "\n"), fence)
+}
+
+// markdownCode renders text as inline code, using a longer delimiter if it contains backticks
+func markdownCode(text string) string {
+	delimiter := "`"
+	for strings.Contains(text, delimiter) {
+		delimiter += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return delimiter + " " + text + " " + delimiter
+	}
+	return delimiter + text + delimiter
+}
+
+// markdownTableRow renders a table row, escaping the characters that would break it
+func markdownTableRow(cells ...string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		escaped[i] = strings.Join(strings.Fields(cell), " ")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeFence(t *testing.T) {
+	assert.Equal(t, "```go\nfunc main() {}\n```\n", codeFence("func main() {}\n", "/src/main.go"))
+	assert.Equal(t, "```\nplain\n```\n", codeFence("plain", ""))
+	// Fences in the text don't end the block
+	assert.Equal(t, "````markdown\n```go\nx\n```\n````\n", codeFence("```go\nx\n```", "README.md"))
+}
+
+func TestMarkdownCode(t *testing.T) {
+	assert.Equal(t, "`Process`", markdownCode("Process"))
+	assert.Equal(t, "``a `b` c``", markdownCode("a `b` c"))
+	assert.Equal(t, "`` `raw` ``", markdownCode("`raw`"))
+}
+
+func TestMarkdownTableRow(t *testing.T) {
+	assert.Equal(t, "| ERROR | a \\| b spans lines |\n", markdownTableRow("ERROR", "a | b\nspans   lines"))
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	const source = "package main\n\n// Process handles items\nfunc Process(items []string) error {\n\treturn nil\n}\n"
+
+	t.Run("definition", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+		server.RespondRaw("workspace/symbol", `[{"name": "Process", "kind": 12, "location": {"uri": "file://`+filePath+`", "range": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 12}}}}]`)
+		server.RespondRaw("textDocument/definition", `{"uri": "file://`+filePath+`", "range": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 12}}}`)
+		server.RespondRaw("textDocument/documentSymbol", `[{"name": "Process", "kind": 12, "range": {"start": {"line": 3, "character": 0}, "end": {"line": 5, "character": 1}}, "selectionRange": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 12}}}]`)
+
+		result, err := ReadDefinitionWithOptions(t.Context(), server.Client, "Process", DefinitionOptions{ContextLines: 1, Format: OutputFormatMarkdown})
+		require.NoError(t, err)
+		assert.Equal(t, "### `Process`\n\n"+
+			"- **File:** `"+filePath+"`\n"+
+			"- **Kind:** Function\n"+
+			"- **Range:** L4:C1 - L6:C2\n"+
+			"- **Lines:** 3-6\n\n"+
+			"```go\n// Process handles items\nfunc Process(items []string) error {\n\treturn nil\n}\n```\n\n", result)
+	})
+
+	t.Run("document symbols", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+		server.RespondRaw("textDocument/documentSymbol", `[{"name": "Process", "kind": 12, "detail": "func(items []string) error", "range": {"start": {"line": 3, "character": 0}, "end": {"line": 5, "character": 1}}, "selectionRange": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 12}}}]`)
+
+		result, err := GetDocumentSymbols(t.Context(), server.Client, filePath, DocumentSymbolsOptions{Format: OutputFormatMarkdown})
+		require.NoError(t, err)
+		assert.Equal(t, "### Document symbols of `"+filePath+"`\n\n"+
+			"```\nFunction Process (func(items []string) error) [4:1-6:2]\n```\n", result)
+	})
+
+	t.Run("diagnostics", func(t *testing.T) {
+		server := newMockServer(t)
+		server.Client.SetDiagnosticPull(true)
+		filePath := writeTestFile(t, "main.go", source)
+		server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": [
+			{"range": {"start": {"line": 3, "character": 13}, "end": {"line": 3, "character": 18}}, "severity": 2, "source": "unusedparams", "message": "unused parameter: items", "tags": [1]}
+		]}`)
+
+		result, err := GetDiagnosticsForFileWithOptions(t.Context(), server.Client, filePath, DiagnosticsOptions{Format: OutputFormatMarkdown})
+		require.NoError(t, err)
+		assert.Equal(t, "### Diagnostics of `"+filePath+"`\n\n"+
+			"| Severity | Location | Message | Source | Code |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| WARNING | L4:C14 | unused parameter: items [unnecessary] | unusedparams |  |\n\n"+
+			"```go\n4|func Process(items []string) error {\n```\n", result)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+
+		_, err := GetDocumentSymbols(t.Context(), server.Client, filePath, DocumentSymbolsOptions{Format: "html"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "format must be 'text' or 'markdown'")
+	})
+}
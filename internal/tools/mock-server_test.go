@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+)
+
+// newMockServer starts a mock language server for a test. Retries are disabled so that
+// scripted empty results are returned as is.
+func newMockServer(t *testing.T) *lsptesting.MockServer {
+	t.Helper()
+	server := lsptesting.NewMockServer()
+	server.Client.SetRequestRetries(0)
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+// writeTestFile writes content to name in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
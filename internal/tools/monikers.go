@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetMonikers returns the stable, cross-repository identifiers of the symbol at the given position
+func GetMonikers(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	params := protocol.MonikerParams{}
+	params.TextDocument = protocol.TextDocumentIdentifier{
+		URI: protocol.DocumentUri("file://" + filePath),
+	}
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	params.Position = protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(column - 1),
+	}
+
+	monikers, err := client.Moniker(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to get monikers: %v", err)
+	}
+
+	if len(monikers) == 0 {
+		return fmt.Sprintf("No monikers found at %s:%d:%d", filePath, line, column), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Monikers at %s:%d:%d:\n\n", filePath, line, column))
+	for i, moniker := range monikers {
+		result.WriteString(fmt.Sprintf("%d. %s:%s\n", i+1, moniker.Scheme, moniker.Identifier))
+		result.WriteString(fmt.Sprintf("   Unique: %s\n", moniker.Unique))
+		if moniker.Kind != nil {
+			result.WriteString(fmt.Sprintf("   Kind: %s\n", *moniker.Kind))
+		}
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// OrganizeImports applies the server's source.organizeImports code action to a file and
+// summarizes the import lines that were added and removed
+func OrganizeImports(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	actions, err := requestCodeActions(ctx, client, filePath, documentRange(before), protocol.SourceOrganizeImports)
+	if err != nil {
+		return "", err
+	}
+	if len(actions) == 0 {
+		return fmt.Sprintf("Imports in %s are already organized", filePath), nil
+	}
+
+	// Prefer the action the server marks as preferred
+	action := actions[0]
+	for _, a := range actions {
+		if a.IsPreferred {
+			action = a
+			break
+		}
+	}
+
+	if _, err := applyCodeAction(ctx, client, action); err != nil {
+		return "", err
+	}
+
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	added, removed := diffLines(string(before), string(after))
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Organized imports in %s\n", filePath))
+	if len(added) == 0 && len(removed) == 0 {
+		if string(before) == string(after) {
+			result.WriteString("No changes were needed\n")
+		} else {
+			result.WriteString("Imports were reordered\n")
+		}
+		return result.String(), nil
+	}
+	if len(added) > 0 {
+		result.WriteString("\nAdded:\n")
+		for _, line := range added {
+			result.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+	}
+	if len(removed) > 0 {
+		result.WriteString("\nRemoved:\n")
+		for _, line := range removed {
+			result.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+	}
+	return result.String(), nil
+}
+
+// diffLines returns the trimmed, non-blank lines of after that are not in before and the
+// lines of before that are not in after, ignoring changes in order
+func diffLines(before, after string) (added, removed []string) {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(before, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			counts[line]++
+		}
+	}
+	for _, line := range strings.Split(after, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range strings.Split(before, "\n") {
+		if line = strings.TrimSpace(line); line != "" && counts[line] > 0 {
+			counts[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "import (\n\t\"os\"\n\t\"fmt\"\n\t\"os\"\n)\n"
+	after := "import (\n\t\"fmt\"\n\t\"strings\"\n\t\"os\"\n)\n"
+
+	added, removed := diffLines(before, after)
+	assert.Equal(t, []string{`"strings"`}, added)
+	assert.Equal(t, []string{`"os"`}, removed)
+
+	// Reordering is not reported as a change
+	added, removed = diffLines("a\nb\n", "b\na\n")
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
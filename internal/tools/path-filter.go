@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathFilter selects files by glob patterns with the syntax of diagnostics_glob, relative to
+// Root unless absolute. A pattern also matches every file below a directory it matches, so
+// "vendor" excludes everything in the vendor directory. Files must match one of Include, if
+// any are given, and none of Exclude.
+type PathFilter struct {
+	Root    string
+	Include []string
+	Exclude []string
+}
+
+// Validate checks the syntax of the patterns
+func (f PathFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		for _, p := range expandBraces(filepath.ToSlash(pattern)) {
+			if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %s: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the filter accepts every file
+func (f PathFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match reports whether the filter accepts the absolute filePath
+func (f PathFilter) Match(filePath string) bool {
+	if len(f.Include) > 0 && !f.matchAny(f.Include, filePath) {
+		return false
+	}
+	return !f.matchAny(f.Exclude, filePath)
+}
+
+func (f PathFilter) matchAny(patterns []string, filePath string) bool {
+	absolute := filepath.ToSlash(filePath)
+	relative := ""
+	if rel, err := filepath.Rel(f.Root, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+		relative = filepath.ToSlash(rel)
+	}
+
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		name := relative
+		if path.IsAbs(pattern) {
+			name = absolute
+		} else if name == "" {
+			continue
+		}
+		for _, p := range expandBraces(strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")) {
+			if matchGlob(p, name) || matchGlob(p+"/**", name) {
+				return true
+			}
+		}
+	}
+	return false
+}
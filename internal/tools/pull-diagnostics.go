@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// PullDiagnostics requests diagnostics for a file with textDocument/diagnostic and stores
+// them in the client's diagnostics cache. The resultId of the previous report is sent along,
+// so an "unchanged" report reuses the cached diagnostics. Full reports for related
+// documents are cached as well.
+func PullDiagnostics(ctx context.Context, client *lsp.Client, filePath string) ([]protocol.Diagnostic, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	// The report describes the content the server had when the request was sent
+	version := client.DocumentVersion(uri)
+
+	report, err := client.Diagnostic(ctx, protocol.DocumentDiagnosticParams{
+		TextDocument:     protocol.TextDocumentIdentifier{URI: uri},
+		PreviousResultID: client.DiagnosticResultID(uri),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull diagnostics: %v", err)
+	}
+
+	// Unchanged reports also decode as RelatedFullDocumentDiagnosticReport, so use the kind
+	full, ok := report.Value.(protocol.RelatedFullDocumentDiagnosticReport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected diagnostic report type %T", report.Value)
+	}
+
+	var diagnostics []protocol.Diagnostic
+	switch full.Kind {
+	case string(protocol.DiagnosticUnchanged):
+		diagnostics = client.GetFileDiagnostics(uri)
+	case string(protocol.DiagnosticFull):
+		diagnostics = full.Items
+	default:
+		return nil, fmt.Errorf("unexpected diagnostic report kind %q", full.Kind)
+	}
+	client.StorePulledDiagnostics(uri, version, full.ResultID, diagnostics)
+
+	for relatedURI, value := range full.RelatedDocuments {
+		storeRelatedDiagnostics(client, relatedURI, value)
+	}
+
+	return diagnostics, nil
+}
+
+// storeRelatedDiagnostics caches a full report for a related document. Unchanged reports
+// leave the cache as is.
+func storeRelatedDiagnostics(client *lsp.Client, uri protocol.DocumentUri, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		toolsLogger.Warn("Failed to encode related diagnostics for %s: %v", uri, err)
+		return
+	}
+	var related protocol.FullDocumentDiagnosticReport
+	if err := json.Unmarshal(data, &related); err != nil {
+		toolsLogger.Warn("Failed to decode related diagnostics for %s: %v", uri, err)
+		return
+	}
+	if related.Kind != string(protocol.DiagnosticFull) {
+		return
+	}
+	client.StorePulledDiagnostics(uri, 0, related.ResultID, related.Items)
+}
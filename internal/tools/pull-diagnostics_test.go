@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullDiagnosticsFullThenUnchanged(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "var x = 1\n")
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	calls := 0
+	server.Handle("textDocument/diagnostic", func(json.RawMessage) (any, error) {
+		calls++
+		if calls == 1 {
+			return json.RawMessage(`{"kind": "full", "resultId": "1", "items": [
+				{"range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}, "severity": 1, "message": "x declared and not used"}
+			]}`), nil
+		}
+		return json.RawMessage(`{"kind": "unchanged", "resultId": "1"}`), nil
+	})
+
+	diagnostics, err := PullDiagnostics(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "x declared and not used", diagnostics[0].Message)
+	assert.Equal(t, diagnostics, server.Client.GetFileDiagnostics(uri))
+
+	// The second request sends the previous resultId and reuses the cached diagnostics
+	diagnostics, err = PullDiagnostics(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "x declared and not used", diagnostics[0].Message)
+
+	requests := server.Received("textDocument/diagnostic")
+	require.Len(t, requests, 2)
+	var params protocol.DocumentDiagnosticParams
+	require.NoError(t, json.Unmarshal(requests[0], &params))
+	assert.Empty(t, params.PreviousResultID)
+	require.NoError(t, json.Unmarshal(requests[1], &params))
+	assert.Equal(t, "1", params.PreviousResultID)
+
+	upToDate, _, _ := server.Client.DiagnosticsUpToDate(uri)
+	assert.True(t, upToDate)
+}
+
+func TestPullDiagnosticsRelatedDocuments(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "var x = 1\n")
+	related := protocol.DocumentUri("file:///workspace/other.go")
+	unchanged := protocol.DocumentUri("file:///workspace/unchanged.go")
+
+	server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": [], "relatedDocuments": {
+		"file:///workspace/other.go": {"kind": "full", "resultId": "7", "items": [
+			{"range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 3}}, "message": "undefined: y"}
+		]},
+		"file:///workspace/unchanged.go": {"kind": "unchanged", "resultId": "3"}
+	}}`)
+
+	diagnostics, err := PullDiagnostics(t.Context(), server.Client, filePath)
+	require.NoError(t, err)
+	assert.Empty(t, diagnostics)
+
+	relatedDiagnostics := server.Client.GetFileDiagnostics(related)
+	require.Len(t, relatedDiagnostics, 1)
+	assert.Equal(t, "undefined: y", relatedDiagnostics[0].Message)
+	assert.Equal(t, "7", server.Client.DiagnosticResultID(related))
+	assert.Empty(t, server.Client.DiagnosticResultID(unchanged))
+}
+
+func TestGetDiagnosticsForFilePull(t *testing.T) {
+	tests := []struct {
+		name     string
+		pull     bool
+		result   string // Raw JSON result, empty to answer with method not found
+		expected string
+	}{
+		{
+			name:     "pull full report",
+			pull:     true,
+			result:   `{"kind": "full", "resultId": "1", "items": [{"range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}, "severity": 1, "message": "x declared and not used"}]}`,
+			expected: "ERROR at L1:C5: x declared and not used",
+		},
+		{
+			name:     "diagnostic tags",
+			pull:     true,
+			result:   `{"kind": "full", "items": [{"range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}, "severity": 4, "message": "x is unused", "tags": [1]}]}`,
+			expected: "HINT at L1:C5: x is unused [unnecessary]",
+		},
+		{
+			name:     "pull error falls back to published diagnostics",
+			pull:     true,
+			expected: "No diagnostics found for",
+		},
+		{
+			name:     "push only server is not asked",
+			pull:     false,
+			result:   `{"kind": "full", "items": [{"range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}, "message": "should not be pulled"}]}`,
+			expected: "No diagnostics found for",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LSP_DIAGNOSTICS_TIMEOUT", "10ms")
+			server := newMockServer(t)
+			server.Client.SetDiagnosticPull(tt.pull)
+			if tt.result != "" {
+				server.RespondRaw("textDocument/diagnostic", tt.result)
+			}
+			filePath := writeTestFile(t, "main.go", "var x = 1\n")
+
+			result, err := GetDiagnosticsForFile(t.Context(), server.Client, filePath, 0, false)
+			require.NoError(t, err)
+			assert.Contains(t, result, tt.expected)
+			if !tt.pull {
+				assert.Empty(t, server.Received("textDocument/diagnostic"))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadFileRange returns lines startLine to endLine (1-indexed, inclusive) of a file with line
+// numbers, e.g. to follow up on a location reported by references or diagnostics. endLine is
+// clamped to the end of the file; 0 reads to the end.
+func ReadFileRange(filePath string, startLine, endLine int) (string, error) {
+	return ReadFileRangeAs(filePath, startLine, endLine, OutputFormatText)
+}
+
+// ReadFileRangeAs is ReadFileRange in the given output format. The header names the
+// language of the file, which the markdown format also tags the code block with.
+func ReadFileRangeAs(filePath string, startLine, endLine int, format string) (string, error) {
+	if err := ValidateOutputFormat(format); err != nil {
+		return "", err
+	}
+	if startLine < 1 {
+		return "", fmt.Errorf("startLine must be at least 1, got %d", startLine)
+	}
+	if endLine != 0 && endLine < startLine {
+		return "", fmt.Errorf("endLine %d is before startLine %d", endLine, startLine)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	// Don't count the empty string after a trailing newline as a line
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if startLine > len(lines) {
+		return "", fmt.Errorf("startLine %d is beyond the end of %s (%d lines)", startLine, filePath, len(lines))
+	}
+	if endLine == 0 || endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	header := fmt.Sprintf("lines %d-%d of %d", startLine, endLine, len(lines))
+	if language := SnippetLanguage(filePath); language != "" {
+		header += fmt.Sprintf(" (%s)", language)
+	}
+	numbered := addLineNumbers(strings.Join(lines[startLine-1:endLine], "\n"), startLine)
+	if format == OutputFormatMarkdown {
+		return fmt.Sprintf("### %s: %s\n\n", markdownCode(filePath), header) + codeFence(numbered, filePath), nil
+	}
+	return fmt.Sprintf("%s: %s\n\n%s", filePath, header, numbered), nil
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFileRange(t *testing.T) {
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc main() {\n\tprintln(1)\n}\n")
+
+	tests := []struct {
+		name        string
+		startLine   int
+		endLine     int
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:      "middle of file",
+			startLine: 3,
+			endLine:   4,
+			expected:  filePath + ": lines 3-4 of 5 (go)\n\n3|func main() {\n4|\tprintln(1)\n",
+		},
+		{
+			name:      "end clamped to file length",
+			startLine: 5,
+			endLine:   100,
+			expected:  filePath + ": lines 5-5 of 5 (go)\n\n5|}\n",
+		},
+		{
+			name:      "zero end reads to end of file",
+			startLine: 4,
+			expected:  filePath + ": lines 4-5 of 5 (go)\n\n4|\tprintln(1)\n5|}\n",
+		},
+		{
+			name:        "start beyond end of file",
+			startLine:   6,
+			endLine:     7,
+			expectedErr: "beyond the end",
+		},
+		{
+			name:        "end before start",
+			startLine:   3,
+			endLine:     2,
+			expectedErr: "is before startLine",
+		},
+		{
+			name:        "start not positive",
+			startLine:   0,
+			endLine:     2,
+			expectedErr: "startLine must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadFileRange(filePath, tt.startLine, tt.endLine)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestReadFileRangeAs(t *testing.T) {
+	filePath := writeTestFile(t, "main.py", "def main():\n    print(1)\n")
+
+	result, err := ReadFileRangeAs(filePath, 1, 2, OutputFormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, "### `"+filePath+"`: lines 1-2 of 2 (python)\n\n```python\n1|def main():\n2|    print(1)\n```\n", result)
+
+	// Files of unknown languages have no hint
+	filePath = writeTestFile(t, "notes.txt", "hello\n")
+	result, err = ReadFileRangeAs(filePath, 1, 1, OutputFormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, "### `"+filePath+"`: lines 1-1 of 1\n\n```\n1|hello\n```\n", result)
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ListRefactorings returns the refactor code actions available at a position. Servers only
+// offer most refactorings for a range, so the innermost selection range containing the
+// position, usually the identifier or expression there, is used. Servers without selection
+// ranges are asked for an empty range at the position.
+func ListRefactorings(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	position := protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)}
+	rng := refactoringRange(ctx, client, filePath, position)
+	actions, err := requestCodeActions(ctx, client, filePath, rng, protocol.Refactor)
+	if err != nil {
+		return "", err
+	}
+
+	rangeText := fmt.Sprintf("L%d:C%d - L%d:C%d", rng.Start.Line+1, rng.Start.Character+1, rng.End.Line+1, rng.End.Character+1)
+	if len(actions) == 0 {
+		return fmt.Sprintf("No refactorings available for %s %s\n", filePath, rangeText), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Refactorings for %s %s (%d available):\n\n", filePath, rangeText, len(actions)))
+	for i, action := range actions {
+		result.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, action.Kind, action.Title))
+		if action.Command != nil {
+			result.WriteString(fmt.Sprintf("   Command: %s\n", action.Command.Command))
+		}
+	}
+	return result.String(), nil
+}
+
+// refactoringRange returns the innermost selection range containing position, or an empty
+// range at position if the server has none
+func refactoringRange(ctx context.Context, client *lsp.Client, filePath string, position protocol.Position) protocol.Range {
+	empty := protocol.Range{Start: position, End: position}
+	if !lsp.HasSelectionRangeSupport(client.ServerCapabilities()) {
+		return empty
+	}
+
+	selections, err := client.SelectionRange(ctx, protocol.SelectionRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+		Positions:    []protocol.Position{position},
+	})
+	if err != nil {
+		toolsLogger.Debug("No selection range at %s L%d:C%d, using an empty range: %v", filePath, position.Line+1, position.Character+1, err)
+		return empty
+	}
+	if len(selections) == 0 {
+		return empty
+	}
+	return selections[0].Range
+}
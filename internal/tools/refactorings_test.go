@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRefactorings(t *testing.T) {
+	const source = "package main\n\nfunc main() {\n\ttotal := compute(1, 2)\n\tprintln(total)\n}\n"
+
+	// The range the code actions were requested for
+	codeActionRange := func(t *testing.T, server *lsptesting.MockServer) protocol.Range {
+		received := server.Received("textDocument/codeAction")
+		require.Len(t, received, 1)
+		var params protocol.CodeActionParams
+		require.NoError(t, json.Unmarshal(received[0], &params))
+		assert.Equal(t, []protocol.CodeActionKind{protocol.Refactor}, params.Context.Only)
+		return params.Range
+	}
+
+	t.Run("innermost selection range", func(t *testing.T) {
+		server := newMockServer(t)
+		server.Client.SetServerCapabilities(&protocol.ServerCapabilities{
+			SelectionRangeProvider: &protocol.Or_ServerCapabilities_selectionRangeProvider{Value: true},
+		})
+		filePath := writeTestFile(t, "main.go", source)
+		server.RespondRaw("textDocument/selectionRange", `[{
+			"range": {"start": {"line": 3, "character": 10}, "end": {"line": 3, "character": 23}},
+			"parent": {"range": {"start": {"line": 3, "character": 1}, "end": {"line": 3, "character": 23}}}
+		}]`)
+		server.RespondRaw("textDocument/codeAction", `[
+			{"title": "Extract variable", "kind": "refactor.extract.variable", "edit": {}},
+			{"title": "Inline call to compute", "kind": "refactor.inline.call", "command": {"title": "Inline", "command": "gopls.apply_fix"}},
+			{"title": "Remove unused parameter", "kind": "quickfix", "edit": {}},
+			{"title": "Extract function", "kind": "refactor.extract.function", "disabled": {"reason": "not a statement"}}
+		]`)
+
+		result, err := ListRefactorings(t.Context(), server.Client, filePath, 4, 12)
+		require.NoError(t, err)
+		assert.Equal(t, "Refactorings for "+filePath+" L4:C11 - L4:C24 (2 available):\n\n"+
+			"1. [refactor.extract.variable] Extract variable\n"+
+			"2. [refactor.inline.call] Inline call to compute\n"+
+			"   Command: gopls.apply_fix\n", result)
+		assert.Equal(t, protocol.Range{
+			Start: protocol.Position{Line: 3, Character: 10},
+			End:   protocol.Position{Line: 3, Character: 23},
+		}, codeActionRange(t, server))
+	})
+
+	t.Run("empty range without selection ranges", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+		server.RespondRaw("textDocument/codeAction", `[]`)
+
+		result, err := ListRefactorings(t.Context(), server.Client, filePath, 4, 12)
+		require.NoError(t, err)
+		assert.Equal(t, "No refactorings available for "+filePath+" L4:C12 - L4:C12\n", result)
+		assert.Empty(t, server.Received("textDocument/selectionRange"))
+		position := protocol.Position{Line: 3, Character: 11}
+		assert.Equal(t, protocol.Range{Start: position, End: position}, codeActionRange(t, server))
+	})
+
+	t.Run("position outside the file", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "main.go", source)
+
+		_, err := ListRefactorings(t.Context(), server.Client, filePath, 40, 1)
+		require.Error(t, err)
+	})
+}
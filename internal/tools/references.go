@@ -10,32 +10,125 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
+// DefaultMaxReferences is the number of references FindReferences lists by default, set
+// LSP_MAX_REFERENCES to change it
+const DefaultMaxReferences = 500
+
+// MaxReferences returns the number of references FindReferences lists at most
+func MaxReferences() int {
+	if value := os.Getenv("LSP_MAX_REFERENCES"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxReferences
+}
+
 func FindReferences(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
-	// Get context lines from environment variable
-	contextLines := 5
-	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
-		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
-			contextLines = val
+	return FindReferencesPage(ctx, client, symbolName, PathFilter{}, 0, MaxReferences())
+}
+
+// FindReferencesPage lists the references of a symbol in the files filter accepts. At most
+// max references are listed (0 for no limit), after skipping the first skip in order of file
+// and position. If there are more, a warning with the total number of references explains
+// how to narrow the search or get the next page.
+func FindReferencesPage(ctx context.Context, client *lsp.Client, symbolName string, filter PathFilter, skip, max int) (string, error) {
+	refs, err := collectReferences(ctx, client, symbolName, 0)
+	if err != nil {
+		return "", err
+	}
+
+	filteredOut := 0
+	if !filter.Empty() {
+		var kept []protocol.Location
+		for _, ref := range refs {
+			if filter.Match(ref.URI.Path()) {
+				kept = append(kept, ref)
+			}
+		}
+		filteredOut = len(refs) - len(kept)
+		refs = kept
+	}
+	filteredNote := ""
+	if filteredOut > 0 {
+		filteredNote = fmt.Sprintf(" (%d references in other paths were filtered out)", filteredOut)
+	}
+
+	if len(refs) == 0 {
+		return fmt.Sprintf("No references found for symbol: %s%s", symbolName, filteredNote), nil
+	}
+
+	total := len(refs)
+	if skip >= total {
+		return fmt.Sprintf("Found %d references for symbol: %s, none left after skipping %d.%s", total, symbolName, skip, filteredNote), nil
+	}
+	end := total
+	if max > 0 {
+		end = min(skip+max, total)
+	}
+
+	result := formatReferences(ctx, client, refs[skip:end])
+	if skip > 0 || end < total {
+		toolsLogger.Info("Listing references %d-%d of %d for %s", skip+1, end, total, symbolName)
+		result += fmt.Sprintf("\nShowing references %d-%d of %d.", skip+1, end, total)
+		if end < total {
+			result += fmt.Sprintf(" Narrow the search, e.g. with includePaths or excludePaths, or pass skip=%d to see the next ones.", end)
 		}
+		result += "\n"
+	}
+	if filteredOut > 0 {
+		result += fmt.Sprintf("\n%d references in other paths were filtered out.\n", filteredOut)
 	}
+	return result, nil
+}
 
+// FindReferencesWithLimit is FindReferences, but stops once limit references were found (0
+// for no limit). Servers that stream references are not waited for after that, so the total
+// number of references is unknown.
+func FindReferencesWithLimit(ctx context.Context, client *lsp.Client, symbolName string, limit int) (string, error) {
+	refs, err := collectReferences(ctx, client, symbolName, limit)
+	if err != nil {
+		return "", err
+	}
+	if len(refs) == 0 {
+		return fmt.Sprintf("No references found for symbol: %s", symbolName), nil
+	}
+
+	result := formatReferences(ctx, client, refs)
+	if limit > 0 && len(refs) >= limit {
+		result += fmt.Sprintf("\nStopped after the first %d references, there may be more.\n", limit)
+	}
+	return result, nil
+}
+
+// collectReferences returns the unique references of the symbols named symbolName, sorted by
+// file and position. With a limit, it stops once that many were found.
+func collectReferences(ctx context.Context, client *lsp.Client, symbolName string, limit int) ([]protocol.Location, error) {
 	// First get the symbol location like ReadDefinition does
-	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
+	symbolResult, err := client.CachedSymbol(ctx, protocol.WorkspaceSymbolParams{
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return nil, fmt.Errorf("failed to parse results: %v", err)
 	}
 
-	var allReferences []string
+	var allRefs []protocol.Location
+	// Some servers report the declaration and a reference at the same location, and several
+	// matching symbols can share references, so each location is only listed once
+	seenLocations := make(map[string]bool)
 	for _, symbol := range results {
+		if limit > 0 && len(allRefs) >= limit {
+			break
+		}
+
 		// Handle different matching strategies based on the search term
 		if strings.Contains(symbolName, ".") {
 			// For qualified names like "Type.Method", check for various matches
@@ -72,80 +165,130 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 			toolsLogger.Error("Error opening file: %v", err)
 			continue
 		}
-		refs, err := client.References(ctx, refsParams)
+		found := len(allRefs)
+		refs, err := lsp.RetryDocumentRequest(ctx, client, loc.URI.Path(),
+			func(r []protocol.Location) bool { return len(r) == 0 },
+			func() ([]protocol.Location, error) {
+				received := 0
+				return client.ReferencesWithPartialResults(ctx, refsParams, func(batch []protocol.Location) bool {
+					received += len(batch)
+					return limit <= 0 || found+received < limit
+				})
+			})
 		if err != nil {
-			return "", fmt.Errorf("failed to get references: %v", err)
+			return nil, fmt.Errorf("failed to get references: %v", err)
 		}
-
-		// Group references by file
-		refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
-		for _, ref := range refs {
-			refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+		refs = uniqueLocations(refs, seenLocations)
+		if limit > 0 && found+len(refs) > limit {
+			refs = refs[:limit-found]
 		}
+		allRefs = append(allRefs, refs...)
+	}
 
-		// Get sorted list of URIs
-		uris := make([]string, 0, len(refsByFile))
-		for uri := range refsByFile {
-			uris = append(uris, string(uri))
+	sort.SliceStable(allRefs, func(i, j int) bool {
+		a, b := allRefs[i], allRefs[j]
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line < b.Range.Start.Line
 		}
-		sort.Strings(uris)
+		return a.Range.Start.Character < b.Range.Start.Character
+	})
+	return allRefs, nil
+}
 
-		// Process each file's references in sorted order
-		for _, uriStr := range uris {
-			uri := protocol.DocumentUri(uriStr)
-			fileRefs := refsByFile[uri]
-			filePath := strings.TrimPrefix(uriStr, "file://")
+// formatReferences lists sorted references grouped by file, with the surrounding lines
+func formatReferences(ctx context.Context, client *lsp.Client, refs []protocol.Location) string {
+	// Get context lines from environment variable
+	contextLines := 5
+	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
+		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
+			contextLines = val
+		}
+	}
 
-			// Format file header
-			fileInfo := fmt.Sprintf("---\n\n%s\nReferences in File: %d\n",
-				filePath,
-				len(fileRefs),
-			)
+	// Group references by file, keeping the files in order
+	var uris []protocol.DocumentUri
+	refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
+	for _, ref := range refs {
+		if _, ok := refsByFile[ref.URI]; !ok {
+			uris = append(uris, ref.URI)
+		}
+		refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+	}
 
-			// Format locations with context
-			fileContent, err := os.ReadFile(filePath)
-			if err != nil {
-				// Log error but continue with other files
-				allReferences = append(allReferences, fileInfo+"\nError reading file: "+err.Error())
-				continue
-			}
+	var allReferences []string
+	for _, uri := range uris {
+		fileRefs := refsByFile[uri]
+		filePath := utilities.URIToPath(uri)
 
-			lines := strings.Split(string(fileContent), "\n")
+		// Format file header
+		fileInfo := fmt.Sprintf("---\n\n%s\nReferences in File: %d\n",
+			filePath,
+			len(fileRefs),
+		)
 
-			// Track reference locations for header display
-			var locStrings []string
-			for _, ref := range fileRefs {
-				locStr := fmt.Sprintf("L%d:C%d",
-					ref.Range.Start.Line+1,
-					ref.Range.Start.Character+1)
-				locStrings = append(locStrings, locStr)
-			}
+		// Format locations with context
+		fileContent, err := os.ReadFile(filePath)
+		if err != nil {
+			// Log error but continue with other files
+			allReferences = append(allReferences, fileInfo+"\nError reading file: "+err.Error())
+			continue
+		}
 
-			// Collect lines to display using the utility function
-			linesToShow, err := GetLineRangesToDisplay(ctx, client, fileRefs, len(lines), contextLines)
-			if err != nil {
-				// Log error but continue with other files
-				continue
-			}
+		lines := strings.Split(string(fileContent), "\n")
 
-			// Convert to line ranges using the utility function
-			lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+		// Collect lines to display using the utility function
+		linesToShow, err := GetLineRangesToDisplay(ctx, client, fileRefs, len(lines), contextLines)
+		if err != nil {
+			// Log error but continue with other files
+			continue
+		}
 
-			// Format with locations in header
-			formattedOutput := fileInfo
-			if len(locStrings) > 0 {
-				formattedOutput += "At: " + strings.Join(locStrings, ", ") + "\n"
-			}
+		// Convert to line ranges using the utility function
+		lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
 
-			// Format the content with ranges
-			formattedOutput += "\n" + FormatLinesWithRanges(lines, lineRanges)
-			allReferences = append(allReferences, formattedOutput)
+		// Format with locations in header
+		formattedOutput := fileInfo
+		if len(fileRefs) > 0 {
+			formattedOutput += "At: " + formatReferenceLocations(fileRefs) + "\n"
 		}
+
+		// Format the content with ranges
+		formattedOutput += "\n" + FormatLinesWithRanges(lines, lineRanges)
+		allReferences = append(allReferences, formattedOutput)
 	}
+	return strings.Join(allReferences, "\n")
+}
 
-	if len(allReferences) == 0 {
-		return fmt.Sprintf("No references found for symbol: %s", symbolName), nil
+// uniqueLocations returns the locations whose URI and range are not in seen yet, adding them
+func uniqueLocations(locations []protocol.Location, seen map[string]bool) []protocol.Location {
+	var unique []protocol.Location
+	for _, location := range locations {
+		key := fmt.Sprintf("%s:%d:%d-%d:%d", location.URI,
+			location.Range.Start.Line, location.Range.Start.Character,
+			location.Range.End.Line, location.Range.End.Character)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, location)
 	}
+	return unique
+}
 
-	return strings.Join(allReferences, "\n"), nil
+// formatReferenceLocations lists sorted references as "L6:C2, L10:C2", merging references
+// on the same line into one entry with several columns, e.g. "L6:C2,C14"
+func formatReferenceLocations(refs []protocol.Location) string {
+	var entries []string
+	for i, ref := range refs {
+		column := fmt.Sprintf("C%d", ref.Range.Start.Character+1)
+		if i > 0 && refs[i-1].Range.Start.Line == ref.Range.Start.Line {
+			entries[len(entries)-1] += "," + column
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("L%d:%s", ref.Range.Start.Line+1, column))
+	}
+	return strings.Join(entries, ", ")
 }
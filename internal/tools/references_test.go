@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamReferences makes the mock server report each batch of references to the line numbers
+// (0-indexed) of uri as a partial result, then answer with an empty result
+func streamReferences(t *testing.T, server *lsptesting.MockServer, uri string, batches ...[]int) {
+	server.Handle("textDocument/references", func(params json.RawMessage) (any, error) {
+		var request struct {
+			PartialResultToken string `json:"partialResultToken"`
+		}
+		require.NoError(t, json.Unmarshal(params, &request))
+		require.NotEmpty(t, request.PartialResultToken)
+
+		for _, lines := range batches {
+			locations := make([]map[string]any, len(lines))
+			for i, line := range lines {
+				position := map[string]any{"line": line, "character": 1}
+				locations[i] = map[string]any{"uri": uri, "range": map[string]any{"start": position, "end": position}}
+			}
+			if err := server.Notify("$/progress", map[string]any{"token": request.PartialResultToken, "value": locations}); err != nil {
+				return nil, err
+			}
+		}
+		return []any{}, nil
+	})
+}
+
+func TestFindReferencesPartialResults(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.RegisterOrderedNotificationHandler("$/progress",
+		func(params json.RawMessage) { lsp.HandleProgress(server.Client, params) })
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc Foo() {}\n\nfunc a() {\n\tFoo()\n}\n\nfunc b() {\n\tFoo()\n}\n")
+	uri := "file://" + filePath
+
+	// The symbol is streamed as well, with an empty final result
+	server.Handle("workspace/symbol", func(params json.RawMessage) (any, error) {
+		var request struct {
+			PartialResultToken string `json:"partialResultToken"`
+		}
+		require.NoError(t, json.Unmarshal(params, &request))
+		symbols := json.RawMessage(fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}}}]`, uri))
+		if err := server.Notify("$/progress", map[string]any{"token": request.PartialResultToken, "value": symbols}); err != nil {
+			return nil, err
+		}
+		return []any{}, nil
+	})
+	server.RespondRaw("textDocument/documentSymbol", `[]`)
+	streamReferences(t, server, uri, []int{5}, []int{9})
+
+	result, err := FindReferences(t.Context(), server.Client, "Foo")
+	require.NoError(t, err)
+	assert.Contains(t, result, "References in File: 2")
+	assert.Contains(t, result, "At: L6:C2, L10:C2")
+
+	// With a limit, the references after the first batch are not waited for
+	result, err = FindReferencesWithLimit(t.Context(), server.Client, "Foo", 1)
+	require.NoError(t, err)
+	assert.Contains(t, result, "References in File: 1")
+	assert.Contains(t, result, "At: L6:C2\n")
+	assert.Contains(t, result, "Stopped after the first 1 references, there may be more.")
+}
+
+func TestFindReferencesDeduplicates(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc Foo() int { return 1 }\n\nfunc a() {\n\t_ = Foo() + Foo()\n}\n")
+	uri := "file://" + filePath
+	location := func(line, start, end int) string {
+		return fmt.Sprintf(`{"uri": "%s", "range": {"start": {"line": %d, "character": %d}, "end": {"line": %d, "character": %d}}}`,
+			uri, line, start, line, end)
+	}
+
+	// Foo is matched twice, and the declaration is also reported as a reference
+	symbol := fmt.Sprintf(`{"name": "Foo", "kind": 12, "location": %s}`, location(2, 5, 8))
+	server.RespondRaw("workspace/symbol", "["+symbol+","+symbol+"]")
+	server.RespondRaw("textDocument/documentSymbol", `[]`)
+	server.RespondRaw("textDocument/references", "["+strings.Join([]string{
+		location(5, 13, 16), location(2, 5, 8), location(5, 5, 8), location(2, 5, 8),
+	}, ",")+"]")
+
+	result, err := FindReferences(t.Context(), server.Client, "Foo")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(result, "References in File: 3\n"), result)
+	assert.Contains(t, result, "At: L3:C6, L6:C6,C14\n")
+}
+
+func TestFindReferencesPage(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n\nfunc Foo() {}\n\nfunc a() {\n\tFoo()\n\tFoo()\n\tFoo()\n}\n")
+	uri := "file://" + filePath
+	location := func(line int) string {
+		return fmt.Sprintf(`{"uri": "%s", "range": {"start": {"line": %d, "character": 1}, "end": {"line": %d, "character": 4}}}`, uri, line, line)
+	}
+	server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}}}]`, uri))
+	server.RespondRaw("textDocument/documentSymbol", `[]`)
+	server.RespondRaw("textDocument/references", "["+location(7)+","+location(5)+","+location(6)+"]")
+
+	result, err := FindReferencesPage(t.Context(), server.Client, "Foo", PathFilter{}, 0, 2)
+	require.NoError(t, err)
+	assert.Contains(t, result, "At: L6:C2, L7:C2\n")
+	assert.Contains(t, result, "\nShowing references 1-2 of 3. Narrow the search, e.g. with includePaths or excludePaths, or pass skip=2 to see the next ones.\n")
+
+	result, err = FindReferencesPage(t.Context(), server.Client, "Foo", PathFilter{}, 2, 2)
+	require.NoError(t, err)
+	assert.Contains(t, result, "At: L8:C2\n")
+	assert.Contains(t, result, "\nShowing references 3-3 of 3.\n")
+
+	result, err = FindReferencesPage(t.Context(), server.Client, "Foo", PathFilter{}, 0, 3)
+	require.NoError(t, err)
+	assert.Contains(t, result, "At: L6:C2, L7:C2, L8:C2\n")
+	assert.NotContains(t, result, "Showing references")
+
+	result, err = FindReferencesPage(t.Context(), server.Client, "Foo", PathFilter{}, 5, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Found 3 references for symbol: Foo, none left after skipping 5.", result)
+}
+
+func TestFindReferencesPathFilter(t *testing.T) {
+	server := newMockServer(t)
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n\nfunc Foo() {}\n",
+		"internal/a.go":        "package internal\n\nvar _ = Foo\n",
+		"internal/a_test.go":   "package internal\n\nvar _ = Foo\n",
+		"vendor/lib/lib.go":    "package lib\n\nvar _ = Foo\n",
+		"cmd/tool/tool.go":     "package main\n\nvar _ = Foo\n",
+		"cmd/tool/vendor/x.go": "package x\n\nvar _ = Foo\n",
+	}
+	var locations []string
+	for name, content := range files {
+		filePath := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+		if name != "main.go" {
+			locations = append(locations, fmt.Sprintf(`{"uri": "file://%s", "range": {"start": {"line": 2, "character": 8}, "end": {"line": 2, "character": 11}}}`, filePath))
+		}
+	}
+	server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "file://%s", "range": {"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}}}]`, filepath.Join(root, "main.go")))
+	server.RespondRaw("textDocument/documentSymbol", `[]`)
+	server.RespondRaw("textDocument/references", "["+strings.Join(locations, ",")+"]")
+
+	tests := []struct {
+		name     string
+		filter   PathFilter
+		expected []string
+		note     string
+	}{
+		{
+			name:     "exclude directories and tests",
+			filter:   PathFilter{Exclude: []string{"vendor", "**/vendor/**", "**/*_test.go"}},
+			expected: []string{"internal/a.go", "cmd/tool/tool.go"},
+			note:     "3 references in other paths were filtered out",
+		},
+		{
+			name:     "include with braces",
+			filter:   PathFilter{Include: []string{"{internal,cmd}/*.go"}},
+			expected: []string{"internal/a.go", "internal/a_test.go"},
+			note:     "3 references in other paths were filtered out",
+		},
+		{
+			name:     "include and exclude",
+			filter:   PathFilter{Include: []string{"cmd/"}, Exclude: []string{filepath.Join(root, "cmd/tool/vendor")}},
+			expected: []string{"cmd/tool/tool.go"},
+			note:     "4 references in other paths were filtered out",
+		},
+		{
+			name:   "nothing left",
+			filter: PathFilter{Include: []string{"docs"}},
+			note:   "No references found for symbol: Foo (5 references in other paths were filtered out)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Root = root
+			require.NoError(t, tt.filter.Validate())
+			result, err := FindReferencesPage(t.Context(), server.Client, "Foo", tt.filter, 0, 0)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.expected), strings.Count(result, "References in File:"), result)
+			for _, name := range tt.expected {
+				assert.Contains(t, result, filepath.Join(root, name)+"\n")
+			}
+			assert.Contains(t, result, tt.note)
+		})
+	}
+
+	assert.Error(t, PathFilter{Include: []string{"[a-"}}.Validate())
+}
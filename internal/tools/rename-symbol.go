@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -14,35 +15,24 @@ import (
 // RenameSymbol renames a symbol (variable, function, class, etc.) at the specified position
 // It uses the LSP rename functionality to handle all references across files
 func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string) (string, error) {
-	// Open the file if not already open
-	err := client.OpenFile(ctx, filePath)
-	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
-	}
+	return RenameSymbolWithOptions(ctx, client, filePath, line, column, newName, RenameOptions{})
+}
 
-	// Convert 1-indexed line/column to 0-indexed for LSP protocol
-	uri := protocol.DocumentUri("file://" + filePath)
-	position := protocol.Position{
-		Line:      uint32(line - 1),
-		Character: uint32(column - 1),
+// RenameSymbolWithOptions is RenameSymbol, optionally also updating the old name in the
+// comments and string literals of the renamed files
+func RenameSymbolWithOptions(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string, opts RenameOptions) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
 	}
 
-	// Create the rename parameters
-	params := protocol.RenameParams{
-		TextDocument: protocol.TextDocumentIdentifier{
-			URI: uri,
-		},
-		Position: position,
-		NewName:  newName,
+	oldName, err := renamedIdentifier(filePath, line, column, opts)
+	if err != nil {
+		return "", err
 	}
 
-	// Skip the PrepareRename check as it might not be supported by all language servers
-	// Execute the rename directly
-
-	// Execute the rename operation
-	workspaceEdit, err := client.Rename(ctx, params)
+	workspaceEdit, err := requestRename(ctx, client, filePath, line, column, newName)
 	if err != nil {
-		return "", fmt.Errorf("failed to rename symbol: %v", err)
+		return "", err
 	}
 
 	// Count the changes that will be made
@@ -112,8 +102,19 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		locationsBuilder.WriteString(fmt.Sprintf("%s: %s\n", change.URI, change.Locations))
 	}
 
+	// Textual occurrences are added after counting so that they are reported separately
+	textualSummary := ""
+	if opts.enabled() && changeCount > 0 {
+		occurrences, err := addTextualRenameEdits(&workspaceEdit, oldName, newName, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to find textual occurrences: %v", err)
+		}
+		textualSummary = formatTextualOccurrences(occurrences)
+	}
+
 	// Apply the workspace edit to files:workspaceEdit
-	if err := utilities.ApplyWorkspaceEdit(workspaceEdit); err != nil {
+	_, err = applyWorkspaceEditAtomically(workspaceEdit)
+	if err != nil {
 		return "", fmt.Errorf("failed to apply changes: %v", err)
 	}
 
@@ -122,6 +123,194 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 	}
 
 	// Generate a summary of changes made
-	return fmt.Sprintf("Successfully renamed symbol to '%s'.\nUpdated %d occurrences across %d files:\n%s",
-		newName, changeCount, fileCount, locationsBuilder.String()), nil
+	return fmt.Sprintf("Successfully renamed symbol to '%s'.\nUpdated %d occurrences across %d files:\n%s%s",
+		newName, changeCount, fileCount, locationsBuilder.String(), textualSummary), nil
+}
+
+// renamedIdentifier returns the name being renamed if opts asks for textual occurrences,
+// which must be read before the rename is applied
+func renamedIdentifier(filePath string, line, column int, opts RenameOptions) (string, error) {
+	if !opts.enabled() {
+		return "", nil
+	}
+	name := identifierAt(filePath, protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)})
+	if name == "" {
+		return "", fmt.Errorf("no identifier at %s:%d:%d", filePath, line, column)
+	}
+	return name, nil
+}
+
+// PreviewRenameSymbol returns the edits a rename would make as unified diffs, without applying them
+func PreviewRenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string) (string, error) {
+	return PreviewRenameSymbolWithOptions(ctx, client, filePath, line, column, newName, RenameOptions{})
+}
+
+// PreviewRenameSymbolWithOptions is PreviewRenameSymbol, including the textual occurrences
+// selected by opts in the diffs
+func PreviewRenameSymbolWithOptions(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string, opts RenameOptions) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	oldName, err := renamedIdentifier(filePath, line, column, opts)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceEdit, err := requestRename(ctx, client, filePath, line, column, newName)
+	if err != nil {
+		return "", err
+	}
+
+	textualSummary := ""
+	if opts.enabled() {
+		occurrences, err := addTextualRenameEdits(&workspaceEdit, oldName, newName, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to find textual occurrences: %v", err)
+		}
+		textualSummary = formatTextualOccurrences(occurrences)
+	}
+
+	preview, err := PreviewWorkspaceEdit(workspaceEdit)
+	if err != nil {
+		return "", fmt.Errorf("failed to preview changes: %v", err)
+	}
+	if preview == "" {
+		return "Rename would not change any files. 0 occurrences found.", nil
+	}
+
+	return fmt.Sprintf("Preview of renaming symbol to '%s' (no files were changed):\n\n%s%s", newName, preview, textualSummary), nil
+}
+
+// requestRename asks the server for the WorkspaceEdit that renames the symbol at the given position
+func requestRename(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string) (protocol.WorkspaceEdit, error) {
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return protocol.WorkspaceEdit{}, fmt.Errorf("could not open file: %v", err)
+	}
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	uri := protocol.DocumentUri("file://" + filePath)
+	position := protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(column - 1),
+	}
+
+	// Create the rename parameters
+	params := protocol.RenameParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: uri,
+		},
+		Position: position,
+		NewName:  newName,
+	}
+
+	// Skip the PrepareRename check as it might not be supported by all language servers
+	// Execute the rename directly
+
+	// Execute the rename operation
+	workspaceEdit, err := client.Rename(ctx, params)
+	if err != nil {
+		return protocol.WorkspaceEdit{}, fmt.Errorf("failed to rename symbol: %v", err)
+	}
+
+	return workspaceEdit, nil
+}
+
+// RenameSymbolByName renames a symbol identified by name rather than by position. The name is
+// resolved the same way as for the definition tool and must match exactly one definition.
+// If preview is true, the changes are returned as diffs without being applied.
+func RenameSymbolByName(ctx context.Context, client *lsp.Client, symbolName, newName string, preview bool, opts RenameOptions) (string, error) {
+	filePath, position, err := resolveSymbolPosition(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert back to the 1-indexed positions the position-based functions take
+	line, column := int(position.Line)+1, int(position.Character)+1
+	if preview {
+		return PreviewRenameSymbolWithOptions(ctx, client, filePath, line, column, newName, opts)
+	}
+	return RenameSymbolWithOptions(ctx, client, filePath, line, column, newName, opts)
+}
+
+// resolveSymbolPosition finds the file and position of the identifier of the single definition
+// matching symbolName. It fails with a list of candidates if several definitions match.
+func resolveSymbolPosition(ctx context.Context, client *lsp.Client, symbolName string) (string, protocol.Position, error) {
+	definitions, err := findDefinitions(ctx, client, symbolName, DefinitionOptions{})
+	if err != nil {
+		return "", protocol.Position{}, err
+	}
+
+	if len(definitions) == 0 {
+		return "", protocol.Position{}, fmt.Errorf("symbol %s not found", symbolName)
+	}
+	if len(definitions) > 1 {
+		var candidates strings.Builder
+		for _, def := range definitions {
+			candidates.WriteString(fmt.Sprintf("\n  - %s at %s:%d:%d", def.name,
+				utilities.URIToPath(def.declaration.URI),
+				def.declaration.Range.Start.Line+1, def.declaration.Range.Start.Character+1))
+		}
+		return "", protocol.Position{}, fmt.Errorf("%s is ambiguous, found %d candidates. Use filePath, line and column to pick one:%s",
+			symbolName, len(definitions), candidates.String())
+	}
+
+	filePath := utilities.URIToPath(definitions[0].declaration.URI)
+	position, err := findIdentifierPosition(filePath, definitions[0].declaration.Range, symbolName)
+	if err != nil {
+		return "", protocol.Position{}, err
+	}
+	return filePath, position, nil
+}
+
+// findIdentifierPosition locates the unqualified identifier of symbolName within rng.
+// Servers may report the whole declaration (e.g. starting at "pub fn") as the definition
+// range, but rename needs a position on the identifier itself.
+func findIdentifierPosition(filePath string, rng protocol.Range, symbolName string) (protocol.Position, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return protocol.Position{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Strip qualifiers such as "Type.method", "Type::method" or "(*Type).Method"
+	identifier := normalizeGoReceiver(symbolName)
+	if i := strings.LastIndexAny(identifier, ".:"); i != -1 {
+		identifier = identifier[i+1:]
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for lineNum := int(rng.Start.Line); lineNum <= int(rng.End.Line) && lineNum < len(lines); lineNum++ {
+		line := lines[lineNum]
+		start := 0
+		if lineNum == int(rng.Start.Line) {
+			start = min(int(rng.Start.Character), len(line))
+		}
+		for offset := start; offset < len(line); {
+			idx := strings.Index(line[offset:], identifier)
+			if idx == -1 {
+				break
+			}
+			idx += offset
+			end := idx + len(identifier)
+			if !isIdentifierByte(line, idx-1) && !isIdentifierByte(line, end) {
+				return protocol.Position{Line: uint32(lineNum), Character: uint32(idx)}, nil
+			}
+			offset = end
+		}
+	}
+
+	// Fall back to the start of the range, which is the identifier for most servers
+	return rng.Start, nil
+}
+
+// isIdentifierByte reports whether line[i] exists and can be part of an identifier
+func isIdentifierByte(line string, i int) bool {
+	if i < 0 || i >= len(line) {
+		return false
+	}
+	c := line[i]
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
 }
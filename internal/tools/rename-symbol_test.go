@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindIdentifierPosition(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "lib.rs")
+	content := "pub fn helper_fn() {}\n\nimpl Shape {\n    pub fn area(&self) -> f64 {\n        0.0\n    }\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	tests := []struct {
+		name       string
+		symbolName string
+		rng        protocol.Range
+		expected   protocol.Position
+	}{
+		{
+			name:       "range covers whole declaration",
+			symbolName: "helper_fn",
+			rng:        protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 21}},
+			expected:   protocol.Position{Line: 0, Character: 7},
+		},
+		{
+			name:       "qualified name",
+			symbolName: "Shape::area",
+			rng:        protocol.Range{Start: protocol.Position{Line: 3, Character: 4}, End: protocol.Position{Line: 5, Character: 5}},
+			expected:   protocol.Position{Line: 3, Character: 11},
+		},
+		{
+			name:       "go receiver syntax",
+			symbolName: "(*Shape).area",
+			rng:        protocol.Range{Start: protocol.Position{Line: 3, Character: 4}, End: protocol.Position{Line: 5, Character: 5}},
+			expected:   protocol.Position{Line: 3, Character: 11},
+		},
+		{
+			name:       "range already on identifier",
+			symbolName: "area",
+			rng:        protocol.Range{Start: protocol.Position{Line: 3, Character: 11}, End: protocol.Position{Line: 3, Character: 15}},
+			expected:   protocol.Position{Line: 3, Character: 11},
+		},
+		{
+			name:       "identifier not found falls back to range start",
+			symbolName: "missing",
+			rng:        protocol.Range{Start: protocol.Position{Line: 2, Character: 5}, End: protocol.Position{Line: 2, Character: 10}},
+			expected:   protocol.Position{Line: 2, Character: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := findIdentifierPosition(filePath, tt.rng, tt.symbolName)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, position)
+		})
+	}
+}
+
+func TestFindTextualOccurrences(t *testing.T) {
+	goSource := "package main\n\n// Foo returns one. See also FooBar.\nfunc Foo() int {\n\t/* Foo in a block */\n\tprintln(\"calling Foo\", 'F', `raw Foo`)\n\treturn 1\n}\n"
+	pySource := "# Foo is used below\ndef Foo():\n    return \"Foo\" # Foo again\n"
+
+	tests := []struct {
+		name     string
+		file     string
+		source   string
+		opts     RenameOptions
+		skip     []protocol.Range
+		expected []string // line:column (1-indexed) and kind of each occurrence
+	}{
+		{
+			name:     "go comments",
+			file:     "main.go",
+			source:   goSource,
+			opts:     RenameOptions{IncludeComments: true},
+			expected: []string{"3:4 comment", "5:5 comment"},
+		},
+		{
+			name:     "go strings",
+			file:     "main.go",
+			source:   goSource,
+			opts:     RenameOptions{IncludeStrings: true},
+			expected: []string{"6:19 string", "6:35 string"},
+		},
+		{
+			name:     "ranges edited by the server are skipped",
+			file:     "main.go",
+			source:   goSource,
+			opts:     RenameOptions{IncludeComments: true},
+			skip:     []protocol.Range{{Start: protocol.Position{Line: 2, Character: 3}, End: protocol.Position{Line: 2, Character: 6}}},
+			expected: []string{"5:5 comment"},
+		},
+		{
+			name:     "python hash comments and strings",
+			file:     "main.py",
+			source:   pySource,
+			opts:     RenameOptions{IncludeComments: true, IncludeStrings: true},
+			expected: []string{"1:3 comment", "3:13 string", "3:20 comment"},
+		},
+		{
+			// é is 2 bytes and 𝔽 is 4 bytes but 1 and 2 UTF-16 code units
+			name:     "columns after non-ASCII characters",
+			file:     "main.go",
+			source:   "package main\n\n// café 𝔽: Foo\nvar s = \"𝔽 Foo\"\n",
+			opts:     RenameOptions{IncludeComments: true, IncludeStrings: true},
+			expected: []string{"3:13 comment", "4:13 string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTestFile(t, tt.file, tt.source)
+			occurrences, err := findTextualOccurrences(filePath, "Foo", tt.opts, tt.skip)
+			require.NoError(t, err)
+
+			var found []string
+			for _, occurrence := range occurrences {
+				kind := "string"
+				if occurrence.inComment {
+					kind = "comment"
+				}
+				found = append(found, fmt.Sprintf("%d:%d %s", occurrence.position.Line+1, occurrence.position.Character+1, kind))
+			}
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
+
+func TestRenameSymbolWithOptions(t *testing.T) {
+	source := "package main\n\n// Foo returns one\nfunc Foo() int {\n\tprintln(\"Foo\")\n\treturn Foo()\n}\n"
+
+	tests := []struct {
+		name     string
+		opts     RenameOptions
+		expected string
+		report   string
+	}{
+		{
+			name:     "semantic rename only",
+			expected: "package main\n\n// Foo returns one\nfunc Bar() int {\n\tprintln(\"Foo\")\n\treturn Bar()\n}\n",
+		},
+		{
+			name:     "comments",
+			opts:     RenameOptions{IncludeComments: true},
+			expected: "package main\n\n// Bar returns one\nfunc Bar() int {\n\tprintln(\"Foo\")\n\treturn Bar()\n}\n",
+			report:   "Also updated 1 textual occurrences in comments or strings (heuristic, please review):\n%[1]s:3:4 (comment)\n",
+		},
+		{
+			name:     "comments and strings",
+			opts:     RenameOptions{IncludeComments: true, IncludeStrings: true},
+			expected: "package main\n\n// Bar returns one\nfunc Bar() int {\n\tprintln(\"Bar\")\n\treturn Bar()\n}\n",
+			report:   "Also updated 2 textual occurrences in comments or strings (heuristic, please review):\n%[1]s:3:4 (comment)\n%[1]s:5:11 (string)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			filePath := writeTestFile(t, "main.go", source)
+			server.RespondRaw("textDocument/rename", fmt.Sprintf(`{"changes": {"file://%s": [
+				{"range": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 8}}, "newText": "Bar"},
+				{"range": {"start": {"line": 5, "character": 8}, "end": {"line": 5, "character": 11}}, "newText": "Bar"}
+			]}}`, filePath))
+
+			result, err := RenameSymbolWithOptions(t.Context(), server.Client, filePath, 4, 6, "Bar", tt.opts)
+			require.NoError(t, err)
+			assert.Contains(t, result, "Updated 2 occurrences across 1 files")
+			if tt.report != "" {
+				assert.Contains(t, result, fmt.Sprintf(tt.report, filePath))
+			} else {
+				assert.NotContains(t, result, "textual occurrences")
+			}
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+}
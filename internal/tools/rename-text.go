@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// RenameOptions controls the optional, heuristic part of a rename. textDocument/rename only
+// changes code, so mentions of the old name in comments and string literals of the renamed
+// files can be updated as well.
+type RenameOptions struct {
+	// IncludeComments also replaces whole-word occurrences of the old name in comments
+	IncludeComments bool
+	// IncludeStrings also replaces whole-word occurrences of the old name in string literals
+	IncludeStrings bool
+}
+
+func (o RenameOptions) enabled() bool {
+	return o.IncludeComments || o.IncludeStrings
+}
+
+// textualOccurrence is a mention of the old name in a comment or string, found by scanning text
+type textualOccurrence struct {
+	filePath  string
+	position  protocol.Position // 0-indexed, character in bytes like the rest of the edit code
+	inComment bool
+}
+
+// textSegment is the byte range [start, end) of a comment or string literal
+type textSegment struct {
+	start, end int
+	comment    bool
+}
+
+// hashCommentExtensions are file types whose line comments start with '#' rather than '//'
+var hashCommentExtensions = map[string]bool{
+	".py": true, ".pyi": true, ".rb": true, ".sh": true, ".bash": true, ".pl": true,
+	".r": true, ".yaml": true, ".yml": true, ".toml": true, ".nix": true,
+}
+
+// addTextualRenameEdits adds edits replacing oldName with newName in the comments and/or
+// string literals of every file the semantic rename touches. Occurrences overlapping an edit
+// the server already made (e.g. a doc link gopls renamed) are skipped. The added
+// occurrences are returned sorted by file and position.
+func addTextualRenameEdits(edit *protocol.WorkspaceEdit, oldName, newName string, opts RenameOptions) ([]textualOccurrence, error) {
+	var occurrences []textualOccurrence
+
+	for uri, edits := range edit.Changes {
+		found, err := findTextualOccurrences(utilities.URIToPath(uri), oldName, opts, editRanges(edits))
+		if err != nil {
+			return nil, err
+		}
+		for _, occurrence := range found {
+			edit.Changes[uri] = append(edit.Changes[uri], textualRenameEdit(occurrence, oldName, newName))
+		}
+		occurrences = append(occurrences, found...)
+	}
+
+	for _, change := range edit.DocumentChanges {
+		if change.TextDocumentEdit == nil {
+			continue
+		}
+		var edits []protocol.TextEdit
+		for _, e := range change.TextDocumentEdit.Edits {
+			if textEdit, err := e.AsTextEdit(); err == nil {
+				edits = append(edits, textEdit)
+			}
+		}
+		found, err := findTextualOccurrences(utilities.URIToPath(change.TextDocumentEdit.TextDocument.URI), oldName, opts, editRanges(edits))
+		if err != nil {
+			return nil, err
+		}
+		for _, occurrence := range found {
+			change.TextDocumentEdit.Edits = append(change.TextDocumentEdit.Edits,
+				protocol.Or_TextDocumentEdit_edits_Elem{Value: textualRenameEdit(occurrence, oldName, newName)})
+		}
+		occurrences = append(occurrences, found...)
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		a, b := occurrences[i], occurrences[j]
+		if a.filePath != b.filePath {
+			return a.filePath < b.filePath
+		}
+		if a.position.Line != b.position.Line {
+			return a.position.Line < b.position.Line
+		}
+		return a.position.Character < b.position.Character
+	})
+	return occurrences, nil
+}
+
+func editRanges(edits []protocol.TextEdit) []protocol.Range {
+	ranges := make([]protocol.Range, len(edits))
+	for i, e := range edits {
+		ranges[i] = e.Range
+	}
+	return ranges
+}
+
+func textualRenameEdit(occurrence textualOccurrence, oldName, newName string) protocol.TextEdit {
+	end := occurrence.position
+	end.Character += uint32(utilities.ByteOffsetToUTF16Offset(oldName, len(oldName)))
+	return protocol.TextEdit{
+		Range:   protocol.Range{Start: occurrence.position, End: end},
+		NewText: newName,
+	}
+}
+
+// findTextualOccurrences returns the whole-word occurrences of name in the comments and/or
+// string literals of a file, except those overlapping skip
+func findTextualOccurrences(filePath, name string, opts RenameOptions, skip []protocol.Range) ([]textualOccurrence, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	text := string(content)
+
+	// Byte offset of the start of each line, to turn offsets into positions
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	// Positions count UTF-16 code units, not bytes
+	positionAt := func(offset int) protocol.Position {
+		line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+		character := utilities.ByteOffsetToUTF16Offset(text[lineStarts[line]:], offset-lineStarts[line])
+		return protocol.Position{Line: uint32(line), Character: uint32(character)}
+	}
+	nameLength := utilities.ByteOffsetToUTF16Offset(name, len(name))
+
+	var occurrences []textualOccurrence
+	hashComments := hashCommentExtensions[strings.ToLower(filepath.Ext(filePath))]
+	for _, segment := range scanTextSegments(text, hashComments) {
+		if segment.comment && !opts.IncludeComments || !segment.comment && !opts.IncludeStrings {
+			continue
+		}
+		body := text[segment.start:segment.end]
+		for offset := 0; offset < len(body); {
+			idx := strings.Index(body[offset:], name)
+			if idx == -1 {
+				break
+			}
+			idx += offset
+			end := idx + len(name)
+			offset = end
+			if isIdentifierByte(body, idx-1) || isIdentifierByte(body, end) {
+				continue
+			}
+
+			position := positionAt(segment.start + idx)
+			if overlapsAny(position, nameLength, skip) {
+				continue
+			}
+			occurrences = append(occurrences, textualOccurrence{
+				filePath:  filePath,
+				position:  position,
+				inComment: segment.comment,
+			})
+		}
+	}
+	return occurrences, nil
+}
+
+// overlapsAny reports whether the single-line span of length n at position overlaps any range.
+// Touching ranges count as overlapping, as they do when the edits are applied.
+func overlapsAny(position protocol.Position, n int, ranges []protocol.Range) bool {
+	span := protocol.Range{Start: position, End: protocol.Position{Line: position.Line, Character: position.Character + uint32(n)}}
+	for _, rng := range ranges {
+		if utilities.RangesOverlap(span, rng) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanTextSegments finds the comments and string literals in source text with a simple lexer
+// that knows C-style comments ("//", "/* */") or, if hashComments is true, "#" comments, and
+// strings delimited by ", ' or `. Escapes are honored except in backtick strings. Quoted
+// strings end at a newline so that unbalanced quotes (e.g. Rust lifetimes) stay local.
+func scanTextSegments(text string, hashComments bool) []textSegment {
+	var segments []textSegment
+	for i := 0; i < len(text); {
+		switch {
+		case hashComments && text[i] == '#',
+			!hashComments && strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end == -1 {
+				end = len(text) - i
+			}
+			segments = append(segments, textSegment{start: i, end: i + end, comment: true})
+			i += end
+		case !hashComments && strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end == -1 {
+				end = len(text) - i
+			} else {
+				end += 4
+			}
+			segments = append(segments, textSegment{start: i, end: i + end, comment: true})
+			i += end
+		case text[i] == '"' || text[i] == '\'' || text[i] == '`':
+			quote := text[i]
+			j := i + 1
+			for j < len(text) && text[j] != quote {
+				if quote != '`' && text[j] == '\n' {
+					break
+				}
+				if quote != '`' && text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(text))
+			segments = append(segments, textSegment{start: i, end: j})
+			i = j
+		default:
+			i++
+		}
+	}
+	return segments
+}
+
+// formatTextualOccurrences lists the comment and string occurrences a rename also changed
+func formatTextualOccurrences(occurrences []textualOccurrence) string {
+	if len(occurrences) == 0 {
+		return "\nNo textual occurrences found in comments or strings.\n"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\nAlso updated %d textual occurrences in comments or strings (heuristic, please review):\n", len(occurrences)))
+	for _, occurrence := range occurrences {
+		where := "string"
+		if occurrence.inComment {
+			where = "comment"
+		}
+		result.WriteString(fmt.Sprintf("%s:%d:%d (%s)\n", occurrence.filePath,
+			occurrence.position.Line+1, occurrence.position.Character+1, where))
+	}
+	return result.String()
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// ReplaceSymbolReferences replaces every reference to the symbol at the given position with
+// newText. Unlike RenameSymbol this is a textual replacement of the reference ranges reported
+// by textDocument/references: the server does not check that the result is valid, so it can
+// be used where a semantic rename is refused, e.g. to replace a name with an expression.
+// If preview is true, the changes are returned as diffs without being applied.
+func ReplaceSymbolReferences(ctx context.Context, client *lsp.Client, filePath string, line, column int, newText string, includeDeclaration, preview bool) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	refs, err := client.References(ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(column - 1),
+			},
+		},
+		Context: protocol.ReferenceContext{
+			IncludeDeclaration: includeDeclaration,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get references: %v", err)
+	}
+
+	// Group the reference ranges by file, skipping duplicates
+	changes := make(map[protocol.DocumentUri][]protocol.TextEdit)
+	seen := make(map[protocol.Location]bool)
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		changes[ref.URI] = append(changes[ref.URI], protocol.TextEdit{
+			Range:   ref.Range,
+			NewText: newText,
+		})
+	}
+
+	if len(changes) == 0 {
+		return fmt.Sprintf("No references found at %s:%d:%d", filePath, line, column), nil
+	}
+
+	const warning = "Note: this is a textual replacement of each reference, not a semantic rename. Check that the result compiles.\n\n"
+	workspaceEdit := protocol.WorkspaceEdit{Changes: changes}
+
+	if preview {
+		diff, err := PreviewWorkspaceEdit(workspaceEdit)
+		if err != nil {
+			return "", fmt.Errorf("failed to preview changes: %v", err)
+		}
+		return fmt.Sprintf("Preview of replacing %d references with '%s' (no files were changed):\n\n", len(seen), newText) +
+			warning + diff, nil
+	}
+
+	// Apply the edits to all files or none
+	_, err = applyWorkspaceEditAtomically(workspaceEdit)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+
+	uris := make([]string, 0, len(changes))
+	for uri := range changes {
+		uris = append(uris, string(uri))
+	}
+	sort.Strings(uris)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Replaced %d references with '%s' across %d files.\n", len(seen), newText, len(changes)))
+	result.WriteString(warning)
+	for _, uri := range uris {
+		edits := changes[protocol.DocumentUri(uri)]
+		locations := make([]string, len(edits))
+		for i, edit := range edits {
+			locations[i] = fmt.Sprintf("L%d:C%d", edit.Range.Start.Line+1, edit.Range.Start.Character+1)
+		}
+		result.WriteString(fmt.Sprintf("%s: %s\n", utilities.URIToPath(protocol.DocumentUri(uri)), strings.Join(locations, ", ")))
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"os"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// referenceAt returns the location of a reference on one line of filePath, with 0-indexed columns
+func referenceAt(filePath string, line, start, end uint32) protocol.Location {
+	return protocol.Location{
+		URI: protocol.DocumentUri("file://" + filePath),
+		Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		},
+	}
+}
+
+func TestReplaceSymbolReferences(t *testing.T) {
+	const libSource = "package lib\n\nconst Limit = 10\n\nfunc Max() int { return Limit }\n"
+	const mainSource = "package main\n\nfunc main() { println(lib.Limit) }\n"
+
+	t.Run("across files with duplicates", func(t *testing.T) {
+		server := newMockServer(t)
+		libPath := writeTestFile(t, "lib.go", libSource)
+		mainPath := writeTestFile(t, "main.go", mainSource)
+		server.Respond("textDocument/references", []protocol.Location{
+			referenceAt(libPath, 2, 6, 11),
+			referenceAt(libPath, 4, 24, 29),
+			referenceAt(mainPath, 2, 26, 31),
+			// Some servers report a reference twice
+			referenceAt(libPath, 4, 24, 29),
+		})
+
+		result, err := ReplaceSymbolReferences(t.Context(), server.Client, libPath, 3, 7, "MaxItems", true, false)
+		require.NoError(t, err)
+		assert.Contains(t, result, "Replaced 3 references with 'MaxItems' across 2 files.\n")
+		assert.Contains(t, result, libPath+": L3:C7, L5:C25\n")
+		assert.Contains(t, result, mainPath+": L3:C27\n")
+
+		content, err := os.ReadFile(libPath)
+		require.NoError(t, err)
+		assert.Equal(t, "package lib\n\nconst MaxItems = 10\n\nfunc Max() int { return MaxItems }\n", string(content))
+		content, err = os.ReadFile(mainPath)
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n\nfunc main() { println(lib.MaxItems) }\n", string(content))
+	})
+
+	t.Run("preview leaves files unchanged", func(t *testing.T) {
+		server := newMockServer(t)
+		libPath := writeTestFile(t, "lib.go", libSource)
+		server.Respond("textDocument/references", []protocol.Location{
+			referenceAt(libPath, 2, 6, 11),
+			referenceAt(libPath, 4, 24, 29),
+		})
+
+		result, err := ReplaceSymbolReferences(t.Context(), server.Client, libPath, 3, 7, "(5 + 5)", true, true)
+		require.NoError(t, err)
+		assert.Contains(t, result, "Preview of replacing 2 references with '(5 + 5)' (no files were changed)")
+		assert.Contains(t, result, "+func Max() int { return (5 + 5) }")
+
+		content, err := os.ReadFile(libPath)
+		require.NoError(t, err)
+		assert.Equal(t, libSource, string(content))
+	})
+
+	t.Run("no references", func(t *testing.T) {
+		server := newMockServer(t)
+		libPath := writeTestFile(t, "lib.go", libSource)
+		server.RespondRaw("textDocument/references", `[]`)
+
+		result, err := ReplaceSymbolReferences(t.Context(), server.Client, libPath, 1, 1, "x", false, false)
+		require.NoError(t, err)
+		assert.Equal(t, "No references found at "+libPath+":1:1", result)
+
+		content, err := os.ReadFile(libPath)
+		require.NoError(t, err)
+		assert.Equal(t, libSource, string(content))
+	})
+}
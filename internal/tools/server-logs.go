@@ -0,0 +1,25 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// DefaultServerLogLines is the number of stderr lines server_logs returns by default
+const DefaultServerLogLines = 100
+
+// GetServerLogs returns the last n lines the language server wrote to stderr or logged with
+// window/logMessage, e.g. a panic or crash report explaining why other tools fail
+func GetServerLogs(client *lsp.Client, n int) string {
+	if n <= 0 {
+		n = DefaultServerLogLines
+	}
+
+	lines := client.ServerLogs(n)
+	if len(lines) == 0 {
+		return "The language server has not logged anything"
+	}
+	return fmt.Sprintf("Last %d lines of language server logs:\n\n%s\n", len(lines), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// GetServerStatus describes the language server, the operations it is reporting progress
+// for, e.g. indexing that has to finish before references are complete, and the messages it
+// recently asked to show to the user. extensions are the file extensions the server
+// handles, if known, and knownIssues the issues of its version that affect tools.
+func GetServerStatus(client *lsp.Client, serverDescription string, extensions []string, knownIssues []lsp.KnownIssue) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Language server: %s\n", serverDescription))
+	if len(extensions) > 0 {
+		result.WriteString(fmt.Sprintf("File extensions: %s\n", strings.Join(extensions, ", ")))
+	}
+
+	if indexing := client.IndexingStatus(); indexing != nil {
+		switch {
+		case !indexing.Done:
+			result.WriteString(fmt.Sprintf("Initial indexing: waiting up to %s (%s so far)\n",
+				indexing.Timeout, time.Since(indexing.Started).Round(time.Second)))
+		case indexing.Complete:
+			result.WriteString(fmt.Sprintf("Initial indexing: finished after %s\n", indexing.Elapsed.Round(time.Millisecond)))
+		default:
+			result.WriteString(fmt.Sprintf("Initial indexing: still running after %s, references and symbols may be incomplete\n", indexing.Timeout))
+		}
+	}
+
+	active := client.ActiveProgress()
+	if len(active) == 0 {
+		result.WriteString("No operations in progress\n")
+	} else {
+		result.WriteString(fmt.Sprintf("\n%d operations in progress:\n", len(active)))
+		for _, progress := range active {
+			result.WriteString("- " + progress.Title)
+			if progress.Percentage >= 0 {
+				result.WriteString(fmt.Sprintf(" %d%%", progress.Percentage))
+			}
+			if progress.Message != "" {
+				result.WriteString(": " + progress.Message)
+			}
+			result.WriteString(fmt.Sprintf(" (%s)\n", time.Since(progress.Started).Round(time.Second)))
+		}
+	}
+
+	if len(knownIssues) > 0 {
+		result.WriteString("\nKnown issues of this version:\n")
+		for _, issue := range knownIssues {
+			result.WriteString(fmt.Sprintf("- %s: %s\n", strings.Join(issue.Tools, ", "), issue.Description))
+		}
+	}
+
+	if messages := client.ServerMessages(); len(messages) > 0 {
+		result.WriteString("\nRecent messages from the server:\n")
+		for _, message := range messages {
+			result.WriteString(message + "\n")
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServerStatus(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.RegisterOrderedNotificationHandler("$/progress",
+		func(params json.RawMessage) { lsp.HandleProgress(server.Client, params) })
+	server.Respond("test/sync", nil)
+
+	// sync returns once the client has handled every notification sent before it
+	sync := func() {
+		require.NoError(t, server.Client.Call(t.Context(), "test/sync", nil, nil))
+	}
+
+	assert.Equal(t, "Language server: gopls v0.18.1\nNo operations in progress\n",
+		GetServerStatus(server.Client, "gopls v0.18.1", nil, nil))
+
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "index", "value": map[string]any{"kind": "begin", "title": "Indexing", "percentage": 0},
+	}))
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "index", "value": map[string]any{"kind": "report", "message": "12/40 packages", "percentage": 30},
+	}))
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": 7, "value": map[string]any{"kind": "begin", "title": "Loading workspace"},
+	}))
+	sync()
+
+	status := GetServerStatus(server.Client, "gopls v0.18.1", nil, nil)
+	assert.Contains(t, status, "2 operations in progress:\n")
+	assert.Contains(t, status, "- Indexing 30%: 12/40 packages (0s)\n")
+	assert.Contains(t, status, "- Loading workspace (0s)\n")
+
+	// Begin and end arriving back to back are handled in order
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "index", "value": map[string]any{"kind": "end"},
+	}))
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": 7, "value": map[string]any{"kind": "end"},
+	}))
+	sync()
+	assert.Contains(t, GetServerStatus(server.Client, "gopls v0.18.1", nil, nil), "No operations in progress")
+}
+
+func TestGetServerStatusExtensions(t *testing.T) {
+	server := newMockServer(t)
+	assert.Equal(t, "Language server: gopls v0.18.1\nFile extensions: .go, .mod\nNo operations in progress\n",
+		GetServerStatus(server.Client, "gopls v0.18.1", []string{".go", ".mod"}, nil))
+}
+
+func TestGetServerStatusKnownIssues(t *testing.T) {
+	server := newMockServer(t)
+	issues := []lsp.KnownIssue{{Server: "clangd", FixedIn: "20.0.0", Tools: []string{"call_hierarchy"}, Description: "no outgoing calls"}}
+	assert.Equal(t, "Language server: clangd 18.1.3\nNo operations in progress\n\n"+
+		"Known issues of this version:\n"+
+		"- call_hierarchy: no outgoing calls\n",
+		GetServerStatus(server.Client, "clangd 18.1.3", nil, issues))
+}
+
+func TestGetServerStatusMessages(t *testing.T) {
+	server := newMockServer(t)
+	lsp.HandleServerMessage(server.Client, json.RawMessage(`{"type": 2, "message": "No compile_commands.json found"}`))
+	_, err := lsp.HandleShowMessageRequest(server.Client, json.RawMessage(`{"type": 3, "message": "Index the workspace?", "actions": [{"title": "Index"}]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Language server: clangd 18.1.3\nNo operations in progress\n\n"+
+		"Recent messages from the server:\n"+
+		"[warning] No compile_commands.json found\n"+
+		"[info] Index the workspace? (actions: Index; answered \"Index\")\n",
+		GetServerStatus(server.Client, "clangd 18.1.3", nil, nil))
+}
+
+func TestGetServerStatusIndexing(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.RegisterOrderedNotificationHandler("$/progress",
+		func(params json.RawMessage) { lsp.HandleProgress(server.Client, params) })
+
+	server.Client.StartIndexingWait(t.Context(), time.Minute)
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "index", "value": map[string]any{"kind": "begin", "title": "Indexing"}}))
+	assert.Eventually(t, func() bool { return len(server.Client.ActiveProgress()) == 1 }, time.Second, time.Millisecond)
+	assert.Contains(t, GetServerStatus(server.Client, "gopls", nil, nil), "Initial indexing: waiting up to 1m0s")
+
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "index", "value": map[string]any{"kind": "end"}}))
+	select {
+	case <-server.Client.IndexingWaitDone():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the wait to end with the indexing")
+	}
+	assert.Contains(t, GetServerStatus(server.Client, "gopls", nil, nil), "Initial indexing: finished after")
+
+	// Progress that doesn't end in time
+	server = newMockServer(t)
+	server.Client.RegisterOrderedNotificationHandler("$/progress",
+		func(params json.RawMessage) { lsp.HandleProgress(server.Client, params) })
+	server.Client.StartIndexingWait(t.Context(), 20*time.Millisecond)
+	require.NoError(t, server.Notify("$/progress", map[string]any{
+		"token": "load", "value": map[string]any{"kind": "begin", "title": "Loading"}}))
+	<-server.Client.IndexingWaitDone()
+	assert.Contains(t, GetServerStatus(server.Client, "gopls", nil, nil), "Initial indexing: still running after 20ms")
+}
+
+func TestIndexingWaitWithoutProgress(t *testing.T) {
+	// A server that reports no progress is assumed to have nothing to index
+	server := newMockServer(t)
+	server.Client.StartIndexingWait(t.Context(), 10*time.Millisecond)
+	<-server.Client.IndexingWaitDone()
+	assert.Contains(t, GetServerStatus(server.Client, "gopls", nil, nil), "Initial indexing: finished after")
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/logging"
+)
+
+// SetLogLevel changes the minimum log level at runtime, for all components or, if component
+// is not empty, for a single one
+func SetLogLevel(levelName, component string) (string, error) {
+	level, err := logging.ParseLevel(levelName)
+	if err != nil {
+		return "", fmt.Errorf("%v (expected debug, info, warn or error)", err)
+	}
+
+	if component == "" {
+		logging.SetGlobalLevel(level)
+		return fmt.Sprintf("Log level set to %s for all components", level), nil
+	}
+
+	components := logging.Components()
+	for _, comp := range components {
+		if string(comp) == component {
+			logging.SetLevel(comp, level)
+			return fmt.Sprintf("Log level set to %s for component %s", level, comp), nil
+		}
+	}
+
+	names := make([]string, len(components))
+	for i, comp := range components {
+		names[i] = string(comp)
+	}
+	return "", fmt.Errorf("unknown component: %s (expected one of %s)", component, strings.Join(names, ", "))
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SetTrace changes how much the server traces its own handling of requests. An empty value
+// only reports the current level.
+func SetTrace(ctx context.Context, client *lsp.Client, value string) (string, error) {
+	if value == "" {
+		return fmt.Sprintf("Trace level is %s.", client.TraceLevel()), nil
+	}
+
+	level := protocol.TraceValue(value)
+	switch level {
+	case protocol.Off, protocol.Messages, protocol.Verbose:
+	default:
+		return "", fmt.Errorf("unknown trace level: %s (expected off, messages or verbose)", value)
+	}
+
+	if err := client.SetTraceLevel(ctx, level); err != nil {
+		return "", err
+	}
+	if level == protocol.Off {
+		return "Trace level set to off.", nil
+	}
+	return fmt.Sprintf("Trace level set to %s. Traces the server sends ($/logTrace) are shown by server_logs; not every server sends them.", level), nil
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTrace(t *testing.T) {
+	server := newMockServer(t)
+
+	result, err := SetTrace(t.Context(), server.Client, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Trace level is off.", result)
+
+	result, err = SetTrace(t.Context(), server.Client, "verbose")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Trace level set to verbose.")
+
+	result, err = SetTrace(t.Context(), server.Client, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Trace level is verbose.", result)
+
+	_, err = SetTrace(t.Context(), server.Client, "loud")
+	assert.ErrorContains(t, err, "unknown trace level: loud")
+
+	require.Eventually(t, func() bool { return len(server.Received("$/setTrace")) == 1 }, time.Second, 10*time.Millisecond)
+	var params protocol.SetTraceParams
+	require.NoError(t, json.Unmarshal(server.Received("$/setTrace")[0], &params))
+	assert.Equal(t, protocol.Verbose, params.Value)
+
+	lsp.HandleLogTrace(server.Client, []byte(`{"message": "Received request 'textDocument/hover - (3)'.", "verbose": "Params: {}"}`))
+	assert.Equal(t, []string{"[trace] Received request 'textDocument/hover - (3)'.\nParams: {}"}, server.Client.ServerLogs(0))
+}
@@ -7,12 +7,42 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
-// GetSignatureHelp returns function signature information at the given position
-func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+// Signature help trigger kinds accepted by SignatureHelpOptions.TriggerKind
+const (
+	SignatureTriggerInvoked       = "invoked"
+	SignatureTriggerCharacter     = "triggerCharacter"
+	SignatureTriggerContentChange = "contentChange"
+)
+
+// SignatureHelpOptions describes how signature help was triggered
+type SignatureHelpOptions struct {
+	// TriggerKind is one of the SignatureTrigger constants. Defaults to triggerCharacter
+	// when TriggerCharacter is set and invoked otherwise.
+	TriggerKind string
+	// TriggerCharacter is the character just typed, e.g. "(" or ","
+	TriggerCharacter string
+	// ActiveSignature is the 1-indexed signature that was active before, 0 if none. When set
+	// the request is sent as a re-trigger so the server keeps tracking that signature.
+	ActiveSignature int
+}
+
+// GetSignatureHelp returns function signature information at the given position.
+// provider holds the trigger characters advertised by the server, used to validate opts.
+// Long signatures are wrapped at MaxLineWidth.
+func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int, opts SignatureHelpOptions, provider *protocol.SignatureHelpOptions) (string, error) {
+	helpContext, err := signatureHelpContext(opts, provider)
+	if err != nil {
+		return "", err
+	}
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
 	// Open the file if not already open
-	err := client.OpenFile(ctx, filePath)
+	err = client.OpenFile(ctx, filePath)
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
@@ -30,6 +60,22 @@ func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string,
 	}
 	params.Position = position
 
+	// A re-trigger carries the previously shown signature help with the chosen signature active
+	if opts.ActiveSignature > 0 {
+		params.Context = &protocol.SignatureHelpContext{TriggerKind: protocol.SigInvoked}
+		previous, err := client.SignatureHelp(ctx, params)
+		if err != nil {
+			return "", fmt.Errorf("failed to get signature help: %v", err)
+		}
+		if opts.ActiveSignature > len(previous.Signatures) {
+			return "", fmt.Errorf("activeSignature %d is out of range, there are %d signatures at this position", opts.ActiveSignature, len(previous.Signatures))
+		}
+		previous.ActiveSignature = uint32(opts.ActiveSignature - 1)
+		helpContext.IsRetrigger = true
+		helpContext.ActiveSignatureHelp = &previous
+	}
+	params.Context = helpContext
+
 	// Execute the signature help request
 	signatureResult, err := client.SignatureHelp(ctx, params)
 	if err != nil {
@@ -85,19 +131,7 @@ func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string,
 			if len(sig.Parameters) > 0 {
 				result.WriteString("\nParameters:\n")
 				for j, param := range sig.Parameters {
-					// Get parameter label
-					var paramLabel string
-					switch v := param.Label.Value.(type) {
-					case string:
-						paramLabel = v
-					case protocol.Tuple_ParameterInformation_label_Item1:
-						// Extract label from signature using offsets
-						start := v.Fld0
-						end := v.Fld1
-						if int(start) < len(sig.Label) && int(end) <= len(sig.Label) {
-							paramLabel = sig.Label[start:end]
-						}
-					}
+					paramLabel := parameterLabel(sig, param)
 
 					// Mark active parameter
 					activeMarker := " "
@@ -138,5 +172,70 @@ func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string,
 			activeSignatureIdx+1, len(signatureResult.Signatures)))
 	}
 
-	return result.String(), nil
+	return wrapLines(result.String(), MaxLineWidth()), nil
+}
+
+// parameterLabel returns the label of a signature parameter. Labels given as [start, end]
+// offsets are UTF-16 offsets into the signature label.
+func parameterLabel(sig protocol.SignatureInformation, param protocol.ParameterInformation) string {
+	switch v := param.Label.Value.(type) {
+	case string:
+		return v
+	case protocol.Tuple_ParameterInformation_label_Item1:
+		start := utilities.UTF16OffsetToByteOffset(sig.Label, int(v.Fld0))
+		end := utilities.UTF16OffsetToByteOffset(sig.Label, int(v.Fld1))
+		if start < end {
+			return sig.Label[start:end]
+		}
+	}
+	return ""
+}
+
+// signatureHelpContext builds the SignatureHelpContext for opts, checking the trigger
+// character against the characters the server advertised
+func signatureHelpContext(opts SignatureHelpOptions, provider *protocol.SignatureHelpOptions) (*protocol.SignatureHelpContext, error) {
+	kind := opts.TriggerKind
+	if kind == "" {
+		kind = SignatureTriggerInvoked
+		if opts.TriggerCharacter != "" {
+			kind = SignatureTriggerCharacter
+		}
+	}
+
+	helpContext := &protocol.SignatureHelpContext{}
+	switch kind {
+	case SignatureTriggerInvoked:
+		helpContext.TriggerKind = protocol.SigInvoked
+	case SignatureTriggerCharacter:
+		helpContext.TriggerKind = protocol.SigTriggerCharacter
+	case SignatureTriggerContentChange:
+		helpContext.TriggerKind = protocol.SigContentChange
+	default:
+		return nil, fmt.Errorf("invalid triggerKind %q (expected %s, %s or %s)", kind,
+			SignatureTriggerInvoked, SignatureTriggerCharacter, SignatureTriggerContentChange)
+	}
+
+	if kind != SignatureTriggerCharacter {
+		if opts.TriggerCharacter != "" {
+			return nil, fmt.Errorf("triggerCharacter can only be used with triggerKind %s", SignatureTriggerCharacter)
+		}
+		return helpContext, nil
+	}
+	if opts.TriggerCharacter == "" {
+		return nil, fmt.Errorf("triggerKind %s requires a triggerCharacter", SignatureTriggerCharacter)
+	}
+
+	// Re-trigger characters are only valid while signature help is already showing
+	var advertised []string
+	if provider != nil {
+		advertised = append(advertised, provider.TriggerCharacters...)
+		if opts.ActiveSignature > 0 {
+			advertised = append(advertised, provider.RetriggerCharacters...)
+		}
+	}
+	if err := validateTriggerCharacter(opts.TriggerCharacter, advertised); err != nil {
+		return nil, err
+	}
+	helpContext.TriggerCharacter = opts.TriggerCharacter
+	return helpContext, nil
 }
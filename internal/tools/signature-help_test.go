@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureHelpContext(t *testing.T) {
+	provider := &protocol.SignatureHelpOptions{
+		TriggerCharacters:   []string{"("},
+		RetriggerCharacters: []string{","},
+	}
+
+	tests := []struct {
+		name        string
+		opts        SignatureHelpOptions
+		expected    *protocol.SignatureHelpContext
+		expectedErr string
+	}{
+		{
+			name:     "invoked by default",
+			opts:     SignatureHelpOptions{},
+			expected: &protocol.SignatureHelpContext{TriggerKind: protocol.SigInvoked},
+		},
+		{
+			name:     "trigger character implies its kind",
+			opts:     SignatureHelpOptions{TriggerCharacter: "("},
+			expected: &protocol.SignatureHelpContext{TriggerKind: protocol.SigTriggerCharacter, TriggerCharacter: "("},
+		},
+		{
+			name:     "content change",
+			opts:     SignatureHelpOptions{TriggerKind: SignatureTriggerContentChange},
+			expected: &protocol.SignatureHelpContext{TriggerKind: protocol.SigContentChange},
+		},
+		{
+			name:        "re-trigger character outside a re-trigger",
+			opts:        SignatureHelpOptions{TriggerCharacter: ","},
+			expectedErr: `trigger character "," is not supported`,
+		},
+		{
+			name:     "re-trigger character with an active signature",
+			opts:     SignatureHelpOptions{TriggerCharacter: ",", ActiveSignature: 2},
+			expected: &protocol.SignatureHelpContext{TriggerKind: protocol.SigTriggerCharacter, TriggerCharacter: ","},
+		},
+		{
+			name:        "trigger character kind without a character",
+			opts:        SignatureHelpOptions{TriggerKind: SignatureTriggerCharacter},
+			expectedErr: "requires a triggerCharacter",
+		},
+		{
+			name:        "character with another kind",
+			opts:        SignatureHelpOptions{TriggerKind: SignatureTriggerInvoked, TriggerCharacter: "("},
+			expectedErr: "can only be used with triggerKind",
+		},
+		{
+			name:        "unknown kind",
+			opts:        SignatureHelpOptions{TriggerKind: "typed"},
+			expectedErr: `invalid triggerKind "typed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpContext, err := signatureHelpContext(tt.opts, provider)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, helpContext)
+		})
+	}
+}
+
+func TestParameterLabel(t *testing.T) {
+	offsets := func(start, end uint32) protocol.ParameterInformation {
+		return protocol.ParameterInformation{Label: protocol.Or_ParameterInformation_label{
+			Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: start, Fld1: end},
+		}}
+	}
+
+	// Offsets are UTF-16 code units: "ü" is one unit but two bytes, "𝔽" is two units and four bytes
+	sig := protocol.SignatureInformation{Label: "grüße(größe Maß, 𝔽 Zähler)"}
+	assert.Equal(t, "größe Maß", parameterLabel(sig, offsets(6, 15)))
+	assert.Equal(t, "𝔽 Zähler", parameterLabel(sig, offsets(17, 26)))
+
+	// Out of range offsets are clamped instead of panicking
+	assert.Equal(t, "Zähler)", parameterLabel(sig, offsets(20, 100)))
+	assert.Equal(t, "", parameterLabel(sig, offsets(100, 200)))
+	assert.Equal(t, "", parameterLabel(sig, offsets(10, 5)))
+
+	assert.Equal(t, "name string", parameterLabel(sig, protocol.ParameterInformation{
+		Label: protocol.Or_ParameterInformation_label{Value: "name string"},
+	}))
+}
+
+func TestGetSignatureHelpResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		contains []string
+	}{
+		{
+			name: "active parameter",
+			result: `{"signatures": [{"label": "Add(a int, b int) int", "documentation": {"kind": "plaintext", "value": "Add adds."},
+				"parameters": [{"label": "a int"}, {"label": [11, 16]}]}], "activeSignature": 0, "activeParameter": 1}`,
+			contains: []string{"▶ Add(a int, b int) int", "▶ b int"},
+		},
+		{
+			name:     "null result",
+			result:   `null`,
+			contains: []string{"No signature help available for this position on the following line:\nAdd(1, 2)\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t)
+			server.RespondRaw("textDocument/signatureHelp", tt.result)
+			filePath := writeTestFile(t, "main.go", "Add(1, 2)\n")
+
+			result, err := GetSignatureHelp(t.Context(), server.Client, filePath, 1, 8, SignatureHelpOptions{}, nil)
+			assert.NoError(t, err)
+			for _, expected := range tt.contains {
+				assert.Contains(t, result, expected)
+			}
+		})
+	}
+}
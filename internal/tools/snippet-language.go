@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// snippetLanguages maps file extensions to the language names markdown renderers highlight
+// code blocks by. These mostly match LSP language identifiers, except where renderers know
+// the language by another name, e.g. tsx rather than typescriptreact.
+var snippetLanguages = map[string]string{
+	".go":    "go",
+	".mod":   "go",
+	".py":    "python",
+	".pyi":   "python",
+	".rs":    "rust",
+	".ts":    "typescript",
+	".mts":   "typescript",
+	".cts":   "typescript",
+	".tsx":   "tsx",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".jsx":   "jsx",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".cxx":   "cpp",
+	".hh":    "cpp",
+	".hpp":   "cpp",
+	".hxx":   "cpp",
+	".m":     "objectivec",
+	".mm":    "objectivec",
+	".cs":    "csharp",
+	".java":  "java",
+	".kt":    "kotlin",
+	".kts":   "kotlin",
+	".scala": "scala",
+	".swift": "swift",
+	".dart":  "dart",
+	".rb":    "ruby",
+	".php":   "php",
+	".lua":   "lua",
+	".zig":   "zig",
+	".hs":    "haskell",
+	".ex":    "elixir",
+	".exs":   "elixir",
+	".erl":   "erlang",
+	".ml":    "ocaml",
+	".mli":   "ocaml",
+	".fs":    "fsharp",
+	".clj":   "clojure",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "zsh",
+	".ps1":   "powershell",
+	".sql":   "sql",
+	".html":  "html",
+	".css":   "css",
+	".scss":  "scss",
+	".vue":   "vue",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".xml":   "xml",
+	".md":    "markdown",
+	".proto": "protobuf",
+	".tf":    "hcl",
+}
+
+// snippetFileNames are the languages of files known by their name rather than extension
+var snippetFileNames = map[string]string{
+	"dockerfile":  "dockerfile",
+	"makefile":    "makefile",
+	"gnumakefile": "makefile",
+	"go.mod":      "go",
+	"go.work":     "go",
+}
+
+// SnippetLanguage returns the language of a file's source for syntax highlighting, or ""
+// if it is not known
+func SnippetLanguage(filePath string) string {
+	base := strings.ToLower(filepath.Base(filePath))
+	if language, ok := snippetFileNames[base]; ok {
+		return language
+	}
+	return snippetLanguages[strings.ToLower(filepath.Ext(base))]
+}
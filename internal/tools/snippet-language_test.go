@@ -0,0 +1,23 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippetLanguage(t *testing.T) {
+	tests := map[string]string{
+		"/src/main.go":            "go",
+		"/src/App.TSX":            "tsx",
+		"/src/lib.rs":             "rust",
+		"/src/include/vector.hpp": "cpp",
+		"/src/Dockerfile":         "dockerfile",
+		"/src/go.mod":             "go",
+		"/src/notes.txt":          "",
+		"/src/LICENSE":            "",
+	}
+	for filePath, expected := range tests {
+		assert.Equal(t, expected, SnippetLanguage(filePath), filePath)
+	}
+}
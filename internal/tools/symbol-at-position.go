@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// enclosingSymbol is a symbol whose range contains a position, with the names of the symbols
+// it is nested in, outermost first
+type enclosingSymbol struct {
+	name       string
+	kind       protocol.SymbolKind
+	containers []string
+	rng        protocol.Range
+	selection  protocol.Range
+}
+
+// GetSymbolAtPosition returns the innermost symbol of a file whose range contains a position,
+// e.g. the method a line is in, with its kind and the symbols containing it. Its name can be
+// passed to the tools that look symbols up by name.
+func GetSymbolAtPosition(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	symbolResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	results, err := symbolResult.Results()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+
+	position := protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)}
+	symbol := innermostSymbol(results, position)
+	identifier := identifierAt(filePath, position)
+	if symbol == nil {
+		result := fmt.Sprintf("No symbol of %s contains L%d:C%d", filePath, line, column)
+		if identifier != "" {
+			result += fmt.Sprintf(", the identifier there is %s", identifier)
+		}
+		return result + "\n", nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Symbol: %s\n", symbol.name))
+	result.WriteString(fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[symbol.kind]))
+	if len(symbol.containers) > 0 {
+		result.WriteString(fmt.Sprintf("Container: %s\n", strings.Join(symbol.containers, " > ")))
+	}
+	result.WriteString(fmt.Sprintf("Range: L%d:C%d - L%d:C%d\n",
+		symbol.rng.Start.Line+1, symbol.rng.Start.Character+1, symbol.rng.End.Line+1, symbol.rng.End.Character+1))
+	switch {
+	case containsPosition(symbol.selection, position):
+		result.WriteString("The position is on the symbol's name\n")
+	case identifier != "":
+		result.WriteString(fmt.Sprintf("Identifier at the position: %s, inside the symbol\n", identifier))
+	}
+	return result.String(), nil
+}
+
+// innermostSymbol returns the most deeply nested symbol whose range contains position, or nil.
+// Flat SymbolInformation results have no nesting, so the one with the smallest range wins.
+func innermostSymbol(results []protocol.DocumentSymbolResult, position protocol.Position) *enclosingSymbol {
+	var innermost *enclosingSymbol
+	var search func(symbols []protocol.DocumentSymbol, containers []string)
+	search = func(symbols []protocol.DocumentSymbol, containers []string) {
+		for _, symbol := range symbols {
+			if !containsPosition(symbol.Range, position) {
+				continue
+			}
+			innermost = &enclosingSymbol{
+				name:       symbol.Name,
+				kind:       symbol.Kind,
+				containers: containers,
+				rng:        symbol.Range,
+				selection:  symbol.SelectionRange,
+			}
+			search(symbol.Children, append(containers[:len(containers):len(containers)], symbol.Name))
+			return
+		}
+	}
+
+	for _, result := range results {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			if containsPosition(v.Range, position) {
+				search([]protocol.DocumentSymbol{*v}, nil)
+			}
+		case *protocol.SymbolInformation:
+			rng := v.Location.Range
+			if !containsPosition(rng, position) || innermost != nil && !rangeContains(innermost.rng, rng) {
+				continue
+			}
+			// Flat symbols don't tell where their name is
+			innermost = &enclosingSymbol{name: v.Name, kind: v.Kind, rng: rng}
+			if v.ContainerName != "" {
+				innermost.containers = []string{v.ContainerName}
+			}
+		}
+	}
+	return innermost
+}
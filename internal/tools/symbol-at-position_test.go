@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSymbolAtPosition(t *testing.T) {
+	const source = "class Outer:\n    def method(self):\n        return helper()\n\n    class Inner:\n        pass\n\nx = 1\n"
+
+	t.Run("hierarchical symbols", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "module.py", source)
+		server.RespondRaw("textDocument/documentSymbol", `[
+			{"name": "Outer", "kind": 5, "range": {"start": {"line": 0, "character": 0}, "end": {"line": 5, "character": 12}}, "selectionRange": {"start": {"line": 0, "character": 6}, "end": {"line": 0, "character": 11}}, "children": [
+				{"name": "method", "kind": 6, "range": {"start": {"line": 1, "character": 4}, "end": {"line": 2, "character": 23}}, "selectionRange": {"start": {"line": 1, "character": 8}, "end": {"line": 1, "character": 14}}},
+				{"name": "Inner", "kind": 5, "range": {"start": {"line": 4, "character": 4}, "end": {"line": 5, "character": 12}}, "selectionRange": {"start": {"line": 4, "character": 10}, "end": {"line": 4, "character": 15}}}
+			]},
+			{"name": "x", "kind": 13, "range": {"start": {"line": 7, "character": 0}, "end": {"line": 7, "character": 5}}, "selectionRange": {"start": {"line": 7, "character": 0}, "end": {"line": 7, "character": 1}}}
+		]`)
+
+		tests := []struct {
+			name         string
+			line, column int
+			expected     string
+		}{
+			{
+				name: "inside a method", line: 3, column: 17,
+				expected: "Symbol: method\nKind: Method\nContainer: Outer\nRange: L2:C5 - L3:C24\n" +
+					"Identifier at the position: helper, inside the symbol\n",
+			},
+			{
+				name: "on the name of a nested class", line: 5, column: 12,
+				expected: "Symbol: Inner\nKind: Class\nContainer: Outer\nRange: L5:C5 - L6:C13\nThe position is on the symbol's name\n",
+			},
+			{
+				name: "top-level symbol", line: 8, column: 5,
+				expected: "Symbol: x\nKind: Variable\nRange: L8:C1 - L8:C6\nIdentifier at the position: 1, inside the symbol\n",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := GetSymbolAtPosition(t.Context(), server.Client, filePath, tt.line, tt.column)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+
+		result, err := GetSymbolAtPosition(t.Context(), server.Client, filePath, 7, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "No symbol of "+filePath+" contains L7:C1\n", result)
+	})
+
+	t.Run("flat symbols", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "module.py", source)
+		server.RespondRaw("textDocument/documentSymbol", `[
+			{"name": "Outer", "kind": 5, "location": {"uri": "file://`+filePath+`", "range": {"start": {"line": 0, "character": 0}, "end": {"line": 5, "character": 12}}}},
+			{"name": "method", "kind": 6, "containerName": "Outer", "location": {"uri": "file://`+filePath+`", "range": {"start": {"line": 1, "character": 4}, "end": {"line": 2, "character": 23}}}}
+		]`)
+
+		result, err := GetSymbolAtPosition(t.Context(), server.Client, filePath, 2, 9)
+		require.NoError(t, err)
+		assert.Equal(t, "Symbol: method\nKind: Method\nContainer: Outer\nRange: L2:C5 - L3:C24\n"+
+			"Identifier at the position: method, inside the symbol\n", result)
+	})
+
+	t.Run("position outside the file", func(t *testing.T) {
+		server := newMockServer(t)
+		filePath := writeTestFile(t, "module.py", source)
+
+		_, err := GetSymbolAtPosition(t.Context(), server.Client, filePath, 20, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds file length")
+	})
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolCache(t *testing.T) {
+	const source = "package main\n\nfunc Foo() int {\n\treturn 1\n}\n"
+	const nameRange = `{"start": {"line": 2, "character": 5}, "end": {"line": 2, "character": 8}}`
+
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", source)
+	uri := "file://" + filePath
+	server.RespondRaw("workspace/symbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "location": {"uri": "%s", "range": %s}}]`, uri, nameRange))
+	server.RespondRaw("textDocument/definition", fmt.Sprintf(`{"uri": "%s", "range": %s}`, uri, nameRange))
+	server.RespondRaw("textDocument/documentSymbol", fmt.Sprintf(`[{"name": "Foo", "kind": 12, "range": {"start": {"line": 2, "character": 0}, "end": {"line": 4, "character": 1}}, "selectionRange": %s}]`, nameRange))
+
+	// Repeated lookups of the same symbol reuse the first result
+	for range 3 {
+		result, err := ReadDefinition(t.Context(), server.Client, "Foo")
+		require.NoError(t, err)
+		assert.Contains(t, result, "Symbol: Foo")
+	}
+	assert.Len(t, server.Received("workspace/symbol"), 1)
+
+	// Other queries are cached separately
+	_, err := ReadDefinition(t.Context(), server.Client, "Bar")
+	require.NoError(t, err)
+	assert.Len(t, server.Received("workspace/symbol"), 2)
+
+	// Editing a file invalidates the cache
+	_, err = ApplyTextEdits(t.Context(), server.Client, filePath, []TextEdit{{StartLine: 1, EndLine: 1, NewText: "// Foo returns one\n"}})
+	require.NoError(t, err)
+	_, err = ReadDefinition(t.Context(), server.Client, "Foo")
+	require.NoError(t, err)
+	assert.Len(t, server.Received("workspace/symbol"), 3)
+}
+
+func TestSymbolCacheLimits(t *testing.T) {
+	server := newMockServer(t)
+	server.RespondRaw("workspace/symbol", `[]`)
+	query := func(name string) {
+		_, err := server.Client.CachedSymbol(t.Context(), protocol.WorkspaceSymbolParams{Query: name})
+		require.NoError(t, err)
+	}
+
+	// The oldest query is evicted once the cache is full
+	server.Client.SetSymbolCache(time.Minute, 2)
+	query("A")
+	query("B")
+	query("C")
+	query("C")
+	query("B")
+	assert.Len(t, server.Received("workspace/symbol"), 3)
+	query("A")
+	assert.Len(t, server.Received("workspace/symbol"), 4)
+
+	// Results expire after the TTL
+	server.Client.SetSymbolCache(10*time.Millisecond, 10)
+	query("A")
+	query("A")
+	assert.Len(t, server.Received("workspace/symbol"), 5)
+	time.Sleep(20 * time.Millisecond)
+	query("A")
+	assert.Len(t, server.Received("workspace/symbol"), 6)
+
+	// A TTL of 0 disables the cache
+	server.Client.SetSymbolCache(0, 10)
+	query("A")
+	query("A")
+	assert.Len(t, server.Received("workspace/symbol"), 8)
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// DefaultOverviewReferences is the number of references symbol_overview lists by default
+const DefaultOverviewReferences = 20
+
+// GetSymbolOverview returns the definition, hover documentation and references of a symbol in
+// one response. The symbol is identified by symbolName, which must match a single definition,
+// or by position if symbolName is empty. At most maxReferences references are listed.
+func GetSymbolOverview(ctx context.Context, client *lsp.Client, symbolName, filePath string, line, column int, includeHover bool, maxReferences int) (string, error) {
+	if maxReferences <= 0 {
+		maxReferences = DefaultOverviewReferences
+	}
+
+	if symbolName != "" {
+		resolvedPath, position, err := resolveSymbolPosition(ctx, client, symbolName)
+		if err != nil {
+			return "", err
+		}
+		filePath = resolvedPath
+		line, column = int(position.Line)+1, int(position.Character)+1
+	} else if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	position := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Position: protocol.Position{
+			Line:      uint32(line - 1),
+			Character: uint32(column - 1),
+		},
+	}
+
+	name := symbolName
+	if name == "" {
+		name = identifierAt(filePath, position.Position)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("=== Definition ===\n\n%s\n", overviewDefinition(ctx, client, position, name)))
+
+	if includeHover {
+		hover, err := GetHoverInfo(ctx, client, filePath, line, column)
+		if err != nil {
+			hover = fmt.Sprintf("Error: %v", err)
+		}
+		result.WriteString(fmt.Sprintf("=== Documentation ===\n\n%s\n\n", strings.TrimSpace(hover)))
+	}
+
+	refs, err := client.References(ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: position,
+		Context: protocol.ReferenceContext{
+			IncludeDeclaration: false,
+		},
+	})
+	if err != nil {
+		result.WriteString(fmt.Sprintf("=== References ===\n\nError: failed to get references: %v\n", err))
+		return result.String(), nil
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line < b.Range.Start.Line
+		}
+		return a.Range.Start.Character < b.Range.Start.Character
+	})
+
+	result.WriteString(fmt.Sprintf("=== References (%d) ===\n\n", len(refs)))
+	if len(refs) == 0 {
+		result.WriteString("No references found\n")
+	}
+
+	// Cache file contents since references tend to cluster in a few files
+	fileLines := make(map[protocol.DocumentUri][]string)
+	for i, ref := range refs {
+		if i == maxReferences {
+			result.WriteString(fmt.Sprintf("... and %d more. Use the references tool to see all of them.\n", len(refs)-maxReferences))
+			break
+		}
+
+		lines, ok := fileLines[ref.URI]
+		if !ok {
+			if content, err := os.ReadFile(ref.URI.Path()); err == nil {
+				lines = strings.Split(string(content), "\n")
+			}
+			fileLines[ref.URI] = lines
+		}
+
+		result.WriteString(fmt.Sprintf("%s:%d:%d", ref.URI.Path(), ref.Range.Start.Line+1, ref.Range.Start.Character+1))
+		if int(ref.Range.Start.Line) < len(lines) {
+			result.WriteString(": " + strings.TrimSpace(lines[ref.Range.Start.Line]))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String(), nil
+}
+
+// overviewDefinition returns the formatted definition of the symbol at position, or a
+// description of why it could not be found
+func overviewDefinition(ctx context.Context, client *lsp.Client, position protocol.TextDocumentPositionParams, name string) string {
+	defParams := protocol.DefinitionParams{
+		TextDocumentPositionParams: position,
+	}
+	defResult, err := lsp.RetryDocumentRequest(ctx, client, position.TextDocument.URI.Path(),
+		func(r protocol.Or_Result_textDocument_definition) bool { return r.Value == nil },
+		func() (protocol.Or_Result_textDocument_definition, error) { return client.Definition(ctx, defParams) })
+	if err != nil {
+		return fmt.Sprintf("Error: failed to get definition: %v\n", err)
+	}
+
+	locations, err := extractDefinitionLocations(defResult)
+	if err != nil || len(locations) == 0 {
+		return "No definition found\n"
+	}
+
+	loc := locations[0]
+	if err := client.OpenFile(ctx, loc.URI.Path()); err != nil {
+		return fmt.Sprintf("Error: could not open file: %v\n", err)
+	}
+
+	definition, finalLoc, err := GetFullDefinition(ctx, client, loc)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to get full definition: %v\n", err)
+	}
+
+	formatted := definitionMatch{
+		name:        name,
+		location:    finalLoc,
+		declaration: loc,
+		body:        addLineNumbers(definition, int(finalLoc.Range.Start.Line)+1),
+	}.format(0, 0)
+	// The section header already separates the definition from the rest of the output
+	return strings.TrimPrefix(formatted, "---\n\n")
+}
+
+// identifierAt returns the identifier surrounding position, in UTF-16 code units, in a file,
+// or "" if there is none
+func identifierAt(filePath string, position protocol.Position) string {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(content), "\n")
+	if int(position.Line) >= len(lines) {
+		return ""
+	}
+
+	line := lines[position.Line]
+	start := utilities.UTF16OffsetToByteOffset(line, int(position.Character))
+	end := start
+	for isIdentifierByte(line, start-1) {
+		start--
+	}
+	for isIdentifierByte(line, end) {
+		end++
+	}
+	return line[start:end]
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifierAt(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("func main() {\n\tfmt.Println(helper_fn(x))\n}\n"), 0644))
+
+	tests := []struct {
+		name     string
+		position protocol.Position
+		expected string
+	}{
+		{name: "start of identifier", position: protocol.Position{Line: 1, Character: 13}, expected: "helper_fn"},
+		{name: "middle of identifier", position: protocol.Position{Line: 1, Character: 17}, expected: "helper_fn"},
+		{name: "after qualifier", position: protocol.Position{Line: 1, Character: 5}, expected: "Println"},
+		{name: "on punctuation", position: protocol.Position{Line: 1, Character: 25}, expected: ""},
+		{name: "line out of range", position: protocol.Position{Line: 10, Character: 0}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, identifierAt(filePath, tt.position))
+		})
+	}
+
+	// Positions are in UTF-16 code units: "é" is one, "😀" two
+	filePath = filepath.Join(t.TempDir(), "text.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("x := \"é😀\" + name\n"), 0644))
+	assert.Equal(t, "name", identifierAt(filePath, protocol.Position{Line: 0, Character: 14}))
+}
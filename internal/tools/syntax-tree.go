@@ -0,0 +1,105 @@
+//go:build treesitter
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// SyntaxTreeSupported reports whether this binary was built with the treesitter tag and can
+// parse files locally, see GetSyntaxTree
+const SyntaxTreeSupported = true
+
+// maxSyntaxNodeText is the longest node text shown inline in a syntax tree path
+const maxSyntaxNodeText = 60
+
+// syntaxTreeLanguages are the tree-sitter grammars by file extension
+var syntaxTreeLanguages = map[string]struct {
+	name     string
+	language func() *sitter.Language
+}{
+	".go": {"go", golang.GetLanguage},
+	".c":  {"c", c.GetLanguage},
+	".h":  {"c", c.GetLanguage},
+}
+
+// GetSyntaxTree parses a file with tree-sitter, without the language server, and returns
+// the path of syntax nodes from the root to the smallest named node at a position. line and
+// column are 1-indexed, column counting bytes.
+func GetSyntaxTree(ctx context.Context, filePath string, line, column int) (string, error) {
+	grammar, ok := syntaxTreeLanguages[strings.ToLower(filepath.Ext(filePath))]
+	if !ok {
+		return "", fmt.Errorf("syntax_tree supports Go and C files, not %s", filepath.Base(filePath))
+	}
+
+	if err := validateBytePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(grammar.language())
+	tree, err := parser.ParseCtx(ctx, nil, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+	defer tree.Close()
+
+	point := sitter.Point{Row: uint32(line - 1), Column: uint32(column - 1)}
+	node := tree.RootNode().NamedDescendantForPointRange(point, point)
+
+	var path []*sitter.Node
+	for n := node; n != nil; n = n.Parent() {
+		path = append([]*sitter.Node{n}, path...)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Syntax tree at L%d:C%d (%s, parsed locally with tree-sitter):\n", line, column, grammar.name))
+	for depth, n := range path {
+		result.WriteString(strings.Repeat("  ", depth))
+		if depth > 0 {
+			if field := fieldName(path[depth-1], n); field != "" {
+				result.WriteString(field + ": ")
+			}
+		}
+		result.WriteString(formatSyntaxNode(n, content))
+		result.WriteString("\n")
+	}
+	return result.String(), nil
+}
+
+// fieldName returns the name of the field child is stored in, e.g. "body", or "" if none
+func fieldName(parent, child *sitter.Node) string {
+	for i := range int(parent.ChildCount()) {
+		if parent.Child(i).Equal(child) {
+			return parent.FieldNameForChild(i)
+		}
+	}
+	return ""
+}
+
+// formatSyntaxNode describes a node as its type and 1-indexed range, followed by its text if
+// that is short and on one line
+func formatSyntaxNode(n *sitter.Node, content []byte) string {
+	start, end := n.StartPoint(), n.EndPoint()
+	text := fmt.Sprintf("%s (L%d:C%d-L%d:C%d)", n.Type(), start.Row+1, start.Column+1, end.Row+1, end.Column+1)
+	if n.IsMissing() {
+		text += " [missing]"
+	}
+	if nodeText := n.Content(content); start.Row == end.Row && nodeText != "" && len(nodeText) <= maxSyntaxNodeText {
+		text += ": " + nodeText
+	}
+	return text
+}
@@ -0,0 +1,17 @@
+//go:build !treesitter
+
+package tools
+
+import (
+	"context"
+	"errors"
+)
+
+// SyntaxTreeSupported reports whether this binary was built with the treesitter tag and can
+// parse files locally, see GetSyntaxTree
+const SyntaxTreeSupported = false
+
+// GetSyntaxTree needs tree-sitter, which is only linked in when building with -tags treesitter
+func GetSyntaxTree(ctx context.Context, filePath string, line, column int) (string, error) {
+	return "", errors.New("syntax_tree is not available, build with -tags treesitter to enable it")
+}
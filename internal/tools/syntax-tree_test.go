@@ -0,0 +1,79 @@
+//go:build treesitter
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSyntaxTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		line     int
+		column   int
+		expected []string
+	}{
+		{
+			name:    "go call in function body",
+			file:    "main.go",
+			content: "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+			line:    4,
+			column:  2,
+			expected: []string{
+				"Syntax tree at L4:C2 (go, parsed locally with tree-sitter):",
+				"source_file (L1:C1-L6:C1)",
+				"  function_declaration (L3:C1-L5:C2)",
+				"    body: block (L3:C13-L5:C2)",
+				"        function: identifier (L4:C2-L4:C9): println",
+			},
+		},
+		{
+			name:    "byte column after multi-byte characters",
+			file:    "main.go",
+			content: "package main\n\nvar s = \"é😀\" + name\n",
+			line:    3,
+			column:  22,
+			expected: []string{
+				"Syntax tree at L3:C22 (go, parsed locally with tree-sitter):",
+				"right: identifier (L3:C20-L3:C24): name",
+			},
+		},
+		{
+			name:    "c struct field",
+			file:    "point.h",
+			content: "struct point {\n  int x;\n};\n",
+			line:    2,
+			column:  7,
+			expected: []string{
+				"(c, parsed locally with tree-sitter)",
+				"    body: field_declaration_list (L1:C14-L3:C2)",
+				"        declarator: field_identifier (L2:C7-L2:C8): x",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTestFile(t, tt.file, tt.content)
+
+			result, err := GetSyntaxTree(t.Context(), filePath, tt.line, tt.column)
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, result, expected)
+			}
+		})
+	}
+}
+
+func TestGetSyntaxTreeErrors(t *testing.T) {
+	_, err := GetSyntaxTree(t.Context(), writeTestFile(t, "main.py", "x = 1\n"), 1, 1)
+	assert.ErrorContains(t, err, "supports Go and C files")
+
+	_, err = GetSyntaxTree(t.Context(), writeTestFile(t, "main.go", "package main\n"), 5, 1)
+	assert.ErrorContains(t, err, "line 5 exceeds file length 1")
+}
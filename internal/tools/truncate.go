@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxOutputSize is the maximum number of bytes a verbose tool returns in a single call
+const DefaultMaxOutputSize = 50000
+
+// TruncateOutput returns the part of text starting at byte offset, capped at maxSize bytes.
+// The cut is made at a line boundary where possible. If output remains after the cut, a
+// marker is appended telling the caller which offset to pass to fetch the remainder.
+func TruncateOutput(text string, offset, maxSize int) (string, error) {
+	if offset < 0 {
+		return "", fmt.Errorf("offset must be non-negative, got %d", offset)
+	}
+	if offset > 0 && offset >= len(text) {
+		return "", fmt.Errorf("offset %d is beyond the end of the output (%d bytes)", offset, len(text))
+	}
+
+	end := offset + maxSize
+	if maxSize <= 0 || end >= len(text) {
+		return text[offset:], nil
+	}
+
+	// Prefer to cut after the last complete line, otherwise on a rune boundary
+	if idx := strings.LastIndex(text[offset:end], "\n"); idx > 0 {
+		end = offset + idx + 1
+	} else {
+		for end > offset && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+
+	return fmt.Sprintf("%s\n[Output truncated: showing bytes %d-%d of %d. Call again with offset=%d to see more.]",
+		text[offset:end], offset, end, len(text), end), nil
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	text := "line one\nline two\nline three\n"
+
+	tests := []struct {
+		name     string
+		offset   int
+		maxSize  int
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "fits",
+			maxSize:  100,
+			expected: text,
+		},
+		{
+			name:     "no limit",
+			maxSize:  0,
+			expected: text,
+		},
+		{
+			name:     "cut at line boundary",
+			maxSize:  20,
+			expected: "line one\nline two\n\n[Output truncated: showing bytes 0-18 of 29. Call again with offset=18 to see more.]",
+		},
+		{
+			name:     "continue from offset",
+			offset:   18,
+			maxSize:  20,
+			expected: "line three\n",
+		},
+		{
+			name:     "no newline in window",
+			offset:   0,
+			maxSize:  4,
+			expected: "line\n[Output truncated: showing bytes 0-4 of 29. Call again with offset=4 to see more.]",
+		},
+		{
+			name:    "offset past end",
+			offset:  29,
+			maxSize: 20,
+			wantErr: true,
+		},
+		{
+			name:    "negative offset",
+			offset:  -1,
+			maxSize: 20,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := TruncateOutput(text, tt.offset, tt.maxSize)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestTruncateOutputRuneBoundary(t *testing.T) {
+	text := strings.Repeat("é", 10) // 2 bytes per rune, no newlines
+
+	result, err := TruncateOutput(text, 0, 5)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result, "éé\n[Output truncated: showing bytes 0-4 of 20."))
+}
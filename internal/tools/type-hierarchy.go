@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// GetTypeHierarchy returns the supertypes or subtypes of a type at the given position
+// direction should be "supertypes" or "subtypes"
+func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (string, error) {
+	// Validate direction parameter
+	if direction != "supertypes" && direction != "subtypes" {
+		return "", fmt.Errorf("direction must be 'supertypes' or 'subtypes', got: %s", direction)
+	}
+	if err := validatePosition(filePath, line, column); err != nil {
+		return "", err
+	}
+
+	// Open the file first
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+
+	// Create URI from file path
+	uri := protocol.DocumentUri(fmt.Sprintf("file://%s", filePath))
+
+	params := protocol.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: uri,
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),   // Convert from 1-indexed to 0-indexed
+				Character: uint32(column - 1), // Convert from 1-indexed to 0-indexed
+			},
+		},
+	}
+
+	// Call PrepareTypeHierarchy to get TypeHierarchyItem
+	items, err := client.PrepareTypeHierarchy(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare type hierarchy: %w", err)
+	}
+
+	// Check if we found any type at the position
+	if len(items) == 0 {
+		return fmt.Sprintf("No type found at %s:%d:%d", filePath, line, column), nil
+	}
+
+	// Take the first item (most relevant)
+	item := items[0]
+
+	var related []protocol.TypeHierarchyItem
+	var header string
+	if direction == "supertypes" {
+		related, err = client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+		if err != nil {
+			return "", fmt.Errorf("failed to get supertypes: %w", err)
+		}
+		header = "Supertypes of"
+	} else {
+		related, err = client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+		if err != nil {
+			return "", fmt.Errorf("failed to get subtypes: %w", err)
+		}
+		header = "Subtypes of"
+	}
+
+	// Format the output
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s: %s\n\n", header, formatTypeHierarchyItem(item)))
+
+	if len(related) == 0 {
+		result.WriteString(fmt.Sprintf("No %s found\n", direction))
+		return result.String(), nil
+	}
+
+	for i, relatedItem := range related {
+		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatTypeHierarchyItem(relatedItem)))
+	}
+
+	return result.String(), nil
+}
+
+// formatTypeHierarchyItem formats a type as "[Kind] Name (detail) at file:line"
+func formatTypeHierarchyItem(item protocol.TypeHierarchyItem) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[%s] %s", protocol.TableKindMap[item.Kind], item.Name))
+	if item.Detail != "" {
+		result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
+	}
+	result.WriteString(fmt.Sprintf(" at %s:%d",
+		utilities.URIToPath(item.URI),
+		item.Range.Start.Line+1))
+	return result.String()
+}
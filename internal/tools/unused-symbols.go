@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultUnusedSymbolsVerifyLimit is the number of symbols GetUnusedSymbols asks the server
+// for references of at most, as every symbol costs a request
+const DefaultUnusedSymbolsVerifyLimit = 100
+
+// unusedDiagnosticPattern matches the messages of diagnostics about unused code, for servers
+// that don't tag them with DiagnosticTag Unnecessary
+var unusedDiagnosticPattern = regexp.MustCompile(`(?i)\b(unused|not used|never used|never read|is declared but)\b`)
+
+// unusedSymbolKinds are the kinds of symbols whose references are checked. Fields, parameters
+// and the like are left out, they are often used in ways references don't show, e.g. by
+// serialization.
+var unusedSymbolKinds = []protocol.SymbolKind{
+	protocol.Function, protocol.Method, protocol.Class, protocol.Struct, protocol.Interface,
+	protocol.Enum, protocol.Variable, protocol.Constant,
+}
+
+// unusedSymbol is a symbol found to be unused, either by a diagnostic or because it has no
+// references
+type unusedSymbol struct {
+	name     string
+	kind     string
+	position protocol.Position
+	reason   string
+}
+
+// GetUnusedSymbols lists the unused symbols of a file. The server's diagnostics about unused
+// code are fast but only cover what the server checks, usually local variables and imports.
+// With verifyReferences, the functions, types, variables and constants of the file are also
+// checked for references, reporting those that are referenced nowhere but in their own body.
+func GetUnusedSymbols(ctx context.Context, client *lsp.Client, filePath string, verifyReferences bool) (string, error) {
+	if err := waitForFileDiagnostics(ctx, client, filePath); err != nil {
+		return "", err
+	}
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	symbols, err := fileSymbols(ctx, client, uri)
+	if err != nil {
+		return "", err
+	}
+
+	var diagnosed []unusedSymbol
+	for _, diagnostic := range client.GetFileDiagnostics(uri) {
+		if !slices.Contains(diagnostic.Tags, protocol.Unnecessary) && !unusedDiagnosticPattern.MatchString(diagnostic.Message) {
+			continue
+		}
+		unused := unusedSymbol{
+			name:     rangeText(lines, diagnostic.Range),
+			kind:     "Code",
+			position: diagnostic.Range.Start,
+			reason:   diagnostic.Message,
+		}
+		if symbol := symbolAt(symbols, diagnostic.Range.Start); symbol != nil {
+			unused.name, unused.kind = symbol.Name, protocol.TableKindMap[symbol.Kind]
+		} else if strings.Contains(strings.ToLower(diagnostic.Message), "import") {
+			unused.kind = "Import"
+		}
+		diagnosed = append(diagnosed, unused)
+	}
+	sortUnusedSymbols(diagnosed)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Unused symbols in %s:\n\n", filePath))
+	result.WriteString(fmt.Sprintf("Reported by the language server (%d):\n", len(diagnosed)))
+	writeUnusedSymbols(&result, diagnosed)
+
+	if !verifyReferences {
+		result.WriteString("\nOnly the server's diagnostics were checked. Pass verifyReferences to also list functions, types, variables and constants without references.\n")
+		return result.String(), nil
+	}
+
+	unreferenced, skipped, err := unreferencedSymbols(ctx, client, uri, symbols, diagnosed)
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(fmt.Sprintf("\nWithout references outside their own body (%d):\n", len(unreferenced)))
+	writeUnusedSymbols(&result, unreferenced)
+	if skipped > 0 {
+		result.WriteString(fmt.Sprintf("\n%d more symbols were not checked, at most %d are.\n", skipped, DefaultUnusedSymbolsVerifyLimit))
+	}
+	result.WriteString("\nSymbols used from outside the workspace, by reflection or as entry points (e.g. main or tests) have no references either, check before removing them.\n")
+	return result.String(), nil
+}
+
+// fileSymbols returns the symbols of a file, flattened. Flat SymbolInformation results are
+// converted to DocumentSymbols whose range and selection range are their location.
+func fileSymbols(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri) ([]protocol.DocumentSymbol, error) {
+	symbolResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	results, err := symbolResult.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+
+	var symbols []protocol.DocumentSymbol
+	var flatten func(symbol protocol.DocumentSymbol)
+	flatten = func(symbol protocol.DocumentSymbol) {
+		symbols = append(symbols, symbol)
+		for _, child := range symbol.Children {
+			flatten(child)
+		}
+	}
+	for _, result := range results {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			flatten(*v)
+		case *protocol.SymbolInformation:
+			symbols = append(symbols, protocol.DocumentSymbol{
+				Name:           v.Name,
+				Kind:           v.Kind,
+				Range:          v.Location.Range,
+				SelectionRange: v.Location.Range,
+			})
+		}
+	}
+	return symbols, nil
+}
+
+// symbolAt returns the symbol whose name is at position, or nil
+func symbolAt(symbols []protocol.DocumentSymbol, position protocol.Position) *protocol.DocumentSymbol {
+	for i := range symbols {
+		if symbols[i].SelectionRange.Start == position {
+			return &symbols[i]
+		}
+	}
+	return nil
+}
+
+// unreferencedSymbols returns the symbols of unusedSymbolKinds that have no references except
+// in their own range, leaving out those already diagnosed. The second value is the number of
+// symbols that were not checked because of DefaultUnusedSymbolsVerifyLimit.
+func unreferencedSymbols(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, symbols []protocol.DocumentSymbol, diagnosed []unusedSymbol) ([]unusedSymbol, int, error) {
+	var unreferenced []unusedSymbol
+	checked, skipped := 0, 0
+	for _, symbol := range symbols {
+		if !slices.Contains(unusedSymbolKinds, symbol.Kind) || slices.ContainsFunc(diagnosed, func(u unusedSymbol) bool {
+			return u.position == symbol.SelectionRange.Start
+		}) {
+			continue
+		}
+		if checked == DefaultUnusedSymbolsVerifyLimit {
+			skipped++
+			continue
+		}
+		checked++
+
+		refs, err := client.References(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Position:     symbol.SelectionRange.Start,
+			},
+			Context: protocol.ReferenceContext{IncludeDeclaration: false},
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get references of %s: %v", symbol.Name, err)
+		}
+		external := slices.ContainsFunc(refs, func(ref protocol.Location) bool {
+			return ref.URI != uri || !rangeContains(symbol.Range, ref.Range)
+		})
+		if !external {
+			unreferenced = append(unreferenced, unusedSymbol{
+				name:     symbol.Name,
+				kind:     protocol.TableKindMap[symbol.Kind],
+				position: symbol.SelectionRange.Start,
+			})
+		}
+	}
+	sortUnusedSymbols(unreferenced)
+	return unreferenced, skipped, nil
+}
+
+// rangeText returns the first line of the text of rng, e.g. the name of an unused variable
+func rangeText(lines []string, rng protocol.Range) string {
+	if int(rng.Start.Line) >= len(lines) {
+		return ""
+	}
+	line := lines[rng.Start.Line]
+	start := min(int(rng.Start.Character), len(line))
+	end := len(line)
+	if rng.End.Line == rng.Start.Line {
+		end = max(start, min(int(rng.End.Character), len(line)))
+	}
+	return strings.TrimSpace(line[start:end])
+}
+
+func sortUnusedSymbols(symbols []unusedSymbol) {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return comparePositions(symbols[i].position, symbols[j].position) < 0
+	})
+}
+
+func writeUnusedSymbols(output *strings.Builder, symbols []unusedSymbol) {
+	if len(symbols) == 0 {
+		output.WriteString("None\n")
+		return
+	}
+	for _, symbol := range symbols {
+		output.WriteString(fmt.Sprintf("L%d:C%d %s %s", symbol.position.Line+1, symbol.position.Character+1, symbol.kind, symbol.name))
+		if symbol.reason != "" {
+			output.WriteString(": " + symbol.reason)
+		}
+		output.WriteString("\n")
+	}
+}
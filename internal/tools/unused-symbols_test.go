@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnusedSymbols(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", `package main
+
+import "os"
+
+func used() {}
+
+func unused() { unused() }
+
+func main() {
+	x := 1
+	used()
+}
+`)
+	uri := "file://" + filePath
+
+	server.Client.SetDiagnosticPull(true)
+	server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": [
+		{"range": {"start": {"line": 2, "character": 7}, "end": {"line": 2, "character": 11}}, "severity": 1, "message": "\"os\" imported and not used"},
+		{"range": {"start": {"line": 9, "character": 1}, "end": {"line": 9, "character": 2}}, "severity": 1, "message": "declared and not used: x", "tags": [1]},
+		{"range": {"start": {"line": 10, "character": 1}, "end": {"line": 10, "character": 5}}, "severity": 2, "message": "call has no effect"}
+	]}`)
+	server.RespondRaw("textDocument/documentSymbol", `[
+		{"name": "used", "kind": 12, "range": {"start": {"line": 4, "character": 0}, "end": {"line": 4, "character": 14}}, "selectionRange": {"start": {"line": 4, "character": 5}, "end": {"line": 4, "character": 9}}},
+		{"name": "unused", "kind": 12, "range": {"start": {"line": 6, "character": 0}, "end": {"line": 6, "character": 26}}, "selectionRange": {"start": {"line": 6, "character": 5}, "end": {"line": 6, "character": 11}}},
+		{"name": "main", "kind": 12, "range": {"start": {"line": 8, "character": 0}, "end": {"line": 11, "character": 1}}, "selectionRange": {"start": {"line": 8, "character": 5}, "end": {"line": 8, "character": 9}}}
+	]`)
+	server.Handle("textDocument/references", func(params json.RawMessage) (any, error) {
+		var refParams protocol.ReferenceParams
+		if err := json.Unmarshal(params, &refParams); err != nil {
+			return nil, err
+		}
+		ref := func(line, character uint32) protocol.Location {
+			return protocol.Location{URI: protocol.DocumentUri(uri), Range: protocol.Range{
+				Start: protocol.Position{Line: line, Character: character},
+				End:   protocol.Position{Line: line, Character: character + 4},
+			}}
+		}
+		switch refParams.Position.Line {
+		case 4:
+			return []protocol.Location{ref(10, 1)}, nil
+		case 6:
+			// Only the recursive call in its own body
+			return []protocol.Location{ref(6, 16)}, nil
+		}
+		return []protocol.Location{}, nil
+	})
+
+	result, err := GetUnusedSymbols(t.Context(), server.Client, filePath, false)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Reported by the language server (2):\nL3:C8 Import \"os\": \"os\" imported and not used\nL10:C2 Code x: declared and not used: x\n")
+	assert.Contains(t, result, "Pass verifyReferences")
+	assert.Empty(t, server.Received("textDocument/references"))
+
+	result, err = GetUnusedSymbols(t.Context(), server.Client, filePath, true)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Without references outside their own body (2):\nL7:C6 Function unused\nL9:C6 Function main\n")
+	assert.Contains(t, result, "entry points (e.g. main or tests)")
+	assert.Len(t, server.Received("textDocument/references"), 3)
+}
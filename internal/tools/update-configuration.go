@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// UpdateConfiguration sends settings to the server with workspace/didChangeConfiguration. With
+// refreshDiagnostics, the diagnostics of the open files are requested again, or waited for
+// on servers that only publish them, so that the effect of the change shows right away.
+// The settings also answer the server's workspace/configuration requests from now on.
+func UpdateConfiguration(ctx context.Context, client *lsp.Client, settings map[string]any, refreshDiagnostics bool) (string, error) {
+	// Note how many publishes we've seen before the change, so that we only wait for
+	// diagnostics published because of it
+	openFiles := client.OpenFilePaths()
+	publishCounts := make(map[string]int, len(openFiles))
+	for _, path := range openFiles {
+		publishCounts[path] = client.DiagnosticsPublishCount(protocol.DocumentUri("file://" + path))
+	}
+
+	// Servers such as gopls ask for the new settings with workspace/configuration on
+	// didChangeConfiguration rather than reading them from the notification
+	client.SetSettings(settings)
+	if err := client.DidChangeConfiguration(ctx, protocol.DidChangeConfigurationParams{Settings: settings}); err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString("Sent the configuration to the language server (workspace/didChangeConfiguration).\n")
+	if !refreshDiagnostics {
+		return result.String(), nil
+	}
+	if len(openFiles) == 0 {
+		result.WriteString("\nNo files are open, so there are no diagnostics to refresh.\n")
+		return result.String(), nil
+	}
+
+	result.WriteString("\nDiagnostics of the open files after the change:\n")
+	deadline := time.Now().Add(diagnosticsWaitTimeout())
+	for _, path := range openFiles {
+		uri := protocol.DocumentUri("file://" + path)
+		refreshed := true
+		if client.DiagnosticPull() {
+			if _, err := PullDiagnostics(ctx, client, path); err != nil {
+				toolsLogger.Warn("failed to pull diagnostics for %s: %v", path, err)
+				refreshed = false
+			}
+		} else {
+			refreshed = client.WaitForDiagnostics(ctx, uri, publishCounts[path], max(time.Until(deadline), 0))
+		}
+
+		result.WriteString(fmt.Sprintf("- %s: %d diagnostics", path, len(client.GetFileDiagnostics(uri))))
+		if !refreshed {
+			result.WriteString(" (not refreshed, the server did not report diagnostics in time)")
+		}
+		result.WriteString("\n")
+	}
+	return result.String(), nil
+}
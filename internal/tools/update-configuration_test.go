@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateConfiguration(t *testing.T) {
+	server := newMockServer(t)
+	settings := map[string]any{"gopls": map[string]any{"staticcheck": true}}
+
+	result, err := UpdateConfiguration(t.Context(), server.Client, settings, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Sent the configuration to the language server (workspace/didChangeConfiguration).\n", result)
+
+	require.Eventually(t, func() bool { return len(server.Received("workspace/didChangeConfiguration")) == 1 }, time.Second, 10*time.Millisecond)
+	var params protocol.DidChangeConfigurationParams
+	require.NoError(t, json.Unmarshal(server.Received("workspace/didChangeConfiguration")[0], &params))
+	assert.Equal(t, settings, params.Settings)
+
+	result, err = UpdateConfiguration(t.Context(), server.Client, settings, true)
+	require.NoError(t, err)
+	assert.Contains(t, result, "No files are open")
+}
+
+func TestUpdateConfigurationRefreshDiagnostics(t *testing.T) {
+	server := newMockServer(t)
+	server.Client.SetDiagnosticPull(true)
+	filePath := writeTestFile(t, "main.go", "var x = 1\n")
+	require.NoError(t, server.Client.OpenFile(t.Context(), filePath))
+
+	server.RespondRaw("textDocument/diagnostic", `{"kind": "full", "items": [
+		{"range": {"start": {"line": 0, "character": 4}, "end": {"line": 0, "character": 5}}, "severity": 3, "message": "SA4006: x is never used"}
+	]}`)
+
+	result, err := UpdateConfiguration(t.Context(), server.Client, map[string]any{"gopls": map[string]any{"staticcheck": true}}, true)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Diagnostics of the open files after the change:\n- "+filePath+": 1 diagnostics\n")
+}
@@ -6,12 +6,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf16"
 
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 func ExtractTextFromLocation(loc protocol.Location) (string, error) {
-	path := strings.TrimPrefix(string(loc.URI), "file://")
+	path := utilities.URIToPath(loc.URI)
 
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -68,6 +70,68 @@ func ExtractTextFromLocation(loc protocol.Location) (string, error) {
 	return result.String(), nil
 }
 
+// validatePosition returns an error if the 1-indexed line and column are not a position in
+// filePath, so that the server isn't sent one it would reject or silently move. The column
+// is counted in UTF-16 code units like LSP characters and may be one past the end of the line.
+func validatePosition(filePath string, line, column int) error {
+	return checkPosition(filePath, line, column, func(text string) int {
+		return len(utf16.Encode([]rune(text)))
+	})
+}
+
+// validateBytePosition is validatePosition for columns counted in bytes, as taken by tools
+// that don't send the position to the server
+func validateBytePosition(filePath string, line, column int) error {
+	return checkPosition(filePath, line, column, func(text string) int { return len(text) })
+}
+
+// checkPosition implements validatePosition and validateBytePosition, measuring lines with width
+func checkPosition(filePath string, line, column int, width func(string) int) error {
+	if line < 1 {
+		return fmt.Errorf("line must be at least 1, got %d", line)
+	}
+	if column < 1 {
+		return fmt.Errorf("column must be at least 1, got %d", column)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	// The empty line after a final newline is a valid position but not counted as a line
+	length := len(lines)
+	if length > 1 && lines[length-1] == "" {
+		length--
+	}
+	if line > len(lines) {
+		if len(lines) > length {
+			return fmt.Errorf("line %d exceeds file length %d (line %d, after the final newline, is the last position)", line, length, len(lines))
+		}
+		return fmt.Errorf("line %d exceeds file length %d", line, length)
+	}
+
+	lineWidth := width(strings.TrimPrefix(lines[line-1], "\uFEFF"))
+	if column > lineWidth+1 {
+		return fmt.Errorf("column %d exceeds the length of line %d (%d characters, the last column is %d)", column, line, lineWidth, lineWidth+1)
+	}
+	return nil
+}
+
+// validateRange is validatePosition for both ends of a range, which must not end before it starts
+func validateRange(filePath string, startLine, startColumn, endLine, endColumn int) error {
+	if err := validatePosition(filePath, startLine, startColumn); err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	if err := validatePosition(filePath, endLine, endColumn); err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	if endLine < startLine || endLine == startLine && endColumn < startColumn {
+		return fmt.Errorf("range L%d:C%d-L%d:C%d ends before it starts", startLine, startColumn, endLine, endColumn)
+	}
+	return nil
+}
+
 func containsPosition(r protocol.Range, p protocol.Position) bool {
 	if r.Start.Line > p.Line || r.End.Line < p.Line {
 		return false
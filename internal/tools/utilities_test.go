@@ -454,3 +454,63 @@ func TestFormatLinesWithRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePosition(t *testing.T) {
+	// Three lines, the last ending with a newline; "é" is one UTF-16 unit, "😀" two
+	filePath := writeTestFile(t, "main.go", "package main\n\nvar s = \"é😀\"\n")
+
+	tests := []struct {
+		name   string
+		line   int
+		column int
+		err    string
+	}{
+		{name: "first character", line: 1, column: 1},
+		{name: "end of line", line: 1, column: 13},
+		{name: "past the end of line", line: 1, column: 14, err: "column 14 exceeds the length of line 1 (12 characters, the last column is 13)"},
+		{name: "empty line", line: 2, column: 1},
+		{name: "past the end of an empty line", line: 2, column: 2, err: "column 2 exceeds the length of line 2 (0 characters, the last column is 1)"},
+		{name: "end of line with UTF-16 surrogates", line: 3, column: 14},
+		{name: "past the end of line with UTF-16 surrogates", line: 3, column: 15, err: "column 15 exceeds the length of line 3 (13 characters, the last column is 14)"},
+		{name: "after the final newline", line: 4, column: 1},
+		{name: "past the end of the file", line: 5, column: 1, err: "line 5 exceeds file length 3 (line 4, after the final newline, is the last position)"},
+		{name: "line zero", line: 0, column: 1, err: "line must be at least 1, got 0"},
+		{name: "column zero", line: 1, column: 0, err: "column must be at least 1, got 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePosition(filePath, tt.line, tt.column)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	assert.NoError(t, validateRange(filePath, 1, 1, 3, 14))
+	assert.EqualError(t, validateRange(filePath, 1, 1, 9, 1), "invalid end: line 9 exceeds file length 3 (line 4, after the final newline, is the last position)")
+	assert.EqualError(t, validateRange(filePath, 3, 5, 3, 2), "range L3:C5-L3:C2 ends before it starts")
+}
+
+func TestValidateBytePosition(t *testing.T) {
+	// "é" is two bytes, "😀" four
+	filePath := writeTestFile(t, "main.go", "var s = \"é😀\"")
+
+	// Past the last UTF-16 column, but within the bytes of the line
+	assert.NoError(t, validateBytePosition(filePath, 1, 17))
+	assert.EqualError(t, validateBytePosition(filePath, 1, 18), "column 18 exceeds the length of line 1 (16 characters, the last column is 17)")
+	assert.EqualError(t, validatePosition(filePath, 1, 17), "column 17 exceeds the length of line 1 (13 characters, the last column is 14)")
+	// Without a final newline there is no line after the last one
+	assert.EqualError(t, validateBytePosition(filePath, 2, 1), "line 2 exceeds file length 1")
+}
+
+func TestHoverRejectsPositionOutsideFile(t *testing.T) {
+	server := newMockServer(t)
+	filePath := writeTestFile(t, "main.go", "package main\n")
+
+	_, err := GetHoverInfo(t.Context(), server.Client, filePath, 500, 1)
+	assert.EqualError(t, err, "line 500 exceeds file length 1 (line 2, after the final newline, is the last position)")
+	assert.Empty(t, server.Received("textDocument/hover"))
+}
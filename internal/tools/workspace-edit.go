@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// PreviewWorkspaceEdit renders a WorkspaceEdit as per-file unified diffs without writing
+// anything to disk. Both the changes and documentChanges representations are handled;
+// file create/rename/delete operations are listed by name.
+func PreviewWorkspaceEdit(edit protocol.WorkspaceEdit) (string, error) {
+	// Original and edited content of each file, keyed by path
+	original := make(map[string]string)
+	edited := make(map[string]string)
+	var operations []string
+
+	applyEdits := func(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+		path := utilities.URIToPath(uri)
+		current, ok := edited[path]
+		if !ok {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %w", err)
+			}
+			current = string(content)
+			original[path] = current
+		}
+		newContent, err := utilities.ApplyTextEditsToContent([]byte(current), edits)
+		if err != nil {
+			return fmt.Errorf("failed to apply edits to %s: %w", path, err)
+		}
+		edited[path] = string(newContent)
+		return nil
+	}
+
+	for uri, edits := range edit.Changes {
+		if err := applyEdits(uri, edits); err != nil {
+			return "", err
+		}
+	}
+
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			textEdits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+			for i, e := range change.TextDocumentEdit.Edits {
+				var err error
+				textEdits[i], err = e.AsTextEdit()
+				if err != nil {
+					return "", fmt.Errorf("invalid edit type: %w", err)
+				}
+			}
+			if err := applyEdits(change.TextDocumentEdit.TextDocument.URI, textEdits); err != nil {
+				return "", err
+			}
+		case change.CreateFile != nil:
+			operations = append(operations, fmt.Sprintf("Create file: %s", utilities.URIToPath(change.CreateFile.URI)))
+		case change.RenameFile != nil:
+			operations = append(operations, fmt.Sprintf("Rename file: %s -> %s",
+				utilities.URIToPath(change.RenameFile.OldURI),
+				utilities.URIToPath(change.RenameFile.NewURI)))
+		case change.DeleteFile != nil:
+			operations = append(operations, fmt.Sprintf("Delete file: %s", utilities.URIToPath(change.DeleteFile.URI)))
+		}
+	}
+
+	// Sort by path for consistent output
+	paths := make([]string, 0, len(edited))
+	for path := range edited {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var result strings.Builder
+	for _, operation := range operations {
+		result.WriteString(operation + "\n")
+	}
+	if len(operations) > 0 && len(paths) > 0 {
+		result.WriteString("\n")
+	}
+
+	for _, path := range paths {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitDiffLines(original[path]),
+			B:        splitDiffLines(edited[path]),
+			FromFile: "a" + path,
+			ToFile:   "b" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to diff %s: %w", path, err)
+		}
+		result.WriteString(diff)
+	}
+
+	return result.String(), nil
+}
+
+// splitDiffLines splits text into lines that keep their terminators. Unlike difflib.SplitLines
+// it does not add a phantom empty line when the text ends with a newline.
+func splitDiffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
+
+// formatAppliedEdits summarizes the workspace edits the server applied while handling a request,
+// so that tool results show which files a command changed
+func formatAppliedEdits(edits []lsp.AppliedEdit) string {
+	if len(edits) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\n\nThe server applied the following edits:\n")
+	for _, edit := range edits {
+		label := edit.Label
+		if label == "" {
+			label = "Workspace edit"
+		}
+		result.WriteString(fmt.Sprintf("- %s: %s\n", label, strings.Join(edit.Files, ", ")))
+	}
+	return result.String()
+}
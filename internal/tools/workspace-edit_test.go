@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWorkspaceEdit(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "main.go")
+	original := "package main\n\nfunc oldName() {}\n\nfunc main() {\n\toldName()\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(original), 0644))
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	renameEdits := []protocol.TextEdit{
+		{Range: protocol.Range{Start: protocol.Position{Line: 2, Character: 5}, End: protocol.Position{Line: 2, Character: 12}}, NewText: "newName"},
+		{Range: protocol.Range{Start: protocol.Position{Line: 5, Character: 1}, End: protocol.Position{Line: 5, Character: 8}}, NewText: "newName"},
+	}
+
+	expectedDiff := "--- a" + filePath + "\n" +
+		"+++ b" + filePath + "\n" +
+		"@@ -1,7 +1,7 @@\n" +
+		" package main\n" +
+		" \n" +
+		"-func oldName() {}\n" +
+		"+func newName() {}\n" +
+		" \n" +
+		" func main() {\n" +
+		"-\toldName()\n" +
+		"+\tnewName()\n" +
+		" }\n"
+
+	t.Run("changes", func(t *testing.T) {
+		preview, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{uri: renameEdits},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expectedDiff, preview)
+	})
+
+	t.Run("documentChanges", func(t *testing.T) {
+		edits := make([]protocol.Or_TextDocumentEdit_edits_Elem, len(renameEdits))
+		for i, edit := range renameEdits {
+			edits[i] = protocol.Or_TextDocumentEdit_edits_Elem{Value: edit}
+		}
+		preview, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{TextDocumentEdit: &protocol.TextDocumentEdit{
+					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+						TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+					},
+					Edits: edits,
+				}},
+				{RenameFile: &protocol.RenameFile{
+					Kind:   "rename",
+					OldURI: uri,
+					NewURI: protocol.DocumentUri("file://" + filepath.Join(dir, "renamed.go")),
+				}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Rename file: "+filePath+" -> "+filepath.Join(dir, "renamed.go")+"\n\n"+expectedDiff, preview)
+	})
+
+	// The file on disk must be left untouched
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
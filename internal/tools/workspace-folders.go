@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// UpdateWorkspaceFolders adds and removes workspace roots, then lists the roots the server
+// knows about. Relative paths are resolved against the primary workspace folder.
+func UpdateWorkspaceFolders(ctx context.Context, client *lsp.Client, add, remove []string) (string, error) {
+	primary := ""
+	if folders := client.WorkspaceFolders(); len(folders) > 0 {
+		primary = folders[0]
+	}
+	resolve := func(dirs []string) []string {
+		resolved := make([]string, len(dirs))
+		for i, dir := range dirs {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(primary, dir)
+			}
+			resolved[i] = filepath.Clean(dir)
+		}
+		return resolved
+	}
+
+	if len(add) > 0 {
+		if err := client.AddWorkspaceFolders(ctx, resolve(add)...); err != nil {
+			return "", err
+		}
+	}
+	if len(remove) > 0 {
+		if err := client.RemoveWorkspaceFolders(ctx, resolve(remove)...); err != nil {
+			return "", err
+		}
+	}
+
+	folders := client.WorkspaceFolders()
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Workspace folders (%d):\n", len(folders)))
+	for i, folder := range folders {
+		if i == 0 {
+			result.WriteString(fmt.Sprintf("- %s (primary)\n", folder))
+			continue
+		}
+		result.WriteString(fmt.Sprintf("- %s\n", folder))
+	}
+	return result.String(), nil
+}
@@ -20,9 +20,12 @@ var (
 	osRename    = os.Rename
 )
 
+// utf8BOM is the byte order mark some editors, notably on Windows, put at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // ApplyTextEdits applies a sequence of text edits to a file specified by URI
 func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
-	path := strings.TrimPrefix(string(uri), "file://")
+	path := URIToPath(uri)
 
 	// Read the file content
 	content, err := osReadFile(path)
@@ -30,6 +33,26 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
+	newContent, err := ApplyTextEditsToContent(content, edits)
+	if err != nil {
+		return err
+	}
+
+	if err := osWriteFile(path, newContent, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyTextEditsToContent applies a sequence of text edits to file content in memory,
+// preserving its byte order mark, line endings and trailing newline
+func ApplyTextEditsToContent(content []byte, edits []protocol.TextEdit) ([]byte, error) {
+	// The BOM isn't part of the text that edits refer to. Set it aside so that an edit at
+	// the start of the file can't remove it, and put it back afterwards.
+	hasBOM := bytes.HasPrefix(content, utf8BOM)
+	content = bytes.TrimPrefix(content, utf8BOM)
+
 	// Detect line ending style
 	var lineEnding string
 	if bytes.Contains(content, []byte("\r\n")) {
@@ -48,7 +71,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for i, edit1 := range edits {
 		for j := i + 1; j < len(edits); j++ {
 			if RangesOverlap(edit1.Range, edits[j].Range) {
-				return fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
+				return nil, fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
 			}
 		}
 	}
@@ -67,7 +90,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for _, edit := range sortedEdits {
 		newLines, err := ApplyTextEdit(lines, edit, lineEnding)
 		if err != nil {
-			return fmt.Errorf("failed to apply edit: %w", err)
+			return nil, fmt.Errorf("failed to apply edit: %w", err)
 		}
 		lines = newLines
 	}
@@ -86,11 +109,11 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		newContent.WriteString(lineEnding)
 	}
 
-	if err := osWriteFile(path, []byte(newContent.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	result := []byte(newContent.String())
+	if hasBOM && !bytes.HasPrefix(result, utf8BOM) {
+		result = append(append([]byte{}, utf8BOM...), result...)
 	}
-
-	return nil
+	return result, nil
 }
 
 // ApplyTextEdit applies a single text edit to a set of lines
@@ -134,8 +157,9 @@ func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([
 			result = append(result, prefix+suffix)
 		}
 	} else {
-		// Split new text into lines
-		newLines := strings.Split(edit.NewText, "\n")
+		// Split new text into lines. The lines are joined with the file's line ending,
+		// so CRLFs in the new text must not leave a stray \r behind.
+		newLines := strings.Split(strings.ReplaceAll(edit.NewText, "\r\n", "\n"), "\n")
 
 		if len(newLines) == 1 {
 			// Single line change
@@ -173,7 +197,7 @@ func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([
 // ApplyDocumentChange applies a DocumentChange (create/rename/delete operations)
 func ApplyDocumentChange(change protocol.DocumentChange) error {
 	if change.CreateFile != nil {
-		path := strings.TrimPrefix(string(change.CreateFile.URI), "file://")
+		path := URIToPath(change.CreateFile.URI)
 		if change.CreateFile.Options != nil {
 			if change.CreateFile.Options.Overwrite {
 				// Proceed with overwrite
@@ -189,7 +213,7 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 	}
 
 	if change.DeleteFile != nil {
-		path := strings.TrimPrefix(string(change.DeleteFile.URI), "file://")
+		path := URIToPath(change.DeleteFile.URI)
 		if change.DeleteFile.Options != nil && change.DeleteFile.Options.Recursive {
 			if err := osRemoveAll(path); err != nil {
 				return fmt.Errorf("failed to delete directory recursively: %w", err)
@@ -202,8 +226,8 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 	}
 
 	if change.RenameFile != nil {
-		oldPath := strings.TrimPrefix(string(change.RenameFile.OldURI), "file://")
-		newPath := strings.TrimPrefix(string(change.RenameFile.NewURI), "file://")
+		oldPath := URIToPath(change.RenameFile.OldURI)
+		newPath := URIToPath(change.RenameFile.NewURI)
 		if change.RenameFile.Options != nil {
 			if !change.RenameFile.Options.Overwrite {
 				if _, err := osStat(newPath); err == nil {
@@ -251,6 +275,39 @@ func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
 	return nil
 }
 
+// WorkspaceEditFiles returns the sorted paths of all files a WorkspaceEdit changes, creates,
+// renames or deletes
+func WorkspaceEditFiles(edit protocol.WorkspaceEdit) []string {
+	seen := make(map[string]bool)
+	add := func(uri protocol.DocumentUri) {
+		seen[URIToPath(uri)] = true
+	}
+
+	for uri := range edit.Changes {
+		add(uri)
+	}
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			add(change.TextDocumentEdit.TextDocument.URI)
+		case change.CreateFile != nil:
+			add(change.CreateFile.URI)
+		case change.RenameFile != nil:
+			add(change.RenameFile.OldURI)
+			add(change.RenameFile.NewURI)
+		case change.DeleteFile != nil:
+			add(change.DeleteFile.URI)
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // RangesOverlap checks if two ranges overlap in position
 func RangesOverlap(r1, r2 protocol.Range) bool {
 	if r1.Start.Line > r2.End.Line || r2.Start.Line > r1.End.Line {
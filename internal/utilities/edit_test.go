@@ -667,6 +667,80 @@ func TestApplyTextEdits(t *testing.T) {
 	}
 }
 
+func TestApplyTextEditsToContentPreservesEncoding(t *testing.T) {
+	const crlfFixture = "package main\r\n\r\nfunc main() {\r\n\treturn\r\n}\r\n"
+	const bomFixture = "\xEF\xBB\xBFline one\nline two\nline three\n"
+
+	lineRange := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		edits    []protocol.TextEdit
+		expected string
+	}{
+		{
+			name:     "CRLF multi-line edit with LF new text",
+			content:  crlfFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(3, 1, 3, 7), NewText: "x := 1\n\t_ = x"}},
+			expected: "package main\r\n\r\nfunc main() {\r\n\tx := 1\r\n\t_ = x\r\n}\r\n",
+		},
+		{
+			name:     "CRLF new text is not doubled",
+			content:  crlfFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(3, 1, 3, 7), NewText: "x := 1\r\n\t_ = x"}},
+			expected: "package main\r\n\r\nfunc main() {\r\n\tx := 1\r\n\t_ = x\r\n}\r\n",
+		},
+		{
+			name:     "CRLF line deletion",
+			content:  crlfFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(1, 0, 2, 0), NewText: ""}},
+			expected: "package main\r\nfunc main() {\r\n\treturn\r\n}\r\n",
+		},
+		{
+			name:     "BOM kept when replacing the first line",
+			content:  bomFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(0, 0, 0, 8), NewText: "first"}},
+			expected: "\xEF\xBB\xBFfirst\nline two\nline three\n",
+		},
+		{
+			name:     "BOM kept when replacing the whole document",
+			content:  bomFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(0, 0, 3, 0), NewText: "a\nb\n"}},
+			expected: "\xEF\xBB\xBFa\nb\n",
+		},
+		{
+			name:     "BOM not doubled when new text has one",
+			content:  bomFixture,
+			edits:    []protocol.TextEdit{{Range: lineRange(0, 0, 3, 0), NewText: "\xEF\xBB\xBFa\n"}},
+			expected: "\xEF\xBB\xBFa\n",
+		},
+		{
+			name:     "BOM and CRLF",
+			content:  "\xEF\xBB\xBFone\r\ntwo\r\n",
+			edits:    []protocol.TextEdit{{Range: lineRange(1, 0, 1, 3), NewText: "2\n3"}},
+			expected: "\xEF\xBB\xBFone\r\n2\r\n3\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyTextEditsToContent([]byte(tt.content), tt.edits)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("ApplyTextEditsToContent() = %q, want %q", string(result), tt.expected)
+			}
+		})
+	}
+}
+
 func TestApplyDocumentChange(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1114,3 +1188,27 @@ func TestApplyWorkspaceEdit(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkspaceEditFiles(t *testing.T) {
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			"file:///test/b.go": {},
+			"file:///test/a.go": {},
+		},
+		DocumentChanges: []protocol.DocumentChange{
+			{TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: "file:///test/a.go"},
+				},
+			}},
+			{CreateFile: &protocol.CreateFile{Kind: "create", URI: "file:///test/new.go"}},
+			{RenameFile: &protocol.RenameFile{Kind: "rename", OldURI: "file:///test/old.go", NewURI: "file:///test/renamed.go"}},
+			{DeleteFile: &protocol.DeleteFile{Kind: "delete", URI: "file:///test/gone.go"}},
+		},
+	}
+
+	expected := []string{"/test/a.go", "/test/b.go", "/test/gone.go", "/test/new.go", "/test/old.go", "/test/renamed.go"}
+	if files := WorkspaceEditFiles(edit); !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
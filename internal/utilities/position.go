@@ -0,0 +1,45 @@
+package utilities
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// UTF16OffsetToByteOffset converts an offset in UTF-16 code units, the unit LSP uses for
+// character positions and label offsets, into a byte offset in text. Offsets past the end
+// of text are clamped to len(text), and an offset that falls inside a surrogate pair maps
+// to the start of that character.
+func UTF16OffsetToByteOffset(text string, offset int) int {
+	units := 0
+	for i, r := range text {
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if units+n > offset {
+			return i
+		}
+		units += n
+	}
+	return len(text)
+}
+
+// ByteOffsetToUTF16Offset converts a byte offset in text into an offset in UTF-16 code
+// units, the inverse of UTF16OffsetToByteOffset. Offsets past the end of text are clamped
+// to its length, and an offset inside a character maps to the start of that character.
+func ByteOffsetToUTF16Offset(text string, offset int) int {
+	units := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if i+size > offset {
+			break
+		}
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		units += n
+		i += size
+	}
+	return units
+}
@@ -0,0 +1,57 @@
+package utilities
+
+import "testing"
+
+func TestUTF16OffsetToByteOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		offset   int
+		expected int
+	}{
+		{"ascii", "func(a int)", 5, 5},
+		{"start", "héllo", 0, 0},
+		{"after two-byte character", "héllo", 2, 3},
+		{"after three-byte character", "x€y", 2, 4},
+		{"after surrogate pair", "a😀b", 3, 5},
+		{"inside surrogate pair", "a😀b", 2, 1},
+		{"end", "héllo", 5, 6},
+		{"past end", "héllo", 10, 6},
+		{"empty", "", 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UTF16OffsetToByteOffset(tt.text, tt.offset); got != tt.expected {
+				t.Errorf("UTF16OffsetToByteOffset(%q, %d) = %d, expected %d", tt.text, tt.offset, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestByteOffsetToUTF16Offset(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		offset   int
+		expected int
+	}{
+		{"ascii", "func(a int)", 5, 5},
+		{"start", "héllo", 0, 0},
+		{"after two-byte character", "héllo", 3, 2},
+		{"after three-byte character", "x€y", 4, 2},
+		{"after surrogate pair", "a😀b", 5, 3},
+		{"inside a character", "a😀b", 3, 1},
+		{"end", "héllo", 6, 5},
+		{"past end", "héllo", 10, 5},
+		{"empty", "", 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ByteOffsetToUTF16Offset(tt.text, tt.offset); got != tt.expected {
+				t.Errorf("ByteOffsetToUTF16Offset(%q, %d) = %d, expected %d", tt.text, tt.offset, got, tt.expected)
+			}
+		})
+	}
+}
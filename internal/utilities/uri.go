@@ -0,0 +1,22 @@
+package utilities
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// URIToPath converts a file URI to a path, decoding percent-escapes such as the %20 servers
+// send for spaces. Paths passed where a URI is expected are returned as they are, and so is
+// the rest of a URI whose escapes are invalid.
+func URIToPath(uri protocol.DocumentUri) string {
+	path, ok := strings.CutPrefix(string(uri), "file://")
+	if !ok {
+		return path
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		return unescaped
+	}
+	return path
+}
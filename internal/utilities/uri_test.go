@@ -0,0 +1,27 @@
+package utilities
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+func TestURIToPath(t *testing.T) {
+	tests := []struct {
+		uri      protocol.DocumentUri
+		expected string
+	}{
+		{uri: "file:///home/user/project/main.go", expected: "/home/user/project/main.go"},
+		{uri: "file:///home/user/my%20project/main.go", expected: "/home/user/my project/main.go"},
+		{uri: "file:///tmp/%E4%BE%8B/a%2Bb.go", expected: "/tmp/例/a+b.go"},
+		{uri: "file:///tmp/100%", expected: "/tmp/100%"},
+		// Paths are not unescaped, a % in them is part of the name
+		{uri: "/tmp/my%20file.go", expected: "/tmp/my%20file.go"},
+	}
+
+	for _, tt := range tests {
+		if path := URIToPath(tt.uri); path != tt.expected {
+			t.Errorf("URIToPath(%q) = %q, want %q", tt.uri, path, tt.expected)
+		}
+	}
+}
@@ -20,6 +20,10 @@ type LSPClient interface {
 
 	// DidChangeWatchedFiles sends watched file events to the server
 	DidChangeWatchedFiles(ctx context.Context, params protocol.DidChangeWatchedFilesParams) error
+
+	// InvalidateSymbolCache drops cached workspace symbols, whose positions may be stale
+	// after files change
+	InvalidateSymbolCache()
 }
 
 // WatcherConfig holds basic configuration for the watcher
@@ -23,6 +23,7 @@ type MockLSPClient struct {
 	notifyErrors   map[string]error
 	changeErrors   map[string]error
 	eventsReceived chan struct{}
+	invalidations  int
 }
 
 // NewMockLSPClient creates a new mock LSP client for testing
@@ -111,6 +112,20 @@ func (m *MockLSPClient) DidChangeWatchedFiles(ctx context.Context, params protoc
 	return nil
 }
 
+// InvalidateSymbolCache mocks dropping cached workspace symbols
+func (m *MockLSPClient) InvalidateSymbolCache() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalidations++
+}
+
+// SymbolCacheInvalidations returns the number of times InvalidateSymbolCache was called
+func (m *MockLSPClient) SymbolCacheInvalidations() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.invalidations
+}
+
 // GetEvents returns a copy of all recorded events
 func (m *MockLSPClient) GetEvents() []FileEvent {
 	m.mu.Lock()
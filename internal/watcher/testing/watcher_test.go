@@ -108,6 +108,15 @@ func TestWatcherBasicFunctionality(t *testing.T) {
 		if count > 1 {
 			t.Errorf("Multiple create events received for %s: %d", filePath, count)
 		}
+
+		// Symbols cached before the file was created are dropped after the server is told
+		deadline := time.Now().Add(time.Second)
+		for mockClient.SymbolCacheInvalidations() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if mockClient.SymbolCacheInvalidations() == 0 {
+			t.Errorf("Symbol cache not invalidated after creating %s", filePath)
+		}
 	})
 
 	t.Run("FileModification", func(t *testing.T) {
@@ -560,7 +560,13 @@ func (w *WorkspaceWatcher) notifyFileEvent(ctx context.Context, uri string, chan
 		},
 	}
 
-	return w.client.DidChangeWatchedFiles(ctx, params)
+	if err := w.client.DidChangeWatchedFiles(ctx, params); err != nil {
+		return err
+	}
+	// Files changed outside the tools, e.g. by a git checkout, aren't synced with didChange,
+	// which invalidates the cache for edits of open files
+	w.client.InvalidateSymbolCache()
+	return nil
 }
 
 // shouldExcludeDir returns true if the directory should be excluded from watching/opening
@@ -8,12 +8,16 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/logging"
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/isaacphi/mcp-language-server/internal/watcher"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -21,30 +25,131 @@ import (
 // Create a logger for the core component
 var coreLogger = logging.NewLogger(logging.Core)
 
+// defaultToolTimeout bounds how long a single tool call waits on the language server
+const defaultToolTimeout = 30 * time.Second
+
+// lspDialTimeout bounds connecting to a language server given by --lsp-address
+const lspDialTimeout = 10 * time.Second
+
 type config struct {
 	workspaceDir string
 	lspCommand   string
 	lspArgs      []string
+	toolTimeout  time.Duration
+	indexWait    time.Duration
+	startupWait  time.Duration
+	logLevel     string
+	logFile      string
+
+	// Additional workspace roots, e.g. the sub-projects of a monorepo
+	workspaceFolders stringList
+
+	// Extra "KEY=value" environment variables and working directory for the language server
+	lspEnv stringList
+	lspDir string
+
+	// Address of an already running language server to connect to instead of lspCommand
+	lspAddress string
+
+	// File extensions the language server handles, see fileExtensions
+	extensions []string
+
+	// Append the unformatted responses of the language server to every tool result
+	rawResponses bool
+
+	// Default format of the tools that can render markdown, see outputFormat
+	outputFormat string
+
+	// How often and how soon to restart the language server after it exits unexpectedly
+	restartAttempts int
+	restartBackoff  time.Duration
 }
 
 type mcpServer struct {
-	config           config
-	lspClient        *lsp.Client
-	mcpServer        *server.MCPServer
-	ctx              context.Context
-	cancelFunc       context.CancelFunc
-	workspaceWatcher *watcher.WorkspaceWatcher
-	capabilities     *protocol.ServerCapabilities
+	config     config
+	mcpServer  *server.MCPServer
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+
+	// The current language server, replaced when it is restarted, see client
+	lspClient   *lsp.Client
+	serverInfo  *protocol.ServerInfo
+	lspStarted  time.Time
+	stopWatcher context.CancelFunc // Stops the workspace watcher of lspClient
+	lspClientMu sync.RWMutex
+
+	// Settings last sent with the update_configuration tool, sent again to a restarted server
+	// and returned for its workspace/configuration requests
+	lspSettings   map[string]any
+	lspSettingsMu sync.Mutex
+
+	// Serializes restarts. restartAttempts counts automatic restarts since the server last
+	// kept running for restartResetAfter.
+	restartAttempts int
+	restartMu       sync.Mutex
+
+	// Names of the registered tools, so registering again after the server registers
+	// capabilities dynamically only adds new ones, and the statistics of their calls
+	registeredTools   map[string]bool
+	toolStats         map[string]*toolStats
+	registeredToolsMu sync.Mutex
+
+	started time.Time
+}
+
+// stringList is a flag that may be repeated to collect several values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func parseConfig() (*config, error) {
 	cfg := &config{}
 	flag.StringVar(&cfg.workspaceDir, "workspace", "", "Path to workspace directory")
+	flag.Var(&cfg.workspaceFolders, "workspace-folder", "Additional workspace root to index (may be repeated)")
 	flag.StringVar(&cfg.lspCommand, "lsp", "", "LSP command to run (args should be passed after --)")
+	flag.StringVar(&cfg.lspAddress, "lsp-address", "", "Connect to a running LSP server at host:port or unix:/path/to/socket instead of starting --lsp")
+	flag.Var(&cfg.lspEnv, "lsp-env", "Environment variable KEY=value for the LSP server, $VAR references are expanded (may be repeated)")
+	flag.StringVar(&cfg.lspDir, "lsp-cwd", "", "Working directory of the LSP server, absolute or relative to the workspace (default: the workspace)")
+	flag.DurationVar(&cfg.toolTimeout, "tool-timeout", toolTimeoutFromEnv(), "Maximum time a tool call waits for the language server (0 disables; default from LSP_TOOL_TIMEOUT)")
+	flag.IntVar(&cfg.restartAttempts, "restart-attempts", 3, "Maximum number of times in a row to restart the LSP server after it exits unexpectedly (0 disables)")
+	flag.DurationVar(&cfg.restartBackoff, "restart-backoff", time.Second, "Delay before restarting the LSP server after it exits, doubled for each further attempt")
+	flag.DurationVar(&cfg.indexWait, "index-wait", 0, "Maximum time tools that need a complete index (definitions, references, renames, ...) wait for the server to finish reporting progress such as indexing (0 disables)")
+	flag.DurationVar(&cfg.startupWait, "startup-index-wait", 0, "Maximum time tool calls wait after the LSP server starts for it to finish its initial indexing, as reported with progress (0 disables)")
+	extensions := flag.String("extensions", "", "Comma separated file extensions the LSP server handles, e.g. .go,.mod, or * for any (default: known for gopls, rust-analyzer, pyright, typescript-language-server and clangd)")
+	flag.BoolVar(&cfg.rawResponses, "raw-responses", false, "Append the raw JSON responses of the LSP server to every tool result, for debugging (single calls can pass raw: true instead)")
+	flag.StringVar(&cfg.outputFormat, "output-format", tools.OutputFormatText, "Format of the results of definition, document_symbols and diagnostics: text or markdown (single calls can pass format instead)")
+	flag.StringVar(&cfg.logLevel, "log-level", "", "Log level: debug, info, warn or error (default from LOG_LEVEL, or info)")
+	flag.StringVar(&cfg.logFile, "log-file", "", "Write logs to this file instead of stderr")
 	flag.Parse()
 
+	// Apply logging options first so that everything after is logged as configured
+	if cfg.logFile != "" {
+		if err := logging.RedirectToFile(cfg.logFile); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.logLevel != "" {
+		level, err := logging.ParseLevel(cfg.logLevel)
+		if err != nil {
+			return nil, err
+		}
+		logging.SetGlobalLevel(level)
+	}
+
+	if err := tools.ValidateOutputFormat(cfg.outputFormat); err != nil {
+		return nil, err
+	}
+
 	// Get remaining args after -- as LSP arguments
 	cfg.lspArgs = flag.Args()
+	cfg.extensions = parseExtensions(*extensions)
 
 	// Validate workspace directory
 	if cfg.workspaceDir == "" {
@@ -61,9 +166,45 @@ func parseConfig() (*config, error) {
 		return nil, fmt.Errorf("workspace directory does not exist: %s", cfg.workspaceDir)
 	}
 
+	// Relative workspace folders are resolved against the workspace directory
+	for i, folder := range cfg.workspaceFolders {
+		if !filepath.IsAbs(folder) {
+			folder = filepath.Join(cfg.workspaceDir, folder)
+		}
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("workspace folder is not a directory: %s", folder)
+		}
+		cfg.workspaceFolders[i] = filepath.Clean(folder)
+	}
+
+	// Expand references to this process's environment, e.g. PATH=/opt/llvm/bin:$PATH
+	for i, variable := range cfg.lspEnv {
+		key, value, ok := strings.Cut(variable, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid LSP environment variable %q, expected KEY=value", variable)
+		}
+		cfg.lspEnv[i] = key + "=" + os.ExpandEnv(value)
+	}
+
+	if cfg.lspDir != "" {
+		if !filepath.IsAbs(cfg.lspDir) {
+			cfg.lspDir = filepath.Join(cfg.workspaceDir, cfg.lspDir)
+		}
+		if info, err := os.Stat(cfg.lspDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("LSP working directory is not a directory: %s", cfg.lspDir)
+		}
+	}
+
+	if cfg.lspAddress != "" {
+		if cfg.lspCommand != "" || len(cfg.lspArgs) > 0 || len(cfg.lspEnv) > 0 || cfg.lspDir != "" {
+			return nil, fmt.Errorf("--lsp-address cannot be combined with --lsp, its arguments, --lsp-env or --lsp-cwd")
+		}
+		return cfg, nil
+	}
+
 	// Validate LSP command
 	if cfg.lspCommand == "" {
-		return nil, fmt.Errorf("LSP command is required")
+		return nil, fmt.Errorf("LSP command or address is required")
 	}
 
 	if _, err := exec.LookPath(cfg.lspCommand); err != nil {
@@ -73,44 +214,163 @@ func parseConfig() (*config, error) {
 	return cfg, nil
 }
 
+// toolTimeoutFromEnv reads the default tool timeout from LSP_TOOL_TIMEOUT, which may be
+// a duration ("45s", "2m") or a number of seconds
+func toolTimeoutFromEnv() time.Duration {
+	value := os.Getenv("LSP_TOOL_TIMEOUT")
+	if value == "" {
+		return defaultToolTimeout
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if timeout, err := time.ParseDuration(value); err == nil && timeout >= 0 {
+		return timeout
+	}
+	coreLogger.Warn("Invalid LSP_TOOL_TIMEOUT %q, using default of %s", value, defaultToolTimeout)
+	return defaultToolTimeout
+}
+
 func newServer(config *config) (*mcpServer, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &mcpServer{
-		config:     *config,
-		ctx:        ctx,
-		cancelFunc: cancel,
+		config:          *config,
+		ctx:             ctx,
+		cancelFunc:      cancel,
+		registeredTools: make(map[string]bool),
+		toolStats:       make(map[string]*toolStats),
+		started:         time.Now(),
 	}, nil
 }
 
-func (s *mcpServer) initializeLSP() error {
-	if err := os.Chdir(s.config.workspaceDir); err != nil {
-		return fmt.Errorf("failed to change to workspace directory: %v", err)
+// initializeLSP completes the handshake with client, which start already serves tool calls
+// for, and registers the tools its capabilities support. Until then the tools registered
+// without capabilities wait for the handshake, see addTool.
+func (s *mcpServer) initializeLSP(client *lsp.Client) {
+	if err := s.handshakeLSP(client); err != nil {
+		// Shutting down or restarted meanwhile
+		if s.ctx.Err() != nil || s.client() != client {
+			return
+		}
+		coreLogger.Error("Failed to initialize language server: %v", err)
+		// monitorLSP restarts it following --restart-attempts
+		s.stopLSP(client)
+		return
+	}
+	if err := s.registerTools(client.ServerCapabilities()); err != nil {
+		coreLogger.Error("Tool registration failed: %v", err)
+	}
+}
+
+// connectLSP starts or connects to the language server and initializes it. The new client
+// replaces the current one before the handshake, so tool calls wait for it to finish.
+func (s *mcpServer) connectLSP() (*lsp.Client, error) {
+	client, err := s.startLSP()
+	if err != nil {
+		return nil, err
 	}
+	return client, s.handshakeLSP(client)
+}
 
-	client, err := lsp.NewClient(s.config.lspCommand, s.config.lspArgs...)
+// startLSP starts or connects to the language server and makes it the current one, without
+// initializing it
+func (s *mcpServer) startLSP() (*lsp.Client, error) {
+	var client *lsp.Client
+	var err error
+	if s.config.lspAddress != "" {
+		dialCtx, cancel := context.WithTimeout(s.ctx, lspDialTimeout)
+		client, err = lsp.DialClient(dialCtx, s.config.lspAddress)
+		cancel()
+	} else {
+		client, err = lsp.NewClientWithOptions(s.config.lspCommand, s.config.lspArgs, lsp.ProcessOptions{
+			Env: s.config.lspEnv,
+			Dir: s.config.lspDir,
+		})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create LSP client: %v", err)
+		return nil, fmt.Errorf("failed to create LSP client: %v", err)
+	}
+
+	s.lspClientMu.Lock()
+	if s.stopWatcher != nil {
+		s.stopWatcher()
+		s.stopWatcher = nil
 	}
 	s.lspClient = client
-	s.workspaceWatcher = watcher.NewWorkspaceWatcher(client)
+	s.serverInfo = nil
+	s.lspStarted = time.Now()
+	s.lspClientMu.Unlock()
+	return client, nil
+}
+
+// handshakeLSP initializes client, started by startLSP, and starts watching the workspace
+// for it
+func (s *mcpServer) handshakeLSP(client *lsp.Client) error {
+	workspaceWatcher := watcher.NewWorkspaceWatcher(client)
 
-	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
+	// Servers may ask for their configuration while initializing
+	s.lspSettingsMu.Lock()
+	client.SetSettings(s.lspSettings)
+	s.lspSettingsMu.Unlock()
+
+	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir, s.config.workspaceFolders...)
 	if err != nil {
 		return fmt.Errorf("initialize failed: %v", err)
 	}
 
-	// Store capabilities for tool registration
-	s.capabilities = &initResult.Capabilities
+	watchCtx, stopWatcher := context.WithCancel(s.ctx)
+	s.lspClientMu.Lock()
+	if s.lspClient != client {
+		// Replaced by a restart while initializing
+		s.lspClientMu.Unlock()
+		stopWatcher()
+		return fmt.Errorf("language server was restarted during initialization")
+	}
+	s.serverInfo = initResult.ServerInfo
+	s.stopWatcher = stopWatcher
+	s.lspClientMu.Unlock()
+	for _, issue := range lsp.KnownIssues(initResult.ServerInfo) {
+		coreLogger.Warn("Known issue of %s affecting %s: %s", s.serverDescription(), strings.Join(issue.Tools, ", "), issue.Description)
+	}
 
 	coreLogger.Debug("Server capabilities: %+v", initResult.Capabilities)
 
-	go s.workspaceWatcher.WatchWorkspace(s.ctx, s.config.workspaceDir)
+	if s.config.startupWait > 0 {
+		client.StartIndexingWait(s.ctx, s.config.startupWait)
+	}
+
+	go workspaceWatcher.WatchWorkspace(watchCtx, s.config.workspaceDir)
 	return client.WaitForServerReady(s.ctx)
 }
 
+// toolContext returns the context a tool call should use for its LSP requests, derived from
+// ctx, the context of the call, so that requests are cancelled if the MCP client cancels the
+// call or disconnects. They are also cancelled when the server shuts down. Requests still
+// pending after the configured timeout fail with a "language server timed out" error.
+// Responses are recorded if ctx records them, see rawResponses, and requests are timed if it
+// times them, see toolStats.
+func (s *mcpServer) toolContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var toolCtx context.Context
+	var cancel context.CancelFunc
+	if s.config.toolTimeout <= 0 {
+		toolCtx, cancel = context.WithCancel(ctx)
+	} else {
+		toolCtx, cancel = context.WithTimeoutCause(ctx, s.config.toolTimeout,
+			fmt.Errorf("language server timed out after %s", s.config.toolTimeout))
+	}
+	stop := context.AfterFunc(s.ctx, cancel)
+	return toolCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// start serves MCP while the language server is initialized in the background, so that a
+// slow server doesn't keep the MCP client waiting for the tool list. The tools that don't
+// depend on capabilities are registered at once, the others when the handshake completes.
 func (s *mcpServer) start() error {
-	if err := s.initializeLSP(); err != nil {
-		return err
+	if err := os.Chdir(s.config.workspaceDir); err != nil {
+		return fmt.Errorf("failed to change to workspace directory: %v", err)
 	}
 
 	s.mcpServer = server.NewMCPServer(
@@ -118,12 +378,19 @@ func (s *mcpServer) start() error {
 		"v0.0.2",
 		server.WithLogging(),
 		server.WithRecovery(),
+		// Tools are added when the server registers capabilities after startup
+		server.WithToolCapabilities(true),
 	)
 
-	err := s.registerTools(s.capabilities)
+	client, err := s.startLSP()
 	if err != nil {
+		return err
+	}
+	if err := s.registerTools(nil); err != nil {
 		return fmt.Errorf("tool registration failed: %v", err)
 	}
+	s.superviseLSP(client)
+	go s.initializeLSP(client)
 
 	return server.ServeStdio(s.mcpServer)
 }
@@ -195,48 +462,74 @@ func main() {
 	os.Exit(0)
 }
 
-func cleanup(s *mcpServer, done chan struct{}) {
-	coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
-
+// stopLSP ends the session with a language server: a server we connected to is only
+// disconnected from, one we started is asked to shut down and then stopped
+func (s *mcpServer) stopLSP(client *lsp.Client) {
 	// Create a context with timeout for shutdown operations
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if s.lspClient != nil {
-		coreLogger.Info("Closing open files")
-		s.lspClient.CloseAllFiles(ctx)
+	if s.config.lspAddress != "" {
+		// A server we connected to may be shared, so leave it running and only disconnect
+		coreLogger.Info("Disconnecting from LSP server at %s", s.config.lspAddress)
+		if err := client.Close(); err != nil {
+			coreLogger.Error("Failed to close LSP client: %v", err)
+		}
+		return
+	}
+
+	// A server that already exited can't be asked to shut down
+	if client.Exited() {
+		coreLogger.Info("Closing LSP client")
+		if err := client.Close(); err != nil {
+			coreLogger.Error("LSP server exited with: %v", err)
+		}
+		return
+	}
 
-		// Create a shorter timeout context for the shutdown request
-		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 500*time.Millisecond)
-		defer shutdownCancel()
+	coreLogger.Info("Closing open files")
+	client.CloseAllFiles(ctx)
 
-		// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
-		shutdownDone := make(chan struct{})
-		go func() {
-			coreLogger.Info("Sending shutdown request")
-			if err := s.lspClient.Shutdown(shutdownCtx); err != nil {
-				coreLogger.Error("Shutdown request failed: %v", err)
-			}
-			close(shutdownDone)
-		}()
+	// Create a shorter timeout context for the shutdown request
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer shutdownCancel()
 
-		// Wait for shutdown with timeout
-		select {
-		case <-shutdownDone:
-			coreLogger.Info("Shutdown request completed")
-		case <-time.After(1 * time.Second):
-			coreLogger.Warn("Shutdown request timed out, proceeding with exit")
+	// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
+	shutdownDone := make(chan struct{})
+	go func() {
+		coreLogger.Info("Sending shutdown request")
+		if err := client.Shutdown(shutdownCtx); err != nil {
+			coreLogger.Error("Shutdown request failed: %v", err)
 		}
+		close(shutdownDone)
+	}()
 
-		coreLogger.Info("Sending exit notification")
-		if err := s.lspClient.Exit(ctx); err != nil {
-			coreLogger.Error("Exit notification failed: %v", err)
-		}
+	// Wait for shutdown with timeout
+	select {
+	case <-shutdownDone:
+		coreLogger.Info("Shutdown request completed")
+	case <-time.After(1 * time.Second):
+		coreLogger.Warn("Shutdown request timed out, proceeding with exit")
+	}
 
-		coreLogger.Info("Closing LSP client")
-		if err := s.lspClient.Close(); err != nil {
-			coreLogger.Error("Failed to close LSP client: %v", err)
-		}
+	coreLogger.Info("Sending exit notification")
+	if err := client.Exit(ctx); err != nil {
+		coreLogger.Error("Exit notification failed: %v", err)
+	}
+
+	coreLogger.Info("Closing LSP client")
+	if err := client.Close(); err != nil {
+		coreLogger.Error("Failed to close LSP client: %v", err)
+	}
+}
+
+func cleanup(s *mcpServer, done chan struct{}) {
+	coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
+
+	// Stop restarting the language server, it is about to exit
+	s.cancelFunc()
+	if client := s.client(); client != nil {
+		s.stopLSP(client)
 	}
 
 	// Send signal to the done channel
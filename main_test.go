@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestToolContext(t *testing.T) {
+	t.Run("cancelled with the call", func(t *testing.T) {
+		s, err := newServer(&config{toolTimeout: time.Minute})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		t.Cleanup(s.cancelFunc)
+
+		ctx, cancelCall := context.WithCancel(context.Background())
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+
+		cancelCall()
+		<-toolCtx.Done()
+		if !errors.Is(toolCtx.Err(), context.Canceled) {
+			t.Errorf("Expected the tool context to be cancelled, got %v", toolCtx.Err())
+		}
+	})
+
+	t.Run("cancelled on shutdown", func(t *testing.T) {
+		s, err := newServer(&config{})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+
+		toolCtx, cancel := s.toolContext(context.Background())
+		defer cancel()
+
+		s.cancelFunc()
+		select {
+		case <-toolCtx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the tool context to be cancelled when the server shuts down")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s, err := newServer(&config{toolTimeout: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		t.Cleanup(s.cancelFunc)
+
+		toolCtx, cancel := s.toolContext(context.Background())
+		defer cancel()
+
+		<-toolCtx.Done()
+		if cause := context.Cause(toolCtx); cause == nil || cause.Error() != "language server timed out after 10ms" {
+			t.Errorf("Expected a timeout cause, got %v", cause)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rawResponses reports whether the unformatted responses of the language server should be
+// appended to the result of a tool call, because of --raw-responses or a raw argument. The
+// argument is accepted by every tool but left out of their schemas, it is only meant for
+// debugging the formatting of results.
+func (s *mcpServer) rawResponses(request mcp.CallToolRequest) bool {
+	if raw, ok := request.Params.Arguments["raw"].(bool); ok {
+		return raw
+	}
+	return s.config.rawResponses
+}
+
+// appendRawResponses adds the responses as indented JSON in a separate content block, so the
+// formatted output stays as it is
+func appendRawResponses(result *mcp.CallToolResult, responses []lsp.RawResponse) {
+	var text strings.Builder
+	if len(responses) == 0 {
+		text.WriteString("Raw LSP responses: none, no request was sent to the language server.\n")
+	} else {
+		text.WriteString(fmt.Sprintf("Raw LSP responses (%d):\n", len(responses)))
+	}
+	for _, response := range responses {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, response.Result, "", "  "); err != nil {
+			indented.Reset()
+			indented.Write(response.Result)
+		}
+		text.WriteString(fmt.Sprintf("\n%s:\n%s\n", response.Method, indented.String()))
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(text.String()))
+}
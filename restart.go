@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// restartToolName is the tool that restarts the language server on request
+const restartToolName = "restart_language_server"
+
+// restartResetAfter is how long a restarted language server has to keep running before
+// earlier automatic restarts no longer count towards --restart-attempts
+const restartResetAfter = time.Minute
+
+// client returns the current language server client, which changes when the server is
+// restarted
+func (s *mcpServer) client() *lsp.Client {
+	s.lspClientMu.RLock()
+	defer s.lspClientMu.RUnlock()
+	return s.lspClient
+}
+
+// lspServerInfo returns the serverInfo of the current language server's initialize result,
+// nil until the handshake has completed
+func (s *mcpServer) lspServerInfo() *protocol.ServerInfo {
+	s.lspClientMu.RLock()
+	defer s.lspClientMu.RUnlock()
+	return s.serverInfo
+}
+
+// superviseLSP keeps the tools in sync with the capabilities client registers and restarts
+// the server if it exits unexpectedly
+func (s *mcpServer) superviseLSP(client *lsp.Client) {
+	client.SetCapabilitiesChangedHandler(func() {
+		coreLogger.Info("Language server capabilities changed, registering newly supported tools")
+		if err := s.registerTools(s.serverCapabilities()); err != nil {
+			coreLogger.Error("Tool registration failed: %v", err)
+		}
+	})
+	go s.monitorLSP(client)
+}
+
+// monitorLSP waits for client's server to exit and restarts it following --restart-attempts
+// and --restart-backoff. Exits caused by shutting down or restarting are ignored.
+func (s *mcpServer) monitorLSP(client *lsp.Client) {
+	select {
+	case <-client.Done():
+	case <-s.ctx.Done():
+		return
+	}
+
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	if s.ctx.Err() != nil || s.client() != client {
+		return
+	}
+
+	coreLogger.Error("Language server exited unexpectedly")
+	if s.config.restartAttempts <= 0 {
+		coreLogger.Warn("Automatic restarts are disabled, use the %s tool to start the language server again", restartToolName)
+		return
+	}
+
+	s.lspClientMu.RLock()
+	uptime := time.Since(s.lspStarted)
+	s.lspClientMu.RUnlock()
+	if uptime >= restartResetAfter {
+		s.restartAttempts = 0
+	}
+
+	// Failed attempts replace the client, so remember the files open in the one that exited
+	openFiles := client.OpenFilePaths()
+	for s.restartAttempts < s.config.restartAttempts {
+		s.restartAttempts++
+		backoff := s.config.restartBackoff << (s.restartAttempts - 1)
+		coreLogger.Info("Restarting language server in %s (attempt %d of %d)", backoff, s.restartAttempts, s.config.restartAttempts)
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return
+		}
+
+		reopened, err := s.restartLSPLocked(openFiles)
+		if err == nil {
+			coreLogger.Info("Restarted language server and reopened %d files", reopened)
+			return
+		}
+		coreLogger.Error("Failed to restart language server: %v", err)
+	}
+	coreLogger.Error("Giving up on restarting the language server after %d attempts, use the %s tool to try again", s.restartAttempts, restartToolName)
+}
+
+// restartLSP replaces the language server with a new one, reopening the files that were
+// open in the old one, and returns the number of files reopened
+func (s *mcpServer) restartLSP(reason string) (int, error) {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+
+	coreLogger.Info("Restarting language server: %s", reason)
+	s.restartAttempts = 0
+	return s.restartLSPLocked(s.client().OpenFilePaths())
+}
+
+// restartLSPLocked stops the current language server and starts a new one with openFiles
+// open. The caller must hold restartMu.
+func (s *mcpServer) restartLSPLocked(openFiles []string) (int, error) {
+	s.stopLSP(s.client())
+
+	client, err := s.connectLSP()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.registerTools(s.serverCapabilities()); err != nil {
+		return 0, fmt.Errorf("tool registration failed: %v", err)
+	}
+	s.superviseLSP(client)
+	s.replaySettings(client)
+
+	reopened := 0
+	for _, path := range openFiles {
+		if err := client.OpenFile(s.ctx, path); err != nil {
+			coreLogger.Warn("Failed to reopen %s: %v", path, err)
+			continue
+		}
+		reopened++
+	}
+	return reopened, nil
+}
+
+// replaySettings sends the settings last sent with update_configuration to a restarted
+// language server
+func (s *mcpServer) replaySettings(client *lsp.Client) {
+	s.lspSettingsMu.Lock()
+	settings := s.lspSettings
+	s.lspSettingsMu.Unlock()
+	if settings == nil {
+		return
+	}
+
+	coreLogger.Info("Sending the configuration set with update_configuration to the restarted language server")
+	if err := client.DidChangeConfiguration(s.ctx, protocol.DidChangeConfigurationParams{Settings: settings}); err != nil {
+		coreLogger.Warn("Failed to send configuration: %v", err)
+	}
+}
+
+// serverExitedMessage is the result of a tool call that failed because the language server
+// exited while handling it
+func (s *mcpServer) serverExitedMessage() string {
+	if s.config.restartAttempts <= 0 {
+		return fmt.Sprintf("The language server exited during this call. Use the %s tool to start it again, then retry.", restartToolName)
+	}
+	return "The language server exited during this call and is being restarted. Please retry in a moment."
+}
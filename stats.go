@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// toolStats counts the calls of a tool and the time they took, see addTool. They are only
+// kept in memory, so they start over when the MCP server restarts.
+type toolStats struct {
+	calls       atomic.Int64
+	failures    atomic.Int64
+	elapsed     atomic.Int64 // Nanoseconds
+	lspRequests atomic.Int64
+	lspElapsed  atomic.Int64 // Nanoseconds spent waiting for the language server
+}
+
+// record adds a call that took elapsed, with the requests timer timed
+func (t *toolStats) record(elapsed time.Duration, timer *lsp.RequestTimer, failed bool) {
+	t.calls.Add(1)
+	if failed {
+		t.failures.Add(1)
+	}
+	t.elapsed.Add(int64(elapsed))
+	t.lspRequests.Add(timer.Requests())
+	t.lspElapsed.Add(int64(timer.Elapsed()))
+}
+
+// formatToolStats lists the tools that were called, slowest on average first. The time
+// not spent waiting for the language server is mostly formatting its responses.
+func formatToolStats(stats map[string]*toolStats, since time.Duration) string {
+	type toolSummary struct {
+		name            string
+		calls, failures int64
+		average, lsp    time.Duration
+		lspRequests     float64
+	}
+	var summaries []toolSummary
+	for name, s := range stats {
+		calls := s.calls.Load()
+		if calls == 0 {
+			continue
+		}
+		summaries = append(summaries, toolSummary{
+			name:        name,
+			calls:       calls,
+			failures:    s.failures.Load(),
+			average:     time.Duration(s.elapsed.Load() / calls),
+			lsp:         time.Duration(s.lspElapsed.Load() / calls),
+			lspRequests: float64(s.lspRequests.Load()) / float64(calls),
+		})
+	}
+	if len(summaries) == 0 {
+		return fmt.Sprintf("No tools were called in the %s since the MCP server started.\n", since.Round(time.Second))
+	}
+	slices.SortFunc(summaries, func(a, b toolSummary) int {
+		if a.average != b.average {
+			return int(b.average - a.average)
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Tool calls in the %s since the MCP server started, slowest first:\n", since.Round(time.Second)))
+	for _, s := range summaries {
+		// Concurrent requests are all counted, so they may add up to more than the call
+		other := max(s.average-s.lsp, 0)
+		result.WriteString(fmt.Sprintf("- %s: %d calls, %d failed (%.0f%%), average %s: language server %s (%.1f requests), formatting and other %s\n",
+			s.name, s.calls, s.failures, 100*float64(s.failures)/float64(s.calls),
+			s.average.Round(time.Millisecond), s.lsp.Round(time.Millisecond), s.lspRequests, other.Round(time.Millisecond)))
+	}
+	return result.String()
+}
+
+// stats returns the statistics of the tools called so far
+func (s *mcpServer) stats() string {
+	s.registeredToolsMu.Lock()
+	defer s.registeredToolsMu.Unlock()
+	return formatToolStats(s.toolStats, time.Since(s.started))
+}
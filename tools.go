@@ -2,46 +2,261 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// withOffset is the parameter verbose tools accept to continue truncated output
+func withOffset() mcp.ToolOption {
+	return mcp.WithNumber("offset",
+		mcp.Description("Byte offset to continue from when a previous call's output was truncated (default 0)"),
+	)
+}
+
+// withFormat is the parameter of the tools that can render their results as markdown
+func withFormat() mcp.ToolOption {
+	return mcp.WithString("format",
+		mcp.Description("'text' for plain text or 'markdown' for code blocks with syntax highlighting and tables, for clients that render markdown (default: text, or as set with --output-format)"),
+		mcp.Enum(tools.OutputFormatText, tools.OutputFormatMarkdown),
+	)
+}
+
+// outputFormat returns the format argument of a tool call taking withFormat, or the
+// --output-format default
+func (s *mcpServer) outputFormat(request mcp.CallToolRequest) string {
+	if format, ok := request.Params.Arguments["format"].(string); ok && format != "" {
+		return format
+	}
+	return s.config.outputFormat
+}
+
+// truncatedResult caps text at tools.DefaultMaxOutputSize, starting at the request's offset
+// argument, so large outputs can be fetched across several calls
+func truncatedResult(request mcp.CallToolRequest, text string) *mcp.CallToolResult {
+	offset := 0
+	switch v := request.Params.Arguments["offset"].(type) {
+	case float64:
+		offset = int(v)
+	case int:
+		offset = v
+	}
+
+	truncated, err := tools.TruncateOutput(text, offset, tools.DefaultMaxOutputSize)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	return mcp.NewToolResultText(truncated)
+}
+
+// indexTools need the server's complete index of the workspace to give complete results.
+// With --index-wait they wait for the server to finish reporting progress (e.g. indexing).
+var indexTools = map[string]bool{
+	"definition":                true,
+	"definitions_batch":         true,
+	"references":                true,
+	"replace_symbol_references": true,
+	"symbol_overview":           true,
+	"rename_symbol":             true,
+	"call_hierarchy":            true,
+	"type_hierarchy":            true,
+}
+
+// serverTools report on or manage the language server itself, so they don't wait for it to
+// finish starting or indexing
+var serverTools = map[string]bool{
+	"server_status": true,
+	"server_logs":   true,
+	"stats":         true,
+	restartToolName: true,
+}
+
+// addTool registers a tool whose handler waits for the language server to finish the
+// initialize handshake, so early calls get a clear error instead of failing confusingly,
+// and for its initial indexing with --startup-index-wait. serverTools don't wait.
+// Tools listed in toolCapabilities are refused with a specific message if the server does
+// not advertise the capability they need. A filePath argument must be inside one of the
+// workspace folders and, except for textTools, have an extension the server handles.
+// Tools listed in indexTools may wait for indexing, see --index-wait.
+// If the server exits during a call, the call fails with a message asking to retry once it
+// has been restarted. With raw responses requested, see rawResponses, the unformatted
+// responses of the server are appended to the result. Every call is counted and timed
+// for the stats tool. Tools that are already registered are left as they are.
+func (s *mcpServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.registeredToolsMu.Lock()
+	defer s.registeredToolsMu.Unlock()
+	if s.registeredTools[tool.Name] {
+		return
+	}
+	s.registeredTools[tool.Name] = true
+	stats := &toolStats{}
+	s.toolStats[tool.Name] = stats
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		timer := &lsp.RequestTimer{}
+		ctx = lsp.WithRequestTimer(ctx, timer)
+		defer func(start time.Time) {
+			stats.record(time.Since(start), timer, err != nil || result != nil && result.IsError)
+		}(time.Now())
+
+		client := s.client()
+
+		timeout := s.config.toolTimeout
+		if timeout <= 0 {
+			timeout = defaultToolTimeout
+		}
+		if !serverTools[tool.Name] {
+			if err := client.WaitForInitialized(ctx, timeout); err != nil {
+				if errors.Is(err, lsp.ErrServerStarting) {
+					coreLogger.Warn("Tool %s called before the language server finished starting", tool.Name)
+				}
+				if errors.Is(err, lsp.ErrServerExited) {
+					return mcp.NewToolResultError(s.serverExitedMessage()), nil
+				}
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			// See --startup-index-wait
+			select {
+			case <-client.IndexingWaitDone():
+			case <-ctx.Done():
+				return mcp.NewToolResultError(fmt.Sprintf("language server still indexing: %v", ctx.Err())), nil
+			}
+		}
+
+		if result := s.unsupportedToolResult(tool.Name); result != nil {
+			return result, nil
+		}
+
+		// Files outside every workspace folder are not indexed by the server
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			if _, ok := client.RootForPath(filePath); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("%s is outside the workspace folders (%s). Use a path under one of them or add its project with the workspace_folders tool.",
+					filePath, strings.Join(client.WorkspaceFolders(), ", "))), nil
+			}
+			if result := s.unsupportedExtensionResult(tool.Name, filePath); result != nil {
+				return result, nil
+			}
+		}
+
+		if indexTools[tool.Name] && s.config.indexWait > 0 {
+			if !client.WaitForProgress(ctx, s.config.indexWait) {
+				coreLogger.Warn("Tool %s called while the language server is still busy after %s, results may be incomplete", tool.Name, s.config.indexWait)
+			}
+		}
+
+		var recorder *lsp.RawResponseRecorder
+		if s.rawResponses(request) {
+			recorder = &lsp.RawResponseRecorder{}
+			ctx = lsp.WithRawResponses(ctx, recorder)
+		}
+
+		result, err = handler(ctx, request)
+		if result != nil && result.IsError && client.Exited() {
+			coreLogger.Warn("Tool %s failed because the language server exited", tool.Name)
+			return mcp.NewToolResultError(s.serverExitedMessage()), nil
+		}
+		if recorder != nil && result != nil {
+			appendRawResponses(result, recorder.Responses())
+		}
+		return result, err
+	})
+}
+
+// textEditSchema is the JSON schema of a line based edit, as taken by edit_file and edit_files
+var textEditSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"startLine": map[string]any{
+			"type":        "number",
+			"description": "Start line to replace, inclusive, one-indexed",
+		},
+		"endLine": map[string]any{
+			"type":        "number",
+			"description": "End line to replace, inclusive, one-indexed",
+		},
+		"newText": map[string]any{
+			"type":        "string",
+			"description": "Replacement text. Replace with the new text. Leave blank to remove lines.",
+		},
+	},
+	"required": []string{"startLine", "endLine"},
+}
+
+// parseTextEdits converts an array of edits following textEditSchema
+func parseTextEdits(editsArg any) ([]tools.TextEdit, error) {
+	editsArray, ok := editsArg.([]any)
+	if !ok {
+		return nil, fmt.Errorf("edits must be an array")
+	}
+
+	var edits []tools.TextEdit
+	for _, editItem := range editsArray {
+		editMap, ok := editItem.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("each edit must be an object")
+		}
+
+		startLine, ok := editMap["startLine"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("startLine must be a number")
+		}
+
+		endLine, ok := editMap["endLine"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("endLine must be a number")
+		}
+
+		newText, _ := editMap["newText"].(string) // newText can be empty
+
+		edits = append(edits, tools.TextEdit{
+			StartLine: int(startLine),
+			EndLine:   int(endLine),
+			NewText:   newText,
+		})
+	}
+	return edits, nil
+}
+
 func (s *mcpServer) registerEditFileTool() {
 	applyTextEditTool := mcp.NewTool("edit_file",
 		mcp.WithDescription("Apply multiple text edits to a file."),
 		mcp.WithArray("edits",
 			mcp.Required(),
 			mcp.Description("List of edits to apply"),
-			mcp.Items(map[string]any{
-				"type": "object",
-				"properties": map[string]any{
-					"startLine": map[string]any{
-						"type":        "number",
-						"description": "Start line to replace, inclusive, one-indexed",
-					},
-					"endLine": map[string]any{
-						"type":        "number",
-						"description": "End line to replace, inclusive, one-indexed",
-					},
-					"newText": map[string]any{
-						"type":        "string",
-						"description": "Replacement text. Replace with the new text. Leave blank to remove lines.",
-					},
-				},
-				"required": []string{"startLine", "endLine"},
-			}),
+			mcp.Items(textEditSchema),
 		),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to edit"),
 		),
+		mcp.WithBoolean("autoFormat",
+			mcp.Description("If true, ask the LSP server to reformat inserted text that ends in an on-type formatting trigger character (e.g. '}', ';', newline). Only has an effect if the server supports on-type formatting."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("didSave",
+			mcp.Description("If true, send a save notification after the edits so that diagnostics which are only refreshed on save (e.g. from attached linters) update. Only has an effect if the server requests save notifications."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("matchIndent",
+			mcp.Description("If true, re-indent newText to the indentation of the lines it replaces, keeping the relative indentation of its lines and converting it to the file's tabs or spaces."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("returnContent",
+			mcp.Description("If true, the response includes the edited lines with 3 lines of context around each edit, numbered as they are after the edit, so that the result can be checked without reading the file again."),
+			mcp.DefaultBool(false),
+		),
 	)
 
-	s.mcpServer.AddTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
@@ -53,236 +268,1715 @@ func (s *mcpServer) registerEditFileTool() {
 		if !ok {
 			return mcp.NewToolResultError("edits is required"), nil
 		}
+		edits, err := parseTextEdits(editsArg)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		autoFormat := false
+		if autoFormatArg, ok := request.Params.Arguments["autoFormat"].(bool); ok {
+			autoFormat = autoFormatArg
+		}
+
+		didSave := false
+		if didSaveArg, ok := request.Params.Arguments["didSave"].(bool); ok {
+			didSave = didSaveArg
+		}
+
+		matchIndent := false
+		if matchIndentArg, ok := request.Params.Arguments["matchIndent"].(bool); ok {
+			matchIndent = matchIndentArg
+		}
+
+		returnContent := false
+		if returnContentArg, ok := request.Params.Arguments["returnContent"].(bool); ok {
+			returnContent = returnContentArg
+		}
+
+		reindented := 0
+		if matchIndent {
+			var err error
+			edits, reindented, err = tools.MatchIndentation(filePath, edits)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to match indentation: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing edit_file for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		response, err := tools.ApplyTextEdits(toolCtx, s.client(), filePath, edits)
+		if err != nil {
+			coreLogger.Error("Failed to apply edits: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
+		}
+		if reindented > 0 {
+			response += fmt.Sprintf(" Indentation adjusted in %d edits.", reindented)
+		}
+
+		caps := s.serverCapabilities()
+		if autoFormat && lsp.HasOnTypeFormattingSupport(caps) {
+			provider := caps.DocumentOnTypeFormattingProvider
+			triggerCharacters := append([]string{provider.FirstTriggerCharacter}, provider.MoreTriggerCharacter...)
+			formatted, err := tools.FormatOnType(toolCtx, s.client(), filePath, edits, triggerCharacters)
+			if err != nil {
+				// The edits themselves were applied, so report the formatting failure without failing the tool
+				coreLogger.Warn("Failed to format inserted text: %v", err)
+				response += fmt.Sprintf(" Auto-format failed: %v", err)
+			} else if formatted > 0 {
+				response += fmt.Sprintf(" Auto-format applied %d formatting edits.", formatted)
+			}
+		}
+
+		if didSave {
+			if saveOptions := lsp.TextDocumentSaveOptions(caps); saveOptions == nil {
+				response += " Save notification skipped: the server does not request save notifications."
+			} else if err := tools.NotifySave(toolCtx, s.client(), filePath, saveOptions.IncludeText); err != nil {
+				coreLogger.Warn("Failed to send save notification: %v", err)
+				response += fmt.Sprintf(" Save notification failed: %v", err)
+			}
+		}
+
+		if returnContent {
+			content, err := tools.EditedContent(filePath, edits)
+			if err != nil {
+				coreLogger.Warn("Failed to read edited content: %v", err)
+				response += fmt.Sprintf(" Reading the edited lines failed: %v", err)
+			} else {
+				response += "\n\nEdited lines:\n" + content
+			}
+		}
+		return mcp.NewToolResultText(response), nil
+	})
+}
+
+func (s *mcpServer) registerEditFilesTool() {
+	editFilesTool := mcp.NewTool("edit_files",
+		mcp.WithDescription("Apply text edits to several files at once. All edits are validated before anything is written and the files are written together, so if the edits of one file are invalid or a write fails, no file is left changed."),
+		mcp.WithObject("files",
+			mcp.Required(),
+			mcp.Description("Map of file path to the list of edits to apply to that file"),
+			mcp.AdditionalProperties(map[string]any{
+				"type":  "array",
+				"items": textEditSchema,
+			}),
+		),
+	)
 
-		// Type assert and convert the edits
-		editsArray, ok := editsArg.([]any)
+	s.addTool(editFilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filesArg, ok := request.Params.Arguments["files"].(map[string]any)
 		if !ok {
-			return mcp.NewToolResultError("edits must be an array"), nil
+			return mcp.NewToolResultError("files must be an object mapping file paths to edits"), nil
 		}
 
-		var edits []tools.TextEdit
-		for _, editItem := range editsArray {
-			editMap, ok := editItem.(map[string]any)
-			if !ok {
-				return mcp.NewToolResultError("each edit must be an object"), nil
+		fileEdits := make(map[string][]tools.TextEdit, len(filesArg))
+		for filePath, editsArg := range filesArg {
+			edits, err := parseTextEdits(editsArg)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s: %v", filePath, err)), nil
+			}
+			fileEdits[filePath] = edits
+		}
+
+		coreLogger.Debug("Executing edit_files for %d files", len(fileEdits))
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		response, err := tools.ApplyTextEditsToFiles(toolCtx, s.client(), fileEdits)
+		if err != nil {
+			coreLogger.Error("Failed to apply edits: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
+		}
+		return mcp.NewToolResultText(response), nil
+	})
+}
+
+func (s *mcpServer) registerDefinitionTool() {
+	readDefinitionTool := mcp.NewTool("definition",
+		mcp.WithDescription("Read the source code definition of a symbol (function, type, constant, etc.) from the codebase. Returns the complete implementation code where the symbol is defined."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol whose definition you want to find (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Number of lines to include before and after the definition, e.g. to include its doc comment (default 0)"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("When several symbols match (overloads, same-named methods), return only the definition with this number from a previous call's output (1-indexed)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only match symbols of this kind, e.g. 'function', 'method', 'struct', 'class', 'interface', 'constant', 'variable', 'field'"),
+		),
+		mcp.WithBoolean("exact",
+			mcp.Description("If true, only match symbols named exactly symbolName (no suffix, substring, or case-insensitive matching)"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("maxLines",
+			mcp.Description("Truncate each definition to this many lines, noting how many more there are (default 0, no limit)"),
+		),
+		mcp.WithBoolean("followAliases",
+			mcp.Description("If true, definitions that are aliases or re-exports (e.g. TypeScript 'export ... from', Go type aliases) are followed to the definition they refer to, listing the hops taken"),
+			mcp.DefaultBool(false),
+		),
+		withFormat(),
+	)
+
+	s.addTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		var opts tools.DefinitionOptions
+		switch v := request.Params.Arguments["contextLines"].(type) {
+		case float64:
+			opts.ContextLines = int(v)
+		case int:
+			opts.ContextLines = v
+		}
+		if opts.ContextLines < 0 {
+			return mcp.NewToolResultError("contextLines must be non-negative"), nil
+		}
+
+		switch v := request.Params.Arguments["index"].(type) {
+		case float64:
+			opts.Index = int(v)
+		case int:
+			opts.Index = v
+		}
+		if opts.Index < 0 {
+			return mcp.NewToolResultError("index must be a positive number"), nil
+		}
+
+		if kindArg, ok := request.Params.Arguments["kind"].(string); ok && kindArg != "" {
+			kind, err := tools.ParseSymbolKind(kindArg)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
+			opts.Kind = kind
+		}
+
+		if exactArg, ok := request.Params.Arguments["exact"].(bool); ok {
+			opts.Exact = exactArg
+		}
+
+		switch v := request.Params.Arguments["maxLines"].(type) {
+		case float64:
+			opts.MaxLines = int(v)
+		case int:
+			opts.MaxLines = v
+		}
+		if opts.MaxLines < 0 {
+			return mcp.NewToolResultError("maxLines must be non-negative"), nil
+		}
+
+		if followArg, ok := request.Params.Arguments["followAliases"].(bool); ok {
+			opts.FollowAliases = followArg
+		}
+		opts.Format = s.outputFormat(request)
+
+		coreLogger.Debug("Executing definition for symbol: %s", symbolName)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ReadDefinitionWithOptions(toolCtx, s.client(), symbolName, opts)
+		if err != nil {
+			coreLogger.Error("Failed to get definition: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerDefinitionsBatchTool() {
+	definitionsBatchTool := mcp.NewTool("definitions_batch",
+		mcp.WithDescription("Read the source code definitions of several symbols at once. Lookups run concurrently and results are returned in a section per symbol name."),
+		mcp.WithArray("symbolNames",
+			mcp.Required(),
+			mcp.Description("The names of the symbols whose definitions you want to find (e.g. ['mypackage.MyFunction', 'MyType.MyMethod'])"),
+			mcp.Items(map[string]any{
+				"type": "string",
+			}),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Number of lines to include before and after each definition (default 0)"),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description(fmt.Sprintf("Maximum number of lookups to run at once (default %d)", tools.DefaultDefinitionConcurrency)),
+		),
+		withOffset(),
+	)
 
-			startLine, ok := editMap["startLine"].(float64)
+	s.addTool(definitionsBatchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		namesArg, ok := request.Params.Arguments["symbolNames"].([]any)
+		if !ok {
+			return mcp.NewToolResultError("symbolNames must be an array of strings"), nil
+		}
+		symbolNames := make([]string, 0, len(namesArg))
+		for _, nameArg := range namesArg {
+			name, ok := nameArg.(string)
 			if !ok {
-				return mcp.NewToolResultError("startLine must be a number"), nil
+				return mcp.NewToolResultError("symbolNames must be an array of strings"), nil
 			}
+			symbolNames = append(symbolNames, name)
+		}
+
+		var opts tools.DefinitionOptions
+		switch v := request.Params.Arguments["contextLines"].(type) {
+		case float64:
+			opts.ContextLines = int(v)
+		case int:
+			opts.ContextLines = v
+		}
+		if opts.ContextLines < 0 {
+			return mcp.NewToolResultError("contextLines must be non-negative"), nil
+		}
+
+		concurrency := tools.DefaultDefinitionConcurrency
+		switch v := request.Params.Arguments["concurrency"].(type) {
+		case float64:
+			concurrency = int(v)
+		case int:
+			concurrency = v
+		}
+		if concurrency <= 0 {
+			return mcp.NewToolResultError("concurrency must be a positive number"), nil
+		}
+
+		coreLogger.Debug("Executing definitions_batch for %d symbols", len(symbolNames))
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ReadDefinitions(toolCtx, s.client(), symbolNames, opts, concurrency)
+		if err != nil {
+			coreLogger.Error("Failed to get definitions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get definitions: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerSymbolOverviewTool() {
+	symbolOverviewTool := mcp.NewTool("symbol_overview",
+		mcp.WithDescription("Get the definition, documentation and references of a symbol in a single call. Identify the symbol either by symbolName or by position (filePath, line and column)."),
+		mcp.WithString("symbolName",
+			mcp.Description("The name of the symbol (e.g. 'mypackage.MyFunction', 'MyType.MyMethod'). Must match exactly one definition."),
+		),
+		mcp.WithString("filePath",
+			mcp.Description("The path to a file containing the symbol, used instead of symbolName"),
+		),
+		mcp.WithNumber("line",
+			mcp.Description("The line number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Description("The column number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithNumber("maxReferences",
+			mcp.Description(fmt.Sprintf("Maximum number of references to list (default %d)", tools.DefaultOverviewReferences)),
+		),
+		withOffset(),
+	)
 
-			endLine, ok := editMap["endLine"].(float64)
+	s.addTool(symbolOverviewTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, _ := request.Params.Arguments["symbolName"].(string)
+
+		var filePath string
+		var line, column int
+		if symbolName == "" {
+			var ok bool
+			filePath, ok = request.Params.Arguments["filePath"].(string)
 			if !ok {
-				return mcp.NewToolResultError("endLine must be a number"), nil
+				return mcp.NewToolResultError("either symbolName or filePath, line and column must be provided"), nil
+			}
+
+			// Handle both float64 and int for line and column due to JSON parsing
+			switch v := request.Params.Arguments["line"].(type) {
+			case float64:
+				line = int(v)
+			case int:
+				line = v
+			default:
+				return mcp.NewToolResultError("line must be a number"), nil
+			}
+
+			switch v := request.Params.Arguments["column"].(type) {
+			case float64:
+				column = int(v)
+			case int:
+				column = v
+			default:
+				return mcp.NewToolResultError("column must be a number"), nil
 			}
+		}
+
+		maxReferences := tools.DefaultOverviewReferences
+		switch v := request.Params.Arguments["maxReferences"].(type) {
+		case float64:
+			maxReferences = int(v)
+		case int:
+			maxReferences = v
+		}
+		if maxReferences <= 0 {
+			return mcp.NewToolResultError("maxReferences must be a positive number"), nil
+		}
+
+		coreLogger.Debug("Executing symbol_overview for symbol: %s file: %s line: %d column: %d", symbolName, filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		includeHover := lsp.HasHoverSupport(s.serverCapabilities())
+		text, err := tools.GetSymbolOverview(toolCtx, s.client(), symbolName, filePath, line, column, includeHover, maxReferences)
+		if err != nil {
+			coreLogger.Error("Failed to get symbol overview: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get symbol overview: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerReferencesTool() {
+	findReferencesTool := mcp.NewTool("references",
+		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"),
+		),
+		mcp.WithNumber("maxReferences",
+			mcp.Description(fmt.Sprintf("Maximum number of references to list (default %d). The total is reported when there are more.", tools.MaxReferences())),
+		),
+		mcp.WithNumber("skip",
+			mcp.Description("Number of references to skip, ordered by file and position, to list the ones after the first maxReferences (default 0)"),
+		),
+		mcp.WithArray("includePaths",
+			mcp.Description("Only list references in files matching one of these globs, relative to the workspace, e.g. 'internal/**' or 'src/*.ts'. A directory matches all files below it."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("excludePaths",
+			mcp.Description("Leave out references in files matching one of these globs, e.g. 'vendor' or '**/*_test.go'"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		withOffset(),
+	)
+
+	s.addTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
 
-			newText, _ := editMap["newText"].(string) // newText can be empty
+		maxReferences := tools.MaxReferences()
+		switch v := request.Params.Arguments["maxReferences"].(type) {
+		case float64:
+			maxReferences = int(v)
+		case int:
+			maxReferences = v
+		}
+		if maxReferences < 1 {
+			return mcp.NewToolResultError("maxReferences must be a positive number"), nil
+		}
 
-			edits = append(edits, tools.TextEdit{
-				StartLine: int(startLine),
-				EndLine:   int(endLine),
-				NewText:   newText,
-			})
+		var skip int
+		switch v := request.Params.Arguments["skip"].(type) {
+		case float64:
+			skip = int(v)
+		case int:
+			skip = v
+		}
+		if skip < 0 {
+			return mcp.NewToolResultError("skip must be non-negative"), nil
+		}
+
+		// Both path lists are optional arrays of globs
+		stringsArg := func(name string) ([]string, bool) {
+			items, ok := request.Params.Arguments[name].([]any)
+			if !ok {
+				return nil, request.Params.Arguments[name] == nil
+			}
+			var values []string
+			for _, item := range items {
+				value, ok := item.(string)
+				if !ok {
+					return nil, false
+				}
+				values = append(values, value)
+			}
+			return values, true
+		}
+		filter := tools.PathFilter{Root: s.config.workspaceDir}
+		if filter.Include, ok = stringsArg("includePaths"); !ok {
+			return mcp.NewToolResultError("includePaths must be an array of strings"), nil
+		}
+		if filter.Exclude, ok = stringsArg("excludePaths"); !ok {
+			return mcp.NewToolResultError("excludePaths must be an array of strings"), nil
+		}
+		if err := filter.Validate(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing references for symbol: %s skip: %d maxReferences: %d", symbolName, skip, maxReferences)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.FindReferencesPage(toolCtx, s.client(), symbolName, filter, skip, maxReferences)
+		if err != nil {
+			coreLogger.Error("Failed to find references: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerApplyWorkspaceEditTool() {
+	applyWorkspaceEditTool := mcp.NewTool("apply_workspace_edit",
+		mcp.WithDescription("Apply an LSP WorkspaceEdit, e.g. one from a code action, to files on disk. Supports both 'changes' and 'documentChanges' (text edits and file create/rename/delete). Positions are 0-indexed as in LSP. The whole edit is validated first and nothing is written if any file is missing or a range is out of bounds."),
+		mcp.WithObject("edit",
+			mcp.Required(),
+			mcp.Description("The WorkspaceEdit as a JSON object with 'changes' (a map from file URI to text edits) or 'documentChanges'"),
+		),
+	)
+
+	s.addTool(applyWorkspaceEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Accept the edit as an object or as a JSON string
+		var raw []byte
+		switch v := request.Params.Arguments["edit"].(type) {
+		case string:
+			raw = []byte(v)
+		case map[string]any:
+			var err error
+			if raw, err = json.Marshal(v); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid edit: %v", err)), nil
+			}
+		default:
+			return mcp.NewToolResultError("edit must be a WorkspaceEdit object"), nil
+		}
+
+		var edit protocol.WorkspaceEdit
+		if err := json.Unmarshal(raw, &edit); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid WorkspaceEdit: %v", err)), nil
+		}
+
+		coreLogger.Debug("Executing apply_workspace_edit")
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ApplyWorkspaceEdit(toolCtx, s.client(), edit)
+		if err != nil {
+			coreLogger.Error("Failed to apply workspace edit: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply workspace edit: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerDiagnosticsTool() {
+	getDiagnosticsTool := mcp.NewTool("diagnostics",
+		mcp.WithDescription("Get diagnostic information for a specific file from the language server."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get diagnostics for"),
+		),
+		mcp.WithBoolean("contextLines",
+			mcp.Description("Lines to include around each diagnostic."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("showLineNumbers",
+			mcp.Description("If true, adds line numbers to the output"),
+			mcp.DefaultBool(true),
+		),
+		withFormat(),
+	)
+
+	s.addTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		contextLines := 5 // default value
+		if contextLinesArg, ok := request.Params.Arguments["contextLines"].(int); ok {
+			contextLines = contextLinesArg
+		}
+
+		showLineNumbers := true // default value
+		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
+			showLineNumbers = showLineNumbersArg
+		}
+
+		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetDiagnosticsForFileWithOptions(toolCtx, s.client(), filePath, tools.DiagnosticsOptions{
+			ContextLines:    contextLines,
+			ShowLineNumbers: showLineNumbers,
+			Format:          s.outputFormat(request),
+		})
+		if err != nil {
+			coreLogger.Error("Failed to get diagnostics: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerUnusedSymbolsTool() {
+	unusedSymbolsTool := mcp.NewTool("unused_symbols",
+		mcp.WithDescription("List the unused symbols of a file with their location and kind, e.g. to clean up dead code. Uses the server's diagnostics about unused variables and imports, and optionally checks the file's functions, types, variables and constants for references."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to check"),
+		),
+		mcp.WithBoolean("verifyReferences",
+			mcp.Description(fmt.Sprintf("If true, also list symbols the server doesn't report that have no references outside their own body. Sends a references request per symbol, for at most %d symbols. Requires references support.", tools.DefaultUnusedSymbolsVerifyLimit)),
+			mcp.DefaultBool(false),
+		),
+		withOffset(),
+	)
+
+	s.addTool(unusedSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		verifyReferences, _ := request.Params.Arguments["verifyReferences"].(bool)
+		if verifyReferences && !lsp.HasReferencesSupport(s.serverCapabilities()) {
+			return mcp.NewToolResultError("verifyReferences requires references support, which the language server does not advertise"), nil
+		}
+
+		coreLogger.Debug("Executing unused_symbols for file: %s verifyReferences: %v", filePath, verifyReferences)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetUnusedSymbols(toolCtx, s.client(), filePath, verifyReferences)
+		if err != nil {
+			coreLogger.Error("Failed to find unused symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find unused symbols: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerDiagnosticsGlobTool() {
+	diagnosticsGlobTool := mcp.NewTool("diagnostics_glob",
+		mcp.WithDescription("Get diagnostics for every file matching a glob pattern (e.g. 'internal/**/*.go'), aggregated into one report. Useful for checking a whole directory after a refactor."),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("Glob pattern relative to the workspace root. '**' matches any number of directories, '{a,b}' matches alternatives."),
+		),
+		mcp.WithNumber("maxFiles",
+			mcp.Description(fmt.Sprintf("Maximum number of files to check (default %d)", tools.DefaultMaxGlobFiles)),
+		),
+		mcp.WithBoolean("showLineNumbers",
+			mcp.Description("If true, adds line numbers to the output"),
+			mcp.DefaultBool(true),
+		),
+		withOffset(),
+	)
+
+	s.addTool(diagnosticsGlobTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		pattern, ok := request.Params.Arguments["pattern"].(string)
+		if !ok {
+			return mcp.NewToolResultError("pattern must be a string"), nil
+		}
+
+		maxFiles := tools.DefaultMaxGlobFiles
+		switch v := request.Params.Arguments["maxFiles"].(type) {
+		case float64:
+			maxFiles = int(v)
+		case int:
+			maxFiles = v
+		}
+
+		showLineNumbers := true // default value
+		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
+			showLineNumbers = showLineNumbersArg
+		}
+
+		coreLogger.Debug("Executing diagnostics_glob for pattern: %s", pattern)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetDiagnosticsForGlob(toolCtx, s.client(), s.config.workspaceDir, pattern, maxFiles, 5, showLineNumbers)
+		if err != nil {
+			coreLogger.Error("Failed to get diagnostics: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerGetCodeLensTool() {
+	getCodeLensTool := mcp.NewTool("get_codelens",
+		mcp.WithDescription("Get code lens hints for a given file from the language server."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get code lens information for"),
+		),
+	)
+
+	s.addTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing get_codelens for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetCodeLens(toolCtx, s.client(), filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get code lens: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerExecuteCodeLensTool() {
+	executeCodeLensTool := mcp.NewTool("execute_codelens",
+		mcp.WithDescription("Execute a code lens command for a given file and lens index."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the code lens to execute"),
+		),
+		mcp.WithNumber("index",
+			mcp.Required(),
+			mcp.Description("The index of the code lens to execute (from get_codelens output), 1 indexed"),
+		),
+	)
+
+	s.addTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for index due to JSON parsing
+		var index int
+		switch v := request.Params.Arguments["index"].(type) {
+		case float64:
+			index = int(v)
+		case int:
+			index = v
+		default:
+			return mcp.NewToolResultError("index must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ExecuteCodeLens(toolCtx, s.client(), filePath, index)
+		if err != nil {
+			coreLogger.Error("Failed to execute code lens: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerHoverTool() {
+	hoverTool := mcp.NewTool("hover",
+		mcp.WithDescription("Get hover information (type, documentation) for a symbol at the specified position. If the server reports it, the output starts with the range of the symbol the hover applies to."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get hover information for"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number where the hover is requested (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number where the hover is requested (1-indexed)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("'raw' to return the contents as sent by the server, usually markdown (default), or 'plaintext' to strip code fences, escapes and HTML entities"),
+			mcp.Enum(tools.HoverFormatRaw, tools.HoverFormatPlaintext),
+		),
+	)
+
+	s.addTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		opts := tools.HoverOptions{IncludeRange: true}
+		if formatArg, ok := request.Params.Arguments["format"].(string); ok {
+			opts.Format = formatArg
+		}
+
+		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetHoverInfoWithOptions(toolCtx, s.client(), filePath, line, column, opts)
+		if err != nil {
+			coreLogger.Error("Failed to get hover information: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerSymbolAtPositionTool() {
+	symbolAtPositionTool := mcp.NewTool("symbol_at_position",
+		mcp.WithDescription("Find the innermost symbol (function, method, class, etc.) containing a position, with its kind and the symbols it is nested in. Use it to turn a position into a symbol name for the tools that take one, such as definition and references."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the position"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the position (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the position (1-indexed)"),
+		),
+	)
+
+	s.addTool(symbolAtPositionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing symbol_at_position for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetSymbolAtPosition(toolCtx, s.client(), filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to find symbol at position: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find symbol at position: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerSyntaxTreeTool() {
+	syntaxTreeTool := mcp.NewTool("syntax_tree",
+		mcp.WithDescription("Show the syntax nodes enclosing a position, from the file's root to the innermost node, with their ranges. Parses the file locally with tree-sitter, so it works even when the language server lacks structural features such as selection ranges. Supports Go and C files."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to parse"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the position (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the position (1-indexed, counting bytes)"),
+		),
+	)
+
+	s.addTool(syntaxTreeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing syntax_tree for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetSyntaxTree(toolCtx, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get syntax tree: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get syntax tree: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerMacroExpansionTool() {
+	macroExpansionTool := mcp.NewTool("macro_expansion",
+		mcp.WithDescription("Show the definition of the C/C++ macro invoked at a position and what the invocation expands to (clangd only, expansions require clangd 15 or later)."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the macro invocation"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the macro invocation (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the macro name (1-indexed)"),
+		),
+	)
+
+	s.addTool(macroExpansionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing macro_expansion for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetMacroExpansion(toolCtx, s.client(), filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get macro expansion: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get macro expansion: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerRenameSymbolTool() {
+	renameSymbolTool := mcp.NewTool("rename_symbol",
+		mcp.WithDescription("Rename a symbol (variable, function, class, etc.) and update all references throughout the codebase. Identify the symbol either by position (filePath, line and column) or by symbolName."),
+		mcp.WithString("filePath",
+			mcp.Description("The path to the file containing the symbol to rename"),
+		),
+		mcp.WithNumber("line",
+			mcp.Description("The line number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Description("The column number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithString("symbolName",
+			mcp.Description("The name of the symbol to rename (e.g. 'mypackage.MyFunction', 'MyType.MyMethod'), used instead of a position. Must match exactly one definition."),
+		),
+		mcp.WithString("newName",
+			mcp.Required(),
+			mcp.Description("The new name for the symbol"),
+		),
+		mcp.WithBoolean("preview",
+			mcp.Description("If true, return the changes as unified diffs without modifying any files. Default is false."),
+		),
+		mcp.WithBoolean("includeComments",
+			mcp.Description("If true, also replace whole-word occurrences of the old name in comments of the renamed files. Heuristic: the changed occurrences are listed for review. Default is false."),
+		),
+		mcp.WithBoolean("includeStrings",
+			mcp.Description("If true, also replace whole-word occurrences of the old name in string literals of the renamed files. Heuristic: the changed occurrences are listed for review. Default is false."),
+		),
+	)
+
+	s.addTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		newName, ok := request.Params.Arguments["newName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newName must be a string"), nil
+		}
+
+		preview := false
+		if previewArg, ok := request.Params.Arguments["preview"].(bool); ok {
+			preview = previewArg
+		}
+
+		var opts tools.RenameOptions
+		if includeComments, ok := request.Params.Arguments["includeComments"].(bool); ok {
+			opts.IncludeComments = includeComments
+		}
+		if includeStrings, ok := request.Params.Arguments["includeStrings"].(bool); ok {
+			opts.IncludeStrings = includeStrings
+		}
+
+		// Rename by name if no position is given
+		if symbolName, ok := request.Params.Arguments["symbolName"].(string); ok && symbolName != "" {
+			if _, hasFilePath := request.Params.Arguments["filePath"]; !hasFilePath {
+				coreLogger.Debug("Executing rename_symbol for symbol: %s newName: %s preview: %v", symbolName, newName, preview)
+				toolCtx, cancel := s.toolContext(ctx)
+				defer cancel()
+				text, err := tools.RenameSymbolByName(toolCtx, s.client(), symbolName, newName, preview, opts)
+				if err != nil {
+					coreLogger.Error("Failed to rename symbol: %v", err)
+					return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+				}
+				return mcp.NewToolResultText(text), nil
+			}
+		}
+
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string, or provide symbolName instead"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s preview: %v", filePath, line, column, newName, preview)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		var text string
+		var err error
+		if preview {
+			text, err = tools.PreviewRenameSymbolWithOptions(toolCtx, s.client(), filePath, line, column, newName, opts)
+		} else {
+			text, err = tools.RenameSymbolWithOptions(toolCtx, s.client(), filePath, line, column, newName, opts)
+		}
+		if err != nil {
+			coreLogger.Error("Failed to rename symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerReplaceSymbolReferencesTool() {
+	replaceReferencesTool := mcp.NewTool("replace_symbol_references",
+		mcp.WithDescription("Replace every reference to the symbol at the specified position with new text. This is a TEXTUAL replacement of the locations reported by the language server, not a semantic rename: nothing checks that the result is valid. Use it when rename_symbol refuses a change, e.g. to replace a name with an expression. Prefer rename_symbol otherwise."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the symbol"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithString("newText",
+			mcp.Required(),
+			mcp.Description("The literal text to put in place of each reference"),
+		),
+		mcp.WithBoolean("includeDeclaration",
+			mcp.Description("If true, the declaration of the symbol is replaced as well. Default is true."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithBoolean("preview",
+			mcp.Description("If true, return the changes as unified diffs without modifying any files. Default is false."),
+		),
+	)
+
+	s.addTool(replaceReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		newText, ok := request.Params.Arguments["newText"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newText must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		includeDeclaration := true
+		if includeArg, ok := request.Params.Arguments["includeDeclaration"].(bool); ok {
+			includeDeclaration = includeArg
+		}
+
+		preview := false
+		if previewArg, ok := request.Params.Arguments["preview"].(bool); ok {
+			preview = previewArg
+		}
+
+		coreLogger.Debug("Executing replace_symbol_references for file: %s line: %d column: %d newText: %s", filePath, line, column, newText)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ReplaceSymbolReferences(toolCtx, s.client(), filePath, line, column, newText, includeDeclaration, preview)
+		if err != nil {
+			coreLogger.Error("Failed to replace references: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to replace references: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerCodeActionsTool() {
+	codeActionsTool := mcp.NewTool("code_actions",
+		mcp.WithDescription("Get available code actions (quick fixes, refactorings) for a range"),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("Start line (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("Start column (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("End line (1-indexed)"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Required(),
+			mcp.Description("End column (1-indexed)"),
+		),
+	)
+
+	s.addTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for all numeric parameters due to JSON parsing
+		var startLine, startColumn, endLine, endColumn int
+
+		switch v := request.Params.Arguments["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["startColumn"].(type) {
+		case float64:
+			startColumn = int(v)
+		case int:
+			startColumn = v
+		default:
+			return mcp.NewToolResultError("startColumn must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		default:
+			return mcp.NewToolResultError("endLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endColumn"].(type) {
+		case float64:
+			endColumn = int(v)
+		case int:
+			endColumn = v
+		default:
+			return mcp.NewToolResultError("endColumn must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing code_actions for file: %s range: (%d,%d) to (%d,%d)", filePath, startLine, startColumn, endLine, endColumn)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetCodeActions(toolCtx, s.client(), filePath, startLine, startColumn, endLine, endColumn)
+		if err != nil {
+			coreLogger.Error("Failed to get code actions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerFileCodeActionsTool() {
+	fileCodeActionsTool := mcp.NewTool("code_actions_for_file",
+		mcp.WithDescription("List the code actions (quick fixes) available for every diagnostic in a file, grouped by the diagnostic they address. Actions offered for several diagnostics are listed once."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		withOffset(),
+	)
+
+	s.addTool(fileCodeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing code_actions_for_file for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetCodeActionsForFile(toolCtx, s.client(), filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get code actions for file: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions for file: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerExtractTool() {
+	extractTool := mcp.NewTool("extract",
+		mcp.WithDescription("Extract a range of code into a new function, method, variable or constant using the language server's extract refactorings, optionally naming the new symbol. If several extractions apply they are listed; call again with 'action' to pick one."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("Start line (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("Start column (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("End line (1-indexed)"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Required(),
+			mcp.Description("End column (1-indexed)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name for the extracted symbol. If omitted the server's default name is kept."),
+		),
+		mcp.WithNumber("action",
+			mcp.Description("Which of the listed extract refactorings to apply (1-indexed). Not needed when only one applies."),
+		),
+	)
+
+	s.addTool(extractTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for all numeric parameters due to JSON parsing
+		var startLine, startColumn, endLine, endColumn int
+
+		switch v := request.Params.Arguments["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["startColumn"].(type) {
+		case float64:
+			startColumn = int(v)
+		case int:
+			startColumn = v
+		default:
+			return mcp.NewToolResultError("startColumn must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		default:
+			return mcp.NewToolResultError("endLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endColumn"].(type) {
+		case float64:
+			endColumn = int(v)
+		case int:
+			endColumn = v
+		default:
+			return mcp.NewToolResultError("endColumn must be a number"), nil
+		}
+
+		// action is optional
+		var action int
+		switch v := request.Params.Arguments["action"].(type) {
+		case float64:
+			action = int(v)
+		case int:
+			action = v
+		}
+
+		name, _ := request.Params.Arguments["name"].(string) // name is optional
+
+		coreLogger.Debug("Executing extract for file: %s range: (%d,%d) to (%d,%d) name: %s", filePath, startLine, startColumn, endLine, endColumn, name)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.Extract(toolCtx, s.client(), filePath, startLine, startColumn, endLine, endColumn, action, name)
+		if err != nil {
+			coreLogger.Error("Failed to extract: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to extract: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerRefactoringsTool() {
+	refactoringsTool := mcp.NewTool("refactorings",
+		mcp.WithDescription("List the refactorings (refactor.* code actions such as extract, inline or rewrite) available at a position, without having to pick an exact range. The innermost selection range at the position, such as the identifier or expression there, is used as the range."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number of the position (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("Column number of the position (1-indexed)"),
+		),
+	)
+
+	s.addTool(refactoringsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing edit_file for file: %s", filePath)
-		response, err := tools.ApplyTextEdits(s.ctx, s.lspClient, filePath, edits)
+		coreLogger.Debug("Executing refactorings for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ListRefactorings(toolCtx, s.client(), filePath, line, column)
 		if err != nil {
-			coreLogger.Error("Failed to apply edits: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
+			coreLogger.Error("Failed to list refactorings: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list refactorings: %v", err)), nil
 		}
-		return mcp.NewToolResultText(response), nil
+		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerDefinitionTool() {
-	readDefinitionTool := mcp.NewTool("definition",
-		mcp.WithDescription("Read the source code definition of a symbol (function, type, constant, etc.) from the codebase. Returns the complete implementation code where the symbol is defined."),
-		mcp.WithString("symbolName",
+func (s *mcpServer) registerOrganizeImportsTool() {
+	organizeImportsTool := mcp.NewTool("organize_imports",
+		mcp.WithDescription("Organize the imports of a file using the language server (add missing, remove unused and sort imports) and report the imports added and removed."),
+		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The name of the symbol whose definition you want to find (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+			mcp.Description("Path to the file"),
 		),
 	)
 
-	s.mcpServer.AddTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(organizeImportsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
-		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
-			return mcp.NewToolResultError("symbolName must be a string"), nil
+			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		coreLogger.Debug("Executing definition for symbol: %s", symbolName)
-		text, err := tools.ReadDefinition(s.ctx, s.lspClient, symbolName)
+		coreLogger.Debug("Executing organize_imports for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.OrganizeImports(toolCtx, s.client(), filePath)
 		if err != nil {
-			coreLogger.Error("Failed to get definition: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
+			coreLogger.Error("Failed to organize imports: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to organize imports: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerReferencesTool() {
-	findReferencesTool := mcp.NewTool("references",
-		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears."),
-		mcp.WithString("symbolName",
+func (s *mcpServer) registerFixAllTool() {
+	fixAllTool := mcp.NewTool("fix_all",
+		mcp.WithDescription("Apply the language server's fix-all code actions to a file, repeating until no fixable diagnostics remain, and report each fix applied."),
+		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("maxIterations",
+			mcp.Description(fmt.Sprintf("Maximum number of fix rounds (default %d)", tools.DefaultFixAllIterations)),
 		),
 	)
 
-	s.mcpServer.AddTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(fixAllTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
-		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
-			return mcp.NewToolResultError("symbolName must be a string"), nil
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// maxIterations is optional, FixAll applies the default
+		var maxIterations int
+		switch v := request.Params.Arguments["maxIterations"].(type) {
+		case float64:
+			maxIterations = int(v)
+		case int:
+			maxIterations = v
 		}
 
-		coreLogger.Debug("Executing references for symbol: %s", symbolName)
-		text, err := tools.FindReferences(s.ctx, s.lspClient, symbolName)
+		coreLogger.Debug("Executing fix_all for file: %s maxIterations: %d", filePath, maxIterations)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.FixAll(toolCtx, s.client(), filePath, maxIterations)
 		if err != nil {
-			coreLogger.Error("Failed to find references: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+			coreLogger.Error("Failed to fix all: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to fix all: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerDiagnosticsTool() {
-	getDiagnosticsTool := mcp.NewTool("diagnostics",
-		mcp.WithDescription("Get diagnostic information for a specific file from the language server."),
+func (s *mcpServer) registerSignatureHelpTool() {
+	var provider *protocol.SignatureHelpOptions
+	if caps := s.serverCapabilities(); caps != nil {
+		provider = caps.SignatureHelpProvider
+	}
+	var triggerCharacters, retriggerCharacters []string
+	if provider != nil {
+		triggerCharacters = provider.TriggerCharacters
+		retriggerCharacters = provider.RetriggerCharacters
+	}
+
+	signatureHelpTool := mcp.NewTool("signature_help",
+		mcp.WithDescription("Get function/method signature information at cursor position"),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file to get diagnostics for"),
+			mcp.Description("Path to the file"),
 		),
-		mcp.WithBoolean("contextLines",
-			mcp.Description("Lines to include around each diagnostic."),
-			mcp.DefaultBool(false),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number (1-indexed)"),
 		),
-		mcp.WithBoolean("showLineNumbers",
-			mcp.Description("If true, adds line numbers to the output"),
-			mcp.DefaultBool(true),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("Column number (1-indexed)"),
+		),
+		mcp.WithString("triggerKind",
+			mcp.Description("How signature help was triggered (default 'invoked', or 'triggerCharacter' if triggerCharacter is set)"),
+			mcp.Enum(tools.SignatureTriggerInvoked, tools.SignatureTriggerCharacter, tools.SignatureTriggerContentChange),
+		),
+		mcp.WithString("triggerCharacter",
+			mcp.Description(fmt.Sprintf("The character just typed before the position, e.g. '(' or ','. Supported: %s (re-trigger only: %s)",
+				strings.Join(triggerCharacters, " "), strings.Join(retriggerCharacters, " "))),
+		),
+		mcp.WithNumber("activeSignature",
+			mcp.Description("The signature (1-indexed, in the order listed) that was active in a previous call. Re-triggers signature help so the server keeps tracking that signature's active parameter, e.g. after typing a comma."),
 		),
 	)
 
-	s.mcpServer.AddTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		contextLines := 5 // default value
-		if contextLinesArg, ok := request.Params.Arguments["contextLines"].(int); ok {
-			contextLines = contextLinesArg
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
 		}
 
-		showLineNumbers := true // default value
-		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
-			showLineNumbers = showLineNumbersArg
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
-		text, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, filePath, contextLines, showLineNumbers)
+		// Trigger context is optional
+		var opts tools.SignatureHelpOptions
+		opts.TriggerKind, _ = request.Params.Arguments["triggerKind"].(string)
+		opts.TriggerCharacter, _ = request.Params.Arguments["triggerCharacter"].(string)
+		switch v := request.Params.Arguments["activeSignature"].(type) {
+		case float64:
+			opts.ActiveSignature = int(v)
+		case int:
+			opts.ActiveSignature = v
+		}
+
+		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d options: %+v", filePath, line, column, opts)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetSignatureHelp(toolCtx, s.client(), filePath, line, column, opts, provider)
 		if err != nil {
-			coreLogger.Error("Failed to get diagnostics: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
+			coreLogger.Error("Failed to get signature help: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerGetCodeLensTool() {
-	getCodeLensTool := mcp.NewTool("get_codelens",
-		mcp.WithDescription("Get code lens hints for a given file from the language server."),
+func (s *mcpServer) registerDocumentSymbolsTool() {
+	documentSymbolsTool := mcp.NewTool("document_symbols",
+		mcp.WithDescription("Get the hierarchical symbol outline of a file (classes, functions, methods, etc.)"),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file to get code lens information for"),
+			mcp.Description("Path to the file to get symbols for"),
+		),
+		mcp.WithString("detail",
+			mcp.Description("'outline' for names and ranges only (default), 'signatures' to add the declaration line of each symbol, or 'full' to add the source of each symbol"),
+			mcp.Enum(tools.SymbolDetailOutline, tools.SymbolDetailSignatures, tools.SymbolDetailFull),
+		),
+		mcp.WithArray("kinds",
+			mcp.Description("Only show symbols of these kinds, e.g. ['function', 'class', 'method']"),
+			mcp.Items(map[string]any{
+				"type": "string",
+			}),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Only show symbols nested at most this deep, where 1 is top-level symbols only (default: no limit)"),
+		),
+		mcp.WithBoolean("includeChildren",
+			mcp.Description("If true, symbols matching kinds are shown even when their parent does not match. Otherwise a parent filtered out by kinds hides its children."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("collapse",
+			mcp.Description("Zoomed out view of large files: at each level, only show this many symbols, those containing the most nested symbols, and count the hidden ones (default 0, show all)"),
+		),
+		mcp.WithString("nameFilter",
+			mcp.Description("Only show symbols whose name contains this (case-insensitive) and the symbols containing them, counting the hidden ones. Combines with collapse."),
+		),
+		mcp.WithBoolean("enrichDetail",
+			mcp.Description(fmt.Sprintf("If true, symbols the server reports without detail get the first line of their hover instead, e.g. their signature or type. Sends a hover request per symbol shown, for at most %d symbols. Requires hover support.", tools.DefaultEnrichDetailLimit)),
+			mcp.DefaultBool(false),
 		),
+		withFormat(),
+		withOffset(),
 	)
 
-	s.mcpServer.AddTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		coreLogger.Debug("Executing get_codelens for file: %s", filePath)
-		text, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
-		if err != nil {
-			coreLogger.Error("Failed to get code lens: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
+		var opts tools.DocumentSymbolsOptions
+		if detailArg, ok := request.Params.Arguments["detail"].(string); ok {
+			opts.Detail = detailArg
 		}
-		return mcp.NewToolResultText(text), nil
-	})
-}
 
-func (s *mcpServer) registerExecuteCodeLensTool() {
-	executeCodeLensTool := mcp.NewTool("execute_codelens",
-		mcp.WithDescription("Execute a code lens command for a given file and lens index."),
-		mcp.WithString("filePath",
-			mcp.Required(),
-			mcp.Description("The path to the file containing the code lens to execute"),
-		),
-		mcp.WithNumber("index",
-			mcp.Required(),
-			mcp.Description("The index of the code lens to execute (from get_codelens output), 1 indexed"),
-		),
-	)
+		if kindsArg, ok := request.Params.Arguments["kinds"].([]any); ok {
+			for _, kindArg := range kindsArg {
+				kindName, ok := kindArg.(string)
+				if !ok {
+					return mcp.NewToolResultError("kinds must be an array of strings"), nil
+				}
+				kind, err := tools.ParseSymbolKind(kindName)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				opts.Kinds = append(opts.Kinds, kind)
+			}
+		}
 
-	s.mcpServer.AddTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Extract arguments
-		filePath, ok := request.Params.Arguments["filePath"].(string)
-		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
+		switch v := request.Params.Arguments["maxDepth"].(type) {
+		case float64:
+			opts.MaxDepth = int(v)
+		case int:
+			opts.MaxDepth = v
+		}
+		if opts.MaxDepth < 0 {
+			return mcp.NewToolResultError("maxDepth must be non-negative"), nil
 		}
 
-		// Handle both float64 and int for index due to JSON parsing
-		var index int
-		switch v := request.Params.Arguments["index"].(type) {
+		if includeChildrenArg, ok := request.Params.Arguments["includeChildren"].(bool); ok {
+			opts.IncludeChildren = includeChildrenArg
+		}
+
+		switch v := request.Params.Arguments["collapse"].(type) {
 		case float64:
-			index = int(v)
+			opts.Collapse = int(v)
 		case int:
-			index = v
-		default:
-			return mcp.NewToolResultError("index must be a number"), nil
+			opts.Collapse = v
+		}
+		if opts.Collapse < 0 {
+			return mcp.NewToolResultError("collapse must be non-negative"), nil
 		}
 
-		coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
-		text, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
+		if nameFilterArg, ok := request.Params.Arguments["nameFilter"].(string); ok {
+			opts.NameFilter = nameFilterArg
+		}
+		opts.EnrichDetail, _ = request.Params.Arguments["enrichDetail"].(bool)
+		if opts.EnrichDetail && !lsp.HasHoverSupport(s.serverCapabilities()) {
+			return mcp.NewToolResultError("enrichDetail requires hover support, which the language server does not advertise"), nil
+		}
+		opts.Format = s.outputFormat(request)
+
+		coreLogger.Debug("Executing document_symbols for file: %s detail: %s", filePath, opts.Detail)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetDocumentSymbols(toolCtx, s.client(), filePath, opts)
 		if err != nil {
-			coreLogger.Error("Failed to execute code lens: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
+			coreLogger.Error("Failed to get document symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return truncatedResult(request, text), nil
 	})
 }
 
-func (s *mcpServer) registerHoverTool() {
-	hoverTool := mcp.NewTool("hover",
-		mcp.WithDescription("Get hover information (type, documentation) for a symbol at the specified position."),
+func (s *mcpServer) registerCallHierarchyTool() {
+	callHierarchyTool := mcp.NewTool("call_hierarchy",
+		mcp.WithDescription("Find incoming callers or outgoing callees for a symbol at the specified position."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file to get hover information for"),
+			mcp.Description("Path to the file containing the symbol"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
-			mcp.Description("The line number where the hover is requested (1-indexed)"),
+			mcp.Description("Line number (1-indexed)"),
 		),
 		mcp.WithNumber("column",
 			mcp.Required(),
-			mcp.Description("The column number where the hover is requested (1-indexed)"),
+			mcp.Description("Column number (1-indexed)"),
+		),
+		mcp.WithString("direction",
+			mcp.Required(),
+			mcp.Description("'incoming' for callers or 'outgoing' for callees"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("Number of levels of callers or callees to expand recursively (default 1, at most %d)", tools.MaxCallHierarchyDepth)),
 		),
+		mcp.WithString("format",
+			mcp.Description("'text' for an indented tree (default) or 'dot' for a Graphviz DOT graph of the calls, with edges from caller to callee"),
+		),
+		withOffset(),
 	)
 
-	s.mcpServer.AddTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(callHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
+		direction, ok := request.Params.Arguments["direction"].(string)
+		if !ok {
+			return mcp.NewToolResultError("direction must be a string"), nil
+		}
+
+		// Validate direction
+		if direction != "incoming" && direction != "outgoing" {
+			return mcp.NewToolResultError("direction must be 'incoming' or 'outgoing'"), nil
+		}
+
 		// Handle both float64 and int for line and column due to JSON parsing
 		var line, column int
 		switch v := request.Params.Arguments["line"].(type) {
@@ -303,47 +1997,81 @@ func (s *mcpServer) registerHoverTool() {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetHoverInfo(s.ctx, s.lspClient, filePath, line, column)
+		depth := 1
+		switch v := request.Params.Arguments["depth"].(type) {
+		case float64:
+			depth = int(v)
+		case int:
+			depth = v
+		}
+		if depth < 1 {
+			return mcp.NewToolResultError("depth must be a positive number"), nil
+		}
+		// Every level costs a request per caller or callee, so deep trees get expensive fast
+		depth = min(depth, tools.MaxCallHierarchyDepth)
+
+		format := "text"
+		if v, ok := request.Params.Arguments["format"].(string); ok && v != "" {
+			format = v
+		}
+		if format != "text" && format != "dot" {
+			return mcp.NewToolResultError("format must be 'text' or 'dot'"), nil
+		}
+
+		coreLogger.Debug("Executing call_hierarchy for file: %s line: %d column: %d direction: %s depth: %d format: %s", filePath, line, column, direction, depth, format)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		var text string
+		var err error
+		if format == "dot" {
+			text, err = tools.GetCallGraph(toolCtx, s.client(), filePath, line, column, direction, depth)
+		} else {
+			text, err = tools.GetCallHierarchy(toolCtx, s.client(), filePath, line, column, direction, depth)
+		}
 		if err != nil {
-			coreLogger.Error("Failed to get hover information: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
+			coreLogger.Error("Failed to get call hierarchy: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get call hierarchy: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return truncatedResult(request, text), nil
 	})
 }
 
-func (s *mcpServer) registerRenameSymbolTool() {
-	renameSymbolTool := mcp.NewTool("rename_symbol",
-		mcp.WithDescription("Rename a symbol (variable, function, class, etc.) at the specified position and update all references throughout the codebase."),
+func (s *mcpServer) registerTypeHierarchyTool() {
+	typeHierarchyTool := mcp.NewTool("type_hierarchy",
+		mcp.WithDescription("Find the supertypes (parents, implemented interfaces) or subtypes (implementations, derived classes) of a type at the specified position."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file containing the symbol to rename"),
+			mcp.Description("Path to the file containing the type"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
-			mcp.Description("The line number where the symbol is located (1-indexed)"),
+			mcp.Description("Line number (1-indexed)"),
 		),
 		mcp.WithNumber("column",
 			mcp.Required(),
-			mcp.Description("The column number where the symbol is located (1-indexed)"),
+			mcp.Description("Column number (1-indexed)"),
 		),
-		mcp.WithString("newName",
+		mcp.WithString("direction",
 			mcp.Required(),
-			mcp.Description("The new name for the symbol"),
+			mcp.Description("'supertypes' for parents or 'subtypes' for children"),
 		),
 	)
 
-	s.mcpServer.AddTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(typeHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		newName, ok := request.Params.Arguments["newName"].(string)
+		direction, ok := request.Params.Arguments["direction"].(string)
 		if !ok {
-			return mcp.NewToolResultError("newName must be a string"), nil
+			return mcp.NewToolResultError("direction must be a string"), nil
+		}
+
+		// Validate direction
+		if direction != "supertypes" && direction != "subtypes" {
+			return mcp.NewToolResultError("direction must be 'supertypes' or 'subtypes'"), nil
 		}
 
 		// Handle both float64 and int for line and column due to JSON parsing
@@ -366,100 +2094,103 @@ func (s *mcpServer) registerRenameSymbolTool() {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s", filePath, line, column, newName)
-		text, err := tools.RenameSymbol(s.ctx, s.lspClient, filePath, line, column, newName)
+		coreLogger.Debug("Executing type_hierarchy for file: %s line: %d column: %d direction: %s", filePath, line, column, direction)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetTypeHierarchy(toolCtx, s.client(), filePath, line, column, direction)
 		if err != nil {
-			coreLogger.Error("Failed to rename symbol: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+			coreLogger.Error("Failed to get type hierarchy: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type hierarchy: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerCodeActionsTool() {
-	codeActionsTool := mcp.NewTool("code_actions",
-		mcp.WithDescription("Get available code actions (quick fixes, refactorings) for a range"),
+func (s *mcpServer) registerCompletionsTool() {
+	var triggerCharacters []string
+	if caps := s.serverCapabilities(); caps != nil && caps.CompletionProvider != nil {
+		triggerCharacters = caps.CompletionProvider.TriggerCharacters
+	}
+
+	completionsTool := mcp.NewTool("completions",
+		mcp.WithDescription("Get code completion suggestions at the specified position, including the exact text each completion inserts."),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file"),
 		),
-		mcp.WithNumber("startLine",
+		mcp.WithNumber("line",
 			mcp.Required(),
-			mcp.Description("Start line (1-indexed)"),
+			mcp.Description("Line number (1-indexed)"),
 		),
-		mcp.WithNumber("startColumn",
+		mcp.WithNumber("column",
 			mcp.Required(),
-			mcp.Description("Start column (1-indexed)"),
+			mcp.Description("Column number (1-indexed)"),
 		),
-		mcp.WithNumber("endLine",
-			mcp.Required(),
-			mcp.Description("End line (1-indexed)"),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of completions to return (default 20)"),
 		),
-		mcp.WithNumber("endColumn",
-			mcp.Required(),
-			mcp.Description("End column (1-indexed)"),
+		mcp.WithString("filterPrefix",
+			mcp.Description("Only return completions matching this prefix (case-insensitive, exact prefix matches ranked first)"),
+		),
+		mcp.WithString("triggerCharacter",
+			mcp.Description(fmt.Sprintf("Request completion as if this character had just been typed before the position, e.g. '.' for member completion. Supported: %s", strings.Join(triggerCharacters, " "))),
 		),
 	)
 
-	s.mcpServer.AddTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(completionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
-		filePath, ok := request.Params.Arguments["filePath"].(string)
-		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
-		}
-
-		// Handle both float64 and int for all numeric parameters due to JSON parsing
-		var startLine, startColumn, endLine, endColumn int
-
-		switch v := request.Params.Arguments["startLine"].(type) {
-		case float64:
-			startLine = int(v)
-		case int:
-			startLine = v
-		default:
-			return mcp.NewToolResultError("startLine must be a number"), nil
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		switch v := request.Params.Arguments["startColumn"].(type) {
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
 		case float64:
-			startColumn = int(v)
+			line = int(v)
 		case int:
-			startColumn = v
+			line = v
 		default:
-			return mcp.NewToolResultError("startColumn must be a number"), nil
+			return mcp.NewToolResultError("line must be a number"), nil
 		}
 
-		switch v := request.Params.Arguments["endLine"].(type) {
+		switch v := request.Params.Arguments["column"].(type) {
 		case float64:
-			endLine = int(v)
+			column = int(v)
 		case int:
-			endLine = v
+			column = v
 		default:
-			return mcp.NewToolResultError("endLine must be a number"), nil
+			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		switch v := request.Params.Arguments["endColumn"].(type) {
+		// limit is optional, GetCompletions applies the default
+		var limit int
+		switch v := request.Params.Arguments["limit"].(type) {
 		case float64:
-			endColumn = int(v)
+			limit = int(v)
 		case int:
-			endColumn = v
-		default:
-			return mcp.NewToolResultError("endColumn must be a number"), nil
+			limit = v
 		}
 
-		coreLogger.Debug("Executing code_actions for file: %s range: (%d,%d) to (%d,%d)", filePath, startLine, startColumn, endLine, endColumn)
-		text, err := tools.GetCodeActions(s.ctx, s.lspClient, filePath, startLine, startColumn, endLine, endColumn)
+		filterPrefix, _ := request.Params.Arguments["filterPrefix"].(string)         // filterPrefix is optional
+		triggerCharacter, _ := request.Params.Arguments["triggerCharacter"].(string) // triggerCharacter is optional
+
+		coreLogger.Debug("Executing completions for file: %s line: %d column: %d filterPrefix: %s triggerCharacter: %s", filePath, line, column, filterPrefix, triggerCharacter)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetCompletions(toolCtx, s.client(), filePath, line, column, limit, filterPrefix, triggerCharacter, triggerCharacters)
 		if err != nil {
-			coreLogger.Error("Failed to get code actions: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+			coreLogger.Error("Failed to get completions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get completions: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerSignatureHelpTool() {
-	signatureHelpTool := mcp.NewTool("signature_help",
-		mcp.WithDescription("Get function/method signature information at cursor position"),
+func (s *mcpServer) registerMonikersTool() {
+	monikersTool := mcp.NewTool("monikers",
+		mcp.WithDescription("Get the stable cross-repository identifiers (monikers) of the symbol at the specified position. Useful for correlating the same symbol across repository boundaries."),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file"),
@@ -474,7 +2205,7 @@ func (s *mcpServer) registerSignatureHelpTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(monikersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
@@ -501,116 +2232,459 @@ func (s *mcpServer) registerSignatureHelpTool() {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetSignatureHelp(s.ctx, s.lspClient, filePath, line, column)
+		coreLogger.Debug("Executing monikers for file: %s line: %d column: %d", filePath, line, column)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetMonikers(toolCtx, s.client(), filePath, line, column)
 		if err != nil {
-			coreLogger.Error("Failed to get signature help: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
+			coreLogger.Error("Failed to get monikers: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get monikers: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerDocumentSymbolsTool() {
-	documentSymbolsTool := mcp.NewTool("document_symbols",
-		mcp.WithDescription("Get the hierarchical symbol outline of a file (classes, functions, methods, etc.)"),
+func (s *mcpServer) registerDocumentLinksTool() {
+	documentLinksTool := mcp.NewTool("document_links",
+		mcp.WithDescription("List all links in a file (import targets, URLs in comments) with their ranges and resolved targets. Useful for following an import to its source file."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("Path to the file to get symbols for"),
+			mcp.Description("Path to the file to get links for"),
 		),
 	)
 
-	s.mcpServer.AddTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(documentLinksTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		coreLogger.Debug("Executing document_symbols for file: %s", filePath)
-		text, err := tools.GetDocumentSymbols(s.ctx, s.lspClient, filePath)
+		coreLogger.Debug("Executing document_links for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetDocumentLinks(toolCtx, s.client(), filePath)
 		if err != nil {
-			coreLogger.Error("Failed to get document symbols: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
+			coreLogger.Error("Failed to get document links: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document links: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
-func (s *mcpServer) registerCallHierarchyTool() {
-	callHierarchyTool := mcp.NewTool("call_hierarchy",
-		mcp.WithDescription("Find incoming callers or outgoing callees for a symbol at the specified position."),
+func (s *mcpServer) registerDocumentColorsTool() {
+	documentColorsTool := mcp.NewTool("document_colors",
+		mcp.WithDescription("List the color literals in a file (CSS, theme configs, etc.) with their ranges and RGBA values."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("Path to the file containing the symbol"),
+			mcp.Description("Path to the file to get colors for"),
 		),
-		mcp.WithNumber("line",
-			mcp.Required(),
-			mcp.Description("Line number (1-indexed)"),
+		mcp.WithBoolean("presentations",
+			mcp.Description("If true, also list alternative representations of each color offered by the server (e.g. hex, rgb(), hsl())"),
+			mcp.DefaultBool(false),
 		),
-		mcp.WithNumber("column",
+	)
+
+	s.addTool(documentColorsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		presentations := false
+		if presentationsArg, ok := request.Params.Arguments["presentations"].(bool); ok {
+			presentations = presentationsArg
+		}
+
+		coreLogger.Debug("Executing document_colors for file: %s", filePath)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.GetDocumentColors(toolCtx, s.client(), filePath, presentations)
+		if err != nil {
+			coreLogger.Error("Failed to get document colors: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document colors: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerExecuteCommandTool() {
+	var commands []string
+	if caps := s.serverCapabilities(); caps != nil && caps.ExecuteCommandProvider != nil {
+		commands = caps.ExecuteCommandProvider.Commands
+	}
+
+	executeCommandTool := mcp.NewTool("execute_command",
+		mcp.WithDescription(fmt.Sprintf("Execute a language server specific command via workspace/executeCommand and apply any edits it makes. Available commands: %s", strings.Join(commands, ", "))),
+		mcp.WithString("command",
 			mcp.Required(),
-			mcp.Description("Column number (1-indexed)"),
+			mcp.Description("The command to execute, e.g. 'gopls.tidy'. Must be one of the commands advertised by the server."),
 		),
-		mcp.WithString("direction",
+		mcp.WithArray("arguments",
+			mcp.Description("JSON arguments to pass to the command"),
+		),
+	)
+
+	s.addTool(executeCommandTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		command, ok := request.Params.Arguments["command"].(string)
+		if !ok {
+			return mcp.NewToolResultError("command must be a string"), nil
+		}
+
+		var arguments []json.RawMessage
+		if argsArg, ok := request.Params.Arguments["arguments"]; ok && argsArg != nil {
+			args, ok := argsArg.([]any)
+			if !ok {
+				return mcp.NewToolResultError("arguments must be an array"), nil
+			}
+			for _, arg := range args {
+				raw, err := json.Marshal(arg)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid argument: %v", err)), nil
+				}
+				arguments = append(arguments, raw)
+			}
+		}
+
+		// The server may have registered commands or been restarted since the tool was added
+		var allowed []string
+		if caps := s.serverCapabilities(); caps != nil && caps.ExecuteCommandProvider != nil {
+			allowed = caps.ExecuteCommandProvider.Commands
+		}
+
+		coreLogger.Debug("Executing execute_command for command: %s", command)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.ExecuteCommand(toolCtx, s.client(), command, arguments, allowed)
+		if err != nil {
+			coreLogger.Error("Failed to execute command: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to execute command: %v", err)), nil
+		}
+		// Not truncated: paging through the output would mean running the command again
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerSetLogLevelTool() {
+	setLogLevelTool := mcp.NewTool("set_log_level",
+		mcp.WithDescription("Change the log verbosity of the MCP language server at runtime. Logs are written to stderr or the configured log file, never to the MCP transport."),
+		mcp.WithString("level",
 			mcp.Required(),
-			mcp.Description("'incoming' for callers or 'outgoing' for callees"),
+			mcp.Description("The minimum level to log: 'debug', 'info', 'warn' or 'error'"),
+		),
+		mcp.WithString("component",
+			mcp.Description("Only change the level of this component, e.g. 'core', 'lsp', 'wire', 'lsp-process', 'watcher' or 'tools'. Defaults to all components."),
 		),
 	)
 
-	s.mcpServer.AddTool(callHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mcpServer.AddTool(setLogLevelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
-		filePath, ok := request.Params.Arguments["filePath"].(string)
+		level, ok := request.Params.Arguments["level"].(string)
 		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
+			return mcp.NewToolResultError("level must be a string"), nil
 		}
 
-		direction, ok := request.Params.Arguments["direction"].(string)
+		component := ""
+		if componentArg, ok := request.Params.Arguments["component"].(string); ok {
+			component = componentArg
+		}
+
+		coreLogger.Debug("Executing set_log_level for level: %s component: %s", level, component)
+		text, err := tools.SetLogLevel(level, component)
+		if err != nil {
+			coreLogger.Error("Failed to set log level: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set log level: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerSetTraceTool() {
+	setTraceTool := mcp.NewTool("set_trace",
+		mcp.WithDescription("Turn on tracing in the language server ($/setTrace) to see how it handles requests, e.g. to debug why it returns unexpected results, without restarting it. The traces it sends ($/logTrace) are shown by server_logs. Tracing is off again after a restart."),
+		mcp.WithString("value",
+			mcp.Description("The trace level: 'off', 'messages' or 'verbose'. Omit it to show the current level."),
+		),
+	)
+
+	s.addTool(setTraceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		value, _ := request.Params.Arguments["value"].(string)
+
+		coreLogger.Debug("Executing set_trace for value: %s", value)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.SetTrace(toolCtx, s.client(), value)
+		if err != nil {
+			coreLogger.Error("Failed to set trace level: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set trace level: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerUpdateConfigurationTool() {
+	updateConfigurationTool := mcp.NewTool("update_configuration",
+		mcp.WithDescription("Change settings of the running language server with workspace/didChangeConfiguration, e.g. {\"gopls\": {\"staticcheck\": true}}, without restarting it. The settings are sent again when the server restarts. Which settings are accepted, and whether they apply live, depends on the server."),
+		mcp.WithObject("settings",
+			mcp.Required(),
+			mcp.Description("The settings as a JSON object, usually keyed by the server's section name, e.g. 'gopls', 'rust-analyzer' or 'python'"),
+		),
+		mcp.WithBoolean("refreshDiagnostics",
+			mcp.Description("If true, get the diagnostics of the open files again after the change and report how many each has"),
+			mcp.DefaultBool(false),
+		),
+	)
+
+	s.addTool(updateConfigurationTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Accept the settings as an object or as a JSON string
+		var settings map[string]any
+		switch v := request.Params.Arguments["settings"].(type) {
+		case string:
+			if err := json.Unmarshal([]byte(v), &settings); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid settings: %v", err)), nil
+			}
+		case map[string]any:
+			settings = v
+		default:
+			return mcp.NewToolResultError("settings must be a JSON object"), nil
+		}
+
+		refreshDiagnostics, _ := request.Params.Arguments["refreshDiagnostics"].(bool)
+
+		coreLogger.Debug("Executing update_configuration refreshDiagnostics: %v", refreshDiagnostics)
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		text, err := tools.UpdateConfiguration(toolCtx, s.client(), settings, refreshDiagnostics)
+		if err != nil {
+			coreLogger.Error("Failed to update configuration: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update configuration: %v", err)), nil
+		}
+
+		s.lspSettingsMu.Lock()
+		s.lspSettings = settings
+		s.lspSettingsMu.Unlock()
+		return mcp.NewToolResultText(text), nil
+	})
+}
+
+func (s *mcpServer) registerWorkspaceFoldersTool() {
+	workspaceFoldersTool := mcp.NewTool("workspace_folders",
+		mcp.WithDescription("List the workspace folders indexed by the language server, optionally adding or removing folders first. Use this to index other projects of a monorepo."),
+		mcp.WithArray("add",
+			mcp.Description("Directories to add as workspace folders, absolute or relative to the primary workspace folder"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("remove",
+			mcp.Description("Workspace folders to remove. The primary workspace folder cannot be removed."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(workspaceFoldersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		stringArray := func(name string) ([]string, bool) {
+			arg, ok := request.Params.Arguments[name]
+			if !ok {
+				return nil, true
+			}
+			values, ok := arg.([]any)
+			if !ok {
+				return nil, false
+			}
+			strs := make([]string, 0, len(values))
+			for _, value := range values {
+				str, ok := value.(string)
+				if !ok {
+					return nil, false
+				}
+				strs = append(strs, str)
+			}
+			return strs, true
+		}
+
+		add, ok := stringArray("add")
 		if !ok {
-			return mcp.NewToolResultError("direction must be a string"), nil
+			return mcp.NewToolResultError("add must be an array of strings"), nil
+		}
+		remove, ok := stringArray("remove")
+		if !ok {
+			return mcp.NewToolResultError("remove must be an array of strings"), nil
 		}
 
-		// Validate direction
-		if direction != "incoming" && direction != "outgoing" {
-			return mcp.NewToolResultError("direction must be 'incoming' or 'outgoing'"), nil
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+
+		coreLogger.Debug("Executing workspace_folders, add: %v remove: %v", add, remove)
+		text, err := tools.UpdateWorkspaceFolders(toolCtx, s.client(), add, remove)
+		if err != nil {
+			coreLogger.Error("Failed to update workspace folders: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update workspace folders: %v", err)), nil
 		}
+		return mcp.NewToolResultText(text), nil
+	})
+}
 
-		// Handle both float64 and int for line and column due to JSON parsing
-		var line, column int
-		switch v := request.Params.Arguments["line"].(type) {
+func (s *mcpServer) registerReadRangeTool() {
+	readRangeTool := mcp.NewTool("read_range",
+		mcp.WithDescription("Read a range of lines from a file with line numbers. Use this to follow up on a file:line location reported by another tool (references, diagnostics, call hierarchy)."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file to read"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("First line to read (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("Last line to read (1-indexed, inclusive). Defaults to the end of the file."),
+		),
+		withFormat(),
+	)
+
+	s.addTool(readRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line numbers due to JSON parsing
+		var startLine, endLine int
+		switch v := request.Params.Arguments["startLine"].(type) {
 		case float64:
-			line = int(v)
+			startLine = int(v)
 		case int:
-			line = v
+			startLine = v
 		default:
-			return mcp.NewToolResultError("line must be a number"), nil
+			return mcp.NewToolResultError("startLine must be a number"), nil
 		}
-
-		switch v := request.Params.Arguments["column"].(type) {
+		switch v := request.Params.Arguments["endLine"].(type) {
 		case float64:
-			column = int(v)
+			endLine = int(v)
 		case int:
-			column = v
-		default:
-			return mcp.NewToolResultError("column must be a number"), nil
+			endLine = v
 		}
 
-		coreLogger.Debug("Executing call_hierarchy for file: %s line: %d column: %d direction: %s", filePath, line, column, direction)
-		text, err := tools.GetCallHierarchy(s.ctx, s.lspClient, filePath, line, column, direction)
+		coreLogger.Debug("Executing read_range for file: %s lines %d-%d", filePath, startLine, endLine)
+		text, err := tools.ReadFileRangeAs(filePath, startLine, endLine, s.outputFormat(request))
 		if err != nil {
-			coreLogger.Error("Failed to get call hierarchy: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get call hierarchy: %v", err)), nil
+			coreLogger.Error("Failed to read range: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read range: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 }
 
+func (s *mcpServer) registerServerLogsTool() {
+	serverLogsTool := mcp.NewTool("server_logs",
+		mcp.WithDescription("Show the most recent output the language server wrote to stderr or logged (window/logMessage), such as panics, crash reports and warnings. Use this to find out why other tools fail."),
+		mcp.WithNumber("lines",
+			mcp.Description(fmt.Sprintf("Number of lines to return (default %d, at most %d are kept)", tools.DefaultServerLogLines, lsp.DefaultServerLogLines)),
+		),
+	)
+
+	s.addTool(serverLogsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		var lines int
+		switch v := request.Params.Arguments["lines"].(type) {
+		case float64:
+			lines = int(v)
+		case int:
+			lines = v
+		}
+		if lines < 0 {
+			return mcp.NewToolResultError("lines must be non-negative"), nil
+		}
+
+		coreLogger.Debug("Executing server_logs for %d lines", lines)
+		return mcp.NewToolResultText(tools.GetServerLogs(s.client(), lines)), nil
+	})
+}
+
+func (s *mcpServer) registerCapabilitiesTool() {
+	capabilitiesTool := mcp.NewTool("capabilities",
+		mcp.WithDescription("Show the capabilities the language server negotiated (ServerCapabilities) as JSON, including those registered later, such as completion and signature help trigger characters, supported code action kinds, the commands execute_command accepts and the position encoding. Use this to decide which tools and parameters will work."),
+		mcp.WithString("section",
+			mcp.Description("Only return this top-level capability, e.g. 'completionProvider', 'codeActionProvider' or 'executeCommandProvider' (default: all)"),
+		),
+		withOffset(),
+	)
+
+	s.addTool(capabilitiesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		section, _ := request.Params.Arguments["section"].(string)
+
+		coreLogger.Debug("Executing capabilities for section: %s", section)
+		text, err := tools.GetCapabilities(s.client(), section)
+		if err != nil {
+			coreLogger.Error("Failed to get capabilities: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get capabilities: %v", err)), nil
+		}
+		return truncatedResult(request, text), nil
+	})
+}
+
+func (s *mcpServer) registerServerStatusTool() {
+	serverStatusTool := mcp.NewTool("server_status",
+		mcp.WithDescription("Show the language server, the operations it is currently reporting progress for, such as indexing, and messages it recently showed. Results of definitions, references and renames may be incomplete until indexing finishes."),
+	)
+
+	s.addTool(serverStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		coreLogger.Debug("Executing server_status")
+		return mcp.NewToolResultText(tools.GetServerStatus(s.client(), s.serverDescription(), s.fileExtensions(), lsp.KnownIssues(s.lspServerInfo()))), nil
+	})
+}
+
+func (s *mcpServer) registerStatsTool() {
+	statsTool := mcp.NewTool("stats",
+		mcp.WithDescription("Show how often each tool was called since the MCP server started, how often it failed and how long it took on average, split into the time spent waiting for the language server and the time spent formatting. Use this to find out which operations are slow with this server."),
+	)
+
+	s.addTool(statsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		coreLogger.Debug("Executing stats")
+		return mcp.NewToolResultText(s.stats()), nil
+	})
+}
+
+func (s *mcpServer) registerRestartTool() {
+	restartTool := mcp.NewTool(restartToolName,
+		mcp.WithDescription("Restart the language server, e.g. after it crashed, hangs or reports stale results. Files that were open in the old server are reopened in the new one."),
+	)
+
+	s.addTool(restartTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		coreLogger.Debug("Executing %s", restartToolName)
+		reopened, err := s.restartLSP("requested by the " + restartToolName + " tool")
+		if err != nil {
+			coreLogger.Error("Failed to restart language server: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to restart language server: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Restarted %s and reopened %d files.", s.serverDescription(), reopened)), nil
+	})
+}
+
 func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	// Handle nil capabilities gracefully
 	if caps == nil {
-		coreLogger.Warn("No server capabilities provided - registering minimal tool set")
+		coreLogger.Info("No server capabilities known yet - registering the tools that don't depend on them")
 		s.registerEditFileTool()
+		s.registerEditFilesTool()
+		s.registerApplyWorkspaceEditTool()
 		s.registerDiagnosticsTool()
+		s.registerDiagnosticsGlobTool()
+		s.registerSetLogLevelTool()
+		s.registerSetTraceTool()
+		s.registerUpdateConfigurationTool()
+		s.registerWorkspaceFoldersTool()
+		s.registerReadRangeTool()
+		s.registerServerLogsTool()
+		s.registerServerStatusTool()
+		s.registerStatsTool()
+		s.registerCapabilitiesTool()
+		s.registerRestartTool()
+		if tools.SyntaxTreeSupported {
+			s.registerSyntaxTreeTool()
+		}
 		return nil
 	}
 
@@ -621,31 +2695,70 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	coreLogger.Info("Hover: %v", lsp.HasHoverSupport(caps))
 	coreLogger.Info("Rename: %v", lsp.HasRenameSupport(caps))
 	coreLogger.Info("Code Actions: %v", lsp.HasCodeActionSupport(caps))
+	coreLogger.Info("Organize Imports: %v", lsp.HasCodeActionKindSupport(caps, protocol.SourceOrganizeImports))
+	coreLogger.Info("Fix All: %v", lsp.HasCodeActionKindSupport(caps, protocol.SourceFixAll))
 	coreLogger.Info("Code Lens: %v", lsp.HasCodeLensSupport(caps))
 	coreLogger.Info("Signature Help: %v", lsp.HasSignatureHelpSupport(caps))
+	coreLogger.Info("Completion: %v", lsp.HasCompletionSupport(caps))
+	coreLogger.Info("On Type Formatting: %v", lsp.HasOnTypeFormattingSupport(caps))
 	coreLogger.Info("Document Symbols: %v", lsp.HasDocumentSymbolSupport(caps))
 	coreLogger.Info("Call Hierarchy: %v", lsp.HasCallHierarchySupport(caps))
+	coreLogger.Info("Type Hierarchy: %v", lsp.HasTypeHierarchySupport(caps))
+	coreLogger.Info("Monikers: %v", lsp.HasMonikerSupport(caps))
+	coreLogger.Info("Document Links: %v", lsp.HasDocumentLinkSupport(caps))
+	coreLogger.Info("Document Colors: %v", lsp.HasDocumentColorSupport(caps))
+	coreLogger.Info("Execute Command: %v", lsp.HasExecuteCommandSupport(caps))
 	coreLogger.Info("Workspace Symbols: %v", lsp.HasWorkspaceSymbolSupport(caps))
 	coreLogger.Info("===============================")
 
 	// Always register core tools (capability-independent)
 	coreLogger.Debug("Registering core tools")
 	s.registerEditFileTool()
+	s.registerEditFilesTool()
+	s.registerApplyWorkspaceEditTool()
 	s.registerDiagnosticsTool()
+	s.registerDiagnosticsGlobTool()
+	s.registerSetLogLevelTool()
+	s.registerSetTraceTool()
+	s.registerUpdateConfigurationTool()
+	s.registerWorkspaceFoldersTool()
+	s.registerReadRangeTool()
+	s.registerServerLogsTool()
+	s.registerServerStatusTool()
+	s.registerStatsTool()
+	s.registerCapabilitiesTool()
+	s.registerRestartTool()
+
+	// Parsing locally needs no server capability, only tree-sitter in the build
+	if tools.SyntaxTreeSupported {
+		coreLogger.Debug("Registering 'syntax_tree' tool")
+		s.registerSyntaxTreeTool()
+	} else {
+		coreLogger.Info("Skipping 'syntax_tree' tool - requires building with -tags treesitter")
+	}
 
 	// Conditionally register capability-dependent tools
 	if lsp.HasDefinitionSupport(caps) {
-		coreLogger.Debug("Registering 'definition' tool")
+		coreLogger.Debug("Registering 'definition' and 'definitions_batch' tools")
 		s.registerDefinitionTool()
+		s.registerDefinitionsBatchTool()
 	} else {
-		coreLogger.Info("Skipping 'definition' tool - LSP server doesn't support Definition or WorkspaceSymbol capabilities")
+		coreLogger.Info("Skipping 'definition' and 'definitions_batch' tools - LSP server doesn't support Definition or WorkspaceSymbol capabilities")
 	}
 
 	if lsp.HasReferencesSupport(caps) {
-		coreLogger.Debug("Registering 'references' tool")
+		coreLogger.Debug("Registering 'references' and 'replace_symbol_references' tools")
 		s.registerReferencesTool()
+		s.registerReplaceSymbolReferencesTool()
 	} else {
-		coreLogger.Info("Skipping 'references' tool - LSP server doesn't support References capability")
+		coreLogger.Info("Skipping 'references' and 'replace_symbol_references' tools - LSP server doesn't support References capability")
+	}
+
+	if lsp.HasDefinitionSupport(caps) && lsp.HasReferencesSupport(caps) {
+		coreLogger.Debug("Registering 'symbol_overview' tool")
+		s.registerSymbolOverviewTool()
+	} else {
+		coreLogger.Info("Skipping 'symbol_overview' tool - LSP server doesn't support Definition, WorkspaceSymbol and References capabilities")
 	}
 
 	if lsp.HasHoverSupport(caps) {
@@ -655,6 +2768,13 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'hover' tool - LSP server doesn't support Hover capability")
 	}
 
+	if lsp.HasHoverSupport(caps) && s.isClangd() {
+		coreLogger.Debug("Registering 'macro_expansion' tool")
+		s.registerMacroExpansionTool()
+	} else {
+		coreLogger.Info("Skipping 'macro_expansion' tool - requires clangd with Hover capability")
+	}
+
 	if lsp.HasRenameSupport(caps) {
 		coreLogger.Debug("Registering 'rename_symbol' tool")
 		s.registerRenameSymbolTool()
@@ -665,8 +2785,18 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	if lsp.HasCodeActionSupport(caps) {
 		coreLogger.Debug("Registering 'code_actions' tool")
 		s.registerCodeActionsTool()
+		coreLogger.Debug("Registering 'code_actions_for_file' tool")
+		s.registerFileCodeActionsTool()
+		coreLogger.Debug("Registering 'extract' tool")
+		s.registerExtractTool()
+		coreLogger.Debug("Registering 'refactorings' tool")
+		s.registerRefactoringsTool()
+		coreLogger.Debug("Registering 'organize_imports' tool")
+		s.registerOrganizeImportsTool()
+		coreLogger.Debug("Registering 'fix_all' tool")
+		s.registerFixAllTool()
 	} else {
-		coreLogger.Info("Skipping 'code_actions' tool - LSP server doesn't support CodeAction capability")
+		coreLogger.Info("Skipping 'code_actions', 'code_actions_for_file', 'extract', 'refactorings', 'organize_imports' and 'fix_all' tools - LSP server doesn't support CodeAction capability")
 	}
 
 	if lsp.HasSignatureHelpSupport(caps) {
@@ -676,11 +2806,20 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'signature_help' tool - LSP server doesn't support SignatureHelp capability")
 	}
 
+	if lsp.HasCompletionSupport(caps) {
+		coreLogger.Debug("Registering 'completions' tool")
+		s.registerCompletionsTool()
+	} else {
+		coreLogger.Info("Skipping 'completions' tool - LSP server doesn't support Completion capability")
+	}
+
 	if lsp.HasDocumentSymbolSupport(caps) {
-		coreLogger.Debug("Registering 'document_symbols' tool")
+		coreLogger.Debug("Registering 'document_symbols', 'unused_symbols' and 'symbol_at_position' tools")
 		s.registerDocumentSymbolsTool()
+		s.registerUnusedSymbolsTool()
+		s.registerSymbolAtPositionTool()
 	} else {
-		coreLogger.Info("Skipping 'document_symbols' tool - LSP server doesn't support DocumentSymbol capability")
+		coreLogger.Info("Skipping 'document_symbols', 'unused_symbols' and 'symbol_at_position' tools - LSP server doesn't support DocumentSymbol capability")
 	}
 
 	if lsp.HasCallHierarchySupport(caps) {
@@ -690,6 +2829,41 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'call_hierarchy' tool - LSP server doesn't support CallHierarchy capability (requires LSP 3.16+)")
 	}
 
+	if lsp.HasTypeHierarchySupport(caps) {
+		coreLogger.Debug("Registering 'type_hierarchy' tool")
+		s.registerTypeHierarchyTool()
+	} else {
+		coreLogger.Info("Skipping 'type_hierarchy' tool - LSP server doesn't support TypeHierarchy capability (requires LSP 3.17+)")
+	}
+
+	if lsp.HasMonikerSupport(caps) {
+		coreLogger.Debug("Registering 'monikers' tool")
+		s.registerMonikersTool()
+	} else {
+		coreLogger.Info("Skipping 'monikers' tool - LSP server doesn't support Moniker capability (requires LSP 3.16+)")
+	}
+
+	if lsp.HasDocumentLinkSupport(caps) {
+		coreLogger.Debug("Registering 'document_links' tool")
+		s.registerDocumentLinksTool()
+	} else {
+		coreLogger.Info("Skipping 'document_links' tool - LSP server doesn't support DocumentLink capability")
+	}
+
+	if lsp.HasDocumentColorSupport(caps) {
+		coreLogger.Debug("Registering 'document_colors' tool")
+		s.registerDocumentColorsTool()
+	} else {
+		coreLogger.Info("Skipping 'document_colors' tool - LSP server doesn't support DocumentColor capability")
+	}
+
+	if lsp.HasExecuteCommandSupport(caps) {
+		coreLogger.Debug("Registering 'execute_command' tool")
+		s.registerExecuteCommandTool()
+	} else {
+		coreLogger.Info("Skipping 'execute_command' tool - LSP server doesn't support ExecuteCommand capability")
+	}
+
 	if lsp.HasCodeLensSupport(caps) {
 		coreLogger.Debug("Registering 'get_codelens' and 'execute_codelens' tools")
 		s.registerGetCodeLensTool()
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	lsptesting "github.com/isaacphi/mcp-language-server/internal/lsp/testing"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestServer returns an mcpServer whose language server is a mock that has not been
+// initialized yet
+func newTestServer(t *testing.T, toolTimeout time.Duration) (*mcpServer, *lsptesting.MockServer) {
+	t.Helper()
+	mock := lsptesting.NewMockServer()
+	t.Cleanup(func() { _ = mock.Close() })
+
+	s, err := newServer(&config{toolTimeout: toolTimeout})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(s.cancelFunc)
+	s.lspClient = mock.Client
+	s.mcpServer = server.NewMCPServer("test", "v0.0.0", server.WithToolCapabilities(true))
+	s.addTool(mcp.NewTool("probe"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("called"), nil
+	})
+	return s, mock
+}
+
+// callTool calls a tool through the MCP server with the context of the MCP request and
+// returns the text of its result and whether it is an error. It may be called from other
+// goroutines than the test's.
+func callTool(ctx context.Context, t *testing.T, s *mcpServer, name string) (string, bool) {
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "` + name + `", "arguments": {}}}`
+	response, ok := s.mcpServer.HandleMessage(ctx, json.RawMessage(request)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Errorf("Expected a result calling %s", name)
+		return "", true
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok || len(result.Content) == 0 {
+		t.Errorf("Unexpected result calling %s: %#v", name, response.Result)
+		return "", true
+	}
+	text, _ := result.Content[0].(mcp.TextContent)
+	return text.Text, result.IsError
+}
+
+func TestToolCallWaitsForInitialize(t *testing.T) {
+	s, mock := newTestServer(t, 5*time.Second)
+	mock.RespondRaw("initialize", `{"capabilities": {}}`)
+
+	type callResult struct {
+		text    string
+		isError bool
+	}
+	results := make(chan callResult, 1)
+	go func() {
+		text, isError := callTool(context.Background(), t, s, "probe")
+		results <- callResult{text, isError}
+	}()
+
+	select {
+	case result := <-results:
+		t.Fatalf("Tool call returned before the handshake: %q", result.text)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := mock.Client.InitializeLSPClient(context.Background(), t.TempDir()); err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+
+	select {
+	case result := <-results:
+		if result.isError || result.text != "called" {
+			t.Errorf("Expected the tool to be called after the handshake, got %q", result.text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Tool call still waiting after the handshake")
+	}
+}
+
+func TestToolCallBeforeInitializeTimesOut(t *testing.T) {
+	s, _ := newTestServer(t, 50*time.Millisecond)
+
+	text, isError := callTool(context.Background(), t, s, "probe")
+	if !isError || text != "language server still starting, try again shortly" {
+		t.Errorf("Expected a still starting error, got %q", text)
+	}
+}
+
+func TestToolCallCancelledByClient(t *testing.T) {
+	s, mock := newTestServer(t, time.Minute)
+	mock.RespondRaw("initialize", `{"capabilities": {}}`)
+	if _, err := mock.Client.InitializeLSPClient(context.Background(), t.TempDir()); err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+
+	ctx, cancelCall := context.WithCancel(context.Background())
+	defer cancelCall()
+	hoverReceived := make(chan struct{})
+	// Answer only once the call is gone and the client has had time to abandon the request.
+	// The mock reads the $/cancelRequest after the handler returns.
+	mock.Handle("textDocument/hover", func(json.RawMessage) (any, error) {
+		close(hoverReceived)
+		<-ctx.Done()
+		time.Sleep(100 * time.Millisecond)
+		return nil, nil
+	})
+	s.addTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolCtx, cancel := s.toolContext(ctx)
+		defer cancel()
+		if _, err := s.client().Hover(toolCtx, protocol.HoverParams{}); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText("hovered"), nil
+	})
+
+	results := make(chan string, 1)
+	go func() {
+		text, _ := callTool(ctx, t, s, "slow")
+		results <- text
+	}()
+
+	select {
+	case <-hoverReceived:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the tool to send a hover request")
+	}
+	cancelCall()
+
+	select {
+	case text := <-results:
+		if text != "context canceled" {
+			t.Errorf("Expected the tool call to be cancelled, got %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Tool call still running after it was cancelled")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(mock.Received("$/cancelRequest")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a $/cancelRequest for the abandoned hover request")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}